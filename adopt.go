@@ -0,0 +1,175 @@
+// GNU AFFERO GENERAL PUBLIC LICENSE
+// Version 3, 19 November 2007
+//
+// Copyright (C) 2025 John Kleijn
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// For more details, see the full AGPL-3.0 license at:
+// https://www.gnu.org/licenses/agpl-3.0.html
+
+package rtkv
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// AdoptTimestampFunc returns the lastModified time of an entity being
+// adopted, for example parsed from its value. Return a zero time to
+// fall back to OBJECT IDLETIME.
+type AdoptTimestampFunc func(ctx context.Context, id []string) (time.Time, error)
+
+// AdoptExisting takes over management of entities written by other
+// code: it SCANs for string keys matching pattern and adds those not
+// yet indexed to the lastModified index, the expiry index if they have
+// a TTL, and the secondary indexes enabled on the store. Use
+// WithNamespaceSeparator to match the key layout of that code. Keys
+// outside the namespace are ignored. Returns the number of entities
+// adopted.
+//
+// The lastModified time comes from timestampFn if set. Otherwise, it
+// is derived from OBJECT IDLETIME, which counts from the last access
+// rather than the last write, so it is approximate at best, and
+// unavailable when Redis uses an LFU eviction policy.
+//
+// Adoption is not atomic and can be run again to pick up keys written
+// in the meantime. Entities that are already indexed keep their time.
+func (r *RedisTKV) AdoptExisting(ctx context.Context, pattern string, timestampFn AdoptTimestampFunc) (int64, error) {
+	defer r.observe(ctx, "adoptExisting", time.Now())
+
+	if err := r.checkWritable(ctx); err != nil {
+		return 0, err
+	}
+
+	clients, err := r.scanClients(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	var adopted int64
+
+	for _, client := range clients {
+		var cursor uint64
+
+		for {
+			keys, next, err := client.ScanType(ctx, cursor, pattern, defaultIDScanCount, "string").Result()
+			if err != nil {
+				return adopted, fmt.Errorf("failed to scan keys: %w", err)
+			}
+
+			n, err := r.adoptKeys(ctx, keys, timestampFn)
+			adopted += n
+
+			if err != nil {
+				return adopted, err
+			}
+
+			if cursor = next; cursor == 0 {
+				break
+			}
+		}
+	}
+
+	return adopted, nil
+}
+
+// adoptKeys adopts the keys of a SCAN batch.
+func (r *RedisTKV) adoptKeys(ctx context.Context, keys []string, timestampFn AdoptTimestampFunc) (int64, error) {
+	index := r.namespacedKey(lastModifiedIdxSuffix)
+	candidates := keys[:0]
+
+	for _, key := range keys {
+		if strings.HasPrefix(key, r.keyPrefix()) && !isInternalID(r.idFromKey(key)) {
+			candidates = append(candidates, key)
+		}
+	}
+
+	if len(candidates) == 0 {
+		return 0, nil
+	}
+
+	scores := make([]*redis.FloatCmd, len(candidates))
+	ttls := make([]*redis.DurationCmd, len(candidates))
+	idle := make([]*redis.DurationCmd, len(candidates))
+	compacted := make([]*redis.BoolCmd, len(candidates))
+
+	_, err := r.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for i, key := range candidates {
+			scores[i] = pipe.ZScore(ctx, index, key)
+			ttls[i] = pipe.PTTL(ctx, key)
+			idle[i] = pipe.ObjectIdleTime(ctx, key)
+
+			if r.compaction {
+				compacted[i] = pipe.HExists(ctx, r.internalKey(compactedSuffix, membersSuffix), key)
+			}
+		}
+
+		return nil
+	})
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return 0, fmt.Errorf("failed to inspect keys: %w", err)
+	}
+
+	var fresh []int
+
+	for i := range candidates {
+		// Skip keys that are indexed or compacted already, or gone by now.
+		if scores[i].Err() != nil && ttls[i].Val() != -2 && //nolint:mnd // -2 means the key doesn't exist
+			(compacted[i] == nil || !compacted[i].Val()) {
+			fresh = append(fresh, i)
+		}
+	}
+
+	if len(fresh) == 0 {
+		return 0, nil
+	}
+
+	now := time.Now()
+
+	_, err = r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		r.writerAdd(ctx, pipe)
+
+		for _, i := range fresh {
+			key, id := candidates[i], r.idFromKey(candidates[i])
+
+			var lastModified time.Time
+
+			if timestampFn != nil {
+				if lastModified, err = timestampFn(ctx, id); err != nil {
+					return fmt.Errorf("failed to get lastModified time of %v: %w", id, err)
+				}
+			}
+
+			if lastModified.IsZero() {
+				lastModified = now.Add(-idle[i].Val())
+			}
+
+			r.expiryAdd(ctx, pipe, key, ttls[i].Val())
+			r.indexAdd(ctx, pipe, float64(lastModified.UnixNano()), key, id)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to adopt keys: %w", err)
+	}
+
+	return int64(len(fresh)), nil
+}
@@ -0,0 +1,82 @@
+// GNU AFFERO GENERAL PUBLIC LICENSE
+// Version 3, 19 November 2007
+//
+// Copyright (C) 2025 John Kleijn
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// For more details, see the full AGPL-3.0 license at:
+// https://www.gnu.org/licenses/agpl-3.0.html
+
+package rtkv_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/johnknl/rtkv"
+	"github.com/johnknl/rtkv/rtkvtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedisTKV_AdoptExisting(t *testing.T) {
+	ctx := context.Background()
+	mr, client := rtkvtest.NewMiniredis(t)
+	store := rtkv.NewRedisTKV(rtkv.DelimPipe, "legacy:users", client, rtkv.WithNamespaceSeparator(":"))
+	managed := time.Unix(1600000000, 0)
+	adopted := time.Unix(1700000000, 0)
+
+	_, err := store.Set(ctx, []byte("managed"), managed, "1")
+	require.NoError(t, err)
+	require.NoError(t, mr.Set("legacy:users:2", "two"))
+	require.NoError(t, mr.Set("legacy:users:3", "three"))
+	mr.SetTTL("legacy:users:3", time.Hour)
+	require.NoError(t, mr.Set("legacy:orders:1", "order"))
+
+	n, err := store.AdoptExisting(ctx, "legacy:*", func(_ context.Context, id []string) (time.Time, error) {
+		if id[0] == "3" {
+			return time.Time{}, nil
+		}
+
+		return adopted, nil
+	})
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, n, "Only unindexed keys in the namespace should be adopted")
+
+	records, total, err := store.FetchPageRecords(ctx, nil, nil, 0, 10)
+	require.NoError(t, err)
+	assert.EqualValues(t, 3, total)
+
+	times := map[string]time.Time{}
+
+	for record, err := range records {
+		require.NoError(t, err)
+
+		times[record.ID[0]] = record.LastModified
+	}
+
+	assert.Equal(t, managed, times["1"], "Indexed entities should keep their time")
+	assert.Equal(t, adopted, times["2"])
+	assert.WithinDuration(t, time.Now(), times["3"], time.Minute, "Should fall back to OBJECT IDLETIME")
+
+	expiring, err := client.ZRange(ctx, "legacy:users:\x00rtkv:expiry", 0, -1).Result()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"legacy:users:3"}, expiring)
+
+	n, err = store.AdoptExisting(ctx, "legacy:*", nil)
+	require.NoError(t, err)
+	assert.Zero(t, n, "Adopting again should be a no-op")
+}
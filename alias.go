@@ -0,0 +1,202 @@
+// GNU AFFERO GENERAL PUBLIC LICENSE
+// Version 3, 19 November 2007
+//
+// Copyright (C) 2025 John Kleijn
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// For more details, see the full AGPL-3.0 license at:
+// https://www.gnu.org/licenses/agpl-3.0.html
+
+package rtkv
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// aliasMarker prefixes the value of an alias,
+	// followed by the key of its target.
+	aliasMarker = valueHeader + "alias\x00"
+
+	// maxAliasHops is the number of aliases Get follows
+	// before giving up on a chain.
+	maxAliasHops = 8
+)
+
+var (
+	// ErrAliasLoop is returned when following aliases doesn't
+	// lead to an entity within a few hops.
+	ErrAliasLoop = errors.New("alias loop")
+
+	// ErrUnknownAliasTarget is returned when an alias refers to an
+	// entity of a namespace the store doesn't know the store of, so
+	// it can't decode its value.
+	ErrUnknownAliasTarget = errors.New("unknown alias target")
+)
+
+// aliasTargets are the stores of other namespaces aliases may
+// refer to, by the prefix of their keys.
+type aliasTargets struct {
+	mu     sync.RWMutex
+	stores map[string]*RedisTKV
+}
+
+// WithAliasTargets declares the stores of other namespaces aliases of
+// the store may refer to, so their values are decoded with the value
+// transforms of their own store. Alias declares its target store, so
+// this is only needed to read aliases created by another process.
+func WithAliasTargets(stores ...*RedisTKV) Option {
+	return func(r *RedisTKV) {
+		for _, store := range stores {
+			r.aliases.add(store)
+		}
+	}
+}
+
+func (a *aliasTargets) add(store *RedisTKV) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.stores == nil {
+		a.stores = make(map[string]*RedisTKV)
+	}
+
+	a.stores[store.keyPrefix()] = store
+}
+
+// find returns the store of key, which has the longest prefix
+// matching key, or nil if none does.
+func (a *aliasTargets) find(key string) *RedisTKV {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	var (
+		found  *RedisTKV
+		prefix string
+	)
+
+	for p, store := range a.stores {
+		if len(p) > len(prefix) && strings.HasPrefix(key, p) {
+			found, prefix = store, p
+		}
+	}
+
+	return found
+}
+
+// Alias makes the entity fromID an alias of the entity toID in the
+// store to, which may be this store or a store of another namespace
+// in the same Redis database. Get and BulkGet transparently return
+// the value of the target, following chains of aliases, which is
+// useful when merging entities or renumbering IDs. Aliases replace
+// the entity fromID, aren't part of the lastModified index and are
+// removed with Delete. Values of the target are decoded with the value
+// transforms of to; see WithAliasTargets.
+func (r *RedisTKV) Alias(ctx context.Context, fromID []string, to *RedisTKV, toID []string) error {
+	defer r.observe(ctx, "alias", time.Now())
+
+	if err := r.checkID(fromID); err != nil {
+		return err
+	}
+
+	if err := to.checkID(toID); err != nil {
+		return err
+	}
+
+	if err := r.checkWritable(ctx); err != nil {
+		return err
+	}
+
+	if to.keyPrefix() != r.keyPrefix() {
+		r.aliases.add(to)
+	}
+
+	key := r.namespacedKey(fromID...)
+
+	_, err := r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		r.writerAdd(ctx, pipe)
+		pipe.Set(ctx, key, aliasMarker+to.namespacedKey(toID...), 0)
+		r.indexRemove(ctx, pipe, key, fromID)
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to set alias: %w", err)
+	}
+
+	return r.compactedRemove(ctx, true, key)
+}
+
+// followAlias returns the value data refers to if it is an alias,
+// or data itself if it isn't, with the store the value belongs to.
+func (r *RedisTKV) followAlias(ctx context.Context, data []byte) (*RedisTKV, []byte, error) {
+	store := r
+
+	for range maxAliasHops {
+		target, ok := bytes.CutPrefix(data, []byte(aliasMarker))
+		if !ok {
+			return store, data, nil
+		}
+
+		key := string(target)
+
+		if store = r.aliasTarget(store, key); store == nil {
+			return nil, nil, fmt.Errorf("%w: %q", ErrUnknownAliasTarget, key)
+		}
+
+		var err error
+
+		data, err = r.reader(ctx).Get(ctx, key).Bytes()
+		if errors.Is(err, redis.Nil) {
+			return store, nil, nil
+		}
+
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to follow alias: %w", err)
+		}
+	}
+
+	if bytes.HasPrefix(data, []byte(aliasMarker)) {
+		return nil, nil, ErrAliasLoop
+	}
+
+	return store, data, nil
+}
+
+// aliasTarget returns the store of the entity key an alias read
+// from the store from refers to, or nil if it is unknown. Namespaces
+// may be nested, so the store with the longest key prefix wins.
+func (r *RedisTKV) aliasTarget(from *RedisTKV, key string) *RedisTKV {
+	var found *RedisTKV
+
+	for _, store := range []*RedisTKV{from, r} {
+		for _, candidate := range []*RedisTKV{store, store.aliases.find(key)} {
+			if candidate != nil && strings.HasPrefix(key, candidate.keyPrefix()) &&
+				(found == nil || len(candidate.keyPrefix()) > len(found.keyPrefix())) {
+				found = candidate
+			}
+		}
+	}
+
+	return found
+}
@@ -0,0 +1,123 @@
+// GNU AFFERO GENERAL PUBLIC LICENSE
+// Version 3, 19 November 2007
+//
+// Copyright (C) 2025 John Kleijn
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// For more details, see the full AGPL-3.0 license at:
+// https://www.gnu.org/licenses/agpl-3.0.html
+
+package rtkv_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/johnknl/rtkv"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedisTKV_Alias(t *testing.T) {
+	ctx := context.Background()
+	client := newGoRedisClient(0)
+	store := newRTKV(t, client)
+	other := rtkv.NewRedisTKV(rtkv.DelimUnit, t.Name()+"Other", client)
+
+	_, err := other.Set(ctx, []byte("merged"), time.Now(), "new", "1")
+	require.NoError(t, err)
+	_, err = store.Set(ctx, []byte("old"), time.Now(), "old", "1")
+	require.NoError(t, err)
+
+	require.NoError(t, store.Alias(ctx, []string{"old", "1"}, other, []string{"new", "1"}))
+	require.NoError(t, store.Alias(ctx, []string{"older", "1"}, store, []string{"old", "1"}))
+
+	value, err := store.Get(ctx, "older", "1")
+	require.NoError(t, err)
+	assert.Equal(t, "merged", string(value))
+
+	entries, err := store.BulkGet(ctx, [][]string{{"old", "1"}, {"missing"}})
+	require.NoError(t, err)
+	assert.Equal(t, "merged", string(entries[0].Data))
+	assert.False(t, entries[1].Exists)
+
+	count, err := store.Count(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, count, "Aliases should not be indexed")
+
+	require.NoError(t, store.Alias(ctx, []string{"a"}, store, []string{"b"}))
+	require.NoError(t, store.Alias(ctx, []string{"b"}, store, []string{"a"}))
+
+	_, err = store.Get(ctx, "a")
+	require.ErrorIs(t, err, rtkv.ErrAliasLoop)
+
+	require.NoError(t, store.Delete(ctx, "old", "1"))
+
+	value, err = store.Get(ctx, "older", "1")
+	require.NoError(t, err)
+	assert.Nil(t, value, "Dangling aliases should read as missing")
+}
+
+func TestRedisTKV_Alias_GetVersioned(t *testing.T) {
+	ctx := context.Background()
+	store := newRTKV(t, newGoRedisClient(0)).With(rtkv.WithVersioning())
+
+	_, err := store.Set(ctx, []byte("target"), time.Now(), "new")
+	require.NoError(t, err)
+	_, err = store.Set(ctx, []byte("target"), time.Now(), "new")
+	require.NoError(t, err)
+
+	require.NoError(t, store.Alias(ctx, []string{"old"}, store, []string{"new"}))
+
+	value, version, err := store.GetVersioned(ctx, "old")
+	require.NoError(t, err)
+	assert.Equal(t, "target", string(value), "Versioned reads should follow aliases")
+	assert.Zero(t, version, "The version should be that of the alias itself")
+
+	require.NoError(t, store.Delete(ctx, "new"))
+
+	value, _, err = store.GetVersioned(ctx, "old")
+	require.NoError(t, err)
+	assert.Nil(t, value, "Dangling aliases should read as missing")
+}
+
+func TestRedisTKV_Alias_Transforms(t *testing.T) {
+	ctx := context.Background()
+	client := newGoRedisClient(0)
+	keys := rtkv.StaticKeys{Current: "k1", Keys: map[string][]byte{"k1": bytes.Repeat([]byte{1}, 32)}}
+	store := newRTKV(t, client).With(rtkv.WithValueCompression(rtkv.ValueZstd, 0))
+	other := rtkv.NewRedisTKV(rtkv.DelimUnit, t.Name()+"Other", client, rtkv.WithEncryption(rtkv.EncryptionOptions{Keys: keys}))
+
+	_, err := other.Set(ctx, []byte("merged"), time.Now(), "new")
+	require.NoError(t, err)
+
+	require.NoError(t, store.Alias(ctx, []string{"old"}, other, []string{"new"}))
+	require.NoError(t, store.Alias(ctx, []string{"older"}, store, []string{"old"}))
+
+	for _, id := range []string{"old", "older"} {
+		value, err := store.Get(ctx, id)
+		require.NoError(t, err)
+		assert.Equal(t, "merged", string(value), "Targets should be decoded by their own store")
+	}
+
+	_, err = newRTKV(t, client).Get(ctx, "old")
+	require.ErrorIs(t, err, rtkv.ErrUnknownAliasTarget)
+
+	value, err := newRTKV(t, client).With(rtkv.WithAliasTargets(other)).Get(ctx, "old")
+	require.NoError(t, err)
+	assert.Equal(t, "merged", string(value))
+}
@@ -0,0 +1,195 @@
+// GNU AFFERO GENERAL PUBLIC LICENSE
+// Version 3, 19 November 2007
+//
+// Copyright (C) 2025 John Kleijn
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// For more details, see the full AGPL-3.0 license at:
+// https://www.gnu.org/licenses/agpl-3.0.html
+
+package rtkv
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+const (
+	defaultAnomalyAlpha       = 0.3
+	defaultAnomalySpikeFactor = 3
+	defaultAnomalyWarmup      = 5
+)
+
+// AnomalyKind is the kind of write rate anomaly detected.
+type AnomalyKind int
+
+const (
+	// AnomalyNone means the write rate is back to normal.
+	AnomalyNone AnomalyKind = iota
+
+	// AnomalySpike means writes spiked above the expected rate.
+	AnomalySpike
+
+	// AnomalyStall means writes stopped entirely.
+	AnomalyStall
+)
+
+// String returns a human readable name for the anomaly kind.
+func (k AnomalyKind) String() string {
+	switch k {
+	case AnomalyNone:
+		return "none"
+	case AnomalySpike:
+		return "spike"
+	case AnomalyStall:
+		return "stall"
+	default:
+		return "unknown"
+	}
+}
+
+// Anomaly describes a change in the state of the write rate.
+type Anomaly struct {
+	Kind      AnomalyKind
+	Namespace string
+	At        time.Time
+
+	// Rate is the observed rate and Expected the moving
+	// average of the rate, in writes per second.
+	Rate     float64
+	Expected float64
+}
+
+// AnomalyOptions configures a WriteRateDetector.
+type AnomalyOptions struct {
+	// Alpha is the smoothing factor of the exponentially weighted
+	// moving average of the write rate, between 0 and 1. Higher
+	// values adapt faster. Defaults to 0.3.
+	Alpha float64
+
+	// SpikeFactor is how many times the expected rate the observed
+	// rate must exceed to count as a spike. Defaults to 3.
+	SpikeFactor float64
+
+	// MinRate is the expected rate in writes per second below which
+	// a stall is not reported, for namespaces that are idle at times.
+	MinRate float64
+
+	// Warmup is the number of observations made before anomalies
+	// are reported. Defaults to 5.
+	Warmup int
+}
+
+// WriteRateDetector watches the rate of writes and deletes made
+// through a store and reports when it spikes or stops, catching
+// broken producers feeding the store. The callback is called when
+// an anomaly starts and again with AnomalyNone when it ends.
+//
+// Only writes made through the store in this process are counted.
+type WriteRateDetector struct {
+	opts      AnomalyOptions
+	onAnomaly func(Anomaly)
+
+	observations int
+	lastAt       time.Time
+	lastTotal    int64
+	expected     float64
+	state        AnomalyKind
+	mx           sync.Mutex
+}
+
+// NewWriteRateDetector creates a detector calling onAnomaly.
+func NewWriteRateDetector(opts AnomalyOptions, onAnomaly func(Anomaly)) *WriteRateDetector {
+	if opts.Alpha <= 0 || opts.Alpha > 1 {
+		opts.Alpha = defaultAnomalyAlpha
+	}
+
+	if opts.SpikeFactor <= 0 {
+		opts.SpikeFactor = defaultAnomalySpikeFactor
+	}
+
+	if opts.Warmup <= 0 {
+		opts.Warmup = defaultAnomalyWarmup
+	}
+
+	return &WriteRateDetector{
+		opts:      opts,
+		onAnomaly: onAnomaly,
+	}
+}
+
+// Task returns a janitor task that observes the write
+// statistics of the janitor's store.
+func (d *WriteRateDetector) Task() JanitorTask {
+	return func(_ context.Context, r *RedisTKV) error {
+		writes := r.Stats().Writes
+		d.Observe(r.namespace, time.Now(), writes.Sets+writes.Deletes)
+
+		return nil
+	}
+}
+
+// Observe records the total number of writes made by time at.
+// It is called by Task, but can be fed from other counters too.
+func (d *WriteRateDetector) Observe(namespace string, at time.Time, total int64) {
+	d.mx.Lock()
+
+	if d.lastAt.IsZero() || !at.After(d.lastAt) {
+		d.lastAt, d.lastTotal = at, total
+		d.mx.Unlock()
+
+		return
+	}
+
+	rate := float64(total-d.lastTotal) / at.Sub(d.lastAt).Seconds()
+	expected := d.expected
+	d.lastAt, d.lastTotal = at, total
+	d.observations++
+
+	state := AnomalyNone
+
+	switch {
+	case d.observations <= d.opts.Warmup:
+		state = d.state
+	case rate > expected*d.opts.SpikeFactor && rate > d.opts.MinRate:
+		state = AnomalySpike
+	case rate == 0 && expected > d.opts.MinRate:
+		state = AnomalyStall
+	}
+
+	// Keep anomalies out of the baseline, so a stall doesn't
+	// become the new normal.
+	switch {
+	case d.observations == 1:
+		d.expected = rate
+	case state == AnomalyNone:
+		d.expected = d.opts.Alpha*rate + (1-d.opts.Alpha)*d.expected
+	}
+
+	changed := state != d.state
+	d.state = state
+	d.mx.Unlock()
+
+	if changed && d.onAnomaly != nil {
+		d.onAnomaly(Anomaly{
+			Kind:      state,
+			Namespace: namespace,
+			At:        at,
+			Rate:      rate,
+			Expected:  expected,
+		})
+	}
+}
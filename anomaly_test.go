@@ -0,0 +1,72 @@
+// GNU AFFERO GENERAL PUBLIC LICENSE
+// Version 3, 19 November 2007
+//
+// Copyright (C) 2025 John Kleijn
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// For more details, see the full AGPL-3.0 license at:
+// https://www.gnu.org/licenses/agpl-3.0.html
+
+package rtkv_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/johnknl/rtkv"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteRateDetector(t *testing.T) {
+	var anomalies []rtkv.Anomaly
+
+	detector := rtkv.NewWriteRateDetector(rtkv.AnomalyOptions{Warmup: 3}, func(a rtkv.Anomaly) {
+		anomalies = append(anomalies, a)
+	})
+
+	start := time.Now()
+
+	var total int64
+
+	// 100 writes per second, then a spike, back to normal, then a stall.
+	for i, writes := range []int64{0, 100, 100, 100, 100, 1000, 100, 0, 0, 100} {
+		total += writes
+		detector.Observe("ns", start.Add(time.Duration(i)*time.Second), total)
+	}
+
+	require.Len(t, anomalies, 4)
+	assert.Equal(t, rtkv.AnomalySpike, anomalies[0].Kind)
+	assert.InDelta(t, 1000, anomalies[0].Rate, 0.001)
+	assert.InDelta(t, 100, anomalies[0].Expected, 0.001)
+	assert.Equal(t, rtkv.AnomalyNone, anomalies[1].Kind)
+	assert.Equal(t, rtkv.AnomalyStall, anomalies[2].Kind)
+	assert.Equal(t, rtkv.AnomalyNone, anomalies[3].Kind)
+}
+
+func TestWriteRateDetector_Task(t *testing.T) {
+	ctx := context.Background()
+	store := newRTKV(t, newGoRedisClient(0))
+	detector := rtkv.NewWriteRateDetector(rtkv.AnomalyOptions{Warmup: 1}, nil)
+	task := detector.Task()
+
+	require.NoError(t, task(ctx, store))
+
+	_, err := store.Set(ctx, []byte("v"), time.Now(), "a")
+	require.NoError(t, err)
+
+	require.NoError(t, task(ctx, store))
+}
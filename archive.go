@@ -0,0 +1,252 @@
+// GNU AFFERO GENERAL PUBLIC LICENSE
+// Version 3, 19 November 2007
+//
+// Copyright (C) 2025 John Kleijn
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// For more details, see the full AGPL-3.0 license at:
+// https://www.gnu.org/licenses/agpl-3.0.html
+
+package rtkv
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	defaultArchiveBatchSize = 500
+
+	// evictScript removes entities from the hot tier, but only
+	// if their lastModified score did not change since they were
+	// archived. It returns the keys of the entities removed.
+	evictScript = `
+local index = KEYS[1] -- the lastModified index
+
+local removed = {}
+
+for i = 1, #ARGV, 2 do
+  local member = ARGV[i]
+
+  local score = redis.call("ZSCORE", index, member)
+
+  if score and tonumber(score) == tonumber(ARGV[i + 1]) then
+    redis.call("DEL", member)
+    redis.call("ZREM", index, member)
+    removed[#removed + 1] = member
+  end
+end
+
+return removed
+`
+)
+
+// ArchiveSink receives entities moved out of the hot tier. A RedisTKV
+// for an archive namespace satisfies this interface, so entities can
+// be archived to another namespace or Redis instance directly.
+type ArchiveSink interface {
+	BulkSet(ctx context.Context, records []BulkSetRecord) error
+}
+
+// WithArchive makes Get fall back to the archive when an entity is
+// not found in the store, making archival transparent to readers.
+func WithArchive(archive Getter) Option {
+	return func(r *RedisTKV) {
+		r.archive = archive
+	}
+}
+
+// Archive moves all entities last modified before the cutoff to sink,
+// batchSize entities at a time. Entities are removed from the store
+// only after the sink accepted them, and only if they were not
+// modified in the meantime. Returns the number of entities moved.
+func (r *RedisTKV) Archive(ctx context.Context, before time.Time, sink ArchiveSink, batchSize int) (int, error) {
+	if err := r.checkWritable(ctx); err != nil {
+		return 0, err
+	}
+
+	if batchSize <= 0 {
+		batchSize = defaultArchiveBatchSize
+	}
+
+	key := r.namespacedKey(lastModifiedIdxSuffix)
+	rangeMax := "(" + strconv.FormatInt(before.UnixNano(), 10)
+
+	var archived int
+
+	budget := budgetFrom(ctx)
+
+	for {
+		if err := budget.spend(ctx, batchSize); err != nil {
+			return archived, err
+		}
+
+		entries, err := r.client.ZRangeByScoreWithScores(ctx, key, &redis.ZRangeBy{
+			Min:   "-inf",
+			Max:   rangeMax,
+			Count: int64(batchSize),
+		}).Result()
+		if err != nil {
+			return archived, fmt.Errorf("failed to read index: %w", err)
+		}
+
+		if len(entries) == 0 {
+			return archived, nil
+		}
+
+		n, err := r.archiveBatch(ctx, entries, sink)
+		archived += n
+
+		if err != nil {
+			return archived, err
+		}
+
+		if len(entries) < batchSize {
+			return archived, nil
+		}
+	}
+}
+
+// ArchiveTask returns a janitor task that archives entities
+// not modified for longer than maxAge to sink.
+func ArchiveTask(maxAge time.Duration, sink ArchiveSink, batchSize int) JanitorTask {
+	return func(ctx context.Context, r *RedisTKV) error {
+		_, err := r.Archive(ctx, time.Now().Add(-maxAge), sink, batchSize)
+
+		return err
+	}
+}
+
+func (r *RedisTKV) archiveBatch(ctx context.Context, entries []redis.Z, sink ArchiveSink) (int, error) {
+	keys := make([]string, len(entries))
+
+	for i := range entries {
+		keys[i] = entries[i].Member.(string)
+	}
+
+	values, err := r.mget(ctx, r.client, keys)
+	if err != nil {
+		return 0, fmt.Errorf("failed to execute mget: %w", err)
+	}
+
+	records := make([]BulkSetRecord, 0, len(entries))
+	args := make([]any, 0, 2*len(entries))
+
+	for i, value := range values {
+		if s, ok := value.(string); ok {
+			data, err := r.decodeValue([]byte(s))
+			if err != nil {
+				return 0, err
+			}
+
+			records = append(records, BulkSetRecord{
+				ID:           r.idFromKey(keys[i]),
+				Data:         data,
+				LastModified: time.Unix(0, int64(entries[i].Score)),
+			})
+		}
+
+		args = append(args, keys[i], strconv.FormatFloat(entries[i].Score, 'f', -1, 64))
+	}
+
+	if err = sink.BulkSet(ctx, records); err != nil {
+		return 0, fmt.Errorf("failed to write to archive: %w", err)
+	}
+
+	result, err := r.evalScript(ctx, evictScript, []string{r.namespacedKey(lastModifiedIdxSuffix)}, args...)
+	if err != nil {
+		return 0, fmt.Errorf("failed to evict archived entities: %w", err)
+	}
+
+	removed, ok := result.([]any)
+	if !ok {
+		return 0, ErrUnexpectedScriptResult
+	}
+
+	_, err = r.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for _, member := range removed {
+			key, _ := member.(string)
+			r.indexRemove(ctx, pipe, key, r.idFromKey(key))
+		}
+
+		return nil
+	})
+	if err != nil {
+		return len(removed), fmt.Errorf("failed to update indexes: %w", err)
+	}
+
+	return len(removed), nil
+}
+
+func (r *RedisTKV) getArchived(ctx context.Context, id []string) ([]byte, error) {
+	if r.archive == nil {
+		return nil, nil
+	}
+
+	data, err := getFrom(ctx, r.archive, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get entity from archive: %w", err)
+	}
+
+	if data != nil && r.restoreArchived {
+		if _, err = r.restore(ctx, data, id); err != nil {
+			r.logger.WarnContext(ctx, "failed to restore archived entity",
+				"namespace", r.namespace,
+				"error", err,
+			)
+		}
+	}
+
+	return data, nil
+}
+
+// writerSink writes archived entities to an io.Writer
+// as newline delimited JSON.
+type writerSink struct {
+	enc *json.Encoder
+	mx  sync.Mutex
+}
+
+// NewWriterSink returns an ArchiveSink that writes archived entities
+// to w in the same format as Export, so they can be loaded back
+// with Import.
+func NewWriterSink(w io.Writer) ArchiveSink {
+	return &writerSink{enc: json.NewEncoder(w)}
+}
+
+func (s *writerSink) BulkSet(_ context.Context, records []BulkSetRecord) error {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	var errs []error
+
+	for i := range records {
+		errs = append(errs, s.enc.Encode(snapshotRecord{
+			LastModified: records[i].LastModified,
+			ID:           records[i].ID,
+			Data:         records[i].Data,
+		}))
+	}
+
+	return errors.Join(errs...)
+}
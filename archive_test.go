@@ -0,0 +1,138 @@
+// GNU AFFERO GENERAL PUBLIC LICENSE
+// Version 3, 19 November 2007
+//
+// Copyright (C) 2025 John Kleijn
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// For more details, see the full AGPL-3.0 license at:
+// https://www.gnu.org/licenses/agpl-3.0.html
+
+package rtkv_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/johnknl/rtkv"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedisTKV_Archive(t *testing.T) {
+	ctx := context.Background()
+
+	redisClient := newGoRedisClient(0)
+
+	t.Cleanup(func() {
+		redisClient.FlushDB(ctx).Err()
+	})
+
+	archive := rtkv.NewRedisTKV(rtkv.DelimUnit, t.Name()+"archive", redisClient)
+	store := rtkv.NewRedisTKV(rtkv.DelimUnit, t.Name(), redisClient, rtkv.WithArchive(archive))
+	now := time.Now()
+
+	require.NoError(t, store.BulkSet(ctx, []rtkv.BulkSetRecord{
+		{Data: []byte(`{"id": "a"}`), ID: []string{"a", "a"}, LastModified: now.Add(-3 * time.Hour)},
+		{Data: []byte(`{"id": "b"}`), ID: []string{"a", "b"}, LastModified: now.Add(-2 * time.Hour)},
+		{Data: []byte(`{"id": "c"}`), ID: []string{"a", "c"}, LastModified: now},
+	}))
+
+	archived, err := store.Archive(ctx, now.Add(-time.Hour), archive, 1)
+
+	require.NoError(t, err)
+	assert.Equal(t, 2, archived)
+
+	_, total, err := store.FetchPage(ctx, nil, nil, 0, 10)
+
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, total, "Archived entities should leave the index")
+
+	_, total, err = archive.FetchPage(ctx, nil, nil, 0, 10)
+
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, total, "Archived entities should keep their lastModified time")
+
+	exists, err := store.Exists(ctx, "a", "a")
+
+	require.NoError(t, err)
+	assert.False(t, exists)
+
+	data, err := store.Get(ctx, "a", "a")
+
+	require.NoError(t, err)
+	assert.Equalf(t, []byte(`{"id": "a"}`), data, "Get should fall back to the archive")
+}
+
+func TestNewWriterSink(t *testing.T) {
+	ctx := context.Background()
+	store := goRedisSetup(t, 10)
+
+	var buf bytes.Buffer
+
+	archived, err := store.Archive(ctx, time.Now(), rtkv.NewWriterSink(&buf), 0)
+
+	require.NoError(t, err)
+	assert.Equal(t, 10, archived)
+
+	dec := json.NewDecoder(&buf)
+	lines := 0
+
+	for dec.More() {
+		var record struct {
+			ID   []string `json:"id"`
+			Data []byte   `json:"data"`
+		}
+
+		require.NoError(t, dec.Decode(&record))
+		assert.Len(t, record.ID, 2)
+		assert.NotEmpty(t, record.Data)
+
+		lines++
+	}
+
+	assert.Equal(t, 10, lines)
+}
+
+func TestRedisTKV_Archive_DecodesAndUnindexes(t *testing.T) {
+	ctx := context.Background()
+	client := newGoRedisClient(0)
+	archive := rtkv.NewRedisTKV(rtkv.DelimUnit, t.Name()+"archive", client)
+	store := rtkv.NewRedisTKV(rtkv.DelimUnit, t.Name(), client,
+		rtkv.WithValueCompression(rtkv.ValueZstd, 0),
+		rtkv.WithChildIndex(),
+	)
+
+	for _, s := range []*rtkv.RedisTKV{store, archive} {
+		_, err := s.Clear(ctx)
+		require.NoError(t, err)
+	}
+
+	_, err := store.Set(ctx, []byte("value"), time.Now().Add(-time.Hour), "parent", "child")
+	require.NoError(t, err)
+
+	archived, err := store.Archive(ctx, time.Now(), archive, 10)
+	require.NoError(t, err)
+	assert.Equal(t, 1, archived)
+
+	data, err := archive.Get(ctx, "parent", "child")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("value"), data, "The archive should receive decoded values")
+
+	assert.Zero(t, client.SCard(ctx, t.Name()+rtkv.DelimUnit+"\x00rtkv:childIdx"+rtkv.DelimUnit+"parent").Val(),
+		"Archived entities should leave the secondary indexes")
+}
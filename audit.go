@@ -0,0 +1,161 @@
+// GNU AFFERO GENERAL PUBLIC LICENSE
+// Version 3, 19 November 2007
+//
+// Copyright (C) 2025 John Kleijn
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// For more details, see the full AGPL-3.0 license at:
+// https://www.gnu.org/licenses/agpl-3.0.html
+
+package rtkv
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+const defaultAuditFreezeTTL = 10 * time.Minute
+
+// ErrVerificationFailed is returned by VerifiedExport when the
+// export doesn't match the frozen state of the namespace.
+var ErrVerificationFailed = errors.New("export verification failed")
+
+// VerifiedExportOptions controls VerifiedExport.
+type VerifiedExportOptions struct {
+	// Transfer controls the export itself.
+	Transfer TransferOptions
+
+	// FreezeTTL is how long writes are frozen at most, should the
+	// export not complete. Defaults to ten minutes.
+	FreezeTTL time.Duration
+
+	// Grace is how long to wait after freezing for writers to
+	// observe the freeze. It should be at least the interval
+	// passed to WithWriteFreeze by writers.
+	Grace time.Duration
+}
+
+// AuditManifest describes a verified export.
+type AuditManifest struct {
+	Namespace string    `json:"namespace"`
+	Frozen    time.Time `json:"frozen"`
+	Completed time.Time `json:"completed"`
+
+	// Records is the number of entities exported, which was
+	// verified against the frozen lastModified index.
+	Records int `json:"records"`
+
+	// Bytes and SHA256 are the size and SHA-256 hash of the export
+	// as written, so auditors can check the dump they received.
+	Bytes  int64  `json:"bytes"`
+	SHA256 string `json:"sha256"`
+}
+
+// VerifiedExport exports the namespace for audits. It freezes writes
+// to the namespace, see WithWriteFreeze, takes a SnapshotView, exports
+// all entities to w as with Export, and verifies that the export
+// holds exactly the entities of the view and that the namespace
+// didn't change while exporting. Writes are unfrozen afterwards.
+//
+// The returned manifest records counts and the hash of the export,
+// for auditors to attach to the dump.
+func (r *RedisTKV) VerifiedExport(
+	ctx context.Context,
+	w io.Writer,
+	opts VerifiedExportOptions,
+) (AuditManifest, error) {
+	defer r.observe(ctx, "verifiedExport", time.Now())
+
+	manifest := AuditManifest{Namespace: r.namespace}
+
+	if opts.FreezeTTL <= 0 {
+		opts.FreezeTTL = defaultAuditFreezeTTL
+	}
+
+	if err := r.Freeze(ctx, opts.FreezeTTL); err != nil {
+		return manifest, err
+	}
+
+	defer func() {
+		if err := r.Unfreeze(context.WithoutCancel(ctx)); err != nil {
+			r.logger.ErrorContext(ctx, "failed to unfreeze after export",
+				"namespace", r.namespace,
+				"error", err,
+			)
+		}
+	}()
+
+	select {
+	case <-time.After(opts.Grace):
+	case <-ctx.Done():
+		return manifest, fmt.Errorf("verified export interrupted: %w", context.Cause(ctx))
+	}
+
+	view, err := r.SnapshotView(ctx, SnapshotViewOptions{TTL: opts.FreezeTTL})
+	if err != nil {
+		return manifest, err
+	}
+
+	defer view.Release(context.WithoutCancel(ctx)) //nolint:errcheck // the view expires anyway
+
+	manifest.Frozen = view.Created()
+
+	expected, err := view.Count(ctx, nil, nil)
+	if err != nil {
+		return manifest, err
+	}
+
+	hash := sha256.New()
+	counter := &countingWriter{w: io.MultiWriter(w, hash)}
+
+	if manifest.Records, err = r.Export(ctx, counter, opts.Transfer); err != nil {
+		return manifest, err
+	}
+
+	manifest.Bytes = counter.n
+	manifest.SHA256 = hex.EncodeToString(hash.Sum(nil))
+
+	current, err := r.Count(ctx)
+	if err != nil {
+		return manifest, err
+	}
+
+	if int64(manifest.Records) != expected || current != expected {
+		return manifest, fmt.Errorf("%w: view holds %d entities, exported %d, namespace now holds %d",
+			ErrVerificationFailed, expected, manifest.Records, current)
+	}
+
+	manifest.Completed = time.Now()
+
+	return manifest, nil
+}
+
+// countingWriter counts the bytes written through it.
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+
+	return n, err //nolint:wrapcheck // transparent writer
+}
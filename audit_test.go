@@ -0,0 +1,131 @@
+// GNU AFFERO GENERAL PUBLIC LICENSE
+// Version 3, 19 November 2007
+//
+// Copyright (C) 2025 John Kleijn
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// For more details, see the full AGPL-3.0 license at:
+// https://www.gnu.org/licenses/agpl-3.0.html
+
+package rtkv_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+	"time"
+
+	"github.com/johnknl/rtkv"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithWriteFreeze(t *testing.T) {
+	ctx := context.Background()
+	store := newRTKV(t, newGoRedisClient(0))
+	writer := store.With(rtkv.WithWriteFreeze(0))
+
+	require.NoError(t, store.Freeze(ctx, time.Minute))
+
+	frozen, err := store.Frozen(ctx)
+	require.NoError(t, err)
+	assert.True(t, frozen)
+
+	_, err = writer.Set(ctx, []byte("a"), time.Now(), "a")
+	require.ErrorIs(t, err, rtkv.ErrFrozen)
+	require.ErrorIs(t, writer.Delete(ctx, "a"), rtkv.ErrFrozen)
+
+	_, err = store.Set(ctx, []byte("a"), time.Now(), "a")
+	require.NoError(t, err, "Stores without WithWriteFreeze should ignore freezes")
+
+	require.NoError(t, store.Unfreeze(ctx))
+
+	_, err = writer.Set(ctx, []byte("b"), time.Now(), "b")
+	require.NoError(t, err)
+}
+
+func TestWithWriteFreeze_Maintenance(t *testing.T) {
+	ctx := context.Background()
+	client := newGoRedisClient(0)
+	archive := rtkv.NewRedisTKV(rtkv.DelimUnit, t.Name()+"archive", client)
+	other := rtkv.NewRedisTKV(rtkv.DelimUnit, t.Name()+"other", client)
+	store := rtkv.NewRedisTKV(rtkv.DelimUnit, t.Name(), client,
+		rtkv.WithArchive(archive), rtkv.WithIndexCompaction(), rtkv.WithWriteFreeze(0))
+
+	_, err := archive.Set(ctx, []byte("a"), time.Now(), "a")
+	require.NoError(t, err)
+
+	require.NoError(t, store.Freeze(ctx, time.Minute))
+
+	_, err = store.Archive(ctx, time.Now(), archive, 0)
+	require.ErrorIs(t, err, rtkv.ErrFrozen)
+
+	_, err = store.RestoreFromArchive(ctx, "a")
+	require.ErrorIs(t, err, rtkv.ErrFrozen)
+
+	_, err = store.RemoveExpired(ctx)
+	require.ErrorIs(t, err, rtkv.ErrFrozen)
+
+	_, err = store.CompactIndex(ctx, time.Now(), rtkv.CompactOptions{})
+	require.ErrorIs(t, err, rtkv.ErrFrozen)
+
+	_, err = rtkv.SwapNamespaces(ctx, other, store)
+	require.ErrorIs(t, err, rtkv.ErrFrozen)
+
+	_, err = store.Clear(ctx)
+	require.ErrorIs(t, err, rtkv.ErrFrozen)
+
+	frozen, err := store.Frozen(ctx)
+	require.NoError(t, err)
+	assert.True(t, frozen, "Clear should not remove the freeze")
+}
+
+func TestRedisTKV_VerifiedExport(t *testing.T) {
+	ctx := context.Background()
+	store := newRTKV(t, newGoRedisClient(0)).With(rtkv.WithWriteFreeze(0))
+
+	var records []rtkv.BulkSetRecord
+
+	for _, id := range []string{"a", "b", "c"} {
+		records = append(records, rtkv.BulkSetRecord{LastModified: time.Now(), ID: []string{id}, Data: []byte(id)})
+	}
+
+	require.NoError(t, store.BulkSet(ctx, records))
+
+	var buf bytes.Buffer
+
+	manifest, err := store.VerifiedExport(ctx, &buf, rtkv.VerifiedExportOptions{})
+	require.NoError(t, err)
+
+	hash := sha256.Sum256(buf.Bytes())
+
+	assert.Equal(t, t.Name(), manifest.Namespace)
+	assert.Equal(t, 3, manifest.Records)
+	assert.EqualValues(t, buf.Len(), manifest.Bytes)
+	assert.Equal(t, hex.EncodeToString(hash[:]), manifest.SHA256)
+	assert.False(t, manifest.Frozen.After(manifest.Completed))
+
+	frozen, err := store.Frozen(ctx)
+	require.NoError(t, err)
+	assert.False(t, frozen, "The namespace should be unfrozen after the export")
+
+	dst := newRTKV(t, newGoRedisClient(1))
+
+	imported, err := dst.Import(ctx, &buf, rtkv.TransferOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, 3, imported)
+}
@@ -0,0 +1,381 @@
+// GNU AFFERO GENERAL PUBLIC LICENSE
+// Version 3, 19 November 2007
+//
+// Copyright (C) 2025 John Kleijn
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// For more details, see the full AGPL-3.0 license at:
+// https://www.gnu.org/licenses/agpl-3.0.html
+
+package rtkv
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"slices"
+	"time"
+)
+
+const (
+	manifestName = "manifest.json"
+
+	defaultChunkRecords = 10_000
+)
+
+// ErrChecksumMismatch is returned when a snapshot chunk
+// does not match the checksum recorded in its manifest.
+var ErrChecksumMismatch = errors.New("snapshot chunk checksum mismatch")
+
+// SnapshotSink stores the files that make up a snapshot.
+type SnapshotSink interface {
+	Put(ctx context.Context, name string, body io.Reader) error
+}
+
+// SnapshotSource reads the files that make up a snapshot.
+type SnapshotSource interface {
+	Get(ctx context.Context, name string) (io.ReadCloser, error)
+}
+
+// SnapshotStore is a snapshot sink and source that can also
+// list and delete files, as needed for snapshot retention.
+type SnapshotStore interface {
+	SnapshotSink
+	SnapshotSource
+
+	// List returns the names of all files whose name
+	// starts with prefix, in lexical order.
+	List(ctx context.Context, prefix string) ([]string, error)
+
+	// Delete removes a file.
+	Delete(ctx context.Context, name string) error
+}
+
+// SnapshotOptions controls ExportSnapshot.
+type SnapshotOptions struct {
+	// Transfer controls how entities are read from the store.
+	// Its State is not used; see State.
+	Transfer TransferOptions
+
+	// ChunkRecords is the maximum number of records per chunk
+	// file. Defaults to 10000.
+	ChunkRecords int
+
+	// State tracks progress. Pass the state of an interrupted export
+	// to resume it under the same name. Optional.
+	State *SnapshotState
+}
+
+// SnapshotState records the progress of ExportSnapshot. Records are
+// only counted as processed once they are in a stored chunk, so an
+// interrupted export resumes without losing or repeating any.
+type SnapshotState struct {
+	// Batch records the progress of reading entities.
+	Batch BatchState
+
+	// Part is the index of the next chunk file.
+	Part int
+
+	// Created is the time the export started.
+	Created time.Time
+
+	// Chunks describes the chunks stored so far.
+	Chunks []SnapshotChunk
+}
+
+// SnapshotManifest describes a snapshot. It is stored
+// alongside the chunks as manifest.json.
+type SnapshotManifest struct {
+	Version   int             `json:"version"`
+	Created   time.Time       `json:"created"`
+	Namespace string          `json:"namespace"`
+	Records   int             `json:"records"`
+	Chunks    []SnapshotChunk `json:"chunks"`
+}
+
+// SnapshotChunk describes a single gzip compressed chunk
+// of newline delimited JSON records.
+type SnapshotChunk struct {
+	Name    string `json:"name"`
+	Records int    `json:"records"`
+	SHA256  string `json:"sha256"`
+}
+
+// ExportSnapshot exports all entities to sink as a snapshot named
+// name: a set of gzip compressed, checksummed chunk files plus a
+// manifest describing them. The manifest is written last, so a
+// snapshot without a manifest is incomplete.
+//
+// When interrupted, the records read so far are stored in a last,
+// short chunk before returning, so passing opts.State again resumes
+// the export with the next chunk. Should that fail too, the state is
+// reset, and resuming starts over.
+func (r *RedisTKV) ExportSnapshot(
+	ctx context.Context,
+	sink SnapshotSink,
+	name string,
+	opts SnapshotOptions,
+) (SnapshotManifest, error) {
+	if opts.ChunkRecords <= 0 {
+		opts.ChunkRecords = defaultChunkRecords
+	}
+
+	state := opts.State
+	if state == nil {
+		state = &SnapshotState{}
+	}
+
+	if state.Created.IsZero() {
+		state.Created = time.Now()
+	}
+
+	opts.Transfer.State = &state.Batch
+
+	// pending holds the records of completed batches not stored yet.
+	var pending []snapshotRecord
+
+	err := r.transfer(ctx, opts.Transfer, func(ctx context.Context, records []snapshotRecord) error {
+		// Chunks are only committed to state along with the batch, so
+		// a failed batch is read again and its chunks overwritten.
+		records = append(slices.Clip(pending), records...)
+		part := state.Part
+
+		var chunks []SnapshotChunk
+
+		for len(records) >= opts.ChunkRecords {
+			chunk, err := storeChunk(ctx, sink, name, part, records[:opts.ChunkRecords])
+			if err != nil {
+				return err
+			}
+
+			chunks = append(chunks, chunk)
+			records = records[opts.ChunkRecords:]
+			part++
+		}
+
+		pending = records
+		state.Part = part
+		state.Chunks = append(state.Chunks, chunks...)
+
+		return nil
+	})
+
+	if len(pending) > 0 {
+		// Stores the records read so far even when interrupted by ctx.
+		chunk, flushErr := storeChunk(context.WithoutCancel(ctx), sink, name, state.Part, pending)
+		if flushErr != nil {
+			// The records read so far can't be stored, so
+			// resuming must read them again.
+			*state = SnapshotState{}
+
+			return SnapshotManifest{}, errors.Join(err, flushErr)
+		}
+
+		state.Part++
+		state.Chunks = append(state.Chunks, chunk)
+	}
+
+	manifest := SnapshotManifest{
+		Version:   SnapshotFormatVersion,
+		Created:   state.Created,
+		Namespace: r.namespace,
+		Chunks:    slices.Clone(state.Chunks),
+	}
+
+	for _, chunk := range manifest.Chunks {
+		manifest.Records += chunk.Records
+	}
+
+	if err != nil {
+		return manifest, err
+	}
+
+	body, err := json.Marshal(manifest)
+	if err != nil {
+		return manifest, fmt.Errorf("failed to encode manifest: %w", err)
+	}
+
+	if err = sink.Put(ctx, path.Join(name, manifestName), bytes.NewReader(body)); err != nil {
+		return manifest, fmt.Errorf("failed to store manifest: %w", err)
+	}
+
+	return manifest, nil
+}
+
+// storeChunk stores records as the chunk numbered part
+// of the snapshot named name.
+func storeChunk(
+	ctx context.Context,
+	sink SnapshotSink,
+	name string,
+	part int,
+	records []snapshotRecord,
+) (SnapshotChunk, error) {
+	chunk, err := newChunkWriter()
+	if err != nil {
+		return SnapshotChunk{}, err
+	}
+
+	for i := range records {
+		if err = chunk.write(records[i]); err != nil {
+			return SnapshotChunk{}, err
+		}
+	}
+
+	meta, body, err := chunk.finish(fmt.Sprintf("part-%05d.ndjson.gz", part))
+	if err != nil {
+		return SnapshotChunk{}, err
+	}
+
+	if err = sink.Put(ctx, path.Join(name, meta.Name), body); err != nil {
+		return SnapshotChunk{}, fmt.Errorf("failed to store chunk %s: %w", meta.Name, err)
+	}
+
+	return meta, nil
+}
+
+// ImportSnapshot reads the snapshot named name from src and writes
+// its entities to the store, verifying the checksum of every chunk
+// before importing it. Returns the number of entities imported.
+func (r *RedisTKV) ImportSnapshot(
+	ctx context.Context,
+	src SnapshotSource,
+	name string,
+	opts TransferOptions,
+) (int, error) {
+	manifest, err := ReadSnapshotManifest(ctx, src, name)
+	if err != nil {
+		return 0, err
+	}
+
+	var imported int
+
+	for _, chunk := range manifest.Chunks {
+		n, err := r.importChunk(ctx, src, path.Join(name, chunk.Name), chunk.SHA256, opts)
+		imported += n
+
+		if err != nil {
+			return imported, fmt.Errorf("failed to import chunk %s: %w", chunk.Name, err)
+		}
+	}
+
+	return imported, nil
+}
+
+// ReadSnapshotManifest reads the manifest of the snapshot named name.
+func ReadSnapshotManifest(ctx context.Context, src SnapshotSource, name string) (SnapshotManifest, error) {
+	var manifest SnapshotManifest
+
+	body, err := src.Get(ctx, path.Join(name, manifestName))
+	if err != nil {
+		return manifest, fmt.Errorf("failed to read manifest: %w", err)
+	}
+
+	defer body.Close()
+
+	if err = json.NewDecoder(body).Decode(&manifest); err != nil {
+		return manifest, fmt.Errorf("failed to decode manifest: %w", err)
+	}
+
+	if manifest.Version > SnapshotFormatVersion {
+		return manifest, fmt.Errorf("%w: version %d", ErrUnsupportedSnapshot, manifest.Version)
+	}
+
+	return manifest, nil
+}
+
+func (r *RedisTKV) importChunk(
+	ctx context.Context,
+	src SnapshotSource,
+	name, checksum string,
+	opts TransferOptions,
+) (int, error) {
+	body, err := src.Get(ctx, name)
+	if err != nil {
+		return 0, err //nolint:wrapcheck // wrapped by caller
+	}
+
+	defer body.Close()
+
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return 0, err //nolint:wrapcheck // wrapped by caller
+	}
+
+	sum := sha256.Sum256(data)
+	if hex.EncodeToString(sum[:]) != checksum {
+		return 0, ErrChecksumMismatch
+	}
+
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return 0, err //nolint:wrapcheck // wrapped by caller
+	}
+
+	return r.Import(ctx, gz, opts)
+}
+
+// chunkWriter compresses records into an in-memory chunk.
+type chunkWriter struct {
+	buf     bytes.Buffer
+	gz      *gzip.Writer
+	enc     *snapshotEncoder
+	records int
+}
+
+func newChunkWriter() (*chunkWriter, error) {
+	c := &chunkWriter{}
+	c.gz = gzip.NewWriter(&c.buf)
+
+	enc, err := newSnapshotEncoder(c.gz, CompressionNone)
+	if err != nil {
+		return nil, err
+	}
+
+	c.enc = enc
+
+	return c, nil
+}
+
+func (c *chunkWriter) write(record snapshotRecord) error {
+	if err := c.enc.encode(record); err != nil {
+		return err
+	}
+
+	c.records++
+
+	return nil
+}
+
+func (c *chunkWriter) finish(name string) (SnapshotChunk, io.Reader, error) {
+	if err := c.gz.Close(); err != nil {
+		return SnapshotChunk{}, nil, fmt.Errorf("failed to compress chunk: %w", err)
+	}
+
+	sum := sha256.Sum256(c.buf.Bytes())
+
+	return SnapshotChunk{
+		Name:    name,
+		Records: c.records,
+		SHA256:  hex.EncodeToString(sum[:]),
+	}, &c.buf, nil
+}
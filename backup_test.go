@@ -0,0 +1,131 @@
+// GNU AFFERO GENERAL PUBLIC LICENSE
+// Version 3, 19 November 2007
+//
+// Copyright (C) 2025 John Kleijn
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// For more details, see the full AGPL-3.0 license at:
+// https://www.gnu.org/licenses/agpl-3.0.html
+
+package rtkv_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/johnknl/rtkv"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedisTKV_ExportSnapshot(t *testing.T) {
+	ctx := context.Background()
+	store := goRedisSetup(t, 100)
+	dir := rtkv.DirStore{Dir: t.TempDir()}
+
+	manifest, err := store.ExportSnapshot(ctx, dir, "backup", rtkv.SnapshotOptions{ChunkRecords: 40})
+
+	require.NoError(t, err)
+	assert.Equal(t, 100, manifest.Records)
+	require.Len(t, manifest.Chunks, 3)
+	assert.Equal(t, 20, manifest.Chunks[2].Records)
+
+	read, err := rtkv.ReadSnapshotManifest(ctx, dir, "backup")
+
+	require.NoError(t, err)
+	assert.Equal(t, manifest.Chunks, read.Chunks)
+
+	dst := rtkv.NewRedisTKV(rtkv.DelimUnit, t.Name()+"import", newGoRedisClient(0))
+
+	imported, err := dst.ImportSnapshot(ctx, dir, "backup", rtkv.TransferOptions{})
+
+	require.NoError(t, err)
+	assert.Equal(t, 100, imported)
+
+	src, err := store.Get(ctx, "entity", "7")
+	require.NoError(t, err)
+
+	data, err := dst.Get(ctx, "entity", "7")
+	require.NoError(t, err)
+
+	assert.Equal(t, src, data)
+
+	t.Run("Corrupt", func(t *testing.T) {
+		chunk := filepath.Join(dir.Dir, "backup", manifest.Chunks[1].Name)
+
+		require.NoError(t, os.WriteFile(chunk, []byte("garbage"), 0o600))
+
+		_, err := dst.ImportSnapshot(ctx, dir, "backup", rtkv.TransferOptions{})
+
+		require.ErrorIs(t, err, rtkv.ErrChecksumMismatch)
+	})
+
+	t.Run("Missing", func(t *testing.T) {
+		_, err := dst.ImportSnapshot(ctx, dir, "nope", rtkv.TransferOptions{})
+
+		require.ErrorIs(t, err, os.ErrNotExist)
+	})
+}
+
+// failingSink fails the Put numbered fail, counting from 1.
+type failingSink struct {
+	rtkv.DirStore
+
+	puts, fail int
+}
+
+func (s *failingSink) Put(ctx context.Context, name string, body io.Reader) error {
+	if s.puts++; s.puts == s.fail {
+		return errors.New("put failed")
+	}
+
+	return s.DirStore.Put(ctx, name, body)
+}
+
+func TestRedisTKV_ExportSnapshot_Resume(t *testing.T) {
+	ctx := context.Background()
+	store := goRedisSetup(t, 100)
+	sink := &failingSink{DirStore: rtkv.DirStore{Dir: t.TempDir()}, fail: 2}
+	state := &rtkv.SnapshotState{}
+	opts := rtkv.SnapshotOptions{
+		Transfer:     rtkv.TransferOptions{Batch: rtkv.BatchOptions{Size: 30}},
+		ChunkRecords: 40,
+		State:        state,
+	}
+
+	_, err := store.ExportSnapshot(ctx, sink, "backup", opts)
+	require.Error(t, err)
+	assert.Equal(t, 2, state.Part, "The records read before the failure should be stored")
+
+	manifest, err := store.ExportSnapshot(ctx, sink, "backup", opts)
+	require.NoError(t, err)
+	assert.Equal(t, 100, manifest.Records)
+	require.Len(t, manifest.Chunks, 3)
+	assert.Equal(t, "part-00002.ndjson.gz", manifest.Chunks[2].Name, "Resuming should continue the numbering")
+
+	dst := rtkv.NewRedisTKV(rtkv.DelimUnit, t.Name()+"import", newGoRedisClient(0))
+
+	imported, err := dst.ImportSnapshot(ctx, sink.DirStore, "backup", rtkv.TransferOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, 100, imported)
+
+	count, err := dst.Count(ctx)
+	require.NoError(t, err)
+	assert.EqualValues(t, 100, count)
+}
@@ -0,0 +1,148 @@
+// GNU AFFERO GENERAL PUBLIC LICENSE
+// Version 3, 19 November 2007
+//
+// Copyright (C) 2025 John Kleijn
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// For more details, see the full AGPL-3.0 license at:
+// https://www.gnu.org/licenses/agpl-3.0.html
+
+package rtkv
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrBatchBudgetExhausted is returned by RunBatches when the caller's
+// deadline leaves too little time to safely start another batch. The
+// batch state is left intact so the job can be resumed.
+var ErrBatchBudgetExhausted = errors.New("not enough time left for another batch")
+
+// BatchState records the progress of a batched job. Passing the same
+// state to RunBatches again resumes the job where it stopped.
+type BatchState struct {
+	// Offset is the number of items processed so far.
+	Offset int
+
+	// Total is the expected number of items, or 0 if unknown.
+	// Knowing the total allows the deadline to be split evenly
+	// across the remaining batches.
+	Total int
+
+	// Done is set once the last batch completed.
+	Done bool
+}
+
+// BatchOptions controls how a job is split into batches.
+type BatchOptions struct {
+	// Size is the maximum number of items per batch.
+	Size int
+
+	// MaxBatchTimeout caps the time a single batch may take.
+	// Zero means no cap other than the caller's deadline.
+	MaxBatchTimeout time.Duration
+
+	// MinBatchTimeout is the least amount of time a batch is given.
+	// If less time than this remains, no new batch is started.
+	MinBatchTimeout time.Duration
+}
+
+// BatchFunc processes up to size items starting at offset and
+// returns the number of items it processed. Processing fewer than
+// size items marks the job as done.
+type BatchFunc func(ctx context.Context, offset, size int) (int, error)
+
+// RunBatches runs fn repeatedly until the job is done, giving each
+// batch its own timeout derived from the caller's deadline. A slow
+// batch can therefore only use its share of the budget, and the job
+// stops between batches instead of dying half way through one.
+func RunBatches(ctx context.Context, state *BatchState, opts BatchOptions, fn BatchFunc) error {
+	if opts.Size <= 0 {
+		return fmt.Errorf("invalid batch size %d", opts.Size) //nolint:err113 // programming error
+	}
+
+	for !state.Done {
+		batchCtx, cancel, err := state.batchContext(ctx, opts)
+		if err != nil {
+			return err
+		}
+
+		n, err := fn.call(batchCtx, state.Offset, opts.Size)
+
+		cancel()
+
+		if err != nil {
+			return fmt.Errorf("batch at offset %d failed: %w", state.Offset, err)
+		}
+
+		state.Offset += n
+		state.Done = n < opts.Size || (state.Total > 0 && state.Offset >= state.Total)
+	}
+
+	return nil
+}
+
+func (s *BatchState) batchContext(
+	ctx context.Context,
+	opts BatchOptions,
+) (context.Context, context.CancelFunc, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, nil, fmt.Errorf("batch job interrupted: %w", err)
+	}
+
+	timeout := opts.MaxBatchTimeout
+
+	if deadline, ok := ctx.Deadline(); ok {
+		remaining := time.Until(deadline)
+		share := remaining
+
+		if s.Total > s.Offset {
+			batches := (s.Total - s.Offset + opts.Size - 1) / opts.Size
+			share = remaining / time.Duration(batches)
+		}
+
+		if share < opts.MinBatchTimeout {
+			if remaining < opts.MinBatchTimeout {
+				return nil, nil, ErrBatchBudgetExhausted
+			}
+
+			share = opts.MinBatchTimeout
+		}
+
+		if timeout == 0 || share < timeout {
+			timeout = share
+		}
+	}
+
+	if timeout == 0 {
+		batchCtx, cancel := context.WithCancel(ctx)
+
+		return batchCtx, cancel, nil
+	}
+
+	batchCtx, cancel := context.WithTimeout(ctx, timeout)
+
+	return batchCtx, cancel, nil
+}
+
+// call calls fn, converting a panic into an error.
+func (fn BatchFunc) call(ctx context.Context, offset, size int) (n int, err error) {
+	defer recoverPanic(&err)
+
+	return fn(ctx, offset, size)
+}
@@ -0,0 +1,118 @@
+// GNU AFFERO GENERAL PUBLIC LICENSE
+// Version 3, 19 November 2007
+//
+// Copyright (C) 2025 John Kleijn
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// For more details, see the full AGPL-3.0 license at:
+// https://www.gnu.org/licenses/agpl-3.0.html
+
+package rtkv_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/johnknl/rtkv"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunBatches(t *testing.T) {
+	ctx := context.Background()
+
+	var offsets []int
+
+	state := &rtkv.BatchState{}
+
+	err := rtkv.RunBatches(ctx, state, rtkv.BatchOptions{Size: 3}, func(_ context.Context, offset, size int) (int, error) {
+		offsets = append(offsets, offset)
+
+		return min(size, 7-offset), nil
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, []int{0, 3, 6}, offsets)
+	assert.Equal(t, 7, state.Offset)
+	assert.True(t, state.Done)
+}
+
+func TestRunBatches_SplitsDeadline(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	state := &rtkv.BatchState{Total: 10}
+
+	err := rtkv.RunBatches(ctx, state, rtkv.BatchOptions{Size: 2}, func(ctx context.Context, offset, size int) (int, error) {
+		deadline, ok := ctx.Deadline()
+
+		require.True(t, ok, "Batch context should have a deadline")
+
+		if offset == 0 {
+			assert.LessOrEqual(t, time.Until(deadline), time.Second/5, "First batch should get a fifth of the budget")
+		}
+
+		return size, nil
+	})
+
+	require.NoError(t, err)
+	assert.True(t, state.Done)
+}
+
+func TestRunBatches_BudgetExhausted(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	state := &rtkv.BatchState{}
+	opts := rtkv.BatchOptions{Size: 2, MinBatchTimeout: 50 * time.Millisecond}
+
+	err := rtkv.RunBatches(ctx, state, opts, func(context.Context, int, int) (int, error) {
+		t.Fatal("No batch should be started")
+
+		return 0, nil
+	})
+
+	require.ErrorIs(t, err, rtkv.ErrBatchBudgetExhausted)
+	assert.Equal(t, 0, state.Offset)
+	assert.False(t, state.Done)
+}
+
+func TestRunBatches_Resume(t *testing.T) {
+	ctx := context.Background()
+	errBatch := errors.New("mock error")
+	state := &rtkv.BatchState{}
+	failAt := 4
+
+	fn := func(_ context.Context, offset, size int) (int, error) {
+		if offset == failAt {
+			return 0, errBatch
+		}
+
+		return min(size, 6-offset), nil
+	}
+
+	err := rtkv.RunBatches(ctx, state, rtkv.BatchOptions{Size: 2}, fn)
+
+	require.ErrorIs(t, err, errBatch)
+	assert.Equal(t, 4, state.Offset)
+
+	failAt = -1
+
+	require.NoError(t, rtkv.RunBatches(ctx, state, rtkv.BatchOptions{Size: 2}, fn))
+	assert.Equal(t, 6, state.Offset)
+	assert.True(t, state.Done)
+}
@@ -0,0 +1,287 @@
+// GNU AFFERO GENERAL PUBLIC LICENSE
+// Version 3, 19 November 2007
+//
+// Copyright (C) 2025 John Kleijn
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// For more details, see the full AGPL-3.0 license at:
+// https://www.gnu.org/licenses/agpl-3.0.html
+
+package rtkv
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	defaultBridgeBatchSize = 100
+	defaultBridgeBlock     = time.Second
+	defaultBridgeBackoff   = time.Second
+
+	// noBlock makes XREADGROUP return right away.
+	noBlock = -1
+)
+
+// ChangePublisher publishes change events to an external system.
+// Publish must only return nil once all events are durably accepted.
+type ChangePublisher interface {
+	Publish(ctx context.Context, events []ChangeEvent) error
+}
+
+// ChangeBridgeOptions configures a ChangeBridge.
+type ChangeBridgeOptions struct {
+	// Group is the consumer group of the bridge, which tracks the
+	// events it delivered. Bridges publishing to different systems
+	// need different groups.
+	Group string
+
+	// Consumer identifies this bridge instance within the group.
+	// Defaults to the group name.
+	Consumer string
+
+	// BatchSize is the maximum number of events per Publish call.
+	// Defaults to 100.
+	BatchSize int64
+
+	// Block is how long to wait for new events per read.
+	// Defaults to one second.
+	Block time.Duration
+
+	// Interval, if set, is the time to wait after publishing a batch
+	// smaller than BatchSize, so events accumulate into larger batches.
+	Interval time.Duration
+
+	// Backoff is how long to wait before retrying a failed
+	// Publish. Defaults to one second.
+	Backoff time.Duration
+}
+
+// ChangeBridge consumes the change feed of a store and publishes the
+// events with a ChangePublisher. Delivery is at least once: events
+// are acknowledged only after they were published, and unacknowledged
+// events are published again, in stream order, after a failure or a
+// restart. Requires WithChangeFeed.
+type ChangeBridge struct {
+	store     *RedisTKV
+	publisher ChangePublisher
+	opts      ChangeBridgeOptions
+	cancel    context.CancelFunc
+	done      chan struct{}
+	mx        sync.Mutex
+}
+
+// NewChangeBridge creates a bridge that publishes the changes of
+// store with publisher.
+func NewChangeBridge(store *RedisTKV, publisher ChangePublisher, opts ChangeBridgeOptions) *ChangeBridge {
+	if opts.Consumer == "" {
+		opts.Consumer = opts.Group
+	}
+
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = defaultBridgeBatchSize
+	}
+
+	if opts.Block <= 0 {
+		opts.Block = defaultBridgeBlock
+	}
+
+	if opts.Backoff <= 0 {
+		opts.Backoff = defaultBridgeBackoff
+	}
+
+	return &ChangeBridge{
+		store:     store,
+		publisher: publisher,
+		opts:      opts,
+	}
+}
+
+// Start runs the bridge until Shutdown is called or ctx is done.
+// Calling Start on a running bridge is a no-op.
+func (b *ChangeBridge) Start(ctx context.Context) {
+	b.mx.Lock()
+	defer b.mx.Unlock()
+
+	if b.cancel != nil {
+		return
+	}
+
+	ctx, b.cancel = context.WithCancel(ctx)
+	b.done = make(chan struct{})
+
+	go b.run(ctx, b.done)
+}
+
+// Shutdown stops the bridge and waits for a running Publish to
+// return until ctx is done.
+func (b *ChangeBridge) Shutdown(ctx context.Context) error {
+	b.mx.Lock()
+	cancel, done := b.cancel, b.done
+	b.cancel, b.done = nil, nil
+	b.mx.Unlock()
+
+	if cancel == nil {
+		return nil
+	}
+
+	cancel()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("change bridge did not stop: %w", context.Cause(ctx))
+	}
+}
+
+// RunOnce publishes one batch of events in the calling goroutine,
+// starting with events that were read before but not acknowledged.
+// Returns the number of events published.
+func (b *ChangeBridge) RunOnce(ctx context.Context) (int, error) {
+	if err := b.createGroup(ctx); err != nil {
+		return 0, err
+	}
+
+	n, err := b.deliver(ctx, "0", noBlock)
+	if err != nil || n > 0 {
+		return n, err
+	}
+
+	return b.deliver(ctx, ">", noBlock)
+}
+
+func (b *ChangeBridge) run(ctx context.Context, done chan struct{}) {
+	defer close(done)
+
+	ready, pending := false, true
+
+	for ctx.Err() == nil {
+		var err error
+
+		if !ready {
+			err = b.createGroup(ctx)
+			ready = err == nil
+		}
+
+		if err == nil && pending {
+			var n int
+
+			n, err = b.deliver(ctx, "0", noBlock)
+			pending = n > 0
+		}
+
+		if err == nil && !pending {
+			var n int
+
+			n, err = b.deliver(ctx, ">", b.opts.Block)
+
+			if err == nil && n > 0 && int64(n) < b.opts.BatchSize && b.opts.Interval > 0 {
+				select {
+				case <-ctx.Done():
+				case <-time.After(b.opts.Interval):
+				}
+			}
+		}
+
+		if err != nil && ctx.Err() == nil {
+			b.store.logger.ErrorContext(ctx, "change bridge failed",
+				"namespace", b.store.namespace,
+				"group", b.opts.Group,
+				"error", err,
+			)
+
+			ready, pending = false, true
+
+			select {
+			case <-ctx.Done():
+			case <-time.After(b.opts.Backoff):
+			}
+		}
+	}
+}
+
+// deliver reads a batch of events starting at id, publishes and
+// acknowledges them. Use "0" for pending and ">" for new events.
+func (b *ChangeBridge) deliver(ctx context.Context, id string, block time.Duration) (int, error) {
+	key := b.store.ChangeStreamKey()
+
+	streams, err := b.store.client.XReadGroup(ctx, &redis.XReadGroupArgs{
+		Group:    b.opts.Group,
+		Consumer: b.opts.Consumer,
+		Streams:  []string{key, id},
+		Count:    b.opts.BatchSize,
+		Block:    block,
+	}).Result()
+	if errors.Is(err, redis.Nil) {
+		return 0, nil
+	}
+
+	if err != nil {
+		return 0, fmt.Errorf("failed to read changes: %w", err)
+	}
+
+	var (
+		events []ChangeEvent
+		ids    []string
+	)
+
+	for _, stream := range streams {
+		for _, message := range stream.Messages {
+			event, err := parseChangeEvent(message)
+			if err != nil {
+				return 0, err
+			}
+
+			events = append(events, event)
+			ids = append(ids, message.ID)
+		}
+	}
+
+	if len(events) == 0 {
+		return 0, nil
+	}
+
+	if err = b.publish(ctx, events); err != nil {
+		return 0, fmt.Errorf("failed to publish changes: %w", err)
+	}
+
+	if err = b.store.client.XAck(ctx, key, b.opts.Group, ids...).Err(); err != nil {
+		return 0, fmt.Errorf("failed to acknowledge changes: %w", err)
+	}
+
+	return len(events), nil
+}
+
+func (b *ChangeBridge) publish(ctx context.Context, events []ChangeEvent) (err error) {
+	defer recoverPanic(&err)
+
+	return b.publisher.Publish(ctx, events)
+}
+
+func (b *ChangeBridge) createGroup(ctx context.Context) error {
+	err := b.store.client.XGroupCreateMkStream(ctx, b.store.ChangeStreamKey(), b.opts.Group, "0").Err()
+	if err != nil && !strings.HasPrefix(err.Error(), "BUSYGROUP") {
+		return fmt.Errorf("failed to create consumer group: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,107 @@
+// GNU AFFERO GENERAL PUBLIC LICENSE
+// Version 3, 19 November 2007
+//
+// Copyright (C) 2025 John Kleijn
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// For more details, see the full AGPL-3.0 license at:
+// https://www.gnu.org/licenses/agpl-3.0.html
+
+package rtkv_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/johnknl/rtkv"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeProducer struct {
+	messages []rtkv.KafkaMessage
+	fail     bool
+	mx       sync.Mutex
+}
+
+func (p *fakeProducer) Produce(_ context.Context, messages []rtkv.KafkaMessage) error {
+	p.mx.Lock()
+	defer p.mx.Unlock()
+
+	if p.fail {
+		return errors.New("broker unavailable")
+	}
+
+	p.messages = append(p.messages, messages...)
+
+	return nil
+}
+
+func (p *fakeProducer) keys() []string {
+	p.mx.Lock()
+	defer p.mx.Unlock()
+
+	keys := make([]string, len(p.messages))
+
+	for i, message := range p.messages {
+		keys[i] = string(message.Key)
+	}
+
+	return keys
+}
+
+func TestChangeBridge_Kafka(t *testing.T) {
+	ctx := context.Background()
+	store := newRTKV(t, newGoRedisClient(0)).With(rtkv.WithChangeFeed(rtkv.ChangeFeedOptions{}))
+	producer := &fakeProducer{fail: true}
+	bridge := rtkv.NewChangeBridge(store, rtkv.NewKafkaPublisher(producer, "changes", "/test"),
+		rtkv.ChangeBridgeOptions{Group: "kafka"},
+	)
+
+	for _, id := range []string{"a", "b"} {
+		_, err := store.Set(ctx, []byte("v"), time.Now(), id)
+		require.NoError(t, err)
+	}
+
+	_, err := bridge.RunOnce(ctx)
+
+	require.Error(t, err)
+
+	producer.fail = false
+
+	n, err := bridge.RunOnce(ctx)
+
+	require.NoError(t, err)
+	assert.Equal(t, 2, n, "events that failed to publish should be published again")
+	assert.Equal(t, []string{"a", "b"}, producer.keys())
+
+	n, err = bridge.RunOnce(ctx)
+
+	require.NoError(t, err)
+	assert.Zero(t, n)
+
+	bridge.Start(ctx)
+	t.Cleanup(func() { require.NoError(t, bridge.Shutdown(ctx)) })
+
+	require.NoError(t, store.Delete(ctx, "a"))
+
+	assert.Eventually(t, func() bool {
+		return len(producer.keys()) == 3
+	}, time.Second, time.Millisecond)
+	assert.Equal(t, "changes", producer.messages[2].Topic)
+}
@@ -0,0 +1,121 @@
+// GNU AFFERO GENERAL PUBLIC LICENSE
+// Version 3, 19 November 2007
+//
+// Copyright (C) 2025 John Kleijn
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// For more details, see the full AGPL-3.0 license at:
+// https://www.gnu.org/licenses/agpl-3.0.html
+
+package rtkv
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrCommandBudgetExhausted is returned by operations that fan out
+// when they used up the per call budget set with WithCommandBudget.
+// The work done so far is kept, and calling the operation again
+// with a fresh budget continues where it stopped.
+var ErrCommandBudgetExhausted = errors.New("command budget exhausted")
+
+// CommandBudget limits the Redis commands an operation that fans
+// out may issue, so destructive maintenance can run safely against
+// production. Operations spend their budget a chunk at a time,
+// before sending the chunk. A command counts as one, and commands
+// and scripts that handle multiple entities count one extra per
+// entity.
+//
+// The budget is honoured by Archive, Clear, CompactIndex,
+// CompactHistory, DeleteRange, RemoveExpired and SwapNamespaces.
+type CommandBudget struct {
+	// PerSecond limits the rate at which commands are issued.
+	// Zero means no limit.
+	PerSecond int
+
+	// PerCall limits the number of commands a single call may
+	// issue. A call always processes at least one chunk, so it
+	// makes progress however small the budget is. Zero means
+	// no limit.
+	PerCall int
+}
+
+type commandBudgetKey struct{}
+
+// WithCommandBudget returns a context that limits operations
+// called with it to budget. Every call gets the full budget.
+func WithCommandBudget(ctx context.Context, budget CommandBudget) context.Context {
+	return context.WithValue(ctx, commandBudgetKey{}, budget)
+}
+
+// commandBudget tracks the commands spent by a single call.
+type commandBudget struct {
+	CommandBudget
+
+	started time.Time
+	spent   int
+}
+
+// budgetFrom starts tracking the budget set on ctx, if any.
+func budgetFrom(ctx context.Context) *commandBudget {
+	budget, ok := ctx.Value(commandBudgetKey{}).(CommandBudget)
+	if !ok {
+		return nil
+	}
+
+	return &commandBudget{CommandBudget: budget, started: time.Now()}
+}
+
+// spend reserves the commands needed to process a chunk of
+// entities, waiting as long as needed to honour the rate limit.
+func (b *commandBudget) spend(ctx context.Context, entities int) error {
+	if b == nil {
+		return nil
+	}
+
+	commands := 1 + entities
+
+	if b.PerCall > 0 && b.spent > 0 && b.spent+commands > b.PerCall {
+		return fmt.Errorf("%w: %d of %d commands spent", ErrCommandBudgetExhausted, b.spent, b.PerCall)
+	}
+
+	b.spent += commands
+
+	if b.PerSecond <= 0 {
+		return nil
+	}
+
+	// Pace commands so the average rate since the start of
+	// the call stays within the limit.
+	due := b.started.Add(time.Duration(b.spent-commands) * time.Second / time.Duration(b.PerSecond))
+
+	wait := time.Until(due)
+	if wait <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("failed to wait for command budget: %w", ctx.Err())
+	}
+}
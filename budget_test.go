@@ -0,0 +1,93 @@
+// GNU AFFERO GENERAL PUBLIC LICENSE
+// Version 3, 19 November 2007
+//
+// Copyright (C) 2025 John Kleijn
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// For more details, see the full AGPL-3.0 license at:
+// https://www.gnu.org/licenses/agpl-3.0.html
+
+package rtkv_test
+
+import (
+	"context"
+	"io"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/johnknl/rtkv"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithCommandBudget_PerCall(t *testing.T) {
+	ctx := context.Background()
+	store := newRTKV(t, newGoRedisClient(0)).With(rtkv.WithIndexCompaction())
+	day := time.Date(2025, 1, 10, 0, 0, 0, 0, time.UTC)
+
+	var records []rtkv.BulkSetRecord
+
+	for i := range 9 {
+		records = append(records, rtkv.BulkSetRecord{
+			ID:           []string{strconv.Itoa(i)},
+			Data:         []byte(strconv.Itoa(i)),
+			LastModified: day.Add(time.Duration(i) * time.Hour),
+		})
+	}
+
+	require.NoError(t, store.BulkSet(ctx, records))
+
+	// Every chunk of 2 entries costs 3 commands.
+	budgetCtx := rtkv.WithCommandBudget(ctx, rtkv.CommandBudget{PerCall: 7})
+	opts := rtkv.CompactOptions{ChunkSize: 2}
+	before := day.Add(24 * time.Hour)
+
+	moved, err := store.CompactIndex(budgetCtx, before, opts)
+	require.ErrorIs(t, err, rtkv.ErrCommandBudgetExhausted)
+	assert.EqualValues(t, 4, moved)
+
+	var total int64
+
+	for total = moved; err != nil; total += moved {
+		moved, err = store.CompactIndex(budgetCtx, before, opts)
+		if err != nil {
+			require.ErrorIs(t, err, rtkv.ErrCommandBudgetExhausted)
+		}
+	}
+
+	assert.EqualValues(t, 9, total)
+}
+
+func TestWithCommandBudget_PerSecond(t *testing.T) {
+	ctx := context.Background()
+	store := goRedisSetup(t, 20)
+
+	// Chunks of 1000 cost 1001 commands each, so the second
+	// chunk has to wait for 1001/10000 seconds.
+	budgetCtx := rtkv.WithCommandBudget(ctx, rtkv.CommandBudget{PerSecond: 10_000})
+	start := time.Now()
+
+	_, err := store.RemoveExpired(budgetCtx)
+	require.NoError(t, err)
+	assert.Less(t, time.Since(start), 50*time.Millisecond, "a single chunk should not wait")
+
+	budgetCtx = rtkv.WithCommandBudget(ctx, rtkv.CommandBudget{PerSecond: 1})
+	budgetCtx, cancel := context.WithTimeout(budgetCtx, 50*time.Millisecond)
+	defer cancel()
+
+	_, err = store.Archive(budgetCtx, time.Now(), rtkv.NewWriterSink(io.Discard), 5)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+}
@@ -0,0 +1,134 @@
+// GNU AFFERO GENERAL PUBLIC LICENSE
+// Version 3, 19 November 2007
+//
+// Copyright (C) 2025 John Kleijn
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// For more details, see the full AGPL-3.0 license at:
+// https://www.gnu.org/licenses/agpl-3.0.html
+
+package rtkv
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const capabilitiesSuffix = "capabilities"
+
+// Capabilities describes what the connected Redis supports.
+type Capabilities struct {
+	// Version is the Redis version, or empty if the server
+	// doesn't report it.
+	Version string
+
+	// Scripts reports support for Lua scripts (EVAL), which
+	// FetchPageConsistent and several options depend on.
+	Scripts bool
+
+	// Functions reports support for Redis functions (FCALL).
+	Functions bool
+
+	// ClientTracking reports support for server assisted client
+	// side caching (CLIENT TRACKING) over RESP3.
+	ClientTracking bool
+
+	// Streams reports support for streams (XADD).
+	Streams bool
+
+	// JSON and Search report whether the RedisJSON and
+	// RediSearch commands are available.
+	JSON   bool
+	Search bool
+
+	// KeyspaceEvents is the notify-keyspace-events setting, which
+	// is empty when keyspace notifications are disabled or the
+	// setting can't be read.
+	KeyspaceEvents string
+}
+
+// Notifications reports whether keyspace notifications are enabled.
+func (c Capabilities) Notifications() bool {
+	return c.KeyspaceEvents != ""
+}
+
+// Capabilities probes the connected Redis for the features it
+// supports, so applications can branch on them at startup. Features
+// are detected by issuing harmless commands rather than by version,
+// as managed services and proxies often disable or rename commands.
+func (r *RedisTKV) Capabilities(ctx context.Context) (Capabilities, error) {
+	var caps Capabilities
+
+	if info, err := r.client.Info(ctx, "server").Result(); err == nil {
+		for _, line := range strings.Split(info, "\n") {
+			if version, ok := strings.CutPrefix(strings.TrimSpace(line), "redis_version:"); ok {
+				caps.Version = version
+			}
+		}
+	}
+
+	probe := r.internalKey(capabilitiesSuffix)
+	probes := []struct {
+		supported *bool
+		args      []any
+	}{
+		{&caps.Scripts, []any{"SCRIPT", "EXISTS", "0"}},
+		{&caps.Functions, []any{"FUNCTION", "LIST", "LIBRARYNAME", probe}},
+		{&caps.ClientTracking, []any{"CLIENT", "TRACKINGINFO"}},
+		{&caps.Streams, []any{"XLEN", probe}},
+		{&caps.JSON, []any{"JSON.TYPE", probe}},
+		{&caps.Search, []any{"FT._LIST"}},
+	}
+
+	for _, p := range probes {
+		supported, err := r.supports(ctx, p.args...)
+		if err != nil {
+			return caps, fmt.Errorf("failed to probe %v: %w", p.args[0], err)
+		}
+
+		*p.supported = supported
+	}
+
+	if events, err := r.client.ConfigGet(ctx, "notify-keyspace-events").Result(); err == nil {
+		caps.KeyspaceEvents = events["notify-keyspace-events"]
+	}
+
+	return caps, nil
+}
+
+// supports runs a command and reports whether Redis knows it.
+// Errors other than an unknown or disabled command mean the command
+// exists, while connection errors are returned.
+func (r *RedisTKV) supports(ctx context.Context, args ...any) (bool, error) {
+	err := r.client.Do(ctx, args...).Err()
+	if err == nil || errors.Is(err, redis.Nil) {
+		return true, nil
+	}
+
+	var redisErr redis.Error
+	if !errors.As(err, &redisErr) {
+		return false, err
+	}
+
+	msg := strings.ToLower(redisErr.Error())
+
+	return !strings.Contains(msg, "unknown command") &&
+		!strings.Contains(msg, "unknown subcommand") &&
+		!strings.Contains(msg, "noperm"), nil
+}
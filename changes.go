@@ -0,0 +1,289 @@
+// GNU AFFERO GENERAL PUBLIC LICENSE
+// Version 3, 19 November 2007
+//
+// Copyright (C) 2025 John Kleijn
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// For more details, see the full AGPL-3.0 license at:
+// https://www.gnu.org/licenses/agpl-3.0.html
+
+package rtkv
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	changesSuffix = "changes"
+
+	defaultChangesMaxLen = 100_000
+
+	cloudEventsSpecVersion = "1.0"
+
+	// streamIDMarker stands in for the stream ID of an event in its
+	// JSON payload, which is only known once it is added.
+	streamIDMarker = "\x00"
+
+	// changePublishScript records a change in the change stream and
+	// publishes it with its stream ID. Returns the stream ID.
+	changePublishScript = `
+local stream = KEYS[1] -- the change stream
+local maxLen = ARGV[1] -- the approximate max length of the stream
+local prefix = ARGV[2] -- the payload up to the stream ID
+local suffix = ARGV[3] -- the payload after the stream ID
+
+local id = redis.call("XADD", stream, "MAXLEN", "~", maxLen, "*", unpack(ARGV, 4))
+redis.call("PUBLISH", stream, prefix .. id .. suffix)
+
+return id
+`
+)
+
+// ChangeOp is the kind of change an event describes.
+type ChangeOp string
+
+const (
+	// ChangeSet is an entity being created or overwritten.
+	ChangeSet ChangeOp = "set"
+
+	// ChangeDelete is an entity being deleted.
+	ChangeDelete ChangeOp = "delete"
+
+	// ChangeExpire is an entity found expired, see ExpiryTombstones.
+	// Its lastModified time is when the entity expired.
+	ChangeExpire ChangeOp = "expire"
+)
+
+// ChangeFormat selects how change events are encoded.
+type ChangeFormat int
+
+const (
+	// ChangeFormatFields encodes events as stream fields "op", "id"
+	// (a JSON array) and "lastModified" (Unix nanoseconds).
+	ChangeFormatFields ChangeFormat = iota
+
+	// ChangeFormatCloudEvents encodes events as a CloudEvents 1.0
+	// JSON envelope in a single "event" field, so they can flow into
+	// existing event-driven infrastructure.
+	ChangeFormatCloudEvents
+)
+
+// ChangeFeedOptions configures the change feed.
+type ChangeFeedOptions struct {
+	// Format selects the event encoding.
+	Format ChangeFormat
+
+	// MaxLen is the approximate number of events kept in the
+	// stream. Defaults to 100000.
+	MaxLen int64
+
+	// Publish also publishes every event on a pub/sub channel
+	// named after the stream, as JSON holding its stream ID: the
+	// CloudEvent, or an object with "streamID", "op", "id" and
+	// "lastModified".
+	Publish bool
+
+	// Source is the CloudEvents source attribute.
+	// Defaults to "rtkv/" followed by the namespace.
+	Source string
+}
+
+// ChangeEvent describes a change to an entity.
+type ChangeEvent struct {
+	// StreamID is the ID of the event in the change stream.
+	StreamID     string
+	Op           ChangeOp
+	ID           []string
+	LastModified time.Time
+}
+
+// CloudEvent is the CloudEvents 1.0 envelope of a change event.
+// Envelopes in the change stream have no ID, as that is the stream
+// ID they are added with.
+type CloudEvent struct {
+	SpecVersion     string         `json:"specversion"`
+	ID              string         `json:"id,omitempty"`
+	Type            string         `json:"type"`
+	Source          string         `json:"source"`
+	Subject         string         `json:"subject"`
+	Time            time.Time      `json:"time"`
+	DataContentType string         `json:"datacontenttype"`
+	Data            CloudEventData `json:"data"`
+}
+
+// CloudEventData is the data of a change CloudEvent.
+type CloudEventData struct {
+	Op ChangeOp `json:"op"`
+	ID []string `json:"id"`
+}
+
+// WithChangeFeed records every write and delete in a Redis stream,
+// in the same transaction as the change itself, so consumers can
+// follow the namespace with ReadChanges. With WithSkipIdenticalWrites
+// events are recorded right after the write instead. The subject of
+// CloudEvents is the ID joined with slashes.
+func WithChangeFeed(opts ChangeFeedOptions) Option {
+	return func(r *RedisTKV) {
+		if opts.MaxLen <= 0 {
+			opts.MaxLen = defaultChangesMaxLen
+		}
+
+		if opts.Source == "" {
+			opts.Source = "rtkv/" + r.namespace
+		}
+
+		r.changes = &opts
+	}
+}
+
+// ChangeStreamKey returns the key of the change stream, which is
+// also the name of the pub/sub channel events are published on.
+func (r *RedisTKV) ChangeStreamKey() string {
+	return r.internalKey(changesSuffix)
+}
+
+// ReadChanges returns up to count events recorded after the stream
+// ID after, oldest first. Pass "0" to read from the start of the
+// stream and the StreamID of the last event to continue.
+func (r *RedisTKV) ReadChanges(ctx context.Context, after string, count int64) ([]ChangeEvent, error) {
+	start := "(" + after
+	if after == "0" || after == "" {
+		start = "-"
+	}
+
+	messages, err := r.client.XRangeN(ctx, r.ChangeStreamKey(), start, "+", count).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read changes: %w", err)
+	}
+
+	events := make([]ChangeEvent, len(messages))
+
+	for i, message := range messages {
+		if events[i], err = parseChangeEvent(message); err != nil {
+			return nil, err
+		}
+	}
+
+	return events, nil
+}
+
+// changeAdd records a change in the change stream.
+func (r *RedisTKV) changeAdd(
+	ctx context.Context,
+	pipe redis.Pipeliner,
+	op ChangeOp,
+	id []string,
+	lastModified int64,
+) {
+	if r.changes == nil {
+		return
+	}
+
+	var (
+		fields  []any
+		payload []byte
+	)
+
+	switch r.changes.Format {
+	case ChangeFormatCloudEvents:
+		change := ChangeEvent{Op: op, ID: id, LastModified: time.Unix(0, lastModified)}
+		event, _ := json.Marshal(change.CloudEvent(r.changes.Source)) //nolint:errchkjson // can't fail
+		fields = []any{"event", event}
+		change.StreamID = streamIDMarker
+		payload, _ = json.Marshal(change.CloudEvent(r.changes.Source)) //nolint:errchkjson // can't fail
+	default:
+		encodedID, _ := json.Marshal(id) //nolint:errchkjson // can't fail
+		fields = []any{"op", string(op), "id", encodedID, "lastModified", lastModified}
+		payload, _ = json.Marshal(struct { //nolint:errchkjson // can't fail
+			StreamID     string   `json:"streamID"`
+			Op           ChangeOp `json:"op"`
+			ID           []string `json:"id"`
+			LastModified int64    `json:"lastModified"`
+		}{streamIDMarker, op, id, lastModified})
+	}
+
+	if !r.changes.Publish {
+		pipe.XAdd(ctx, &redis.XAddArgs{
+			Stream: r.ChangeStreamKey(),
+			MaxLen: r.changes.MaxLen,
+			Approx: true,
+			Values: fields,
+		})
+
+		return
+	}
+
+	marker, _ := json.Marshal(streamIDMarker) //nolint:errchkjson // can't fail
+	prefix, suffix, _ := strings.Cut(string(payload), string(marker[1:len(marker)-1]))
+	args := append([]any{r.changes.MaxLen, prefix, suffix}, fields...)
+
+	pipe.Eval(ctx, changePublishScript, []string{r.ChangeStreamKey()}, args...)
+}
+
+// CloudEvent returns the CloudEvents envelope of the event. Its ID
+// is the stream ID of the event, so consumers can deduplicate events
+// delivered more than once.
+func (e ChangeEvent) CloudEvent(source string) CloudEvent {
+	return CloudEvent{
+		SpecVersion:     cloudEventsSpecVersion,
+		ID:              e.StreamID,
+		Type:            "rtkv.entity." + string(e.Op),
+		Source:          source,
+		Subject:         strings.Join(e.ID, "/"),
+		Time:            e.LastModified.UTC(),
+		DataContentType: "application/json",
+		Data:            CloudEventData{Op: e.Op, ID: e.ID},
+	}
+}
+
+func parseChangeEvent(message redis.XMessage) (ChangeEvent, error) {
+	event := ChangeEvent{StreamID: message.ID}
+
+	if raw, ok := message.Values["event"].(string); ok {
+		var ce CloudEvent
+		if err := json.Unmarshal([]byte(raw), &ce); err != nil {
+			return event, fmt.Errorf("failed to decode change event %s: %w", message.ID, err)
+		}
+
+		event.Op, event.ID, event.LastModified = ce.Data.Op, ce.Data.ID, ce.Time
+
+		return event, nil
+	}
+
+	op, _ := message.Values["op"].(string)
+	rawID, _ := message.Values["id"].(string)
+	rawLastModified, _ := message.Values["lastModified"].(string)
+
+	if err := json.Unmarshal([]byte(rawID), &event.ID); err != nil {
+		return event, fmt.Errorf("failed to decode change event %s: %w", message.ID, err)
+	}
+
+	nanos, err := strconv.ParseInt(rawLastModified, 10, 64)
+	if err != nil {
+		return event, fmt.Errorf("failed to decode change event %s: %w", message.ID, err)
+	}
+
+	event.Op = ChangeOp(op)
+	event.LastModified = time.Unix(0, nanos)
+
+	return event, nil
+}
@@ -0,0 +1,143 @@
+// GNU AFFERO GENERAL PUBLIC LICENSE
+// Version 3, 19 November 2007
+//
+// Copyright (C) 2025 John Kleijn
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// For more details, see the full AGPL-3.0 license at:
+// https://www.gnu.org/licenses/agpl-3.0.html
+
+package rtkv_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/johnknl/rtkv"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedisTKV_ReadChanges(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+
+	for name, format := range map[string]rtkv.ChangeFormat{
+		"Fields":      rtkv.ChangeFormatFields,
+		"CloudEvents": rtkv.ChangeFormatCloudEvents,
+	} {
+		t.Run(name, func(t *testing.T) {
+			store := newRTKV(t, newGoRedisClient(0)).With(rtkv.WithChangeFeed(rtkv.ChangeFeedOptions{Format: format}))
+
+			_, err := store.Set(ctx, []byte("v"), now, "a", "1")
+			require.NoError(t, err)
+			require.NoError(t, store.Delete(ctx, "a", "1"))
+
+			events, err := store.ReadChanges(ctx, "0", 10)
+
+			require.NoError(t, err)
+			require.Len(t, events, 2)
+			assert.Equal(t, rtkv.ChangeSet, events[0].Op)
+			assert.Equal(t, []string{"a", "1"}, events[0].ID)
+			assert.WithinDuration(t, now, events[0].LastModified, time.Microsecond)
+			assert.Equal(t, rtkv.ChangeDelete, events[1].Op)
+
+			events, err = store.ReadChanges(ctx, events[0].StreamID, 10)
+
+			require.NoError(t, err)
+			require.Len(t, events, 1)
+			assert.Equal(t, rtkv.ChangeDelete, events[0].Op)
+		})
+	}
+}
+
+func TestWithChangeFeed_CloudEvents(t *testing.T) {
+	ctx := context.Background()
+	client := newGoRedisClient(0)
+	store := newRTKV(t, client).With(rtkv.WithChangeFeed(rtkv.ChangeFeedOptions{
+		Format: rtkv.ChangeFormatCloudEvents,
+		Source: "/users",
+	}))
+
+	_, err := store.Set(ctx, []byte("v"), time.Now(), "a", "1")
+	require.NoError(t, err)
+
+	messages, err := client.XRange(ctx, store.ChangeStreamKey(), "-", "+").Result()
+
+	require.NoError(t, err)
+	require.Len(t, messages, 1)
+
+	var event rtkv.CloudEvent
+
+	require.NoError(t, json.Unmarshal([]byte(messages[0].Values["event"].(string)), &event))
+	assert.Equal(t, "1.0", event.SpecVersion)
+	assert.Equal(t, "rtkv.entity.set", event.Type)
+	assert.Equal(t, "/users", event.Source)
+	assert.Equal(t, "a/1", event.Subject)
+	assert.Empty(t, event.ID, "The ID of stored events is their stream ID")
+
+	events, err := store.ReadChanges(ctx, "0", 10)
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	assert.Equal(t, messages[0].ID, events[0].CloudEvent("/users").ID)
+}
+
+func TestWithChangeFeed_Publish(t *testing.T) {
+	ctx := context.Background()
+	client := newGoRedisClient(0)
+
+	for name, format := range map[string]rtkv.ChangeFormat{
+		"Fields":      rtkv.ChangeFormatFields,
+		"CloudEvents": rtkv.ChangeFormatCloudEvents,
+	} {
+		t.Run(name, func(t *testing.T) {
+			store := newRTKV(t, client).With(rtkv.WithChangeFeed(rtkv.ChangeFeedOptions{
+				Format:  format,
+				Publish: true,
+			}))
+
+			_, err := store.Clear(ctx)
+			require.NoError(t, err)
+
+			sub := client.Subscribe(ctx, store.ChangeStreamKey())
+			t.Cleanup(func() { _ = sub.Close() })
+
+			_, err = sub.Receive(ctx)
+			require.NoError(t, err)
+
+			_, err = store.Set(ctx, []byte("v"), time.Now(), "a", "1")
+			require.NoError(t, err)
+
+			message, err := sub.ReceiveMessage(ctx)
+			require.NoError(t, err)
+
+			events, err := store.ReadChanges(ctx, "0", 10)
+			require.NoError(t, err)
+			require.Len(t, events, 1)
+
+			var payload map[string]any
+
+			require.NoError(t, json.Unmarshal([]byte(message.Payload), &payload))
+
+			if format == rtkv.ChangeFormatCloudEvents {
+				assert.Equal(t, events[0].StreamID, payload["id"])
+			} else {
+				assert.Equal(t, events[0].StreamID, payload["streamID"])
+			}
+		})
+	}
+}
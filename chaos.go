@@ -0,0 +1,157 @@
+// GNU AFFERO GENERAL PUBLIC LICENSE
+// Version 3, 19 November 2007
+//
+// Copyright (C) 2025 John Kleijn
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// For more details, see the full AGPL-3.0 license at:
+// https://www.gnu.org/licenses/agpl-3.0.html
+
+package rtkv
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// ChaosAnyCommand is the ChaosRules key of the rule applied
+// to commands without a rule of their own.
+const ChaosAnyCommand = "*"
+
+// ErrChaos is the error injected by WithChaos
+// unless a rule sets its own.
+var ErrChaos = errors.New("injected failure")
+
+// ChaosRule describes the failures injected into a Redis command.
+type ChaosRule struct {
+	// ErrorRate is the fraction of commands, between 0 and 1,
+	// that fail with Err instead of being sent to Redis.
+	ErrorRate float64
+
+	// Err is the injected error. Defaults to ErrChaos.
+	Err error
+
+	// LatencyRate is the fraction of commands, between 0 and 1,
+	// that are delayed by Latency.
+	LatencyRate float64
+
+	// Latency is the injected delay.
+	Latency time.Duration
+}
+
+// ChaosRules maps lowercase Redis command names, such as "get",
+// "mget", "evalsha" or "zrangebyscore", to the failures injected
+// into them. Use ChaosAnyCommand to target all other commands.
+type ChaosRules map[string]ChaosRule
+
+// WithChaos injects latency and errors into the Redis commands the
+// store sends, at the rates set per command by rules, so applications
+// embedding rtkv can test their retry, fallback and resume logic
+// without a proxy. Commands in a pipeline or transaction are subject
+// to their rules together: the whole pipeline is delayed or fails.
+//
+// WithChaos is meant for tests only. It only affects this store and
+// its clones, not other stores sharing the same client.
+func WithChaos(rules ChaosRules) Option {
+	return func(r *RedisTKV) {
+		// Hooks can't be removed, so the hook is added to a new
+		// client rather than the caller's.
+		r.client = cloneClient(r.client)
+		r.client.AddHook(chaosHook{rules: rules})
+	}
+}
+
+// chaosHook is the redis.Hook that injects failures.
+type chaosHook struct {
+	rules ChaosRules
+}
+
+func (chaosHook) DialHook(next redis.DialHook) redis.DialHook {
+	return next
+}
+
+func (h chaosHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		if err := h.inject(ctx, cmd); err != nil {
+			cmd.SetErr(err)
+
+			return err
+		}
+
+		return next(ctx, cmd)
+	}
+}
+
+func (h chaosHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		if err := h.inject(ctx, cmds...); err != nil {
+			for _, cmd := range cmds {
+				cmd.SetErr(err)
+			}
+
+			return err
+		}
+
+		return next(ctx, cmds)
+	}
+}
+
+// inject applies the rules of cmds, sleeping for the longest
+// injected latency and returning the first injected error.
+func (h chaosHook) inject(ctx context.Context, cmds ...redis.Cmder) error {
+	var (
+		latency time.Duration
+		err     error
+	)
+
+	for _, cmd := range cmds {
+		rule, ok := h.rules[strings.ToLower(cmd.Name())]
+		if !ok {
+			rule = h.rules[ChaosAnyCommand]
+		}
+
+		if rule.LatencyRate > 0 && rand.Float64() < rule.LatencyRate { //nolint:gosec // no need for crypto
+			latency = max(latency, rule.Latency)
+		}
+
+		if err == nil && rule.ErrorRate > 0 && rand.Float64() < rule.ErrorRate { //nolint:gosec // no need for crypto
+			err = rule.Err
+			if err == nil {
+				err = ErrChaos
+			}
+
+			err = fmt.Errorf("chaos on %s: %w", cmd.Name(), err)
+		}
+	}
+
+	if latency > 0 {
+		timer := time.NewTimer(latency)
+		defer timer.Stop()
+
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			return ctx.Err() //nolint:wrapcheck // as returned by go-redis
+		}
+	}
+
+	return err
+}
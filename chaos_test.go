@@ -0,0 +1,58 @@
+// GNU AFFERO GENERAL PUBLIC LICENSE
+// Version 3, 19 November 2007
+//
+// Copyright (C) 2025 John Kleijn
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// For more details, see the full AGPL-3.0 license at:
+// https://www.gnu.org/licenses/agpl-3.0.html
+
+package rtkv_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/johnknl/rtkv"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithChaos(t *testing.T) {
+	ctx := context.Background()
+	store := newRTKV(t, newGoRedisClient(0))
+	errTimeout := errors.New("timeout")
+
+	chaotic := store.With(rtkv.WithChaos(rtkv.ChaosRules{
+		"get":                {ErrorRate: 1, Err: errTimeout},
+		"exec":               {LatencyRate: 1, Latency: 30 * time.Millisecond},
+		rtkv.ChaosAnyCommand: {},
+	}))
+
+	start := time.Now()
+
+	_, err := chaotic.Set(ctx, []byte("value"), time.Now(), "a")
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, time.Since(start), 30*time.Millisecond, "Transactions should be delayed")
+
+	_, err = chaotic.Get(ctx, "a")
+	require.ErrorIs(t, err, errTimeout)
+
+	value, err := store.Get(ctx, "a")
+	require.NoError(t, err)
+	assert.Equal(t, "value", string(value), "The original client should be unaffected")
+}
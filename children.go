@@ -0,0 +1,129 @@
+// GNU AFFERO GENERAL PUBLIC LICENSE
+// Version 3, 19 November 2007
+//
+// Copyright (C) 2025 John Kleijn
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// For more details, see the full AGPL-3.0 license at:
+// https://www.gnu.org/licenses/agpl-3.0.html
+
+package rtkv
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const childIdxSuffix = "childIdx"
+
+// WithChildIndex maintains a set of descendants for every proper
+// prefix of the IDs written, enabling GetChildren. Only entities
+// written while the option is enabled are indexed.
+func WithChildIndex() Option {
+	return func(r *RedisTKV) {
+		r.childIndex = true
+	}
+}
+
+// GetChildren returns all entities whose composite ID extends the
+// given parent ID, ordered by ID. For example, with IDs of the form
+// tenant, order, line, GetChildren(ctx, tenant) returns all orders
+// and order lines of a tenant. Requires WithChildIndex.
+func (r *RedisTKV) GetChildren(ctx context.Context, parentID ...string) ([]Record, error) {
+	setKey := r.childSetKey(parentID)
+
+	keys, err := r.client.SMembers(ctx, setKey).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read child index: %w", err)
+	}
+
+	if len(keys) == 0 {
+		return nil, nil
+	}
+
+	slices.Sort(keys)
+
+	var (
+		values *redis.SliceCmd
+		scores *redis.FloatSliceCmd
+	)
+
+	_, err = r.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		values = pipe.MGet(ctx, keys...)
+		scores = pipe.ZMScore(ctx, r.namespacedKey(lastModifiedIdxSuffix), keys...)
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get children: %w", err)
+	}
+
+	records := make([]Record, 0, len(keys))
+
+	var stale []any
+
+	for i, value := range values.Val() {
+		s, ok := value.(string)
+		if !ok {
+			stale = append(stale, keys[i])
+
+			continue
+		}
+
+		data, err := r.readValue(ctx, []byte(s))
+		if err != nil {
+			return nil, err
+		}
+
+		if data == nil {
+			// A dangling alias.
+			continue
+		}
+
+		records = append(records, Record{
+			ID:           r.idFromKey(keys[i]),
+			LastModified: time.Unix(0, int64(scores.Val()[i])),
+			Data:         data,
+		})
+	}
+
+	if len(stale) > 0 {
+		// Entities removed without going through the child index,
+		// for example by archiving. Clean up lazily.
+		_ = r.client.SRem(ctx, setKey, stale...).Err()
+	}
+
+	return records, nil
+}
+
+func (r *RedisTKV) childSetKey(parentID []string) string {
+	return r.internalKey(childIdxSuffix, parentID...)
+}
+
+func (r *RedisTKV) childSetsAdd(ctx context.Context, pipe redis.Pipeliner, key string, id []string) {
+	for i := 1; i < len(id); i++ {
+		pipe.SAdd(ctx, r.childSetKey(id[:i]), key)
+	}
+}
+
+func (r *RedisTKV) childSetsRemove(ctx context.Context, pipe redis.Pipeliner, key string, id []string) {
+	for i := 1; i < len(id); i++ {
+		pipe.SRem(ctx, r.childSetKey(id[:i]), key)
+	}
+}
@@ -0,0 +1,120 @@
+// GNU AFFERO GENERAL PUBLIC LICENSE
+// Version 3, 19 November 2007
+//
+// Copyright (C) 2025 John Kleijn
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// For more details, see the full AGPL-3.0 license at:
+// https://www.gnu.org/licenses/agpl-3.0.html
+
+package rtkv_test
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/johnknl/rtkv"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedisTKV_GetChildren(t *testing.T) {
+	ctx := context.Background()
+
+	redisClient := newGoRedisClient(0)
+
+	t.Cleanup(func() {
+		redisClient.FlushDB(ctx).Err()
+	})
+
+	store := newRTKV(t, redisClient).With(rtkv.WithChildIndex())
+	now := time.Now()
+
+	require.NoError(t, store.BulkSet(ctx, []rtkv.BulkSetRecord{
+		{ID: []string{"t1", "o1"}, Data: []byte("o1"), LastModified: now},
+		{ID: []string{"t1", "o1", "l1"}, Data: []byte("l1"), LastModified: now},
+		{ID: []string{"t1", "o1", "l2"}, Data: []byte("l2"), LastModified: now},
+		{ID: []string{"t2", "o2"}, Data: []byte("o2"), LastModified: now},
+	}))
+
+	_, err := store.Set(ctx, []byte("o3"), now, "t1", "o3")
+	require.NoError(t, err)
+
+	ids := func(records []rtkv.Record) [][]string {
+		var result [][]string
+
+		for _, record := range records {
+			result = append(result, record.ID)
+		}
+
+		return result
+	}
+
+	children, err := store.GetChildren(ctx, "t1")
+
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{{"t1", "o1"}, {"t1", "o1", "l1"}, {"t1", "o1", "l2"}, {"t1", "o3"}}, ids(children))
+	assert.Equal(t, []byte("o1"), children[0].Data)
+	assert.WithinDuration(t, now, children[0].LastModified, time.Microsecond)
+
+	require.NoError(t, store.Delete(ctx, "t1", "o1", "l1"))
+
+	children, err = store.GetChildren(ctx, "t1", "o1")
+
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{{"t1", "o1", "l2"}}, ids(children))
+
+	t.Run("Stale", func(t *testing.T) {
+		_, err = store.Archive(ctx, now.Add(time.Second), rtkv.NewWriterSink(io.Discard), 0)
+		require.NoError(t, err)
+
+		children, err = store.GetChildren(ctx, "t1")
+
+		require.NoError(t, err)
+		assert.Empty(t, children)
+	})
+
+	t.Run("Missing", func(t *testing.T) {
+		children, err = store.GetChildren(ctx, "t3")
+
+		require.NoError(t, err)
+		assert.Empty(t, children)
+	})
+}
+
+func TestRedisTKV_GetChildren_Alias(t *testing.T) {
+	ctx := context.Background()
+	client := newGoRedisClient(0)
+	store := newRTKV(t, client).With(rtkv.WithChildIndex())
+	key := func(id ...string) string { return t.Name() + rtkv.DelimUnit + strings.Join(id, rtkv.DelimUnit) }
+
+	_, err := store.Set(ctx, []byte("target"), time.Now(), "target")
+	require.NoError(t, err)
+	_, err = store.Set(ctx, []byte("child"), time.Now(), "parent", "child")
+	require.NoError(t, err)
+	require.NoError(t, store.Alias(ctx, []string{"parent", "child"}, store, []string{"target"}))
+
+	// An alias still listed as a child, as when it replaced the
+	// child while the child index was read.
+	require.NoError(t, client.SAdd(ctx, key("\x00rtkv:childIdx", "parent"), key("parent", "child")).Err())
+
+	children, err := store.GetChildren(ctx, "parent")
+	require.NoError(t, err)
+	require.Len(t, children, 1)
+	assert.Equal(t, []byte("target"), children[0].Data, "Aliases should be followed")
+}
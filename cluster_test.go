@@ -0,0 +1,79 @@
+// GNU AFFERO GENERAL PUBLIC LICENSE
+// Version 3, 19 November 2007
+//
+// Copyright (C) 2025 John Kleijn
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// For more details, see the full AGPL-3.0 license at:
+// https://www.gnu.org/licenses/agpl-3.0.html
+
+package rtkv_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/johnknl/rtkv"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedisTKV_ClusterClient(t *testing.T) {
+	ctx := context.Background()
+	server := miniredis.RunT(t)
+	client := redis.NewClusterClient(&redis.ClusterOptions{Addrs: []string{server.Addr()}})
+
+	t.Cleanup(func() {
+		_ = client.Close()
+	})
+
+	store := rtkv.NewRedisTKV(rtkv.DelimUnit, "{users}", client)
+
+	_, err := store.Set(ctx, []byte("a"), time.Now(), "a")
+	require.NoError(t, err)
+	require.NoError(t, store.BulkSet(ctx, []rtkv.BulkSetRecord{
+		{LastModified: time.Now(), ID: []string{"b"}, Data: []byte("b")},
+	}))
+
+	value, err := store.Get(ctx, "a")
+	require.NoError(t, err)
+	assert.Equal(t, "a", string(value))
+
+	values, total, err := store.FetchPage(ctx, nil, nil, 0, 10)
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, total)
+
+	for value, err := range values {
+		require.NoError(t, err)
+		assert.NotEmpty(t, value)
+	}
+
+	var ids [][]string
+
+	for id, err := range store.IDs(ctx) {
+		require.NoError(t, err)
+
+		ids = append(ids, id)
+	}
+
+	assert.ElementsMatch(t, [][]string{{"a"}, {"b"}}, ids)
+
+	deleted, err := store.Clear(ctx)
+	require.NoError(t, err)
+	assert.EqualValues(t, 3, deleted)
+}
@@ -0,0 +1,166 @@
+// GNU AFFERO GENERAL PUBLIC LICENSE
+// Version 3, 19 November 2007
+//
+// Copyright (C) 2025 John Kleijn
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// For more details, see the full AGPL-3.0 license at:
+// https://www.gnu.org/licenses/agpl-3.0.html
+
+package rtkv
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Coalescer collapses Sets to the same entity made within a short
+// window into a single write of the newest value, cutting the write
+// load of producers that update the same entity many times per
+// second. Pending writes are flushed with BulkSet once the window
+// after the first of them has passed. Flush errors are logged
+// through the store's logger.
+//
+// Writes are acknowledged before they reach Redis, so they are
+// lost if the process dies within the window. Call Shutdown to
+// flush pending writes before exiting.
+type Coalescer struct {
+	store   *RedisTKV
+	window  time.Duration
+	pending map[string]BulkSetRecord
+	timer   *time.Timer
+	flushes sync.WaitGroup
+	mx      sync.Mutex
+}
+
+// NewCoalescer creates a coalescer that writes to store,
+// waiting window before flushing pending writes.
+func NewCoalescer(store *RedisTKV, window time.Duration) *Coalescer {
+	return &Coalescer{
+		store:   store,
+		window:  window,
+		pending: map[string]BulkSetRecord{},
+	}
+}
+
+// Set queues a write of an entity, replacing a pending write of the
+// same entity unless that one has a newer lastModified time.
+func (c *Coalescer) Set(ctx context.Context, data []byte, lastModified time.Time, id ...string) error {
+	if err := c.store.checkID(id); err != nil {
+		return err
+	}
+
+	key := c.store.namespacedKey(id...)
+
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	if pending, ok := c.pending[key]; ok {
+		c.store.stats.recordCoalesced(ctx)
+
+		if pending.LastModified.After(lastModified) {
+			return nil
+		}
+	}
+
+	c.pending[key] = BulkSetRecord{
+		LastModified: lastModified,
+		ID:           slices.Clone(id),
+		Data:         slices.Clone(data),
+	}
+
+	if c.timer == nil {
+		c.flushes.Add(1)
+		c.timer = time.AfterFunc(c.window, c.flushAsync)
+	}
+
+	return nil
+}
+
+// Pending returns the number of writes waiting to be flushed.
+func (c *Coalescer) Pending() int {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	return len(c.pending)
+}
+
+// Flush writes all pending writes right away.
+func (c *Coalescer) Flush(ctx context.Context) error {
+	c.mx.Lock()
+
+	if c.timer != nil && c.timer.Stop() {
+		c.flushes.Done()
+	}
+
+	c.timer = nil
+
+	records := make([]BulkSetRecord, 0, len(c.pending))
+
+	for _, record := range c.pending {
+		records = append(records, record)
+	}
+
+	clear(c.pending)
+	c.mx.Unlock()
+
+	if len(records) == 0 {
+		return nil
+	}
+
+	if err := c.store.BulkSet(ctx, records); err != nil {
+		return fmt.Errorf("failed to flush %d coalesced writes: %w", len(records), err)
+	}
+
+	return nil
+}
+
+// Shutdown flushes pending writes and waits for scheduled
+// flushes to finish until ctx is done.
+func (c *Coalescer) Shutdown(ctx context.Context) error {
+	if err := c.Flush(ctx); err != nil {
+		return err
+	}
+
+	done := make(chan struct{})
+
+	go func() {
+		c.flushes.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("coalescer did not drain: %w", context.Cause(ctx))
+	}
+}
+
+func (c *Coalescer) flushAsync() {
+	defer c.flushes.Done()
+
+	ctx := context.Background()
+
+	if err := c.Flush(ctx); err != nil {
+		c.store.logger.ErrorContext(ctx, "coalescer flush failed",
+			"namespace", c.store.namespace,
+			"error", err,
+		)
+	}
+}
@@ -0,0 +1,71 @@
+// GNU AFFERO GENERAL PUBLIC LICENSE
+// Version 3, 19 November 2007
+//
+// Copyright (C) 2025 John Kleijn
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// For more details, see the full AGPL-3.0 license at:
+// https://www.gnu.org/licenses/agpl-3.0.html
+
+package rtkv_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/johnknl/rtkv"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCoalescer(t *testing.T) {
+	ctx := context.Background()
+	store := newRTKV(t, newGoRedisClient(0))
+	coalescer := rtkv.NewCoalescer(store, 100*time.Millisecond)
+	now := time.Now()
+
+	require.NoError(t, coalescer.Set(ctx, []byte("1"), now, "entity", "1"))
+	require.NoError(t, coalescer.Set(ctx, []byte("2"), now.Add(time.Second), "entity", "1"))
+	require.NoError(t, coalescer.Set(ctx, []byte("stale"), now, "entity", "1"))
+	require.NoError(t, coalescer.Set(ctx, []byte("3"), now, "entity", "2"))
+
+	assert.Equal(t, 2, coalescer.Pending())
+
+	value, err := store.Get(ctx, "entity", "1")
+	require.NoError(t, err)
+	assert.Nil(t, value, "Writes should wait for the window")
+
+	assert.Eventually(t, func() bool {
+		return coalescer.Pending() == 0
+	}, time.Second, time.Millisecond)
+	require.NoError(t, coalescer.Shutdown(ctx))
+
+	value, lastModified, err := store.GetWithLastModified(ctx, "entity", "1")
+	require.NoError(t, err)
+	assert.Equal(t, "2", string(value))
+	assert.WithinDuration(t, now.Add(time.Second), lastModified, time.Microsecond)
+
+	stats := store.Stats().Writes
+	assert.EqualValues(t, 2, stats.Sets)
+	assert.EqualValues(t, 2, stats.Coalesced)
+
+	require.NoError(t, coalescer.Set(ctx, []byte("4"), now, "entity", "3"))
+	require.NoError(t, coalescer.Shutdown(ctx))
+
+	value, err = store.Get(ctx, "entity", "3")
+	require.NoError(t, err)
+	assert.Equal(t, "4", string(value), "Shutdown should flush pending writes")
+}
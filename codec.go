@@ -0,0 +1,138 @@
+// GNU AFFERO GENERAL PUBLIC LICENSE
+// Version 3, 19 November 2007
+//
+// Copyright (C) 2025 John Kleijn
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// For more details, see the full AGPL-3.0 license at:
+// https://www.gnu.org/licenses/agpl-3.0.html
+
+package rtkv
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Codec converts between values and their stored representation.
+// The jsoncodec, msgpackcodec and protocodec packages provide
+// implementations; switching between them doesn't affect call sites.
+type Codec interface {
+	Marshal(v any) ([]byte, error)
+	Unmarshal(data []byte, v any) error
+}
+
+// TypedRecord is a record of a Store.
+type TypedRecord[T any] struct {
+	LastModified time.Time
+	ID           []string
+	Value        T
+
+	// TTL, if set, makes the entity expire. See SetWithTTL.
+	TTL time.Duration
+}
+
+// Store is a typed view of a RedisTKV that encodes values with a
+// Codec, so callers work with T instead of bytes.
+type Store[T any] struct {
+	tkv     *RedisTKV
+	codec   Codec
+	garbage *garbageProbe
+}
+
+// NewStore returns a Store that stores values of type T in tkv,
+// encoded with codec.
+func NewStore[T any](tkv *RedisTKV, codec Codec) *Store[T] {
+	return &Store[T]{tkv: tkv, codec: codec}
+}
+
+// TKV returns the underlying store.
+func (s *Store[T]) TKV() *RedisTKV {
+	return s.tkv
+}
+
+// Get returns the value of an entity, or ErrNotFound if it doesn't
+// exist, as the zero T can't tell a missing entity apart.
+func (s *Store[T]) Get(ctx context.Context, id ...string) (T, error) {
+	var v T
+
+	data, err := s.tkv.Get(ctx, id...)
+	if err != nil {
+		return v, err
+	}
+
+	if data == nil {
+		return v, ErrNotFound
+	}
+
+	return v, s.decodeProbed(ctx, id, time.Now(), data, &v)
+}
+
+// Set encodes v and sets it like RedisTKV.Set.
+func (s *Store[T]) Set(ctx context.Context, v T, lastModified time.Time, id ...string) (bool, error) {
+	data, err := s.codec.Marshal(v)
+	if err != nil {
+		return false, fmt.Errorf("failed to encode value: %w", err)
+	}
+
+	return s.tkv.Set(ctx, data, lastModified, id...)
+}
+
+// BulkSet encodes the values of records and sets them
+// like RedisTKV.BulkSet.
+func (s *Store[T]) BulkSet(ctx context.Context, records []TypedRecord[T]) error {
+	encoded, err := EncodeRecords(s.codec, records)
+	if err != nil {
+		return err
+	}
+
+	return s.tkv.BulkSet(ctx, encoded)
+}
+
+// Delete deletes an entity like RedisTKV.Delete.
+func (s *Store[T]) Delete(ctx context.Context, id ...string) error {
+	return s.tkv.Delete(ctx, id...)
+}
+
+// EncodeRecords encodes typed records with codec, for
+// RedisTKV.BulkSet.
+func EncodeRecords[T any](codec Codec, records []TypedRecord[T]) ([]BulkSetRecord, error) {
+	encoded := make([]BulkSetRecord, len(records))
+
+	for i := range records {
+		data, err := codec.Marshal(records[i].Value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode value of %v: %w", records[i].ID, err)
+		}
+
+		encoded[i] = BulkSetRecord{
+			LastModified: records[i].LastModified,
+			ID:           records[i].ID,
+			Data:         data,
+			TTL:          records[i].TTL,
+		}
+	}
+
+	return encoded, nil
+}
+
+func (s *Store[T]) decode(data []byte, v *T) error {
+	if err := s.codec.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("failed to decode value: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,64 @@
+// GNU AFFERO GENERAL PUBLIC LICENSE
+// Version 3, 19 November 2007
+//
+// Copyright (C) 2025 John Kleijn
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// For more details, see the full AGPL-3.0 license at:
+// https://www.gnu.org/licenses/agpl-3.0.html
+
+package rtkv_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/johnknl/rtkv"
+	"github.com/johnknl/rtkv/jsoncodec"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStore(t *testing.T) {
+	type order struct {
+		Status string `json:"status"`
+	}
+
+	ctx := context.Background()
+	store := rtkv.NewStore[order](newRTKV(t, newGoRedisClient(0)), jsoncodec.Codec{})
+	now := time.Now()
+
+	_, err := store.Set(ctx, order{Status: "pending"}, now, "orders", "1")
+	require.NoError(t, err)
+
+	err = store.BulkSet(ctx, []rtkv.TypedRecord[order]{
+		{LastModified: now, ID: []string{"orders", "2"}, Value: order{Status: "paid"}},
+	})
+	require.NoError(t, err)
+
+	got, err := store.Get(ctx, "orders", "2")
+	require.NoError(t, err)
+	assert.Equal(t, order{Status: "paid"}, got)
+
+	raw, err := store.TKV().Get(ctx, "orders", "1")
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"status":"pending"}`, string(raw))
+
+	require.NoError(t, store.Delete(ctx, "orders", "1"))
+
+	_, err = store.Get(ctx, "orders", "1")
+	require.ErrorIs(t, err, rtkv.ErrNotFound)
+}
@@ -0,0 +1,345 @@
+// GNU AFFERO GENERAL PUBLIC LICENSE
+// Version 3, 19 November 2007
+//
+// Copyright (C) 2025 John Kleijn
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// For more details, see the full AGPL-3.0 license at:
+// https://www.gnu.org/licenses/agpl-3.0.html
+
+package rtkv
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"iter"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// compactedSuffix names the per-epoch shards of compacted
+	// entities, and the registry of their epochs and the hash of
+	// the epoch of every compacted entity next to them.
+	compactedSuffix = "compacted"
+	epochsSuffix    = "epochs"
+	membersSuffix   = "members"
+
+	defaultCompactEpoch     = 24 * time.Hour
+	defaultCompactChunkSize = 1000
+
+	// compactScript moves index entries into the shards of their
+	// epochs, unless their score changed since they were read, and
+	// registers the epochs it wrote to. Returns the number of entries
+	// moved.
+	compactScript = `
+local index = KEYS[1] -- the lastModified index
+local epochs = KEYS[2] -- the epoch registry
+local members = KEYS[3] -- the epoch of every compacted entity
+local moved = 0
+
+-- ARGV holds the member, its score, its epoch and the position
+-- in KEYS of the shard of that epoch, for every entry.
+for i = 1, #ARGV, 4 do
+  local member = ARGV[i]
+  local epoch = ARGV[i + 2]
+  local score = redis.call("ZSCORE", index, member)
+
+  if score and tonumber(score) == tonumber(ARGV[i + 1]) then
+    redis.call("ZADD", KEYS[tonumber(ARGV[i + 3])], score, member)
+    redis.call("ZADD", epochs, epoch, epoch)
+    redis.call("HSET", members, member, epoch)
+    redis.call("ZREM", index, member)
+    moved = moved + 1
+  end
+end
+
+return moved
+`
+
+	// compactedRemoveScript removes an entity from the shard of the
+	// given epoch, if it is still held there, and unregisters the epoch
+	// once its shard is empty. Entities that were rewritten rather than
+	// removed are only dropped once they are back in the lastModified
+	// index, so compacting them again isn't undone. Returns 1 if the
+	// entity was removed from the shard.
+	compactedRemoveScript = `
+local index = KEYS[1] -- the lastModified index
+local epochs = KEYS[2] -- the epoch registry
+local members = KEYS[3] -- the epoch of every compacted entity
+local shard = KEYS[4] -- the shard of the epoch
+local key = ARGV[1] -- the entity key
+local epoch = ARGV[2] -- the epoch the entity was compacted into
+local removed = ARGV[3] == "1" -- whether the entity was removed
+
+if redis.call("HGET", members, key) ~= epoch then
+  return 0
+end
+
+if not removed and not redis.call("ZSCORE", index, key) then
+  return 0
+end
+
+redis.call("ZREM", shard, key)
+redis.call("HDEL", members, key)
+
+if redis.call("EXISTS", shard) == 0 then
+  redis.call("ZREM", epochs, epoch)
+end
+
+return 1
+`
+)
+
+// ErrCompactionDisabled is returned by CompactIndex on
+// stores created without WithIndexCompaction.
+var ErrCompactionDisabled = errors.New("index compaction is not enabled")
+
+// WithIndexCompaction enables CompactIndex, and makes writes and
+// deletes of compacted entities remove them from their archive shard,
+// so a rewritten entity is only listed in the lastModified index and
+// SetIfNewer compares against its compacted lastModified time. Stores
+// without it don't pay for the lookups. All writers of a namespace
+// must enable it for the shards to stay accurate.
+func WithIndexCompaction() Option {
+	return func(r *RedisTKV) {
+		r.compaction = true
+	}
+}
+
+// CompactOptions controls how the index is compacted.
+type CompactOptions struct {
+	// Epoch is the time span covered by a single epoch.
+	// Defaults to 24 hours.
+	Epoch time.Duration
+
+	// ChunkSize is the number of entries moved per script call.
+	// Defaults to 1000.
+	ChunkSize int
+}
+
+// CompactIndex moves index entries last modified before the cutoff
+// into per-epoch archive shards, keeping the hot index and every shard
+// small enough for Redis to use its compact listpack encoding.
+// Entities rewritten or removed later leave their shard. Requires
+// WithIndexCompaction.
+//
+// Compacted entities are no longer returned by FetchPage or
+// FetchPageConsistent. Use IndexEpochs and FetchEpochPage to
+// read them. Returns the number of entries moved.
+func (r *RedisTKV) CompactIndex(ctx context.Context, before time.Time, opts CompactOptions) (int64, error) {
+	if !r.compaction {
+		return 0, ErrCompactionDisabled
+	}
+
+	if err := r.checkWritable(ctx); err != nil {
+		return 0, err
+	}
+
+	opts = opts.withDefaults()
+
+	var moved int64
+
+	budget := budgetFrom(ctx)
+
+	for {
+		if err := budget.spend(ctx, opts.ChunkSize); err != nil {
+			return moved, err
+		}
+
+		entries, err := r.client.ZRangeArgsWithScores(ctx, redis.ZRangeArgs{
+			Key:     r.namespacedKey(lastModifiedIdxSuffix),
+			Start:   "-inf",
+			Stop:    "(" + strconv.FormatInt(before.UnixNano(), 10),
+			ByScore: true,
+			Count:   int64(opts.ChunkSize),
+		}).Result()
+		if err != nil {
+			return moved, fmt.Errorf("failed to read index: %w", err)
+		}
+
+		if len(entries) == 0 {
+			return moved, nil
+		}
+
+		n, err := r.compactEntries(ctx, entries, opts.Epoch)
+		if err != nil {
+			return moved, err
+		}
+
+		moved += n
+
+		if len(entries) < opts.ChunkSize {
+			return moved, nil
+		}
+	}
+}
+
+// compactEntries moves index entries into the shards of their epochs.
+// Every shard is passed as a key, so the script only touches keys it
+// declares.
+func (r *RedisTKV) compactEntries(ctx context.Context, entries []redis.Z, epochLen time.Duration) (int64, error) {
+	keys := []string{
+		r.namespacedKey(lastModifiedIdxSuffix),
+		r.internalKey(compactedSuffix, epochsSuffix),
+		r.internalKey(compactedSuffix, membersSuffix),
+	}
+	shards := map[string]int{}
+	args := make([]any, 0, 4*len(entries)) //nolint:mnd // four arguments per entry
+
+	for _, entry := range entries {
+		epoch := strconv.FormatInt(int64(math.Floor(entry.Score/float64(epochLen)))*int64(epochLen), 10)
+
+		shard, ok := shards[epoch]
+		if !ok {
+			keys = append(keys, r.internalKey(compactedSuffix, epoch))
+			shard = len(keys)
+			shards[epoch] = shard
+		}
+
+		args = append(args, entry.Member, strconv.FormatFloat(entry.Score, 'f', -1, 64), epoch, shard)
+	}
+
+	result, err := r.evalScript(ctx, compactScript, keys, args...)
+	if err != nil {
+		return 0, fmt.Errorf("failed to compact index: %w", err)
+	}
+
+	moved, ok := result.(int64)
+	if !ok {
+		return 0, ErrUnexpectedScriptResult
+	}
+
+	return moved, nil
+}
+
+// IndexEpochs returns the start times of all archive shards
+// created by CompactIndex, oldest first.
+func (r *RedisTKV) IndexEpochs(ctx context.Context) ([]time.Time, error) {
+	members, err := r.client.ZRange(ctx, r.internalKey(compactedSuffix, epochsSuffix), 0, -1).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list epochs: %w", err)
+	}
+
+	epochs := make([]time.Time, 0, len(members))
+
+	for _, member := range members {
+		nanos, err := strconv.ParseInt(member, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid epoch %q: %w", member, err)
+		}
+
+		epochs = append(epochs, time.Unix(0, nanos))
+	}
+
+	return epochs, nil
+}
+
+// FetchEpochPage fetches a page of entities from the archive shard
+// starting at epoch, oldest first.
+func (r *RedisTKV) FetchEpochPage(
+	ctx context.Context,
+	epoch time.Time,
+	offset, limit int,
+) (iter.Seq2[[]byte, error], int64, error) {
+	key := r.internalKey(compactedSuffix, strconv.FormatInt(epoch.UnixNano(), 10))
+
+	return r.fetchIndexPage(ctx, key, "-inf", "+inf", offset, limit)
+}
+
+// compactedEpoch returns the epoch of the shard holding an
+// entity, or an empty string if it isn't compacted.
+func (r *RedisTKV) compactedEpoch(ctx context.Context, key string) (string, error) {
+	if !r.compaction {
+		return "", nil
+	}
+
+	epoch, err := r.client.HGet(ctx, r.internalKey(compactedSuffix, membersSuffix), key).Result()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return "", fmt.Errorf("failed to look up compacted entity: %w", err)
+	}
+
+	return epoch, nil
+}
+
+// compactedRemove removes entities from the archive shards holding
+// them, after a write that removed them, or rewrote them into the
+// lastModified index. A no-op without WithIndexCompaction.
+func (r *RedisTKV) compactedRemove(ctx context.Context, removed bool, keys ...string) error {
+	if !r.compaction || len(keys) == 0 {
+		return nil
+	}
+
+	epochs, err := r.client.HMGet(ctx, r.internalKey(compactedSuffix, membersSuffix), keys...).Result()
+	if err != nil {
+		return fmt.Errorf("failed to look up compacted entities: %w", err)
+	}
+
+	for i, epoch := range epochs {
+		epoch, ok := epoch.(string)
+		if !ok {
+			continue
+		}
+
+		shardKeys := []string{
+			r.namespacedKey(lastModifiedIdxSuffix),
+			r.internalKey(compactedSuffix, epochsSuffix),
+			r.internalKey(compactedSuffix, membersSuffix),
+			r.internalKey(compactedSuffix, epoch),
+		}
+
+		if _, err = r.evalScript(ctx, compactedRemoveScript, shardKeys, keys[i], epoch, removed); err != nil {
+			return fmt.Errorf("failed to remove compacted entity: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// IndexCompactionTask returns a janitor task that compacts the index
+// once it holds more than maxMembers entries, moving everything last
+// modified longer than keepFor ago into archive shards.
+func IndexCompactionTask(maxMembers int64, keepFor time.Duration, opts CompactOptions) JanitorTask {
+	return func(ctx context.Context, r *RedisTKV) error {
+		members, err := r.client.ZCard(ctx, r.namespacedKey(lastModifiedIdxSuffix)).Result()
+		if err != nil {
+			return fmt.Errorf("failed to count index: %w", err)
+		}
+
+		if members <= maxMembers {
+			return nil
+		}
+
+		_, err = r.CompactIndex(ctx, time.Now().Add(-keepFor), opts)
+
+		return err
+	}
+}
+
+func (o CompactOptions) withDefaults() CompactOptions {
+	if o.Epoch <= 0 {
+		o.Epoch = defaultCompactEpoch
+	}
+
+	if o.ChunkSize <= 0 {
+		o.ChunkSize = defaultCompactChunkSize
+	}
+
+	return o
+}
@@ -0,0 +1,222 @@
+// GNU AFFERO GENERAL PUBLIC LICENSE
+// Version 3, 19 November 2007
+//
+// Copyright (C) 2025 John Kleijn
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// For more details, see the full AGPL-3.0 license at:
+// https://www.gnu.org/licenses/agpl-3.0.html
+
+package rtkv_test
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/johnknl/rtkv"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedisTKV_CompactIndex(t *testing.T) {
+	ctx := context.Background()
+
+	redisClient := newGoRedisClient(0)
+
+	t.Cleanup(func() {
+		redisClient.FlushDB(ctx).Err()
+	})
+
+	store := newRTKV(t, redisClient).With(rtkv.WithIndexCompaction())
+	day := time.Date(2025, 1, 10, 0, 0, 0, 0, time.UTC)
+
+	var records []rtkv.BulkSetRecord
+
+	for i := range 9 {
+		records = append(records, rtkv.BulkSetRecord{
+			ID:           []string{strconv.Itoa(i)},
+			Data:         []byte(strconv.Itoa(i)),
+			LastModified: day.Add(time.Duration(i) * 8 * time.Hour),
+		})
+	}
+
+	require.NoError(t, store.BulkSet(ctx, records))
+
+	moved, err := store.CompactIndex(ctx, day.Add(48*time.Hour), rtkv.CompactOptions{ChunkSize: 2})
+
+	require.NoError(t, err)
+	assert.EqualValues(t, 6, moved)
+
+	_, total, err := store.FetchPage(ctx, nil, nil, 0, 10)
+
+	require.NoError(t, err)
+	assert.EqualValues(t, 3, total, "Compacted entries should leave the hot index")
+
+	epochs, err := store.IndexEpochs(ctx)
+
+	require.NoError(t, err)
+	require.Len(t, epochs, 2)
+	assert.True(t, day.Equal(epochs[0]))
+	assert.True(t, day.Add(24*time.Hour).Equal(epochs[1]))
+
+	shard := t.Name() + rtkv.DelimUnit + "\x00rtkv:compacted" + rtkv.DelimUnit + strconv.FormatInt(epochs[1].UnixNano(), 10)
+	assert.EqualValues(t, 3, redisClient.ZCard(ctx, shard).Val(), "Each epoch should have a shard of its own")
+
+	it, total, err := store.FetchEpochPage(ctx, epochs[1], 0, 10)
+
+	require.NoError(t, err)
+	assert.EqualValues(t, 3, total)
+
+	var values []string
+
+	for data, err := range it {
+		require.NoError(t, err)
+		values = append(values, string(data))
+	}
+
+	assert.Equal(t, []string{"3", "4", "5"}, values)
+}
+
+func TestIndexCompactionTask(t *testing.T) {
+	ctx := context.Background()
+	store := goRedisSetup(t, 100).With(rtkv.WithIndexCompaction())
+
+	require.NoError(t, rtkv.IndexCompactionTask(100, 0, rtkv.CompactOptions{})(ctx, store))
+
+	_, total, err := store.FetchPage(ctx, nil, nil, 0, 1)
+
+	require.NoError(t, err)
+	assert.EqualValues(t, 100, total, "Index within limits should not be compacted")
+
+	require.NoError(t, rtkv.IndexCompactionTask(99, 0, rtkv.CompactOptions{})(ctx, store))
+
+	_, total, err = store.FetchPage(ctx, nil, nil, 0, 1)
+
+	require.NoError(t, err)
+	assert.EqualValues(t, 0, total)
+}
+
+func TestRedisTKV_CompactIndex_Delete(t *testing.T) {
+	ctx := context.Background()
+	store := newRTKV(t, newGoRedisClient(0)).With(rtkv.WithIndexCompaction())
+	day := time.Date(2025, 1, 10, 0, 0, 0, 0, time.UTC)
+
+	_, err := store.Clear(ctx)
+	require.NoError(t, err)
+
+	for _, id := range []string{"a", "b"} {
+		_, err = store.Set(ctx, []byte(id), day, id)
+		require.NoError(t, err)
+	}
+
+	_, err = store.CompactIndex(ctx, day.Add(time.Hour), rtkv.CompactOptions{})
+	require.NoError(t, err)
+
+	// Entities named like internal keys don't address them.
+	_, err = store.Set(ctx, []byte("c"), time.Now(), "compacted", "epochs")
+	require.NoError(t, err)
+	require.NoError(t, store.Delete(ctx, "compacted", "epochs"))
+
+	epochs, err := store.IndexEpochs(ctx)
+	require.NoError(t, err)
+	assert.Len(t, epochs, 1)
+
+	require.NoError(t, store.Delete(ctx, "a"))
+
+	it, total, err := store.FetchEpochPage(ctx, day, 0, 10)
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, total, "Deleted entities should leave their shard")
+
+	var values []string
+
+	for data, err := range it {
+		require.NoError(t, err)
+		values = append(values, string(data))
+	}
+
+	assert.Equal(t, []string{"b"}, values)
+
+	require.NoError(t, store.Delete(ctx, "b"))
+
+	epochs, err = store.IndexEpochs(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, epochs, "Empty shards should be unregistered")
+}
+
+func TestRedisTKV_CompactIndex_Disabled(t *testing.T) {
+	store := newRTKV(t, newGoRedisClient(0))
+
+	_, err := store.CompactIndex(context.Background(), time.Now(), rtkv.CompactOptions{})
+	require.ErrorIs(t, err, rtkv.ErrCompactionDisabled)
+}
+
+func TestRedisTKV_CompactIndex_Rewrite(t *testing.T) {
+	ctx := context.Background()
+	store := newRTKV(t, newGoRedisClient(0)).With(rtkv.WithIndexCompaction())
+	day := time.Date(2025, 1, 10, 0, 0, 0, 0, time.UTC)
+
+	_, err := store.Clear(ctx)
+	require.NoError(t, err)
+
+	require.NoError(t, store.BulkSet(ctx, []rtkv.BulkSetRecord{
+		{LastModified: day, ID: []string{"a"}, Data: []byte("a")},
+		{LastModified: day, ID: []string{"b"}, Data: []byte("b")},
+		{LastModified: day, ID: []string{"c"}, Data: []byte("c")},
+	}))
+
+	moved, err := store.CompactIndex(ctx, day.Add(time.Hour), rtkv.CompactOptions{})
+	require.NoError(t, err)
+	assert.EqualValues(t, 3, moved)
+
+	written, err := store.SetIfNewer(ctx, []byte("a0"), day.Add(-time.Hour), "a")
+	require.NoError(t, err)
+	assert.False(t, written, "Older writes of compacted entities should be rejected")
+
+	written, err = store.SetIfNewer(ctx, []byte("a1"), day.Add(time.Hour), "a")
+	require.NoError(t, err)
+	assert.True(t, written)
+
+	_, err = store.Set(ctx, []byte("b1"), day.Add(time.Hour), "b")
+	require.NoError(t, err)
+
+	_, total, err := store.FetchPage(ctx, nil, nil, 0, 10)
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, total)
+
+	it, total, err := store.FetchEpochPage(ctx, day, 0, 10)
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, total, "Rewritten entities should leave their shard")
+	assert.Equal(t, []string{"c"}, collect(t, it))
+
+	require.NoError(t, store.Rename(ctx, []string{"c"}, []string{"d"}))
+
+	it, _, err = store.FetchEpochPage(ctx, day, 0, 10)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"c"}, collect(t, it), "Renamed entities should stay compacted")
+
+	_, total, err = store.FetchPage(ctx, nil, nil, 0, 10)
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, total)
+
+	require.NoError(t, store.BulkSet(ctx, []rtkv.BulkSetRecord{
+		{LastModified: day.Add(2 * time.Hour), ID: []string{"d"}, Data: []byte("d")},
+	}))
+
+	epochs, err := store.IndexEpochs(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, epochs, "Epochs whose entities were all rewritten should be unregistered")
+}
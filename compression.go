@@ -0,0 +1,120 @@
+// GNU AFFERO GENERAL PUBLIC LICENSE
+// Version 3, 19 November 2007
+//
+// Copyright (C) 2025 John Kleijn
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// For more details, see the full AGPL-3.0 license at:
+// https://www.gnu.org/licenses/agpl-3.0.html
+
+package rtkv
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/klauspost/compress/snappy"
+	"github.com/klauspost/compress/zstd"
+)
+
+// compressionHeader starts compressed values. It is
+// followed by a byte identifying the algorithm.
+const compressionHeader = valueHeader + "z"
+
+// ValueCompression is an algorithm to compress values with.
+type ValueCompression byte
+
+const (
+	// ValueZstd compresses values with Zstandard, which
+	// compresses best.
+	ValueZstd ValueCompression = 'z'
+
+	// ValueSnappy compresses values with Snappy, which
+	// is the fastest.
+	ValueSnappy ValueCompression = 's'
+)
+
+// ErrUnknownCompression is returned for values compressed
+// with an unknown algorithm.
+var ErrUnknownCompression = errors.New("unknown value compression")
+
+var (
+	zstdEncoder, _ = zstd.NewWriter(nil)
+	zstdDecoder, _ = zstd.NewReader(nil)
+)
+
+// valueCompressor compresses values of at least threshold bytes.
+type valueCompressor struct {
+	algorithm ValueCompression
+	threshold int
+}
+
+// WithValueCompression compresses values of at least threshold bytes
+// before writing them, and decompresses them when read. Compressed
+// values start with a short header naming the algorithm, so values
+// written without compression, or with another algorithm, can still
+// be read. Export, Import and CopyNamespace move values compressed.
+func WithValueCompression(algorithm ValueCompression, threshold int) Option {
+	return func(r *RedisTKV) {
+		r.withTransform(valueCompressor{algorithm: algorithm, threshold: threshold})
+	}
+}
+
+func (c valueCompressor) encode(_ []string, data []byte) ([]byte, error) {
+	if len(data) < c.threshold {
+		return data, nil
+	}
+
+	dst := make([]byte, 0, len(compressionHeader)+1+len(data)/2) //nolint:mnd // a guess
+	dst = append(dst, compressionHeader...)
+	dst = append(dst, byte(c.algorithm))
+
+	switch c.algorithm {
+	case ValueZstd:
+		return zstdEncoder.EncodeAll(data, dst), nil
+	case ValueSnappy:
+		return append(dst, snappy.Encode(nil, data)...), nil
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnknownCompression, byte(c.algorithm))
+	}
+}
+
+func (valueCompressor) decode(data []byte) ([]byte, error) {
+	rest, ok := bytes.CutPrefix(data, []byte(compressionHeader))
+	if !ok || len(rest) == 0 {
+		return data, nil
+	}
+
+	var (
+		decoded []byte
+		err     error
+	)
+
+	switch ValueCompression(rest[0]) {
+	case ValueZstd:
+		decoded, err = zstdDecoder.DecodeAll(rest[1:], nil)
+	case ValueSnappy:
+		decoded, err = snappy.Decode(nil, rest[1:])
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnknownCompression, rest[0])
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress value: %w", err)
+	}
+
+	return decoded, nil
+}
@@ -0,0 +1,91 @@
+// GNU AFFERO GENERAL PUBLIC LICENSE
+// Version 3, 19 November 2007
+//
+// Copyright (C) 2025 John Kleijn
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// For more details, see the full AGPL-3.0 license at:
+// https://www.gnu.org/licenses/agpl-3.0.html
+
+package rtkv_test
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/johnknl/rtkv"
+	"github.com/johnknl/rtkv/rtkvtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedisTKV_WithValueCompression(t *testing.T) {
+	for _, algorithm := range []rtkv.ValueCompression{rtkv.ValueZstd, rtkv.ValueSnappy} {
+		t.Run(string(rune(algorithm)), func(t *testing.T) {
+			ctx := context.Background()
+			mr, client := rtkvtest.NewMiniredis(t)
+			plain := rtkv.NewRedisTKV(rtkv.DelimUnit, t.Name(), client)
+			store := plain.With(rtkv.WithValueCompression(algorithm, 64))
+			large := []byte(strings.Repeat("compressible ", 100))
+
+			_, err := plain.Set(ctx, []byte("old"), time.Now(), "old")
+			require.NoError(t, err)
+			_, err = store.Set(ctx, []byte("small"), time.Now(), "small")
+			require.NoError(t, err)
+			_, err = store.Set(ctx, large, time.Now(), "large")
+			require.NoError(t, err)
+
+			stored, err := mr.Get(t.Name() + rtkv.DelimUnit + "large")
+			require.NoError(t, err)
+			assert.True(t, strings.HasPrefix(stored, "\x00rtkv:z"), "Large values should be stored compressed")
+			assert.Less(t, len(stored), len(large))
+
+			stored, err = mr.Get(t.Name() + rtkv.DelimUnit + "small")
+			require.NoError(t, err)
+			assert.Equal(t, "small", stored, "Values below the threshold should be stored as is")
+
+			for id, want := range map[string][]byte{"old": []byte("old"), "small": []byte("small"), "large": large} {
+				value, err := store.Get(ctx, id)
+				require.NoError(t, err)
+				assert.Equal(t, want, value)
+			}
+
+			values, _, err := store.FetchPage(ctx, nil, nil, 0, 10)
+			require.NoError(t, err)
+
+			for value, err := range values {
+				require.NoError(t, err)
+				assert.True(t, bytes.Equal(value, large) || len(value) < 64, "Pages should be decompressed")
+			}
+		})
+	}
+}
+
+func TestRedisTKV_WithValueCompression_BulkSet(t *testing.T) {
+	ctx := context.Background()
+	_, client := rtkvtest.NewMiniredis(t)
+	store := rtkv.NewRedisTKV(rtkv.DelimUnit, t.Name(), client, rtkv.WithValueCompression(rtkv.ValueZstd, 0))
+	records := []rtkv.BulkSetRecord{{ID: []string{"a"}, Data: []byte("value"), LastModified: time.Now()}}
+
+	require.NoError(t, store.BulkSet(ctx, records))
+	assert.Equal(t, "value", string(records[0].Data), "BulkSet shouldn't modify its argument")
+
+	value, err := store.Get(ctx, "a")
+	require.NoError(t, err)
+	assert.Equal(t, "value", string(value))
+}
@@ -0,0 +1,363 @@
+// GNU AFFERO GENERAL PUBLIC LICENSE
+// Version 3, 19 November 2007
+//
+// Copyright (C) 2025 John Kleijn
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// For more details, see the full AGPL-3.0 license at:
+// https://www.gnu.org/licenses/agpl-3.0.html
+
+package rtkv
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// ErrInvalidConfig is returned for configurations NewFromConfig
+// can't create a store from.
+var ErrInvalidConfig = errors.New("invalid configuration")
+
+// Duration is a time.Duration that is encoded as a string such as
+// "1m30s" in JSON, YAML and environment variables.
+type Duration time.Duration
+
+// MarshalText implements encoding.TextMarshaler.
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(time.Duration(d).String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (d *Duration) UnmarshalText(text []byte) error {
+	parsed, err := time.ParseDuration(string(text))
+	if err != nil {
+		return fmt.Errorf("failed to parse duration: %w", err)
+	}
+
+	*d = Duration(parsed)
+
+	return nil
+}
+
+// Config declares a store and its Redis connection, so deployments
+// can configure rtkv without code changes. It can be unmarshaled
+// from JSON or YAML, or loaded from the environment with
+// ConfigFromEnv. Use NewFromConfig to create a store from it.
+//
+// Codecs and metrics are out of its scope and are set up in code: a
+// codec is bound to the value type of a Store, see NewStore, and
+// Stats returns the metrics of a store to feed to the metrics system
+// of the application, which serves them.
+type Config struct {
+	Namespace string `env:"NAMESPACE" json:"namespace" yaml:"namespace"`
+
+	// Delimiter is "unit" (DelimUnit, the default), "pipe"
+	// (DelimPipe) or a literal delimiter.
+	Delimiter string `env:"DELIMITER" json:"delimiter" yaml:"delimiter"`
+
+	// NamespaceSeparator is a literal separator between the namespace
+	// and IDs. Defaults to the delimiter. See WithNamespaceSeparator.
+	NamespaceSeparator string `env:"NAMESPACE_SEPARATOR" json:"namespaceSeparator" yaml:"namespaceSeparator"`
+
+	Redis RedisConfig `env:"REDIS_" json:"redis" yaml:"redis"`
+
+	// Replicas are the addresses of read replicas. They share
+	// the connection settings of Redis.
+	Replicas      []string `env:"REPLICAS"        json:"replicas"      yaml:"replicas"`
+	MaxReplicaLag Duration `env:"MAX_REPLICA_LAG" json:"maxReplicaLag" yaml:"maxReplicaLag"`
+
+	// Consistency is "eventual" (the default) or "strong".
+	Consistency string `env:"CONSISTENCY" json:"consistency" yaml:"consistency"`
+
+	// RefPolicy is "ignore" (the default), "block" or "cascade".
+	RefPolicy string `env:"REF_POLICY" json:"refPolicy" yaml:"refPolicy"`
+
+	SkipIdenticalWrites bool     `env:"SKIP_IDENTICAL_WRITES" json:"skipIdenticalWrites" yaml:"skipIdenticalWrites"`
+	ChildIndex          bool     `env:"CHILD_INDEX"           json:"childIndex"          yaml:"childIndex"`
+	History             bool     `env:"HISTORY"               json:"history"             yaml:"history"`
+	ConflictTracking    bool     `env:"CONFLICT_TRACKING"     json:"conflictTracking"    yaml:"conflictTracking"`
+	SlowOpThreshold     Duration `env:"SLOW_OP_THRESHOLD"     json:"slowOpThreshold"     yaml:"slowOpThreshold"`
+	MultiKeyLimit       int      `env:"MULTI_KEY_LIMIT"       json:"multiKeyLimit"       yaml:"multiKeyLimit"`
+	NotFoundError       bool     `env:"NOT_FOUND_ERROR"       json:"notFoundError"       yaml:"notFoundError"`
+
+	// DefaultTTL is the TTL of entities written without one.
+	DefaultTTL Duration `env:"DEFAULT_TTL" json:"defaultTTL" yaml:"defaultTTL"`
+
+	// FetchConcurrency limits the number of concurrent page fetches.
+	FetchConcurrency int `env:"FETCH_CONCURRENCY" json:"fetchConcurrency" yaml:"fetchConcurrency"`
+
+	// Compression is "zstd" or "snappy" to compress values of at
+	// least CompressionThreshold bytes. See WithValueCompression.
+	Compression          string `env:"COMPRESSION"           json:"compression"          yaml:"compression"`
+	CompressionThreshold int    `env:"COMPRESSION_THRESHOLD" json:"compressionThreshold" yaml:"compressionThreshold"`
+
+	// UpdateMaxRetries, UpdateBackoff and UpdateMaxBackoff set how
+	// updates retry on conflicts. See WithUpdateRetries. Retries of
+	// failed commands are set in Redis.
+	UpdateMaxRetries int      `env:"UPDATE_MAX_RETRIES" json:"updateMaxRetries" yaml:"updateMaxRetries"`
+	UpdateBackoff    Duration `env:"UPDATE_BACKOFF"     json:"updateBackoff"    yaml:"updateBackoff"`
+	UpdateMaxBackoff Duration `env:"UPDATE_MAX_BACKOFF" json:"updateMaxBackoff" yaml:"updateMaxBackoff"`
+
+	// TotalsCache is the time totals are cached. See WithTotalsCache.
+	TotalsCache Duration `env:"TOTALS_CACHE" json:"totalsCache" yaml:"totalsCache"`
+}
+
+// RedisConfig declares a Redis connection. Zero values
+// use the defaults of go-redis.
+type RedisConfig struct {
+	// Addr is the address of Redis, in any of the
+	// forms accepted by NewRedisTKVAddr.
+	Addr     string `env:"ADDR"      json:"addr"     yaml:"addr"`
+	Username string `env:"USERNAME"  json:"username" yaml:"username"`
+	Password string `env:"PASSWORD"  json:"password" yaml:"password"`
+	DB       int    `env:"DB"        json:"db"       yaml:"db"`
+	PoolSize int    `env:"POOL_SIZE" json:"poolSize" yaml:"poolSize"`
+
+	MaxRetries      int      `env:"MAX_RETRIES"       json:"maxRetries"      yaml:"maxRetries"`
+	MinRetryBackoff Duration `env:"MIN_RETRY_BACKOFF" json:"minRetryBackoff" yaml:"minRetryBackoff"`
+	MaxRetryBackoff Duration `env:"MAX_RETRY_BACKOFF" json:"maxRetryBackoff" yaml:"maxRetryBackoff"`
+	DialTimeout     Duration `env:"DIAL_TIMEOUT"      json:"dialTimeout"     yaml:"dialTimeout"`
+	ReadTimeout     Duration `env:"READ_TIMEOUT"      json:"readTimeout"     yaml:"readTimeout"`
+	WriteTimeout    Duration `env:"WRITE_TIMEOUT"     json:"writeTimeout"    yaml:"writeTimeout"`
+}
+
+// ConfigFromEnv loads a Config from environment variables named
+// after the env tags of its fields, with prefix prepended: with
+// prefix "RTKV_", the namespace is read from RTKV_NAMESPACE and the
+// Redis address from RTKV_REDIS_ADDR. Lists are comma separated.
+// Variables that are not set leave fields at their zero value.
+func ConfigFromEnv(prefix string) (Config, error) {
+	var cfg Config
+
+	err := loadEnv(reflect.ValueOf(&cfg).Elem(), prefix)
+
+	return cfg, err
+}
+
+// NewFromConfig creates a store and its Redis clients from cfg.
+// Options are applied after those derived from cfg.
+func NewFromConfig(cfg Config, opts ...Option) (*RedisTKV, error) {
+	if cfg.Namespace == "" {
+		return nil, fmt.Errorf("%w: namespace is required", ErrInvalidConfig)
+	}
+
+	derived, err := cfg.options()
+	if err != nil {
+		return nil, err
+	}
+
+	delimiter := cfg.Delimiter
+
+	switch delimiter {
+	case "", "unit":
+		delimiter = DelimUnit
+	case "pipe":
+		delimiter = DelimPipe
+	}
+
+	options, err := cfg.Redis.parse()
+	if err != nil {
+		return nil, err
+	}
+
+	return NewRedisTKV(delimiter, cfg.Namespace, redis.NewClient(options), append(derived, opts...)...), nil
+}
+
+func (cfg Config) options() ([]Option, error) {
+	var opts []Option
+
+	switch cfg.Consistency {
+	case "", ConsistencyEventual.String():
+	case ConsistencyStrong.String():
+		opts = append(opts, WithConsistency(ConsistencyStrong))
+	default:
+		return nil, fmt.Errorf("%w: unknown consistency %q", ErrInvalidConfig, cfg.Consistency)
+	}
+
+	switch cfg.RefPolicy {
+	case "", "ignore":
+	case "block":
+		opts = append(opts, WithRefPolicy(RefPolicyBlock))
+	case "cascade":
+		opts = append(opts, WithRefPolicy(RefPolicyCascade))
+	default:
+		return nil, fmt.Errorf("%w: unknown ref policy %q", ErrInvalidConfig, cfg.RefPolicy)
+	}
+
+	if len(cfg.Replicas) > 0 {
+		replicas := make([]*redis.Client, len(cfg.Replicas))
+
+		for i, addr := range cfg.Replicas {
+			replica := cfg.Redis
+			replica.Addr = addr
+
+			options, err := replica.parse()
+			if err != nil {
+				return nil, err
+			}
+
+			replicas[i] = redis.NewClient(options)
+		}
+
+		opts = append(opts, WithReadReplicas(ReplicaOptions{MaxLag: time.Duration(cfg.MaxReplicaLag)}, replicas...))
+	}
+
+	if cfg.NamespaceSeparator != "" {
+		opts = append(opts, WithNamespaceSeparator(cfg.NamespaceSeparator))
+	}
+
+	if cfg.SkipIdenticalWrites {
+		opts = append(opts, WithSkipIdenticalWrites())
+	}
+
+	if cfg.ChildIndex {
+		opts = append(opts, WithChildIndex())
+	}
+
+	if cfg.History {
+		opts = append(opts, WithHistory())
+	}
+
+	if cfg.ConflictTracking {
+		opts = append(opts, WithConflictTracking())
+	}
+
+	if cfg.SlowOpThreshold > 0 {
+		opts = append(opts, WithSlowOpThreshold(time.Duration(cfg.SlowOpThreshold)))
+	}
+
+	if cfg.MultiKeyLimit > 0 {
+		opts = append(opts, WithMultiKeyLimit(cfg.MultiKeyLimit))
+	}
+
+	if cfg.DefaultTTL > 0 {
+		opts = append(opts, WithDefaultTTL(time.Duration(cfg.DefaultTTL)))
+	}
+
+	if cfg.NotFoundError {
+		opts = append(opts, WithNotFoundError())
+	}
+
+	if cfg.FetchConcurrency > 0 {
+		opts = append(opts, WithFetchConcurrency(cfg.FetchConcurrency))
+	}
+
+	switch cfg.Compression {
+	case "":
+	case "zstd":
+		opts = append(opts, WithValueCompression(ValueZstd, cfg.CompressionThreshold))
+	case "snappy":
+		opts = append(opts, WithValueCompression(ValueSnappy, cfg.CompressionThreshold))
+	default:
+		return nil, fmt.Errorf("%w: unknown compression %q", ErrInvalidConfig, cfg.Compression)
+	}
+
+	if cfg.UpdateMaxRetries > 0 || cfg.UpdateBackoff > 0 || cfg.UpdateMaxBackoff > 0 {
+		opts = append(opts, WithUpdateRetries(UpdateRetryPolicy{
+			MaxRetries: cfg.UpdateMaxRetries,
+			Backoff:    time.Duration(cfg.UpdateBackoff),
+			MaxBackoff: time.Duration(cfg.UpdateMaxBackoff),
+		}))
+	}
+
+	if cfg.TotalsCache > 0 {
+		opts = append(opts, WithTotalsCache(time.Duration(cfg.TotalsCache)))
+	}
+
+	return opts, nil
+}
+
+func (cfg RedisConfig) options() *redis.Options {
+	return &redis.Options{
+		Addr:            cfg.Addr,
+		Username:        cfg.Username,
+		Password:        cfg.Password,
+		DB:              cfg.DB,
+		PoolSize:        cfg.PoolSize,
+		MaxRetries:      cfg.MaxRetries,
+		MinRetryBackoff: time.Duration(cfg.MinRetryBackoff),
+		MaxRetryBackoff: time.Duration(cfg.MaxRetryBackoff),
+		DialTimeout:     time.Duration(cfg.DialTimeout),
+		ReadTimeout:     time.Duration(cfg.ReadTimeout),
+		WriteTimeout:    time.Duration(cfg.WriteTimeout),
+	}
+}
+
+// loadEnv sets the fields of the struct v from environment
+// variables named after their env tags.
+func loadEnv(v reflect.Value, prefix string) error {
+	for i := range v.NumField() {
+		field := v.Type().Field(i)
+		name := prefix + field.Tag.Get("env")
+		value := v.Field(i)
+
+		if field.Type.Kind() == reflect.Struct {
+			if err := loadEnv(value, name); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		raw, ok := os.LookupEnv(name)
+		if !ok {
+			continue
+		}
+
+		if err := setEnvValue(value, raw); err != nil {
+			return fmt.Errorf("%w: %s: %w", ErrInvalidConfig, name, err)
+		}
+	}
+
+	return nil
+}
+
+func setEnvValue(value reflect.Value, raw string) error {
+	if u, ok := value.Addr().Interface().(interface{ UnmarshalText(text []byte) error }); ok {
+		return u.UnmarshalText([]byte(raw))
+	}
+
+	switch value.Kind() { //nolint:exhaustive // only kinds used by Config
+	case reflect.String:
+		value.SetString(raw)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return err //nolint:wrapcheck // wrapped by caller
+		}
+
+		value.SetBool(b)
+	case reflect.Int:
+		n, err := strconv.Atoi(raw)
+		if err != nil {
+			return err //nolint:wrapcheck // wrapped by caller
+		}
+
+		value.SetInt(int64(n))
+	case reflect.Slice:
+		value.Set(reflect.ValueOf(strings.Split(raw, ",")))
+	default:
+		return fmt.Errorf("unsupported field type %s", value.Type()) //nolint:err113 // programming error
+	}
+
+	return nil
+}
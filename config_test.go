@@ -0,0 +1,126 @@
+// GNU AFFERO GENERAL PUBLIC LICENSE
+// Version 3, 19 November 2007
+//
+// Copyright (C) 2025 John Kleijn
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// For more details, see the full AGPL-3.0 license at:
+// https://www.gnu.org/licenses/agpl-3.0.html
+
+package rtkv_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/johnknl/rtkv"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewFromConfig(t *testing.T) {
+	var cfg rtkv.Config
+
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"namespace": "`+t.Name()+`",
+		"delimiter": "pipe",
+		"redis": {"addr": "localhost:6379", "maxRetries": 2, "readTimeout": "1s"},
+		"history": true
+	}`), &cfg))
+
+	assert.Equal(t, rtkv.Duration(time.Second), cfg.Redis.ReadTimeout)
+
+	store, err := rtkv.NewFromConfig(cfg)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+
+	_, err = store.Set(ctx, []byte("v"), time.Now(), "a")
+	require.NoError(t, err)
+
+	history, err := store.History(ctx, "a")
+
+	require.NoError(t, err)
+	assert.Len(t, history, 1)
+
+	_, err = rtkv.NewFromConfig(rtkv.Config{Namespace: "x", Consistency: "sometimes"})
+
+	require.ErrorIs(t, err, rtkv.ErrInvalidConfig)
+}
+
+func TestConfigFromEnv(t *testing.T) {
+	t.Setenv("RTKV_NAMESPACE", "users")
+	t.Setenv("RTKV_REDIS_ADDR", "redis:6379")
+	t.Setenv("RTKV_REDIS_DIAL_TIMEOUT", "250ms")
+	t.Setenv("RTKV_REPLICAS", "replica-1:6379,replica-2:6379")
+	t.Setenv("RTKV_SKIP_IDENTICAL_WRITES", "true")
+
+	cfg, err := rtkv.ConfigFromEnv("RTKV_")
+
+	require.NoError(t, err)
+	assert.Equal(t, "users", cfg.Namespace)
+	assert.Equal(t, "redis:6379", cfg.Redis.Addr)
+	assert.Equal(t, rtkv.Duration(250*time.Millisecond), cfg.Redis.DialTimeout)
+	assert.Equal(t, []string{"replica-1:6379", "replica-2:6379"}, cfg.Replicas)
+	assert.True(t, cfg.SkipIdenticalWrites)
+
+	t.Setenv("RTKV_MULTI_KEY_LIMIT", "many")
+
+	_, err = rtkv.ConfigFromEnv("RTKV_")
+
+	require.ErrorIs(t, err, rtkv.ErrInvalidConfig)
+}
+
+func TestNewFromConfig_Compression(t *testing.T) {
+	ctx := context.Background()
+	cfg := rtkv.Config{
+		Namespace:   t.Name(),
+		Redis:       rtkv.RedisConfig{Addr: "localhost:6379"},
+		Compression: "zstd",
+		TotalsCache: rtkv.Duration(time.Second),
+	}
+
+	store, err := rtkv.NewFromConfig(cfg)
+	require.NoError(t, err)
+
+	value := bytes.Repeat([]byte("v"), 100)
+
+	_, err = store.Set(ctx, value, time.Now(), "a")
+	require.NoError(t, err)
+
+	raw, err := newGoRedisClient(0).Get(ctx, t.Name()+rtkv.DelimUnit+"a").Bytes()
+	require.NoError(t, err)
+	assert.Less(t, len(raw), len(value), "Values should be stored compressed")
+
+	data, err := store.Get(ctx, "a")
+	require.NoError(t, err)
+	assert.Equal(t, value, data)
+
+	cfg.Compression = "lz4"
+
+	_, err = rtkv.NewFromConfig(cfg)
+	require.ErrorIs(t, err, rtkv.ErrInvalidConfig)
+
+	t.Setenv("RTKV_UPDATE_MAX_RETRIES", "3")
+	t.Setenv("RTKV_UPDATE_BACKOFF", "10ms")
+
+	cfg, err = rtkv.ConfigFromEnv("RTKV_")
+	require.NoError(t, err)
+	assert.Equal(t, 3, cfg.UpdateMaxRetries)
+	assert.Equal(t, rtkv.Duration(10*time.Millisecond), cfg.UpdateBackoff)
+}
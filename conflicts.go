@@ -0,0 +1,99 @@
+// GNU AFFERO GENERAL PUBLIC LICENSE
+// Version 3, 19 November 2007
+//
+// Copyright (C) 2025 John Kleijn
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// For more details, see the full AGPL-3.0 license at:
+// https://www.gnu.org/licenses/agpl-3.0.html
+
+package rtkv
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const conflictsSuffix = "conflicts"
+
+// ConflictCount is the number of write conflicts seen for an entity.
+type ConflictCount struct {
+	ID    []string
+	Count int64
+}
+
+// WithConflictTracking counts write conflicts per entity in Redis,
+// so entities contended by concurrent producers can be found with
+// HotConflicts. A conflict is any write that had to be retried or
+// was rejected because the entity changed concurrently.
+func WithConflictTracking() Option {
+	return func(r *RedisTKV) {
+		r.trackConflicts = true
+	}
+}
+
+// HotConflicts returns up to topN entities with the most
+// write conflicts, most contended first.
+func (r *RedisTKV) HotConflicts(ctx context.Context, topN int) ([]ConflictCount, error) {
+	entries, err := r.client.ZRevRangeWithScores(ctx, r.internalKey(conflictsSuffix), 0, int64(topN-1)).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read conflicts: %w", err)
+	}
+
+	counts := make([]ConflictCount, len(entries))
+
+	for i := range entries {
+		counts[i] = ConflictCount{
+			ID:    r.idFromKey(entries[i].Member.(string)),
+			Count: int64(entries[i].Score),
+		}
+	}
+
+	return counts, nil
+}
+
+// ResetConflicts clears all recorded conflicts.
+func (r *RedisTKV) ResetConflicts(ctx context.Context) error {
+	if err := r.client.Del(ctx, r.internalKey(conflictsSuffix)).Err(); err != nil {
+		return fmt.Errorf("failed to reset conflicts: %w", err)
+	}
+
+	return nil
+}
+
+// recordConflicts counts a conflict for each of the given keys.
+// Failures are logged rather than returned, as they must not
+// affect the outcome of the write that conflicted.
+func (r *RedisTKV) recordConflicts(ctx context.Context, keys ...string) {
+	if !r.trackConflicts || len(keys) == 0 {
+		return
+	}
+
+	_, err := r.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for _, key := range keys {
+			pipe.ZIncrBy(ctx, r.internalKey(conflictsSuffix), 1, key)
+		}
+
+		return nil
+	})
+	if err != nil {
+		r.logger.WarnContext(ctx, "failed to record write conflict",
+			"namespace", r.namespace,
+			"error", err,
+		)
+	}
+}
@@ -0,0 +1,106 @@
+// GNU AFFERO GENERAL PUBLIC LICENSE
+// Version 3, 19 November 2007
+//
+// Copyright (C) 2025 John Kleijn
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// For more details, see the full AGPL-3.0 license at:
+// https://www.gnu.org/licenses/agpl-3.0.html
+
+package rtkv_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/johnknl/rtkv"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedisTKV_HotConflicts(t *testing.T) {
+	ctx := context.Background()
+
+	redisClient := newGoRedisClient(0)
+
+	t.Cleanup(func() {
+		redisClient.FlushDB(ctx).Err()
+	})
+
+	store := newRTKV(t, redisClient).With(rtkv.WithConflictTracking())
+
+	_, err := store.Set(ctx, []byte("a"), time.Now(), "a")
+	require.NoError(t, err)
+
+	interfere := 2
+
+	changed, err := store.UpdateMany(ctx, [][]string{{"a"}}, func(_ []string, old []byte) ([]byte, bool, error) {
+		if interfere > 0 {
+			interfere--
+
+			_, err := store.Set(ctx, append(old, 'x'), time.Now(), "a")
+			require.NoError(t, err)
+		}
+
+		return append(old, 'y'), true, nil
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, changed)
+
+	conflicts, err := store.HotConflicts(ctx, 10)
+
+	require.NoError(t, err)
+	assert.Equal(t, []rtkv.ConflictCount{{ID: []string{"a"}, Count: 2}}, conflicts)
+
+	require.NoError(t, store.ResetConflicts(ctx))
+
+	conflicts, err = store.HotConflicts(ctx, 10)
+
+	require.NoError(t, err)
+	assert.Empty(t, conflicts)
+}
+
+func TestRedisTKV_HotConflicts_ConditionalWrites(t *testing.T) {
+	ctx := context.Background()
+	store := newRTKV(t, newGoRedisClient(0)).With(rtkv.WithConflictTracking())
+	now := time.Now()
+
+	require.NoError(t, store.ResetConflicts(ctx))
+
+	for _, id := range []string{"a", "b"} {
+		_, err := store.Set(ctx, []byte(id), now, id)
+		require.NoError(t, err)
+	}
+
+	written, err := store.SetIfNewer(ctx, []byte("older"), now.Add(-time.Hour), "a")
+	require.NoError(t, err)
+	assert.False(t, written)
+
+	written, err = store.SetIfAbsent(ctx, []byte("again"), now, "a")
+	require.NoError(t, err)
+	assert.False(t, written)
+
+	err = store.Copy(ctx, []string{"a"}, []string{"b"}, false)
+	require.ErrorIs(t, err, rtkv.ErrExists)
+
+	conflicts, err := store.HotConflicts(ctx, 10)
+	require.NoError(t, err)
+	assert.Equal(t, []rtkv.ConflictCount{
+		{ID: []string{"a"}, Count: 2},
+		{ID: []string{"b"}, Count: 1},
+	}, conflicts, "Rejected conditional writes should count as conflicts")
+}
@@ -0,0 +1,197 @@
+// GNU AFFERO GENERAL PUBLIC LICENSE
+// Version 3, 19 November 2007
+//
+// Copyright (C) 2025 John Kleijn
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// For more details, see the full AGPL-3.0 license at:
+// https://www.gnu.org/licenses/agpl-3.0.html
+
+package rtkv
+
+import (
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const defaultRedisPort = "6379"
+
+// ReadPreference selects where a store created with
+// NewRedisTKVSentinel reads from.
+type ReadPreference int
+
+const (
+	// ReadPrimary reads from the primary only.
+	ReadPrimary ReadPreference = iota
+
+	// ReadReplica reads from a replica discovered through Sentinel,
+	// falling back to the primary while the replica lags behind.
+	// Writes and strongly consistent reads always go to the primary.
+	ReadReplica
+)
+
+// SentinelOptions declares a Redis deployment managed by Sentinel.
+type SentinelOptions struct {
+	// MasterName is the name Sentinel monitors the primary by.
+	MasterName string
+
+	// SentinelAddrs are the addresses of the Sentinels.
+	SentinelAddrs []string
+
+	SentinelUsername string
+	SentinelPassword string
+
+	// Redis declares how to connect to the primary and replicas
+	// once Sentinel resolved them. Its Addr is ignored.
+	Redis RedisConfig
+
+	// ReadPreference selects where reads go. Defaults to ReadPrimary.
+	ReadPreference ReadPreference
+
+	// MaxReplicaLag is passed on to WithReadReplicas when
+	// reading from replicas.
+	MaxReplicaLag time.Duration
+}
+
+// NewRedisTKVSentinel creates a store on a Sentinel managed deployment.
+// The store follows failovers of the primary. Options are applied
+// after those derived from so.
+func NewRedisTKVSentinel(idDelimiter, namespace string, so SentinelOptions, opts ...Option) (*RedisTKV, error) {
+	if so.MasterName == "" || len(so.SentinelAddrs) == 0 {
+		return nil, fmt.Errorf("%w: master name and sentinel addresses are required", ErrInvalidConfig)
+	}
+
+	primary := redis.NewFailoverClient(so.failoverOptions(false))
+
+	if so.ReadPreference == ReadReplica {
+		replica := redis.NewFailoverClient(so.failoverOptions(true))
+		opts = append([]Option{WithReadReplicas(ReplicaOptions{MaxLag: so.MaxReplicaLag}, replica)}, opts...)
+	}
+
+	return NewRedisTKV(idDelimiter, namespace, primary, opts...), nil
+}
+
+// NewRedisTKVUnix creates a store connected to Redis over
+// the unix socket at path.
+func NewRedisTKVUnix(idDelimiter, namespace, path string, opts ...Option) *RedisTKV {
+	return NewRedisTKV(idDelimiter, namespace, redis.NewClient(&redis.Options{
+		Network: "unix",
+		Addr:    path,
+	}), opts...)
+}
+
+// NewRedisTKVAddr creates a store connected to Redis at addr, which
+// is one of:
+//
+//   - a host and port, such as localhost:6379 or [::1]:6379
+//   - a bare IPv4 or IPv6 address, such as ::1, using port 6379
+//   - a unix socket, such as unix:///run/redis.sock or /run/redis.sock
+//   - a redis:// or rediss:// URL
+func NewRedisTKVAddr(idDelimiter, namespace, addr string, opts ...Option) (*RedisTKV, error) {
+	options, err := (RedisConfig{Addr: addr}).parse()
+	if err != nil {
+		return nil, err
+	}
+
+	return NewRedisTKV(idDelimiter, namespace, redis.NewClient(options), opts...), nil
+}
+
+// parse returns the client options of cfg, working out
+// the network from its address.
+func (cfg RedisConfig) parse() (*redis.Options, error) {
+	if strings.HasPrefix(cfg.Addr, "redis://") || strings.HasPrefix(cfg.Addr, "rediss://") {
+		options, err := redis.ParseURL(cfg.Addr)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidConfig, err)
+		}
+
+		return options, nil
+	}
+
+	options := cfg.options()
+	options.Network, options.Addr = networkAddr(cfg.Addr)
+
+	return options, nil
+}
+
+// networkAddr splits a Redis address into a network and an
+// address to dial.
+func networkAddr(addr string) (string, string) {
+	switch {
+	case strings.HasPrefix(addr, "unix://"):
+		return "unix", strings.TrimPrefix(addr, "unix://")
+	case strings.HasPrefix(addr, "unix:"):
+		return "unix", strings.TrimPrefix(addr, "unix:")
+	case strings.HasPrefix(addr, "/"), strings.HasPrefix(addr, "./"), strings.HasSuffix(addr, ".sock"):
+		return "unix", addr
+	case net.ParseIP(strings.Trim(addr, "[]")) != nil:
+		return "tcp", net.JoinHostPort(strings.Trim(addr, "[]"), defaultRedisPort)
+	}
+
+	return "tcp", addr
+}
+
+func (so SentinelOptions) failoverOptions(replicaOnly bool) *redis.FailoverOptions {
+	cfg := so.Redis
+
+	return &redis.FailoverOptions{
+		MasterName:       so.MasterName,
+		SentinelAddrs:    so.SentinelAddrs,
+		SentinelUsername: so.SentinelUsername,
+		SentinelPassword: so.SentinelPassword,
+		ReplicaOnly:      replicaOnly,
+		Username:         cfg.Username,
+		Password:         cfg.Password,
+		DB:               cfg.DB,
+		PoolSize:         cfg.PoolSize,
+		MaxRetries:       cfg.MaxRetries,
+		MinRetryBackoff:  time.Duration(cfg.MinRetryBackoff),
+		MaxRetryBackoff:  time.Duration(cfg.MaxRetryBackoff),
+		DialTimeout:      time.Duration(cfg.DialTimeout),
+		ReadTimeout:      time.Duration(cfg.ReadTimeout),
+		WriteTimeout:     time.Duration(cfg.WriteTimeout),
+	}
+}
+
+// cloneClient returns a new client with the options of c, so hooks
+// can be added without affecting c. It has a pool of its own. Clients
+// of unknown types are returned as is.
+func cloneClient(c redis.UniversalClient) redis.UniversalClient {
+	switch c := c.(type) {
+	case *redis.Client:
+		opts := *c.Options()
+
+		// NewClient sets the processor of c, which
+		// can't be shared between clients.
+		opts.PushNotificationProcessor = nil
+
+		return redis.NewClient(&opts)
+	case *redis.ClusterClient:
+		opts := *c.Options()
+		opts.PushNotificationProcessor = nil
+
+		return redis.NewClusterClient(&opts)
+	case *redis.Ring:
+		opts := *c.Options()
+
+		return redis.NewRing(&opts)
+	default:
+		return c
+	}
+}
@@ -0,0 +1,129 @@
+// GNU AFFERO GENERAL PUBLIC LICENSE
+// Version 3, 19 November 2007
+//
+// Copyright (C) 2025 John Kleijn
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// For more details, see the full AGPL-3.0 license at:
+// https://www.gnu.org/licenses/agpl-3.0.html
+
+package rtkv_test
+
+import (
+	"context"
+	"io"
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/johnknl/rtkv"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// unixProxy forwards connections on a unix socket to the test Redis.
+func unixProxy(t *testing.T) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "redis.sock")
+
+	l, err := net.Listen("unix", path)
+	require.NoError(t, err)
+
+	t.Cleanup(func() { l.Close() })
+
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+
+			upstream, err := net.Dial("tcp", "localhost:6379")
+			if err != nil {
+				conn.Close()
+
+				return
+			}
+
+			go func() {
+				_, _ = io.Copy(upstream, conn)
+				upstream.Close()
+			}()
+
+			go func() {
+				_, _ = io.Copy(conn, upstream)
+				conn.Close()
+			}()
+		}
+	}()
+
+	return path
+}
+
+func TestNewRedisTKVUnix(t *testing.T) {
+	ctx := context.Background()
+	path := unixProxy(t)
+
+	for _, store := range []*rtkv.RedisTKV{
+		rtkv.NewRedisTKVUnix(rtkv.DelimUnit, t.Name(), path),
+		must(rtkv.NewRedisTKVAddr(rtkv.DelimUnit, t.Name(), "unix://"+path)),
+		must(rtkv.NewRedisTKVAddr(rtkv.DelimUnit, t.Name(), path)),
+	} {
+		_, err := store.Set(ctx, []byte("v"), time.Now(), "a")
+		require.NoError(t, err)
+
+		value, err := store.Get(ctx, "a")
+		require.NoError(t, err)
+		assert.Equal(t, []byte("v"), value)
+	}
+}
+
+func TestNewRedisTKVAddr(t *testing.T) {
+	ctx := context.Background()
+
+	for _, addr := range []string{"localhost:6379", "redis://localhost:6379/0"} {
+		store, err := rtkv.NewRedisTKVAddr(rtkv.DelimUnit, t.Name(), addr)
+		require.NoError(t, err)
+
+		_, err = store.Exists(ctx, "a")
+		require.NoError(t, err, addr)
+	}
+
+	_, err := rtkv.NewRedisTKVAddr(rtkv.DelimUnit, t.Name(), "redis://localhost:6379/x")
+	require.ErrorIs(t, err, rtkv.ErrInvalidConfig)
+}
+
+func TestNewRedisTKVSentinel(t *testing.T) {
+	_, err := rtkv.NewRedisTKVSentinel(rtkv.DelimUnit, t.Name(), rtkv.SentinelOptions{MasterName: "mymaster"})
+	require.ErrorIs(t, err, rtkv.ErrInvalidConfig)
+
+	store, err := rtkv.NewRedisTKVSentinel(rtkv.DelimUnit, t.Name(), rtkv.SentinelOptions{
+		MasterName:     "mymaster",
+		SentinelAddrs:  []string{"localhost:26379"},
+		ReadPreference: rtkv.ReadReplica,
+	})
+	require.NoError(t, err)
+	assert.Len(t, store.Stats().Replicas, 1)
+}
+
+func must[T any](v T, err error) T {
+	if err != nil {
+		panic(err)
+	}
+
+	return v
+}
@@ -0,0 +1,94 @@
+// GNU AFFERO GENERAL PUBLIC LICENSE
+// Version 3, 19 November 2007
+//
+// Copyright (C) 2025 John Kleijn
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// For more details, see the full AGPL-3.0 license at:
+// https://www.gnu.org/licenses/agpl-3.0.html
+
+package rtkv
+
+import (
+	"context"
+	"iter"
+	"time"
+)
+
+// Consistency selects the guarantees a read is served with.
+type Consistency int
+
+const (
+	// ConsistencyEventual reads without coordination between the
+	// index and the values, from read replicas, the read batcher and
+	// the totals cache where enabled. This is the behaviour of
+	// FetchPage and the default for stores.
+	ConsistencyEventual Consistency = iota
+
+	// ConsistencyStrong reads from the primary, bypassing read
+	// replicas, the read batcher and the totals cache. Fetch reads
+	// pages atomically using a Lua script, as FetchPageConsistent.
+	ConsistencyStrong
+)
+
+type consistencyCtxKey struct{}
+
+// String returns a human readable name for the consistency level.
+func (c Consistency) String() string {
+	switch c {
+	case ConsistencyEventual:
+		return "eventual"
+	case ConsistencyStrong:
+		return "strong"
+	default:
+		return "unknown"
+	}
+}
+
+// WithConsistency sets the default consistency level of reads, used
+// by Fetch, Get, BulkGet, Count and CountRange among others.
+func WithConsistency(c Consistency) Option {
+	return func(r *RedisTKV) {
+		r.consistency = c
+	}
+}
+
+// ContextWithConsistency returns a context that overrides the store's
+// default consistency level for calls made with it.
+func ContextWithConsistency(ctx context.Context, c Consistency) context.Context {
+	return context.WithValue(ctx, consistencyCtxKey{}, c)
+}
+
+// Fetch fetches a page using the consistency level selected by the
+// context, falling back to the store default. It satisfies PageFunc.
+func (r *RedisTKV) Fetch(
+	ctx context.Context,
+	from, to *time.Time, //nolint:varnamelen // from and to are clear
+	offset, limit int,
+) (iter.Seq2[[]byte, error], int64, error) {
+	if r.consistencyFor(ctx) == ConsistencyStrong {
+		return r.FetchPageConsistent(ctx, from, to, offset, limit)
+	}
+
+	return r.FetchPage(ctx, from, to, offset, limit)
+}
+
+func (r *RedisTKV) consistencyFor(ctx context.Context) Consistency {
+	if c, ok := ctx.Value(consistencyCtxKey{}).(Consistency); ok {
+		return c
+	}
+
+	return r.consistency
+}
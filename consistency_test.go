@@ -0,0 +1,124 @@
+// GNU AFFERO GENERAL PUBLIC LICENSE
+// Version 3, 19 November 2007
+//
+// Copyright (C) 2025 John Kleijn
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// For more details, see the full AGPL-3.0 license at:
+// https://www.gnu.org/licenses/agpl-3.0.html
+
+package rtkv_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/johnknl/rtkv"
+	"github.com/johnknl/rtkv/rtkvtest"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedisTKV_Fetch(t *testing.T) {
+	const testSetSize = 100
+
+	store := goRedisSetup(t, testSetSize)
+	strict := store.With(rtkv.WithConsistency(rtkv.ConsistencyStrong))
+
+	from := time.Now().Add(-time.Minute)
+	to := time.Now()
+
+	tests := map[string]struct {
+		ctx   context.Context //nolint:containedctx // test table
+		store *rtkv.RedisTKV
+	}{
+		"Default":         {ctx: context.Background(), store: store},
+		"StoreStrong":     {ctx: context.Background(), store: strict},
+		"ContextStrong":   {ctx: rtkv.ContextWithConsistency(context.Background(), rtkv.ConsistencyStrong), store: store},
+		"ContextEventual": {ctx: rtkv.ContextWithConsistency(context.Background(), rtkv.ConsistencyEventual), store: strict},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			it, total, err := tc.store.Fetch(tc.ctx, &from, &to, 0, testSetSize/2)
+
+			require.NoError(t, err)
+			assert.EqualValues(t, testSetSize, total)
+
+			var i int
+
+			for _, err = range it {
+				require.NoError(t, err)
+				i++
+			}
+
+			assert.Equal(t, testSetSize/2, i)
+		})
+	}
+}
+
+func TestConsistency_String(t *testing.T) {
+	assert.Equal(t, "eventual", rtkv.ConsistencyEventual.String())
+	assert.Equal(t, "strong", rtkv.ConsistencyStrong.String())
+	assert.Equal(t, "unknown", rtkv.Consistency(-1).String())
+}
+
+func TestConsistencyStrong_BypassesReadBatching(t *testing.T) {
+	_, client := rtkvtest.NewMiniredis(t)
+	store := rtkv.NewRedisTKV(rtkv.DelimUnit, t.Name(), client, rtkv.WithReadBatching(time.Second, 0))
+	ctx := rtkv.ContextWithConsistency(context.Background(), rtkv.ConsistencyStrong)
+
+	_, err := store.Set(ctx, []byte("value"), time.Now(), "a")
+	require.NoError(t, err)
+
+	start := time.Now()
+
+	value, err := store.Get(ctx, "a")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("value"), value)
+
+	entries, err := store.BulkGet(ctx, [][]string{{"a"}})
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, []byte("value"), entries[0].Data)
+
+	assert.Less(t, time.Since(start), 500*time.Millisecond, "Strong reads should not wait for a batch")
+}
+
+func TestConsistencyStrong_BypassesTotalsCache(t *testing.T) {
+	ctx := context.Background()
+	_, client := rtkvtest.NewMiniredis(t)
+	store := rtkv.NewRedisTKV(rtkv.DelimUnit, t.Name(), client, rtkv.WithTotalsCache(time.Minute))
+
+	_, err := store.Set(ctx, []byte("value"), time.Now(), "a")
+	require.NoError(t, err)
+
+	total, err := store.Count(ctx)
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, total)
+
+	// Written by another process, so the cache isn't cleared.
+	require.NoError(t, client.ZAdd(ctx, t.Name()+rtkv.DelimUnit+"lmIdx", redis.Z{Score: 1, Member: "b"}).Err())
+
+	total, err = store.Count(ctx)
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, total, "Eventual reads should use the cache")
+
+	total, err = store.Count(rtkv.ContextWithConsistency(ctx, rtkv.ConsistencyStrong))
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, total, "Strong reads should bypass the cache")
+}
@@ -0,0 +1,141 @@
+// GNU AFFERO GENERAL PUBLIC LICENSE
+// Version 3, 19 November 2007
+//
+// Copyright (C) 2025 John Kleijn
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// For more details, see the full AGPL-3.0 license at:
+// https://www.gnu.org/licenses/agpl-3.0.html
+
+package rtkv
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// copyScript copies an entity, indexing the copy with the
+// lastModified time of the original. Returns -1 if the destination
+// exists and may not be replaced, 0 if the source doesn't exist,
+// otherwise { score, created, data }.
+const copyScript = `
+local src = KEYS[1] -- the source entity key
+local dst = KEYS[2] -- the destination entity key
+local srcIndex = KEYS[3] -- the source lastModified index
+local dstIndex = KEYS[4] -- the destination lastModified index
+local dstExpiry = KEYS[5] -- the destination expiry index
+local dstVersions = KEYS[6] -- the destination versions hash
+local replace = ARGV[1] == "1" -- whether to replace an existing destination
+local now = tonumber(ARGV[2]) -- the current time in nanoseconds
+local versioned = ARGV[3] == "1" -- whether to increment the version
+
+local created = 1 - redis.call("EXISTS", dst)
+
+if created == 0 and not replace then
+  return -1
+end
+
+if redis.call("EXISTS", src) == 0 then
+  return 0
+end
+
+redis.call("COPY", src, dst, "REPLACE")
+
+local score = redis.call("ZSCORE", srcIndex, src) or ARGV[2]
+redis.call("ZADD", dstIndex, score, dst)
+
+local ttl = redis.call("PTTL", dst)
+if ttl > 0 then
+  redis.call("ZADD", dstExpiry, now + ttl * 1000000, dst)
+else
+  redis.call("ZREM", dstExpiry, dst)
+end
+
+if versioned then
+  redis.call("HINCRBY", dstVersions, dst, 1)
+end
+
+return {score, created, redis.call("GET", dst)}
+`
+
+// Copy copies an entity to another ID, keeping its value, TTL and
+// lastModified time. It returns ErrNotFound if the entity doesn't
+// exist, or ErrExists if the destination exists and replace isn't
+// set. The copy is atomic.
+func (r *RedisTKV) Copy(ctx context.Context, srcID, dstID []string, replace bool) error {
+	return r.CopyTo(ctx, srcID, r, dstID, replace)
+}
+
+// CopyTo copies an entity to an ID in the namespace of dst, like
+// Copy, for example to promote a staged entity. dst must use the
+// same Redis server; use CopyNamespace to copy between servers.
+// The copy is written with the options of dst, but isn't validated
+// against its schemas.
+func (r *RedisTKV) CopyTo(ctx context.Context, srcID []string, dst *RedisTKV, dstID []string, replace bool) error {
+	defer r.observe(ctx, "copy", time.Now())
+
+	if err := r.checkID(srcID); err != nil {
+		return err
+	}
+
+	if err := dst.checkID(dstID); err != nil {
+		return err
+	}
+
+	if err := dst.checkWritable(ctx); err != nil {
+		return err
+	}
+
+	dstKey := dst.namespacedKey(dstID...)
+	versions, versioned := dst.versionsArgs()
+	keys := []string{
+		r.namespacedKey(srcID...),
+		dstKey,
+		r.namespacedKey(lastModifiedIdxSuffix),
+		dst.namespacedKey(lastModifiedIdxSuffix),
+		dst.internalKey(expirySuffix),
+		versions,
+	}
+
+	result, err := r.evalScript(ctx, copyScript, keys, replace, time.Now().UnixNano(), versioned)
+	if err != nil {
+		return fmt.Errorf("failed to copy entity: %w", err)
+	}
+
+	switch result {
+	case int64(-1):
+		dst.recordConflicts(ctx, dstKey)
+
+		return ErrExists
+	case int64(0):
+		return ErrNotFound
+	}
+
+	parts, ok := result.([]any)
+	if !ok || len(parts) != 3 { //nolint:mnd // score, created, data
+		return ErrUnexpectedScriptResult
+	}
+
+	rawScore, _ := parts[0].(string)
+	score, _ := strconv.ParseFloat(rawScore, 64)
+	created, _ := parts[1].(int64)
+	data, _ := parts[2].(string)
+
+	dst.stats.recordSet(ctx, len(data), created == 1)
+
+	return dst.conditionalSetIndexes(ctx, []byte(data), int64(score), dstKey)
+}
@@ -0,0 +1,83 @@
+// GNU AFFERO GENERAL PUBLIC LICENSE
+// Version 3, 19 November 2007
+//
+// Copyright (C) 2025 John Kleijn
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// For more details, see the full AGPL-3.0 license at:
+// https://www.gnu.org/licenses/agpl-3.0.html
+
+package rtkv_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/johnknl/rtkv"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedisTKV_Copy(t *testing.T) {
+	ctx := context.Background()
+	client := newGoRedisClient(0)
+	store := newRTKV(t, client).With(rtkv.WithChildIndex())
+	lastModified := time.Date(2025, 1, 10, 12, 0, 0, 0, time.UTC)
+
+	_, err := store.Clear(ctx)
+	require.NoError(t, err)
+
+	_, err = store.SetWithTTL(ctx, []byte("template"), lastModified, time.Hour, "templates", "a")
+	require.NoError(t, err)
+
+	require.NoError(t, store.Copy(ctx, []string{"templates", "a"}, []string{"templates", "b"}, false))
+
+	value, got, err := store.GetWithLastModified(ctx, "templates", "b")
+	require.NoError(t, err)
+	assert.Equal(t, "template", string(value))
+	assert.WithinDuration(t, lastModified, got, time.Microsecond)
+	assert.Positive(t, client.PTTL(ctx, t.Name()+rtkv.DelimUnit+"templates"+rtkv.DelimUnit+"b").Val())
+
+	children, err := store.GetChildren(ctx, "templates")
+	require.NoError(t, err)
+	assert.Len(t, children, 2)
+
+	err = store.Copy(ctx, []string{"templates", "a"}, []string{"templates", "b"}, false)
+	require.ErrorIs(t, err, rtkv.ErrExists)
+
+	require.NoError(t, store.Copy(ctx, []string{"templates", "a"}, []string{"templates", "b"}, true))
+
+	err = store.Copy(ctx, []string{"templates", "missing"}, []string{"templates", "c"}, false)
+	require.ErrorIs(t, err, rtkv.ErrNotFound)
+
+	prod := rtkv.NewRedisTKV(rtkv.DelimUnit, t.Name()+"-prod", client)
+
+	_, err = prod.Clear(ctx)
+	require.NoError(t, err)
+
+	require.NoError(t, store.CopyTo(ctx, []string{"templates", "a"}, prod, []string{"a"}, false))
+
+	value, err = prod.Get(ctx, "a")
+	require.NoError(t, err)
+	assert.Equal(t, "template", string(value))
+
+	count, err := prod.Count(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+
+	stats := prod.Stats().Writes
+	assert.Equal(t, int64(1), stats.Creates)
+}
@@ -0,0 +1,222 @@
+// GNU AFFERO GENERAL PUBLIC LICENSE
+// Version 3, 19 November 2007
+//
+// Copyright (C) 2025 John Kleijn
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// For more details, see the full AGPL-3.0 license at:
+// https://www.gnu.org/licenses/agpl-3.0.html
+
+package rtkv
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"iter"
+	"strconv"
+	"time"
+)
+
+// cursorScript reads a page of entities after the last entity of the
+// previous page: entities with a higher score, or the same score and a
+// higher member, the order Redis sorts members with equal scores in.
+// Returns { keys, scores, values, more }.
+const cursorScript = `
+local index = KEYS[1] -- the lastModified index
+local min = ARGV[1] -- the score of the last entity, or the range minimum
+local max = ARGV[2] -- the range maximum
+local count = tonumber(ARGV[3]) -- the page size
+local lastMember = ARGV[4] -- the member of the last entity, or ""
+local lastScore = tonumber(ARGV[5]) -- the score of the last entity
+
+local keys, scores = {}, {}
+local offset = 0
+
+while #keys <= count do
+  local entries = redis.call("ZRANGE", index, min, max, "BYSCORE", "LIMIT", offset, count + 1, "WITHSCORES")
+  if #entries == 0 then
+    break
+  end
+
+  for i = 1, #entries, 2 do
+    local member, score = entries[i], entries[i + 1]
+
+    if lastMember == "" or tonumber(score) > lastScore or member > lastMember then
+      keys[#keys + 1] = member
+      scores[#scores + 1] = score
+
+      if #keys > count then
+        break
+      end
+    end
+  end
+
+  offset = offset + #entries / 2
+end
+
+local more = 0
+if #keys > count then
+  more = 1
+  keys[#keys] = nil
+  scores[#scores] = nil
+end
+
+if #keys == 0 then
+  return { {}, {}, {}, more }
+end
+
+return { keys, scores, redis.call("MGET", unpack(keys)), more }
+`
+
+// ErrInvalidCursor is returned for cursors not returned by FetchPageAfter.
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// pageCursor is the position a cursor resumes after.
+type pageCursor struct {
+	Score  string `json:"s"`
+	Member string `json:"m"`
+}
+
+// FetchPageAfter fetches a page of up to limit entities last modified
+// in the given range, resuming after cursor, and returns the cursor
+// of the next page. Pass an empty cursor for the first page. The next
+// cursor is empty after the last page.
+//
+// Unlike offsets, cursors don't get slower as iteration progresses,
+// and entities written or deleted mid-iteration don't shift pages,
+// so no entity is skipped. An entity modified mid-iteration moves
+// to the end of the index and may be returned twice.
+func (r *RedisTKV) FetchPageAfter(
+	ctx context.Context,
+	from, to *time.Time, //nolint:varnamelen // from and to are clear
+	cursor string,
+	limit int,
+) (iter.Seq2[[]byte, error], string, error) {
+	defer r.observe(ctx, "fetchPageAfter", time.Now())
+
+	page, err := r.fetchPageAfter(ctx, from, to, cursor, limit)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if !page.more {
+		return r.yieldValues(page.keys, page.values), "", nil
+	}
+
+	return r.yieldValues(page.keys, page.values), page.last, nil
+}
+
+// cursorPage is a page read with cursorScript.
+type cursorPage struct {
+	keys   []string
+	scores []float64
+	values []any
+
+	// last is the cursor of the last entity of the page,
+	// or the cursor the page was read after if it is empty.
+	last string
+	more bool
+}
+
+func (r *RedisTKV) fetchPageAfter(
+	ctx context.Context,
+	from, to *time.Time, //nolint:varnamelen // from and to are clear
+	cursor string,
+	limit int,
+) (cursorPage, error) {
+	page := cursorPage{last: cursor}
+	rangeMin, rangeMax := scoreRange(from, to)
+	args := []any{rangeMin, rangeMax, limit, "", 0}
+
+	if cursor != "" {
+		last, err := decodeCursor(cursor)
+		if err != nil {
+			return page, err
+		}
+
+		args[0], args[3], args[4] = last.Score, last.Member, last.Score
+	}
+
+	release, err := r.acquireFetch(ctx)
+	if err != nil {
+		return page, err
+	}
+
+	defer release()
+
+	result, err := r.evalScript(ctx, cursorScript, []string{r.namespacedKey(lastModifiedIdxSuffix)}, args...)
+	if err != nil {
+		return page, fmt.Errorf("failed to fetch page: %w", err)
+	}
+
+	parts, ok := result.([]any)
+	if !ok || len(parts) != 4 { //nolint:mnd // keys, scores, values, more
+		return page, ErrUnexpectedScriptResult
+	}
+
+	keys, _ := parts[0].([]any)
+	scores, _ := parts[1].([]any)
+	page.values, _ = parts[2].([]any)
+
+	page.keys = make([]string, len(keys))
+	page.scores = make([]float64, len(keys))
+
+	for i := range keys {
+		page.keys[i], _ = keys[i].(string)
+		score, _ := scores[i].(string)
+		page.scores[i], _ = strconv.ParseFloat(score, 64)
+	}
+
+	if len(keys) == 0 {
+		return page, nil
+	}
+
+	page.more = parts[3] == int64(1)
+	score, _ := scores[len(scores)-1].(string)
+
+	page.last, err = encodeCursor(pageCursor{Score: score, Member: page.keys[len(keys)-1]})
+	if err != nil {
+		return page, err
+	}
+
+	return page, nil
+}
+
+func encodeCursor(c pageCursor) (string, error) {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode cursor: %w", err)
+	}
+
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+func decodeCursor(cursor string) (pageCursor, error) {
+	var c pageCursor
+
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return c, fmt.Errorf("%w: %w", ErrInvalidCursor, err)
+	}
+
+	if err = json.Unmarshal(data, &c); err != nil || c.Score == "" || c.Member == "" {
+		return c, ErrInvalidCursor
+	}
+
+	return c, nil
+}
@@ -0,0 +1,127 @@
+// GNU AFFERO GENERAL PUBLIC LICENSE
+// Version 3, 19 November 2007
+//
+// Copyright (C) 2025 John Kleijn
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// For more details, see the full AGPL-3.0 license at:
+// https://www.gnu.org/licenses/agpl-3.0.html
+
+package rtkv_test
+
+import (
+	"context"
+	"iter"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/johnknl/rtkv"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedisTKV_FetchPageAfter(t *testing.T) {
+	ctx := context.Background()
+	store := newRTKV(t, newGoRedisClient(0))
+	base := time.Date(2025, 1, 10, 12, 0, 0, 0, time.UTC)
+
+	var records []rtkv.BulkSetRecord
+
+	// Pairs of entities share a lastModified time, so pages
+	// end in the middle of runs of equal scores.
+	for i := range 10 {
+		records = append(records, rtkv.BulkSetRecord{
+			LastModified: base.Add(time.Duration(i/2) * time.Second),
+			ID:           []string{strconv.Itoa(i)},
+			Data:         []byte(strconv.Itoa(i)),
+		})
+	}
+
+	require.NoError(t, store.BulkSet(ctx, records))
+
+	var (
+		seen   []string
+		cursor string
+		pages  int
+	)
+
+	for {
+		values, next, err := store.FetchPageAfter(ctx, nil, nil, cursor, 3)
+		require.NoError(t, err)
+
+		for value, err := range values {
+			require.NoError(t, err)
+
+			seen = append(seen, string(value))
+		}
+
+		pages++
+
+		if pages == 1 {
+			// Deleting an entity already seen doesn't shift pages.
+			require.NoError(t, store.Delete(ctx, "0"))
+		}
+
+		if next == "" {
+			break
+		}
+
+		cursor = next
+	}
+
+	assert.Equal(t, []string{"0", "1", "2", "3", "4", "5", "6", "7", "8", "9"}, seen)
+	assert.Equal(t, 4, pages)
+}
+
+func TestRedisTKV_FetchPageAfter_Range(t *testing.T) {
+	ctx := context.Background()
+	store := newRTKV(t, newGoRedisClient(0))
+	base := time.Date(2025, 1, 10, 12, 0, 0, 0, time.UTC)
+
+	for i := range 5 {
+		_, err := store.Set(ctx, []byte(strconv.Itoa(i)), base.Add(time.Duration(i)*time.Hour), strconv.Itoa(i))
+		require.NoError(t, err)
+	}
+
+	from, to := base.Add(time.Hour), base.Add(3*time.Hour)
+
+	values, next, err := store.FetchPageAfter(ctx, &from, &to, "", 2)
+	require.NoError(t, err)
+	require.NotEmpty(t, next)
+	assert.Equal(t, []string{"1", "2"}, collect(t, values))
+
+	values, next, err = store.FetchPageAfter(ctx, &from, &to, next, 2)
+	require.NoError(t, err)
+	assert.Empty(t, next)
+	assert.Equal(t, []string{"3"}, collect(t, values))
+
+	_, _, err = store.FetchPageAfter(ctx, nil, nil, "not a cursor", 2)
+	require.ErrorIs(t, err, rtkv.ErrInvalidCursor)
+}
+
+func collect(t *testing.T, values iter.Seq2[[]byte, error]) []string {
+	t.Helper()
+
+	var result []string
+
+	for value, err := range values {
+		require.NoError(t, err)
+
+		result = append(result, string(value))
+	}
+
+	return result
+}
@@ -0,0 +1,119 @@
+// GNU AFFERO GENERAL PUBLIC LICENSE
+// Version 3, 19 November 2007
+//
+// Copyright (C) 2025 John Kleijn
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// For more details, see the full AGPL-3.0 license at:
+// https://www.gnu.org/licenses/agpl-3.0.html
+
+package rtkv
+
+import (
+	"sync"
+	"time"
+)
+
+// dedupSize is the number of tracked entities above
+// which those outside the window are swept.
+const dedupSize = 65536
+
+// DedupOptions configure WithWriteDedup.
+type DedupOptions struct {
+	// Window is the time during which further writes
+	// of an entity publish no change events.
+	Window time.Duration
+
+	// SkipIndexBumps also leaves the lastModified time of an
+	// entity in the index unchanged for writes within the window,
+	// so FetchPage ranges stay stable while it is rewritten. Writes
+	// made by scripts, such as SetIfNewer, GetSet, CompareAndSet
+	// and those of WithSkipIdenticalWrites, still update it.
+	SkipIndexBumps bool
+}
+
+// writeDedup tracks when entities last published a change event.
+// It is shared between a store and its clones.
+type writeDedup struct {
+	opts DedupOptions
+	last map[string]time.Time
+	mx   sync.Mutex
+}
+
+// WithWriteDedup suppresses the change events of writes to an entity
+// that published one less than opts.Window ago, so producers that
+// rewrite entities in tight loops publish at most one event per
+// entity per window. Values are still written. Deleting an entity
+// resets its window.
+//
+// Consumers may miss the last writes of a burst, so they should read
+// the current value of an entity rather than rely on the events of
+// every write. Writes are tracked per process, so stores in different
+// processes each publish their own events.
+func WithWriteDedup(opts DedupOptions) Option {
+	return func(r *RedisTKV) {
+		r.dedup = &writeDedup{opts: opts, last: map[string]time.Time{}}
+	}
+}
+
+// recent reports whether the entity at key published a change event
+// within the window, and if not, records that it publishes one now.
+func (d *writeDedup) recent(key string) bool {
+	if d == nil {
+		return false
+	}
+
+	d.mx.Lock()
+	defer d.mx.Unlock()
+
+	now := time.Now()
+
+	if last, ok := d.last[key]; ok && now.Sub(last) < d.opts.Window {
+		return true
+	}
+
+	if len(d.last) >= dedupSize {
+		for k, last := range d.last {
+			if now.Sub(last) >= d.opts.Window {
+				delete(d.last, k)
+			}
+		}
+
+		if len(d.last) >= dedupSize {
+			clear(d.last)
+		}
+	}
+
+	d.last[key] = now
+
+	return false
+}
+
+// skipIndexBump reports whether writes within
+// the window leave the index unchanged.
+func (d *writeDedup) skipIndexBump() bool {
+	return d != nil && d.opts.SkipIndexBumps
+}
+
+func (d *writeDedup) forget(key string) {
+	if d == nil {
+		return
+	}
+
+	d.mx.Lock()
+	defer d.mx.Unlock()
+
+	delete(d.last, key)
+}
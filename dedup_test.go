@@ -0,0 +1,102 @@
+// GNU AFFERO GENERAL PUBLIC LICENSE
+// Version 3, 19 November 2007
+//
+// Copyright (C) 2025 John Kleijn
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// For more details, see the full AGPL-3.0 license at:
+// https://www.gnu.org/licenses/agpl-3.0.html
+
+package rtkv_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/johnknl/rtkv"
+	"github.com/johnknl/rtkv/rtkvtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithWriteDedup(t *testing.T) {
+	ctx := context.Background()
+	_, client := rtkvtest.NewMiniredis(t)
+	store := rtkv.NewRedisTKV(rtkv.DelimUnit, t.Name(), client,
+		rtkv.WithChangeFeed(rtkv.ChangeFeedOptions{}),
+		rtkv.WithWriteDedup(rtkv.DedupOptions{Window: time.Hour}),
+	)
+	first := time.Unix(1700000000, 0)
+
+	for i := range 5 {
+		_, err := store.Set(ctx, []byte{byte(i)}, first.Add(time.Duration(i)*time.Second), "a")
+		require.NoError(t, err)
+	}
+
+	_, err := store.Set(ctx, []byte("b"), first, "b")
+	require.NoError(t, err)
+
+	events, err := store.ReadChanges(ctx, "0", 100)
+	require.NoError(t, err)
+	require.Len(t, events, 2, "Writes within the window should publish one event per entity")
+
+	value, err := store.Get(ctx, "a")
+	require.NoError(t, err)
+	assert.Equal(t, []byte{4}, value, "Values should still be written")
+
+	records, _, err := store.FetchPageRecords(ctx, nil, nil, 0, 10)
+	require.NoError(t, err)
+
+	for record, err := range records {
+		require.NoError(t, err)
+
+		if record.ID[0] == "a" {
+			assert.Equal(t, first.Add(4*time.Second), record.LastModified, "Index bumps should not be skipped by default")
+		}
+	}
+
+	require.NoError(t, store.Delete(ctx, "a"))
+	_, err = store.Set(ctx, []byte("again"), first, "a")
+	require.NoError(t, err)
+
+	events, err = store.ReadChanges(ctx, "0", 100)
+	require.NoError(t, err)
+	assert.Len(t, events, 4, "Deleting should reset the window")
+}
+
+func TestWithWriteDedup_SkipIndexBumps(t *testing.T) {
+	ctx := context.Background()
+	_, client := rtkvtest.NewMiniredis(t)
+	store := rtkv.NewRedisTKV(rtkv.DelimUnit, t.Name(), client,
+		rtkv.WithWriteDedup(rtkv.DedupOptions{Window: time.Hour, SkipIndexBumps: true}),
+	)
+	first := time.Unix(1700000000, 0)
+
+	for i := range 3 {
+		_, err := store.Set(ctx, []byte{byte(i)}, first.Add(time.Duration(i)*time.Second), "a")
+		require.NoError(t, err)
+	}
+
+	records, total, err := store.FetchPageRecords(ctx, nil, nil, 0, 10)
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, total)
+
+	for record, err := range records {
+		require.NoError(t, err)
+		assert.Equal(t, first, record.LastModified)
+		assert.Equal(t, []byte{2}, record.Data)
+	}
+}
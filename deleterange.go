@@ -0,0 +1,134 @@
+// GNU AFFERO GENERAL PUBLIC LICENSE
+// Version 3, 19 November 2007
+//
+// Copyright (C) 2025 John Kleijn
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// For more details, see the full AGPL-3.0 license at:
+// https://www.gnu.org/licenses/agpl-3.0.html
+
+package rtkv
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	defaultDeleteRangeChunkSize = 500
+
+	// deleteRangeScript deletes up to count entities last modified
+	// in a score range. Returns the keys of the deleted entities.
+	deleteRangeScript = `
+local index = KEYS[1] -- the lastModified index
+local min = ARGV[1] -- the minimum score
+local max = ARGV[2] -- the maximum score
+local count = tonumber(ARGV[3]) -- the max number of entities to delete
+
+local keys = redis.call("ZRANGE", index, min, max, "BYSCORE", "LIMIT", 0, count)
+
+if #keys > 0 then
+  redis.call("DEL", unpack(keys))
+  redis.call("ZREM", index, unpack(keys))
+end
+
+return keys
+`
+)
+
+// DeleteRange deletes all entities last modified in the given range,
+// without reading them client side. Nil means unbounded. Entities are
+// deleted in chunks, each of which is deleted atomically, so readers
+// never see an entity that is indexed but gone. Returns the number of
+// entities deleted.
+//
+// The reference policy is not applied: references to deleted
+// entities are left dangling, as with RefPolicyIgnore.
+//
+// DeleteRange honours the budget set with WithCommandBudget. Calling
+// it again after ErrCommandBudgetExhausted continues the deletion.
+func (r *RedisTKV) DeleteRange(ctx context.Context, from, to *time.Time) (int64, error) { //nolint:varnamelen // from and to are clear
+	defer r.observe(ctx, "deleteRange", time.Now())
+
+	if err := r.checkWritable(ctx); err != nil {
+		return 0, err
+	}
+
+	rangeMin, rangeMax := scoreRange(from, to)
+	index := r.namespacedKey(lastModifiedIdxSuffix)
+	budget := budgetFrom(ctx)
+
+	var deleted int64
+
+	for {
+		if err := budget.spend(ctx, defaultDeleteRangeChunkSize); err != nil {
+			return deleted, err
+		}
+
+		result, err := r.evalScript(ctx, deleteRangeScript, []string{index}, rangeMin, rangeMax, defaultDeleteRangeChunkSize)
+		if err != nil {
+			return deleted, fmt.Errorf("failed to delete range: %w", err)
+		}
+
+		keys, ok := result.([]any)
+		if !ok {
+			return deleted, ErrUnexpectedScriptResult
+		}
+
+		deleted += int64(len(keys))
+		r.stats.recordDeletes(ctx, len(keys))
+
+		if err = r.deletedIndexes(ctx, keys); err != nil {
+			return deleted, err
+		}
+
+		if len(keys) < defaultDeleteRangeChunkSize {
+			return deleted, nil
+		}
+	}
+}
+
+// deletedIndexes removes entities deleted by a script from the
+// secondary indexes enabled on the store.
+func (r *RedisTKV) deletedIndexes(ctx context.Context, keys []any) error {
+	if len(keys) == 0 {
+		return nil
+	}
+
+	_, err := r.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		r.writerAdd(ctx, pipe)
+
+		for _, rawKey := range keys {
+			key, _ := rawKey.(string)
+			r.indexRemove(ctx, pipe, key, r.idFromKey(key))
+		}
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update indexes: %w", err)
+	}
+
+	removed := make([]string, len(keys))
+
+	for i, rawKey := range keys {
+		removed[i], _ = rawKey.(string)
+	}
+
+	return r.compactedRemove(ctx, true, removed...)
+}
@@ -0,0 +1,75 @@
+// GNU AFFERO GENERAL PUBLIC LICENSE
+// Version 3, 19 November 2007
+//
+// Copyright (C) 2025 John Kleijn
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// For more details, see the full AGPL-3.0 license at:
+// https://www.gnu.org/licenses/agpl-3.0.html
+
+package rtkv_test
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/johnknl/rtkv"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedisTKV_DeleteRange(t *testing.T) {
+	ctx := context.Background()
+	store := newRTKV(t, newGoRedisClient(0)).With(rtkv.WithChildIndex())
+	base := time.Date(2025, 1, 10, 0, 0, 0, 0, time.UTC)
+
+	var records []rtkv.BulkSetRecord
+
+	for i := range 1200 {
+		records = append(records, rtkv.BulkSetRecord{
+			LastModified: base.Add(time.Duration(i) * time.Minute),
+			ID:           []string{"parent", strconv.Itoa(i)},
+			Data:         []byte(strconv.Itoa(i)),
+		})
+	}
+
+	require.NoError(t, store.BulkSet(ctx, records))
+
+	to := base.Add(1099 * time.Minute)
+
+	deleted, err := store.DeleteRange(ctx, nil, &to)
+	require.NoError(t, err)
+	assert.EqualValues(t, 1100, deleted)
+
+	_, total, err := store.FetchPage(ctx, nil, nil, 0, 1)
+	require.NoError(t, err)
+	assert.EqualValues(t, 100, total)
+
+	value, err := store.Get(ctx, "parent", "0")
+	require.NoError(t, err)
+	assert.Nil(t, value)
+
+	children, err := store.GetChildren(ctx, "parent")
+	require.NoError(t, err)
+	assert.Len(t, children, 100)
+
+	from := base.Add(2000 * time.Minute)
+
+	deleted, err = store.DeleteRange(ctx, &from, nil)
+	require.NoError(t, err)
+	assert.Zero(t, deleted)
+}
@@ -0,0 +1,219 @@
+// GNU AFFERO GENERAL PUBLIC LICENSE
+// Version 3, 19 November 2007
+//
+// Copyright (C) 2025 John Kleijn
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// For more details, see the full AGPL-3.0 license at:
+// https://www.gnu.org/licenses/agpl-3.0.html
+
+package rtkv
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"fmt"
+)
+
+// encryptionHeader starts encrypted values. It is followed by the
+// length of the key ID, the key ID, the nonce and the ciphertext.
+const encryptionHeader = valueHeader + "e"
+
+var (
+	// ErrKeyNotFound is returned when a value is encrypted
+	// with a key the KeyProvider doesn't know, or no longer
+	// knows because it was revoked.
+	ErrKeyNotFound = errors.New("encryption key not found")
+
+	// ErrInvalidCiphertext is returned for encrypted values
+	// that are truncated or fail authentication.
+	ErrInvalidCiphertext = errors.New("invalid ciphertext")
+
+	// ErrInvalidKeyID is returned for key IDs longer
+	// than 255 bytes.
+	ErrInvalidKeyID = errors.New("key ID must be at most 255 bytes")
+)
+
+// KeyProvider provides the AES keys values are encrypted with. Keys
+// must be 16, 24 or 32 bytes long, for AES-128, AES-192 or AES-256.
+type KeyProvider interface {
+	// CurrentKey returns the ID and the key to encrypt new values
+	// with. Rotate keys by changing the current key while keeping
+	// the old one available to Key.
+	CurrentKey() (keyID string, key []byte, err error)
+
+	// Key returns the key with the given ID, or ErrKeyNotFound.
+	Key(keyID string) ([]byte, error)
+}
+
+// EncryptionOptions configure WithEncryption.
+type EncryptionOptions struct {
+	// Keys provides the keys. Required.
+	Keys KeyProvider
+
+	// KeySelector, if set, selects the key per entity. Otherwise,
+	// values are encrypted with the current key of Keys.
+	KeySelector KeySelector
+}
+
+// StaticKeys is a KeyProvider holding keys by ID in memory.
+type StaticKeys struct {
+	// Current is the ID of the key to encrypt new values with.
+	Current string
+
+	// Keys holds the keys by ID.
+	Keys map[string][]byte
+}
+
+// CurrentKey implements KeyProvider.
+func (s StaticKeys) CurrentKey() (string, []byte, error) {
+	key, err := s.Key(s.Current)
+
+	return s.Current, key, err
+}
+
+// Key implements KeyProvider.
+func (s StaticKeys) Key(keyID string) ([]byte, error) {
+	key, ok := s.Keys[keyID]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrKeyNotFound, keyID)
+	}
+
+	return key, nil
+}
+
+// valueEncryptor encrypts values with AES-GCM.
+type valueEncryptor EncryptionOptions
+
+// WithEncryption encrypts values with AES-GCM before writing them,
+// and decrypts them when read. Encrypted values start with a short
+// header holding the ID of their key, so keys can be rotated without
+// rewriting existing values. Values written without encryption are
+// returned as is, so existing data stays readable until rewritten.
+// Export, Import and CopyNamespace move values encrypted.
+//
+// Combine with WithValueCompression by passing the compression
+// option first, as encrypted values don't compress.
+func WithEncryption(opts EncryptionOptions) Option {
+	return func(r *RedisTKV) {
+		r.withTransform(valueEncryptor(opts))
+	}
+}
+
+func (e valueEncryptor) encode(id []string, data []byte) ([]byte, error) {
+	keyID, key, err := e.key(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(keyID) > 255 { //nolint:mnd // length is stored in a byte
+		return nil, fmt.Errorf("%w: %q", ErrInvalidKeyID, keyID)
+	}
+
+	aead, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	dst := make([]byte, 0, len(encryptionHeader)+1+len(keyID)+aead.NonceSize()+len(data)+aead.Overhead())
+	dst = append(dst, encryptionHeader...)
+	dst = append(dst, byte(len(keyID)))
+	dst = append(dst, keyID...)
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err = rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	dst = append(dst, nonce...)
+
+	return aead.Seal(dst, nonce, data, nil), nil
+}
+
+func (e valueEncryptor) decode(data []byte) ([]byte, error) {
+	rest, ok := bytes.CutPrefix(data, []byte(encryptionHeader))
+	if !ok {
+		return data, nil
+	}
+
+	if len(rest) == 0 || len(rest) < 1+int(rest[0]) {
+		return nil, ErrInvalidCiphertext
+	}
+
+	keyID := string(rest[1 : 1+rest[0]])
+	rest = rest[1+rest[0]:]
+
+	key, err := e.Keys.Key(keyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get key %q: %w", keyID, err)
+	}
+
+	aead, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(rest) < aead.NonceSize() {
+		return nil, ErrInvalidCiphertext
+	}
+
+	plain, err := aead.Open(nil, rest[:aead.NonceSize()], rest[aead.NonceSize():], nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidCiphertext, err)
+	}
+
+	return plain, nil
+}
+
+// key returns the key to encrypt the value of id with.
+func (e valueEncryptor) key(id []string) (string, []byte, error) {
+	if e.KeySelector == nil {
+		keyID, key, err := e.Keys.CurrentKey()
+		if err != nil {
+			return "", nil, fmt.Errorf("failed to get current key: %w", err)
+		}
+
+		return keyID, key, nil
+	}
+
+	keyID, err := e.KeySelector(id)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to select key: %w", err)
+	}
+
+	key, err := e.Keys.Key(keyID)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to get key %q: %w", keyID, err)
+	}
+
+	return keyID, key, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create GCM: %w", err)
+	}
+
+	return aead, nil
+}
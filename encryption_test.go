@@ -0,0 +1,125 @@
+// GNU AFFERO GENERAL PUBLIC LICENSE
+// Version 3, 19 November 2007
+//
+// Copyright (C) 2025 John Kleijn
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// For more details, see the full AGPL-3.0 license at:
+// https://www.gnu.org/licenses/agpl-3.0.html
+
+package rtkv_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/johnknl/rtkv"
+	"github.com/johnknl/rtkv/rtkvtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedisTKV_WithEncryption(t *testing.T) {
+	ctx := context.Background()
+	mr, client := rtkvtest.NewMiniredis(t)
+	keys := rtkv.StaticKeys{Current: "k1", Keys: map[string][]byte{"k1": bytes.Repeat([]byte{1}, 32)}}
+	plain := rtkv.NewRedisTKV(rtkv.DelimUnit, t.Name(), client)
+	store := plain.With(rtkv.WithEncryption(rtkv.EncryptionOptions{Keys: keys}))
+
+	_, err := plain.Set(ctx, []byte("old"), time.Now(), "old")
+	require.NoError(t, err)
+	_, err = store.Set(ctx, []byte("secret"), time.Now(), "a")
+	require.NoError(t, err)
+
+	stored, err := mr.Get(t.Name() + rtkv.DelimUnit + "a")
+	require.NoError(t, err)
+	assert.NotContains(t, stored, "secret")
+	assert.True(t, strings.HasPrefix(stored, "\x00rtkv:e\x02k1"), "Values should carry the key ID")
+
+	value, err := store.Get(ctx, "a")
+	require.NoError(t, err)
+	assert.Equal(t, "secret", string(value))
+
+	value, err = store.Get(ctx, "old")
+	require.NoError(t, err)
+	assert.Equal(t, "old", string(value), "Unencrypted values should be readable")
+
+	// Rotate: new values use k2, old ones still decrypt with k1.
+	keys.Keys["k2"] = bytes.Repeat([]byte{2}, 32)
+	keys.Current = "k2"
+	rotated := plain.With(rtkv.WithEncryption(rtkv.EncryptionOptions{Keys: keys}))
+
+	_, err = rotated.Set(ctx, []byte("new"), time.Now(), "b")
+	require.NoError(t, err)
+
+	stored, err = mr.Get(t.Name() + rtkv.DelimUnit + "b")
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(stored, "\x00rtkv:e\x02k2"))
+
+	values, _, err := rotated.FetchPage(ctx, nil, nil, 0, 10)
+	require.NoError(t, err)
+
+	var got []string
+
+	for value, err := range values {
+		require.NoError(t, err)
+
+		got = append(got, string(value))
+	}
+
+	assert.ElementsMatch(t, []string{"old", "secret", "new"}, got)
+
+	// Tampering is detected.
+	require.NoError(t, mr.Set(t.Name()+rtkv.DelimUnit+"b", stored[:len(stored)-1]+string([]byte{stored[len(stored)-1] ^ 1})))
+
+	_, err = rotated.Get(ctx, "b")
+	require.ErrorIs(t, err, rtkv.ErrInvalidCiphertext)
+}
+
+func TestRedisTKV_WithEncryption_KeySelector(t *testing.T) {
+	ctx := context.Background()
+	_, client := rtkvtest.NewMiniredis(t)
+	keys := rtkv.StaticKeys{Keys: map[string][]byte{
+		"acme":   bytes.Repeat([]byte{1}, 16),
+		"globex": bytes.Repeat([]byte{2}, 16),
+	}}
+	store := rtkv.NewRedisTKV(rtkv.DelimUnit, t.Name(), client, rtkv.WithEncryption(rtkv.EncryptionOptions{
+		Keys:        keys,
+		KeySelector: rtkv.IDPartKeySelector(0, ""),
+	}))
+
+	_, err := store.Set(ctx, []byte("a"), time.Now(), "acme", "1")
+	require.NoError(t, err)
+	_, err = store.Set(ctx, []byte("g"), time.Now(), "globex", "1")
+	require.NoError(t, err)
+
+	_, err = store.Set(ctx, []byte("x"), time.Now(), "initech", "1")
+	require.ErrorIs(t, err, rtkv.ErrKeyNotFound)
+
+	// Revoke the key of one tenant.
+	delete(keys.Keys, "globex")
+
+	value, err := store.Get(ctx, "acme", "1")
+	require.NoError(t, err)
+	assert.Equal(t, "a", string(value))
+
+	_, err = store.Get(ctx, "globex", "1")
+	require.ErrorIs(t, err, rtkv.ErrKeyNotFound)
+	assert.False(t, errors.Is(err, rtkv.ErrInvalidCiphertext))
+}
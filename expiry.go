@@ -0,0 +1,162 @@
+// GNU AFFERO GENERAL PUBLIC LICENSE
+// Version 3, 19 November 2007
+//
+// Copyright (C) 2025 John Kleijn
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// For more details, see the full AGPL-3.0 license at:
+// https://www.gnu.org/licenses/agpl-3.0.html
+
+package rtkv
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	expiredSuffix = "expired"
+
+	defaultShadowRetention = 24 * time.Hour
+)
+
+// ExpiryMode determines how RemoveExpired records the
+// entities it finds expired.
+type ExpiryMode int
+
+const (
+	// ExpiryForget removes expired entities from the indexes
+	// without a trace. This is the default.
+	ExpiryForget ExpiryMode = iota
+
+	// ExpiryTombstones records a ChangeExpire event in the change
+	// feed for every expired entity, so consumers following the
+	// feed learn it is gone. Requires WithChangeFeed.
+	ExpiryTombstones
+
+	// ExpiryShadows keeps a shadow entry for every expired entity,
+	// for ExpiryOptions.ShadowRetention, so consumers can find the
+	// entities that expired with FetchExpired.
+	ExpiryShadows
+)
+
+// ExpiryOptions configures WithExpirySemantics.
+type ExpiryOptions struct {
+	Mode ExpiryMode
+
+	// ShadowRetention is how long shadow entries are kept.
+	// Defaults to 24 hours.
+	ShadowRetention time.Duration
+}
+
+// ExpiredEntity is an entity returned by FetchExpired.
+type ExpiredEntity struct {
+	ID      []string
+	Expired time.Time
+}
+
+// WithExpirySemantics sets how entities that expire through their
+// TTL are recorded when RemoveExpired removes them from the index,
+// so downstream caches learn they are gone. Run RemoveExpired
+// regularly, for example with ExpiryCleanupTask, as entities are
+// only recorded once it finds them expired.
+func WithExpirySemantics(opts ExpiryOptions) Option {
+	return func(r *RedisTKV) {
+		if opts.ShadowRetention <= 0 {
+			opts.ShadowRetention = defaultShadowRetention
+		}
+
+		r.expiry = opts
+	}
+}
+
+// FetchExpired returns up to limit entities that expired since
+// the given time, oldest first. Only entities recorded with
+// ExpiryShadows in the last ShadowRetention are returned. An
+// entity written again after it expired is still returned;
+// compare with its lastModified time.
+func (r *RedisTKV) FetchExpired(ctx context.Context, since time.Time, limit int) ([]ExpiredEntity, error) {
+	defer r.observe(ctx, "fetchExpired", time.Now())
+
+	entries, err := r.reader(ctx).ZRangeByScoreWithScores(ctx, r.internalKey(expiredSuffix), &redis.ZRangeBy{
+		Min:   "(" + strconv.FormatInt(since.UnixNano(), 10),
+		Max:   "+inf",
+		Count: int64(limit),
+	}).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute zrangebyscore: %w", err)
+	}
+
+	expired := make([]ExpiredEntity, len(entries))
+
+	for i := range entries {
+		member, _ := entries[i].Member.(string)
+
+		expired[i] = ExpiredEntity{
+			ID:      r.idFromKey(member),
+			Expired: time.Unix(0, int64(entries[i].Score)),
+		}
+	}
+
+	return expired, nil
+}
+
+// expiredIndexes removes entities found expired by removeExpiredScript
+// from the secondary indexes and records them. entries holds the keys
+// of the entities followed by their expiry scores.
+func (r *RedisTKV) expiredIndexes(ctx context.Context, entries []any) error {
+	if len(entries) == 0 {
+		return nil
+	}
+
+	shadows := r.internalKey(expiredSuffix)
+	keys := make([]string, 0, len(entries)/2) //nolint:mnd // keys and scores
+
+	_, err := r.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for i := 0; i+1 < len(entries); i += 2 {
+			key, _ := entries[i].(string)
+			keys = append(keys, key)
+			rawScore, _ := entries[i+1].(string)
+			score, _ := strconv.ParseFloat(rawScore, 64)
+			id := r.idFromKey(key)
+
+			r.secondaryIndexRemove(ctx, pipe, key, id)
+
+			switch r.expiry.Mode {
+			case ExpiryTombstones:
+				r.changeAdd(ctx, pipe, ChangeExpire, id, int64(score))
+			case ExpiryShadows:
+				pipe.ZAdd(ctx, shadows, redis.Z{Score: score, Member: key})
+			case ExpiryForget:
+			}
+		}
+
+		if r.expiry.Mode == ExpiryShadows {
+			cutoff := time.Now().Add(-r.expiry.ShadowRetention).UnixNano()
+			pipe.ZRemRangeByScore(ctx, shadows, "-inf", "("+strconv.FormatInt(cutoff, 10))
+		}
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update indexes: %w", err)
+	}
+
+	return r.compactedRemove(ctx, true, keys...)
+}
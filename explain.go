@@ -0,0 +1,116 @@
+// GNU AFFERO GENERAL PUBLIC LICENSE
+// Version 3, 19 November 2007
+//
+// Copyright (C) 2025 John Kleijn
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// For more details, see the full AGPL-3.0 license at:
+// https://www.gnu.org/licenses/agpl-3.0.html
+
+package rtkv
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	// ExplainPathPipeline is the FetchPage code path, which reads
+	// the index and values in separate round trips.
+	ExplainPathPipeline = "pipeline"
+
+	// ExplainPathScript is the FetchPageConsistent code path,
+	// which reads the index and values in a single Lua script.
+	ExplainPathScript = "script"
+)
+
+// Explain describes how a page was fetched. Pass one to a fetch with
+// ContextWithExplain to find out what the call did and where the time
+// went.
+type Explain struct {
+	// Path is the code path used, ExplainPathPipeline
+	// or ExplainPathScript.
+	Path string
+
+	// ScriptSHA is the SHA of the Lua script used, if any.
+	ScriptSHA string
+
+	// RoundTrips is the number of round trips made to Redis.
+	RoundTrips int
+
+	// Keys is the number of index entries read.
+	Keys int
+
+	// BytesReceived is the total size of the values received.
+	BytesReceived int
+
+	// Stages lists the steps of the call in order.
+	Stages []ExplainStage
+}
+
+// ExplainStage is a single step of an explained call.
+type ExplainStage struct {
+	Name     string
+	Duration time.Duration
+}
+
+type explainCtxKey struct{}
+
+// ContextWithExplain returns a context that makes page fetches
+// record how they were executed into e. The same Explain must
+// not be used by concurrent calls.
+func ContextWithExplain(ctx context.Context, e *Explain) context.Context {
+	return context.WithValue(ctx, explainCtxKey{}, e)
+}
+
+// explainFrom returns the Explain attached to ctx, or nil.
+// All Explain methods are safe to call on nil.
+func explainFrom(ctx context.Context) *Explain {
+	e, _ := ctx.Value(explainCtxKey{}).(*Explain)
+
+	return e
+}
+
+func (e *Explain) start(path string) {
+	if e == nil {
+		return
+	}
+
+	*e = Explain{Path: path}
+}
+
+// stage records a completed round trip that started at start.
+func (e *Explain) stage(name string, start time.Time) {
+	if e == nil {
+		return
+	}
+
+	e.RoundTrips++
+	e.Stages = append(e.Stages, ExplainStage{Name: name, Duration: time.Since(start)})
+}
+
+func (e *Explain) values(keys int, rawValues []any) {
+	if e == nil {
+		return
+	}
+
+	e.Keys += keys
+
+	for _, rawValue := range rawValues {
+		if s, ok := rawValue.(string); ok {
+			e.BytesReceived += len(s)
+		}
+	}
+}
@@ -0,0 +1,70 @@
+// GNU AFFERO GENERAL PUBLIC LICENSE
+// Version 3, 19 November 2007
+//
+// Copyright (C) 2025 John Kleijn
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// For more details, see the full AGPL-3.0 license at:
+// https://www.gnu.org/licenses/agpl-3.0.html
+
+package rtkv_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/johnknl/rtkv"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedisTKV_Explain(t *testing.T) {
+	store := goRedisSetup(t, 100)
+
+	t.Run("Pipeline", func(t *testing.T) {
+		var explain rtkv.Explain
+
+		ctx := rtkv.ContextWithExplain(context.Background(), &explain)
+
+		_, _, err := store.FetchPage(ctx, nil, nil, 0, 10)
+
+		require.NoError(t, err)
+		assert.Equal(t, rtkv.ExplainPathPipeline, explain.Path)
+		assert.Empty(t, explain.ScriptSHA)
+		assert.Equal(t, 3, explain.RoundTrips)
+		assert.Equal(t, 10, explain.Keys)
+		assert.Positive(t, explain.BytesReceived)
+		require.Len(t, explain.Stages, 3)
+		assert.Equal(t, "mget", explain.Stages[2].Name)
+	})
+
+	t.Run("Script", func(t *testing.T) {
+		var explain rtkv.Explain
+
+		ctx := rtkv.ContextWithConsistency(
+			rtkv.ContextWithExplain(context.Background(), &explain),
+			rtkv.ConsistencyStrong,
+		)
+
+		_, _, err := store.Fetch(ctx, nil, nil, 0, 10)
+
+		require.NoError(t, err)
+		assert.Equal(t, rtkv.ExplainPathScript, explain.Path)
+		assert.Len(t, explain.ScriptSHA, 40)
+		assert.Equal(t, 1, explain.RoundTrips)
+		assert.Equal(t, 10, explain.Keys)
+		assert.Positive(t, explain.BytesReceived)
+	})
+}
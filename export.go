@@ -0,0 +1,485 @@
+// GNU AFFERO GENERAL PUBLIC LICENSE
+// Version 3, 19 November 2007
+//
+// Copyright (C) 2025 John Kleijn
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// For more details, see the full AGPL-3.0 license at:
+// https://www.gnu.org/licenses/agpl-3.0.html
+
+package rtkv
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const defaultTransferBatchSize = 1000
+
+// TransferMode selects how values are read and written when
+// moving entities between stores.
+type TransferMode int
+
+const (
+	// TransferValues moves values with GET and SET.
+	TransferValues TransferMode = iota
+
+	// TransferDump moves values with DUMP and RESTORE. This avoids
+	// decoding values on the Redis side, preserves expiry times and
+	// is significantly faster for large namespaces. Dumped values
+	// can only be restored on a Redis version that is compatible
+	// with the source.
+	TransferDump
+)
+
+// TransferOptions controls CopyNamespace, Export and Import.
+type TransferOptions struct {
+	// Mode selects how values are moved.
+	Mode TransferMode
+
+	// Batch controls batch sizes and per-batch timeouts.
+	// The batch size defaults to 1000.
+	Batch BatchOptions
+
+	// State tracks progress. Pass the state of an interrupted
+	// transfer to resume it. Optional.
+	State *BatchState
+
+	// NotAfter, if set, restricts writes to records last modified
+	// at or before this time, for point-in-time restores.
+	NotAfter time.Time
+
+	// SkipNewer leaves entities untouched whose lastModified in the
+	// destination is newer than the record being written, so a restore
+	// doesn't clobber data written after the snapshot was taken.
+	SkipNewer bool
+
+	// Compression is applied to the records written by Export.
+	// Defaults to CompressionNone.
+	Compression SnapshotCompression
+}
+
+// writeIfNotNewerScript writes records unless the store holds
+// a newer version of the entity. Returns a 1 for every record
+// written and a 0 for every record skipped.
+const writeIfNotNewerScript = `
+local index = KEYS[1] -- the lastModified index
+local written = {}
+
+for i = 2, #KEYS do
+  local key = KEYS[i]
+  local offset = (i - 2) * 4
+  local score = ARGV[offset + 1] -- the lastModified score
+  local data = ARGV[offset + 2] -- the value or dump
+  local ttl = tonumber(ARGV[offset + 3]) -- the TTL in milliseconds
+  local dump = ARGV[offset + 4] == "1" -- whether data is a dump
+  local current = redis.call("ZSCORE", index, key)
+
+  if current and tonumber(current) > tonumber(score) then
+    written[#written + 1] = 0
+  else
+    if dump then
+      redis.call("RESTORE", key, ttl, data, "REPLACE")
+    elseif ttl > 0 then
+      redis.call("SET", key, data, "PX", ttl)
+    else
+      redis.call("SET", key, data)
+    end
+
+    redis.call("ZADD", index, score, key)
+    written[#written + 1] = 1
+  end
+end
+
+return written
+`
+
+// snapshotRecord is a single entity in an export.
+type snapshotRecord struct {
+	LastModified time.Time     `json:"lastModified"`
+	ID           []string      `json:"id"`
+	Data         []byte        `json:"data"`
+	TTL          time.Duration `json:"ttl,omitempty"`
+	Dump         bool          `json:"dump,omitempty"`
+}
+
+// CopyNamespace copies all entities and their index entries to dst,
+// which may use a different namespace or Redis instance. Existing
+// entities in dst are overwritten. Returns the number of entities
+// copied.
+//
+// Entities are read in index order. The copy is not a consistent
+// snapshot: entities modified while copying may be skipped or copied
+// twice.
+func (r *RedisTKV) CopyNamespace(ctx context.Context, dst *RedisTKV, opts TransferOptions) (int, error) {
+	var copied int
+
+	err := r.transfer(ctx, opts, func(ctx context.Context, records []snapshotRecord) error {
+		n, err := dst.writeSnapshotRecords(ctx, records, opts)
+		copied += n
+
+		return err
+	})
+
+	return copied, err
+}
+
+// Export writes all entities to w as a SnapshotHeader followed by
+// newline delimited JSON objects with "id", "lastModified" and base64
+// encoded "data" fields, and a "ttl" field in nanoseconds for entities
+// that expire. Use Import to read an export back into a
+// store. Returns the number of entities exported.
+func (r *RedisTKV) Export(ctx context.Context, w io.Writer, opts TransferOptions) (int, error) {
+	var exported int
+
+	enc, err := newSnapshotEncoder(w, opts.Compression)
+	if err != nil {
+		return 0, err
+	}
+
+	err = r.transfer(ctx, opts, func(_ context.Context, records []snapshotRecord) error {
+		for i := range records {
+			if err := enc.encode(records[i]); err != nil {
+				return err
+			}
+
+			exported++
+		}
+
+		return nil
+	})
+	if err != nil {
+		return exported, err
+	}
+
+	return exported, enc.close()
+}
+
+// Import reads an export created by Export, of the current or any
+// older format version, and writes its entities to the store,
+// overwriting existing ones unless opts.SkipNewer is set. Entities
+// keep the TTL they had when exported. IDs the store doesn't accept
+// fail the batch holding them, like a failed write. The mode, state
+// and compression of opts are ignored. Returns the number of entities
+// imported.
+func (r *RedisTKV) Import(ctx context.Context, rd io.Reader, opts TransferOptions) (int, error) {
+	size := opts.batchSize()
+	batch := make([]snapshotRecord, 0, size)
+
+	dec, err := newSnapshotDecoder(rd)
+	if err != nil {
+		return 0, err
+	}
+
+	var imported int
+
+	for {
+		record, err := dec.decode()
+		if err != nil && !errors.Is(err, io.EOF) {
+			return imported, err
+		}
+
+		if err == nil {
+			batch = append(batch, record)
+		}
+
+		if len(batch) == size || (errors.Is(err, io.EOF) && len(batch) > 0) {
+			n, err := r.writeSnapshotRecords(ctx, batch, opts)
+			imported += n
+
+			if err != nil {
+				return imported, err
+			}
+
+			batch = batch[:0]
+		}
+
+		if errors.Is(err, io.EOF) {
+			return imported, nil
+		}
+	}
+}
+
+func (r *RedisTKV) transfer(
+	ctx context.Context,
+	opts TransferOptions,
+	write func(ctx context.Context, records []snapshotRecord) error,
+) error {
+	index := r.namespacedKey(lastModifiedIdxSuffix)
+
+	count := func(ctx context.Context) (int64, error) {
+		return r.client.ZCard(ctx, index).Result() //nolint:wrapcheck // wrapped by transferEntries
+	}
+
+	read := func(ctx context.Context, offset, size int) ([]redis.Z, int, error) {
+		entries, err := r.client.ZRangeWithScores(ctx, index, int64(offset), int64(offset+size-1)).Result()
+
+		return entries, len(entries), err //nolint:wrapcheck // wrapped by transferEntries
+	}
+
+	return r.transferEntries(ctx, opts, opts.State, count, read, write)
+}
+
+// entryReader reads a batch of index entries starting at offset,
+// returning the entries to transfer and the number of entries read.
+type entryReader func(ctx context.Context, offset, size int) ([]redis.Z, int, error)
+
+// transferEntries reads the entities of the index entries returned by
+// read in batches, tracking progress in state, and passes them to write.
+func (r *RedisTKV) transferEntries(
+	ctx context.Context,
+	opts TransferOptions,
+	state *BatchState,
+	count func(ctx context.Context) (int64, error),
+	read entryReader,
+	write func(ctx context.Context, records []snapshotRecord) error,
+) error {
+	if state == nil {
+		state = &BatchState{}
+	}
+
+	batch := opts.Batch
+	batch.Size = opts.batchSize()
+
+	if state.Total == 0 {
+		total, err := count(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to count entities: %w", err)
+		}
+
+		state.Total = int(total)
+	}
+
+	return RunBatches(ctx, state, batch, func(ctx context.Context, offset, size int) (int, error) {
+		entries, n, err := read(ctx, offset, size)
+		if err != nil {
+			return 0, fmt.Errorf("failed to read index: %w", err)
+		}
+
+		records, err := r.readSnapshotRecords(ctx, entries, opts.Mode)
+		if err != nil {
+			return 0, err
+		}
+
+		if err = write(ctx, records); err != nil {
+			return 0, err
+		}
+
+		return n, nil
+	})
+}
+
+// readSnapshotRecords reads the entities of index entries, skipping
+// those that disappeared in the meantime.
+func (r *RedisTKV) readSnapshotRecords(
+	ctx context.Context,
+	entries []redis.Z,
+	mode TransferMode,
+) ([]snapshotRecord, error) {
+	if len(entries) == 0 {
+		return nil, nil
+	}
+
+	values := make([]*redis.StringCmd, len(entries))
+	ttls := make([]*redis.DurationCmd, len(entries))
+
+	_, err := r.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for i := range entries {
+			key := entries[i].Member.(string)
+
+			if mode == TransferDump {
+				values[i] = pipe.Dump(ctx, key)
+			} else {
+				values[i] = pipe.Get(ctx, key)
+			}
+
+			ttls[i] = pipe.PTTL(ctx, key)
+		}
+
+		return nil
+	})
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return nil, fmt.Errorf("failed to read values: %w", err)
+	}
+
+	records := make([]snapshotRecord, 0, len(entries))
+
+	for i := range entries {
+		data, err := values[i].Bytes()
+		if errors.Is(err, redis.Nil) {
+			continue
+		}
+
+		record := snapshotRecord{
+			ID:           r.idFromKey(entries[i].Member.(string)),
+			Data:         data,
+			LastModified: time.Unix(0, int64(entries[i].Score)),
+			Dump:         mode == TransferDump,
+		}
+
+		if ttls[i].Val() > 0 {
+			record.TTL = ttls[i].Val()
+		}
+
+		records = append(records, record)
+	}
+
+	return records, nil
+}
+
+func (r *RedisTKV) writeSnapshotRecords(
+	ctx context.Context,
+	records []snapshotRecord,
+	opts TransferOptions,
+) (int, error) {
+	if err := r.checkWritable(ctx); err != nil {
+		return 0, err
+	}
+
+	for i := range records {
+		if err := r.checkID(records[i].ID); err != nil {
+			return 0, fmt.Errorf("failed to write record %q: %w", records[i].ID, err)
+		}
+	}
+
+	if !opts.NotAfter.IsZero() {
+		kept := make([]snapshotRecord, 0, len(records))
+
+		for i := range records {
+			if !records[i].LastModified.After(opts.NotAfter) {
+				kept = append(kept, records[i])
+			}
+		}
+
+		records = kept
+	}
+
+	if len(records) == 0 {
+		return 0, nil
+	}
+
+	if opts.SkipNewer {
+		return r.writeSnapshotRecordsIfNotNewer(ctx, records)
+	}
+
+	_, err := r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		for i := range records {
+			key := r.namespacedKey(records[i].ID...)
+
+			if records[i].Dump {
+				pipe.RestoreReplace(ctx, key, records[i].TTL, string(records[i].Data))
+			} else {
+				pipe.Set(ctx, key, records[i].Data, records[i].TTL)
+			}
+
+			r.expiryAdd(ctx, pipe, key, records[i].TTL)
+			r.indexAdd(ctx, pipe, float64(records[i].LastModified.UnixNano()), key, records[i].ID)
+			r.snapshotHistoryAdd(ctx, pipe, key, records[i])
+		}
+
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to write records: %w", err)
+	}
+
+	keys := make([]string, len(records))
+
+	for i := range records {
+		keys[i] = r.namespacedKey(records[i].ID...)
+	}
+
+	return len(records), r.compactedRemove(ctx, false, keys...)
+}
+
+// writeSnapshotRecordsIfNotNewer writes records whose entity isn't
+// newer in the store, returning the number of records written.
+func (r *RedisTKV) writeSnapshotRecordsIfNotNewer(ctx context.Context, records []snapshotRecord) (int, error) {
+	keys := make([]string, 1, len(records)+1)
+	keys[0] = r.namespacedKey(lastModifiedIdxSuffix)
+	args := make([]any, 0, len(records)*4) //nolint:mnd // arguments per record
+
+	for i := range records {
+		keys = append(keys, r.namespacedKey(records[i].ID...))
+		args = append(args,
+			float64(records[i].LastModified.UnixNano()),
+			records[i].Data,
+			records[i].TTL.Milliseconds(),
+			records[i].Dump,
+		)
+	}
+
+	result, err := r.evalScript(ctx, writeIfNotNewerScript, keys, args...)
+	if err != nil {
+		return 0, fmt.Errorf("failed to write records: %w", err)
+	}
+
+	flags, ok := result.([]any)
+	if !ok || len(flags) != len(records) {
+		return 0, ErrUnexpectedScriptResult
+	}
+
+	var (
+		written  []string
+		rejected []string
+	)
+
+	_, err = r.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for i, flag := range flags {
+			if flag != int64(1) {
+				rejected = append(rejected, keys[i+1])
+
+				continue
+			}
+
+			written = append(written, keys[i+1])
+
+			r.expiryAdd(ctx, pipe, keys[i+1], records[i].TTL)
+			r.versionsAdd(ctx, pipe, keys[i+1])
+			r.secondaryIndexAdd(ctx, pipe, float64(records[i].LastModified.UnixNano()), keys[i+1], records[i].ID)
+			r.snapshotHistoryAdd(ctx, pipe, keys[i+1], records[i])
+		}
+
+		return nil
+	})
+	if err != nil {
+		return len(written), fmt.Errorf("failed to update indexes: %w", err)
+	}
+
+	r.recordConflicts(ctx, rejected...)
+
+	return len(written), r.compactedRemove(ctx, false, written...)
+}
+
+// snapshotHistoryAdd records the value of an imported record in the
+// history. Dumps are opaque, so their values aren't recorded.
+func (r *RedisTKV) snapshotHistoryAdd(ctx context.Context, pipe redis.Pipeliner, key string, record snapshotRecord) {
+	if !record.Dump {
+		r.historyAdd(ctx, pipe, record.LastModified.UnixNano(), key, record.Data)
+	}
+}
+
+func (o TransferOptions) batchSize() int {
+	if o.Batch.Size > 0 {
+		return o.Batch.Size
+	}
+
+	return defaultTransferBatchSize
+}
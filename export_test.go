@@ -0,0 +1,229 @@
+// GNU AFFERO GENERAL PUBLIC LICENSE
+// Version 3, 19 November 2007
+//
+// Copyright (C) 2025 John Kleijn
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// For more details, see the full AGPL-3.0 license at:
+// https://www.gnu.org/licenses/agpl-3.0.html
+
+package rtkv_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/johnknl/rtkv"
+	"github.com/johnknl/rtkv/rtkvtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedisTKV_CopyNamespace(t *testing.T) {
+	ctx := context.Background()
+	store := goRedisSetup(t, 100)
+
+	for name, mode := range map[string]rtkv.TransferMode{
+		"Values": rtkv.TransferValues,
+		"Dump":   rtkv.TransferDump,
+	} {
+		t.Run(name, func(t *testing.T) {
+			dst := newRTKV(t, newGoRedisClient(0))
+			opts := rtkv.TransferOptions{Mode: mode, Batch: rtkv.BatchOptions{Size: 30}}
+
+			copied, err := store.CopyNamespace(ctx, dst, opts)
+
+			require.NoError(t, err)
+			assert.Equal(t, 100, copied)
+
+			_, total, err := dst.FetchPage(ctx, nil, nil, 0, 1)
+
+			require.NoError(t, err)
+			assert.EqualValues(t, 100, total)
+
+			src, err := store.Get(ctx, "entity", "42")
+			require.NoError(t, err)
+
+			data, err := dst.Get(ctx, "entity", "42")
+			require.NoError(t, err)
+
+			assert.Equal(t, src, data)
+		})
+	}
+}
+
+func TestRedisTKV_ExportImport(t *testing.T) {
+	ctx := context.Background()
+	store := goRedisSetup(t, 100)
+
+	var buf bytes.Buffer
+
+	exported, err := store.Export(ctx, &buf, rtkv.TransferOptions{})
+
+	require.NoError(t, err)
+	assert.Equal(t, 100, exported)
+
+	dst := rtkv.NewRedisTKV(rtkv.DelimUnit, t.Name()+"import", newGoRedisClient(0))
+
+	imported, err := dst.Import(ctx, &buf, rtkv.TransferOptions{Batch: rtkv.BatchOptions{Size: 30}})
+
+	require.NoError(t, err)
+	assert.Equal(t, 100, imported)
+
+	from := time.Now().Add(-time.Minute)
+
+	_, total, err := dst.FetchPage(ctx, &from, nil, 0, 1)
+
+	require.NoError(t, err)
+	assert.EqualValues(t, 100, total, "Import should restore lastModified times")
+}
+
+func TestRedisTKV_ExportImport_TTLAndIDs(t *testing.T) {
+	ctx := context.Background()
+	client := newGoRedisClient(0)
+	store := newRTKV(t, client)
+
+	require.NoError(t, store.BulkSet(ctx, []rtkv.BulkSetRecord{
+		{ID: []string{"expiring"}, Data: []byte("a"), LastModified: time.Now(), TTL: time.Hour},
+		{ID: []string{"kept"}, Data: []byte("b"), LastModified: time.Now()},
+	}))
+
+	var buf bytes.Buffer
+
+	_, err := store.Export(ctx, &buf, rtkv.TransferOptions{})
+	require.NoError(t, err)
+
+	export := buf.Bytes()
+	dst := rtkv.NewRedisTKV(rtkv.DelimUnit, t.Name()+"import", client)
+
+	imported, err := dst.Import(ctx, bytes.NewReader(export), rtkv.TransferOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, 2, imported)
+
+	ttl, err := client.PTTL(ctx, t.Name()+"import"+rtkv.DelimUnit+"expiring").Result()
+	require.NoError(t, err)
+	assert.InDelta(t, time.Hour, ttl, float64(time.Minute), "Values should keep their TTL")
+
+	ttl, err = client.PTTL(ctx, t.Name()+"import"+rtkv.DelimUnit+"kept").Result()
+	require.NoError(t, err)
+	assert.Equal(t, time.Duration(-1), ttl)
+
+	strict := rtkv.NewRedisTKV(rtkv.DelimUnit, t.Name()+"strict", client, rtkv.WithIDScheme(orderScheme))
+
+	imported, err = strict.Import(ctx, bytes.NewReader(export), rtkv.TransferOptions{})
+	require.ErrorIs(t, err, rtkv.ErrInvalidID)
+	assert.Zero(t, imported)
+
+	count, err := strict.Count(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, count, "Nothing should be written")
+}
+
+func TestRedisTKV_Export_Resume(t *testing.T) {
+	ctx := context.Background()
+	store := goRedisSetup(t, 100)
+	state := &rtkv.BatchState{Offset: 60}
+
+	var buf bytes.Buffer
+
+	exported, err := store.Export(ctx, &buf, rtkv.TransferOptions{State: state})
+
+	require.NoError(t, err)
+	assert.Equal(t, 40, exported)
+	assert.True(t, state.Done)
+}
+
+func TestRedisTKV_Import_PointInTime(t *testing.T) {
+	ctx := context.Background()
+	store := newRTKV(t, newGoRedisClient(0))
+	now := time.Now()
+
+	require.NoError(t, store.BulkSet(ctx, []rtkv.BulkSetRecord{
+		{ID: []string{"a"}, Data: []byte("a1"), LastModified: now.Add(-2 * time.Hour)},
+		{ID: []string{"b"}, Data: []byte("b1"), LastModified: now.Add(-2 * time.Hour)},
+		{ID: []string{"c"}, Data: []byte("c1"), LastModified: now},
+	}))
+
+	var buf bytes.Buffer
+
+	_, err := store.Export(ctx, &buf, rtkv.TransferOptions{})
+	require.NoError(t, err)
+
+	require.NoError(t, store.Delete(ctx, "a"))
+	require.NoError(t, store.Delete(ctx, "c"))
+
+	_, err = store.Set(ctx, []byte("b2"), now.Add(-time.Hour), "b")
+	require.NoError(t, err)
+
+	imported, err := store.Import(ctx, &buf, rtkv.TransferOptions{
+		NotAfter:  now.Add(-30 * time.Minute),
+		SkipNewer: true,
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, imported)
+
+	data, err := store.Get(ctx, "a")
+
+	require.NoError(t, err)
+	assert.Equal(t, []byte("a1"), data)
+
+	data, err = store.Get(ctx, "b")
+
+	require.NoError(t, err)
+	assert.Equal(t, []byte("b2"), data, "newer entities should be left untouched")
+
+	data, err = store.Get(ctx, "c")
+
+	require.NoError(t, err)
+	assert.Nil(t, data, "entities modified after NotAfter should not be restored")
+}
+
+func TestRedisTKV_Import_ChangesAndHistory(t *testing.T) {
+	ctx := context.Background()
+	source := rtkvtest.NewMiniredisTKV(t)
+	then := time.Unix(1700000000, 0)
+
+	require.NoError(t, source.BulkSet(ctx, []rtkv.BulkSetRecord{
+		{ID: []string{"a"}, Data: []byte("a"), LastModified: then},
+		{ID: []string{"b"}, Data: []byte("b"), LastModified: then},
+	}))
+
+	var buf bytes.Buffer
+
+	_, err := source.Export(ctx, &buf, rtkv.TransferOptions{})
+	require.NoError(t, err)
+
+	for _, skipNewer := range []bool{false, true} {
+		target := rtkvtest.NewMiniredisTKV(t,
+			rtkv.WithChangeFeed(rtkv.ChangeFeedOptions{}),
+			rtkv.WithHistory(),
+		)
+
+		imported, err := target.Import(ctx, bytes.NewReader(buf.Bytes()), rtkv.TransferOptions{SkipNewer: skipNewer})
+		require.NoError(t, err)
+		assert.Equal(t, 2, imported)
+
+		events, err := target.ReadChanges(ctx, "0", 10)
+		require.NoError(t, err)
+		assert.Len(t, events, 2, "Imported records should be published")
+
+		history, err := target.History(ctx, "a")
+		require.NoError(t, err)
+		assert.Len(t, history, 1, "Imported records should be in the history")
+	}
+}
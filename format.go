@@ -0,0 +1,219 @@
+// GNU AFFERO GENERAL PUBLIC LICENSE
+// Version 3, 19 November 2007
+//
+// Copyright (C) 2025 John Kleijn
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// For more details, see the full AGPL-3.0 license at:
+// https://www.gnu.org/licenses/agpl-3.0.html
+
+package rtkv
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+const (
+	// SnapshotFormatVersion is the snapshot format version written
+	// by this release. Version 1 exports have no header.
+	SnapshotFormatVersion = 2
+
+	snapshotFormatName = "rtkv-snapshot"
+	snapshotCodecJSON  = "json"
+)
+
+// ErrUnsupportedSnapshot is returned when reading a snapshot
+// written with a newer format version, codec or compression
+// than this release supports.
+var ErrUnsupportedSnapshot = errors.New("unsupported snapshot format")
+
+// SnapshotCompression is the compression applied to the records
+// of an export, after its header.
+type SnapshotCompression string
+
+const (
+	// CompressionNone writes records uncompressed.
+	CompressionNone SnapshotCompression = "none"
+
+	// CompressionGzip compresses records with gzip.
+	CompressionGzip SnapshotCompression = "gzip"
+)
+
+// SnapshotHeader is the first line of every export. It describes
+// how the records that follow are encoded, so future releases can
+// read exports written by older ones.
+type SnapshotHeader struct {
+	Format      string              `json:"format"`
+	Version     int                 `json:"version"`
+	Codec       string              `json:"codec"`
+	Compression SnapshotCompression `json:"compression"`
+}
+
+// ConvertSnapshot reads an export of any supported format version
+// from rd and writes it to w in the current version, using the given
+// compression. It doesn't need a store, so old exports can be upgraded
+// offline. Returns the number of records converted.
+func ConvertSnapshot(w io.Writer, rd io.Reader, compression SnapshotCompression) (int, error) {
+	dec, err := newSnapshotDecoder(rd)
+	if err != nil {
+		return 0, err
+	}
+
+	enc, err := newSnapshotEncoder(w, compression)
+	if err != nil {
+		return 0, err
+	}
+
+	var converted int
+
+	for {
+		record, err := dec.decode()
+		if errors.Is(err, io.EOF) {
+			return converted, enc.close()
+		}
+
+		if err != nil {
+			return converted, err
+		}
+
+		if err = enc.encode(record); err != nil {
+			return converted, err
+		}
+
+		converted++
+	}
+}
+
+// snapshotEncoder writes a header followed by records.
+type snapshotEncoder struct {
+	enc *json.Encoder
+	gz  *gzip.Writer
+}
+
+func newSnapshotEncoder(w io.Writer, compression SnapshotCompression) (*snapshotEncoder, error) {
+	if compression == "" {
+		compression = CompressionNone
+	}
+
+	if compression != CompressionNone && compression != CompressionGzip {
+		return nil, fmt.Errorf("%w: compression %q", ErrUnsupportedSnapshot, compression)
+	}
+
+	header := SnapshotHeader{
+		Format:      snapshotFormatName,
+		Version:     SnapshotFormatVersion,
+		Codec:       snapshotCodecJSON,
+		Compression: compression,
+	}
+
+	if err := json.NewEncoder(w).Encode(header); err != nil {
+		return nil, fmt.Errorf("failed to write header: %w", err)
+	}
+
+	if compression == CompressionNone {
+		return &snapshotEncoder{enc: json.NewEncoder(w)}, nil
+	}
+
+	gz := gzip.NewWriter(w)
+
+	return &snapshotEncoder{enc: json.NewEncoder(gz), gz: gz}, nil
+}
+
+func (e *snapshotEncoder) encode(record snapshotRecord) error {
+	if err := e.enc.Encode(record); err != nil {
+		return fmt.Errorf("failed to write record: %w", err)
+	}
+
+	return nil
+}
+
+func (e *snapshotEncoder) close() error {
+	if e.gz == nil {
+		return nil
+	}
+
+	if err := e.gz.Close(); err != nil {
+		return fmt.Errorf("failed to compress records: %w", err)
+	}
+
+	return nil
+}
+
+// snapshotDecoder reads records of any supported format version.
+type snapshotDecoder struct {
+	header SnapshotHeader
+	dec    *json.Decoder
+}
+
+func newSnapshotDecoder(rd io.Reader) (*snapshotDecoder, error) {
+	br := bufio.NewReader(rd)
+
+	line, err := br.ReadBytes('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("failed to read header: %w", err)
+	}
+
+	var header SnapshotHeader
+
+	if len(bytes.TrimSpace(line)) > 0 {
+		if err = json.Unmarshal(line, &header); err != nil {
+			return nil, fmt.Errorf("failed to decode header: %w", err)
+		}
+	}
+
+	if header.Format != snapshotFormatName {
+		// Version 1 exports start with the first record.
+		return &snapshotDecoder{
+			header: SnapshotHeader{Version: 1, Codec: snapshotCodecJSON, Compression: CompressionNone},
+			dec:    json.NewDecoder(io.MultiReader(bytes.NewReader(line), br)),
+		}, nil
+	}
+
+	if header.Version > SnapshotFormatVersion || header.Codec != snapshotCodecJSON {
+		return nil, fmt.Errorf("%w: version %d, codec %q", ErrUnsupportedSnapshot, header.Version, header.Codec)
+	}
+
+	var records io.Reader = br
+
+	switch header.Compression {
+	case CompressionNone, "":
+	case CompressionGzip:
+		if records, err = gzip.NewReader(br); err != nil {
+			return nil, fmt.Errorf("failed to decompress records: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("%w: compression %q", ErrUnsupportedSnapshot, header.Compression)
+	}
+
+	return &snapshotDecoder{header: header, dec: json.NewDecoder(records)}, nil
+}
+
+// decode returns the next record, or io.EOF after the last one.
+func (d *snapshotDecoder) decode() (snapshotRecord, error) {
+	var record snapshotRecord
+
+	err := d.dec.Decode(&record)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return record, fmt.Errorf("failed to read record: %w", err)
+	}
+
+	return record, err //nolint:wrapcheck // io.EOF must not be wrapped
+}
@@ -0,0 +1,88 @@
+// GNU AFFERO GENERAL PUBLIC LICENSE
+// Version 3, 19 November 2007
+//
+// Copyright (C) 2025 John Kleijn
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// For more details, see the full AGPL-3.0 license at:
+// https://www.gnu.org/licenses/agpl-3.0.html
+
+package rtkv_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/johnknl/rtkv"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConvertSnapshot(t *testing.T) {
+	ctx := context.Background()
+	legacy := `{"lastModified":"2024-01-02T03:04:05Z","id":["a"],"data":"YQ=="}
+{"lastModified":"2024-01-02T03:04:05Z","id":["b"],"data":"Yg=="}
+`
+
+	var buf bytes.Buffer
+
+	converted, err := rtkv.ConvertSnapshot(&buf, strings.NewReader(legacy), rtkv.CompressionGzip)
+
+	require.NoError(t, err)
+	assert.Equal(t, 2, converted)
+
+	line, _, _ := strings.Cut(buf.String(), "\n")
+
+	var header rtkv.SnapshotHeader
+
+	require.NoError(t, json.Unmarshal([]byte(line), &header))
+	assert.Equal(t, rtkv.SnapshotFormatVersion, header.Version)
+	assert.Equal(t, rtkv.CompressionGzip, header.Compression)
+
+	store := newRTKV(t, newGoRedisClient(0))
+
+	imported, err := store.Import(ctx, &buf, rtkv.TransferOptions{})
+
+	require.NoError(t, err)
+	assert.Equal(t, 2, imported)
+
+	data, err := store.Get(ctx, "b")
+
+	require.NoError(t, err)
+	assert.Equal(t, []byte("b"), data)
+}
+
+func TestRedisTKV_Import_UnsupportedVersion(t *testing.T) {
+	store := newRTKV(t, newGoRedisClient(0))
+	export := `{"format":"rtkv-snapshot","version":99,"codec":"json","compression":"none"}` + "\n"
+
+	_, err := store.Import(context.Background(), strings.NewReader(export), rtkv.TransferOptions{})
+
+	require.ErrorIs(t, err, rtkv.ErrUnsupportedSnapshot)
+}
+
+func TestRedisTKV_Export_UnsupportedCompression(t *testing.T) {
+	store := newRTKV(t, newGoRedisClient(0))
+
+	var buf bytes.Buffer
+
+	_, err := store.Export(context.Background(), &buf, rtkv.TransferOptions{Compression: "lz4"})
+
+	require.ErrorIs(t, err, rtkv.ErrUnsupportedSnapshot)
+	assert.Zero(t, buf.Len(), "Nothing should be written")
+}
@@ -0,0 +1,94 @@
+// GNU AFFERO GENERAL PUBLIC LICENSE
+// Version 3, 19 November 2007
+//
+// Copyright (C) 2025 John Kleijn
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// For more details, see the full AGPL-3.0 license at:
+// https://www.gnu.org/licenses/agpl-3.0.html
+
+package rtkv
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+// GarbageFunc is called for every value a Store fails to decode.
+// The ID is nil for values read without their IDs, such as by
+// FetchPageTyped.
+type GarbageFunc func(ctx context.Context, id []string, data []byte, err error)
+
+// GarbageOptions configures WithGarbageProbe.
+type GarbageOptions struct {
+	// Quarantine, if set, receives a copy of every value that fails
+	// to decode and was read with its ID, under the same ID. Use a
+	// store in a companion namespace, such as "users-quarantine".
+	// Values are left in place; delete them once inspected.
+	Quarantine *RedisTKV
+
+	// OnGarbage, if set, is called for every value that fails
+	// to decode.
+	OnGarbage GarbageFunc
+}
+
+type garbageProbe struct {
+	opts  GarbageOptions
+	found atomic.Int64
+}
+
+// WithGarbageProbe returns a copy of the store that counts values
+// its codec fails to decode, for example because a producer wrote
+// them with another codec, and reports or quarantines them. Reads
+// fail for such values as before. The copy has a count of its own.
+func (s *Store[T]) WithGarbageProbe(opts GarbageOptions) *Store[T] {
+	return &Store[T]{tkv: s.tkv, codec: s.codec, garbage: &garbageProbe{opts: opts}}
+}
+
+// Garbage returns the number of values that failed to decode since
+// WithGarbageProbe, or 0 for stores without a probe.
+func (s *Store[T]) Garbage() int64 {
+	if s.garbage == nil {
+		return 0
+	}
+
+	return s.garbage.found.Load()
+}
+
+// decodeProbed decodes data like decode, reporting
+// values that fail to the garbage probe.
+func (s *Store[T]) decodeProbed(ctx context.Context, id []string, lastModified time.Time, data []byte, v *T) error {
+	err := s.decode(data, v)
+	if err == nil || s.garbage == nil {
+		return err
+	}
+
+	s.garbage.found.Add(1)
+
+	if s.garbage.opts.OnGarbage != nil {
+		s.garbage.opts.OnGarbage(ctx, id, data, err)
+	}
+
+	if s.garbage.opts.Quarantine != nil && id != nil {
+		if _, qErr := s.garbage.opts.Quarantine.Set(ctx, data, lastModified, id...); qErr != nil {
+			return errors.Join(err, fmt.Errorf("failed to quarantine value: %w", qErr))
+		}
+	}
+
+	return err
+}
@@ -0,0 +1,103 @@
+// GNU AFFERO GENERAL PUBLIC LICENSE
+// Version 3, 19 November 2007
+//
+// Copyright (C) 2025 John Kleijn
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// For more details, see the full AGPL-3.0 license at:
+// https://www.gnu.org/licenses/agpl-3.0.html
+
+package rtkv_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/johnknl/rtkv"
+	"github.com/johnknl/rtkv/jsoncodec"
+	"github.com/johnknl/rtkv/rtkvtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStore_WithGarbageProbe(t *testing.T) {
+	ctx := context.Background()
+	_, client := rtkvtest.NewMiniredis(t)
+	typed := rtkv.NewStore[typedOrder](rtkv.NewRedisTKV(rtkv.DelimUnit, t.Name(), client), jsoncodec.Codec{})
+	quarantine := rtkv.NewRedisTKV(rtkv.DelimUnit, t.Name()+"-quarantine", client)
+	written := time.Unix(1700000000, 0)
+
+	_, err := typed.Set(ctx, typedOrder{N: 1}, written, "json")
+	require.NoError(t, err)
+
+	_, err = typed.TKV().Set(ctx, []byte("\x93\x01\x02\x03"), written, "msgpack")
+	require.NoError(t, err)
+
+	var reported [][]string
+
+	store := typed.WithGarbageProbe(rtkv.GarbageOptions{
+		Quarantine: quarantine,
+		OnGarbage: func(_ context.Context, id []string, _ []byte, _ error) {
+			reported = append(reported, id)
+		},
+	})
+
+	_, err = store.Get(ctx, "json")
+	require.NoError(t, err)
+
+	_, err = store.Get(ctx, "msgpack")
+	require.Error(t, err)
+
+	records, _, err := store.FetchPageTypedRecords(ctx, nil, nil, 0, 10)
+	require.NoError(t, err)
+
+	var failed int
+
+	for _, err := range records {
+		if err != nil {
+			failed++
+		}
+	}
+
+	assert.Equal(t, 1, failed)
+
+	values, _, err := store.FetchPageTyped(ctx, nil, nil, 0, 10)
+	require.NoError(t, err)
+
+	for _, err := range values {
+		if err != nil {
+			failed++
+		}
+	}
+
+	assert.Equal(t, 2, failed)
+
+	assert.EqualValues(t, 3, store.Garbage())
+	assert.Zero(t, typed.Garbage(), "The original store should have no probe")
+	assert.Equal(t, [][]string{{"msgpack"}, {"msgpack"}, nil}, reported)
+
+	value, err := quarantine.Get(ctx, "msgpack")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("\x93\x01\x02\x03"), value)
+
+	quarantined, _, err := quarantine.FetchPageRecords(ctx, nil, nil, 0, 10)
+	require.NoError(t, err)
+
+	for record, err := range quarantined {
+		require.NoError(t, err)
+		assert.Equal(t, written, record.LastModified, "Records should keep their lastModified time")
+	}
+}
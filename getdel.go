@@ -0,0 +1,89 @@
+// GNU AFFERO GENERAL PUBLIC LICENSE
+// Version 3, 19 November 2007
+//
+// Copyright (C) 2025 John Kleijn
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// For more details, see the full AGPL-3.0 license at:
+// https://www.gnu.org/licenses/agpl-3.0.html
+
+package rtkv
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// getDelScript deletes an entity and returns its value,
+// or nil if it doesn't exist.
+const getDelScript = `
+local key = KEYS[1] -- the entity key
+local index = KEYS[2] -- the lastModified index
+
+local data = redis.call("GET", key)
+
+if data then
+  redis.call("DEL", key)
+  redis.call("ZREM", index, key)
+end
+
+return data
+`
+
+// GetDel deletes an entity and returns the value it had, or nil if
+// it didn't exist. Reading and deleting is atomic, so of many
+// concurrent callers exactly one gets the value, as needed to claim
+// work items. The reference policy is not applied. If the entity is
+// an alias, the alias is deleted and the value of its target is
+// returned.
+func (r *RedisTKV) GetDel(ctx context.Context, id ...string) ([]byte, error) {
+	defer r.observe(ctx, "getDel", time.Now())
+
+	if err := r.checkID(id); err != nil {
+		return nil, err
+	}
+
+	if err := r.checkWritable(ctx); err != nil {
+		return nil, err
+	}
+
+	key := r.namespacedKey(id...)
+
+	result, err := r.evalScript(ctx, getDelScript, []string{key, r.namespacedKey(lastModifiedIdxSuffix)})
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return nil, fmt.Errorf("failed to delete entity: %w", err)
+	}
+
+	data, ok := result.(string)
+	if !ok {
+		if r.notFoundError {
+			return nil, ErrNotFound
+		}
+
+		return nil, nil
+	}
+
+	r.stats.recordDeletes(ctx, 1)
+
+	if err = r.deletedIndexes(ctx, []any{key}); err != nil {
+		return nil, err
+	}
+
+	return r.readValue(ctx, []byte(data))
+}
@@ -0,0 +1,124 @@
+// GNU AFFERO GENERAL PUBLIC LICENSE
+// Version 3, 19 November 2007
+//
+// Copyright (C) 2025 John Kleijn
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// For more details, see the full AGPL-3.0 license at:
+// https://www.gnu.org/licenses/agpl-3.0.html
+
+package rtkv
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// getSetScript sets an entity and returns the number of index
+// entries added (1 if the entity is new) and the previous value,
+// or nil if there was none.
+const getSetScript = `
+local key = KEYS[1] -- the entity key
+local index = KEYS[2] -- the lastModified index
+local expiry = KEYS[3] -- the expiry index
+local versions = KEYS[4] -- the versions hash
+local data = ARGV[1] -- the new value
+local score = ARGV[2] -- the lastModified score
+local ttl = tonumber(ARGV[3]) -- the TTL in milliseconds, or 0
+local expireAt = ARGV[4] -- the expiry score
+local versioned = ARGV[5] == "1" -- whether to increment the version
+
+local old = redis.call("GET", key)
+
+if ttl > 0 then
+  redis.call("SET", key, data, "PX", ttl)
+  redis.call("ZADD", expiry, expireAt, key)
+else
+  redis.call("SET", key, data)
+end
+
+if versioned then
+  redis.call("HINCRBY", versions, key, 1)
+end
+
+return {redis.call("ZADD", index, score, key), old}
+`
+
+// GetSet sets an entity and returns the value it replaced, or nil if
+// the entity didn't exist. Reading and writing is atomic, so state
+// transitions can be implemented without a separate Get and without
+// losing updates. If the entity was an alias, the alias is replaced
+// and the value of its former target is returned.
+func (r *RedisTKV) GetSet(ctx context.Context, data []byte, lastModified time.Time, id ...string) ([]byte, error) {
+	defer r.observe(ctx, "getSet", time.Now())
+
+	if err := r.checkID(id); err != nil {
+		return nil, err
+	}
+
+	if err := r.checkWritable(ctx); err != nil {
+		return nil, err
+	}
+
+	if err := r.validate(ctx, id, data); err != nil {
+		return nil, err
+	}
+
+	if err := r.checkWriteConcern(ctx); err != nil {
+		return nil, err
+	}
+
+	data, err := r.encodeValue(id, data)
+	if err != nil {
+		return nil, err
+	}
+
+	timestamp := lastModified.UnixNano()
+	key := r.namespacedKey(id...)
+	ttl := r.ttlFor(0)
+	versions, versioned := r.versionsArgs()
+	keys := []string{key, r.namespacedKey(lastModifiedIdxSuffix), r.internalKey(expirySuffix), versions}
+
+	result, err := r.writeScript(ctx, getSetScript, keys, data, timestamp,
+		ttl.Milliseconds(), time.Now().Add(ttl).UnixNano(), versioned)
+	if err != nil {
+		return nil, fmt.Errorf("failed to set entity: %w", err)
+	}
+
+	parts, ok := result.([]any)
+	if !ok || len(parts) != 2 { //nolint:mnd // added, old
+		return nil, ErrUnexpectedScriptResult
+	}
+
+	added, _ := parts[0].(int64)
+
+	r.stats.recordSet(ctx, len(data), added == 1)
+
+	if err = r.conditionalSetIndexes(ctx, data, timestamp, key); err != nil {
+		return nil, err
+	}
+
+	if err = r.verifyWrites(ctx, []string{key}, [][]byte{data}); err != nil {
+		return nil, err
+	}
+
+	old, ok := parts[1].(string)
+	if !ok {
+		return nil, nil
+	}
+
+	return r.readValue(ctx, []byte(old))
+}
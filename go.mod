@@ -3,9 +3,15 @@ module github.com/johnknl/rtkv
 go 1.23.5
 
 require (
+	github.com/alicebob/miniredis/v2 v2.39.0
 	github.com/buger/jsonparser v1.1.1
 	github.com/go-redis/redis/v8 v8.11.5
+	github.com/klauspost/compress v1.17.11
+	github.com/redis/go-redis/v9 v9.17.0
+	github.com/redis/rueidis v1.0.69
 	github.com/stretchr/testify v1.10.0
+	github.com/vmihailenco/msgpack/v5 v5.4.1
+	google.golang.org/protobuf v1.36.12
 )
 
 require (
@@ -13,5 +19,8 @@ require (
 	github.com/davecgh/go-spew v1.1.1 // indirect
 	github.com/dgryski/go-rendezvous v0.0.0-20200823014737-9f7001d12a5f // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
+	github.com/vmihailenco/tagparser/v2 v2.0.0 // indirect
+	github.com/yuin/gopher-lua v1.1.1 // indirect
+	golang.org/x/sys v0.31.0 // indirect
 	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
@@ -0,0 +1,206 @@
+// GNU AFFERO GENERAL PUBLIC LICENSE
+// Version 3, 19 November 2007
+//
+// Copyright (C) 2025 John Kleijn
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// For more details, see the full AGPL-3.0 license at:
+// https://www.gnu.org/licenses/agpl-3.0.html
+
+package rtkv
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	historySuffix     = "history"
+	historyKeysSuffix = "historyKeys"
+
+	defaultHistoryScanCount = 100
+
+	// compactHistoryScript thins the revisions of one entity: the
+	// newest keepLast revisions are kept, and of the older ones only
+	// the newest per time bucket. Without a bucket, older revisions
+	// are removed. Returns the number of revisions removed.
+	compactHistoryScript = `
+local history = KEYS[1] -- the revisions of the entity
+local keepLast = tonumber(ARGV[1]) -- the number of newest revisions to keep
+local bucket = tonumber(ARGV[2]) -- the bucket length in nanoseconds, or 0
+
+local revisions = redis.call("ZREVRANGE", history, keepLast, -1, "WITHSCORES")
+local removed = 0
+local lastBucket = nil
+
+for i = 1, #revisions, 2 do
+  local b = nil
+
+  if bucket > 0 then
+    b = math.floor(tonumber(revisions[i + 1]) / bucket)
+  end
+
+  if b ~= nil and b ~= lastBucket then
+    lastBucket = b
+  else
+    redis.call("ZREM", history, revisions[i])
+    removed = removed + 1
+  end
+end
+
+return removed
+`
+)
+
+// HistoryPolicy controls how CompactHistory thins revisions.
+type HistoryPolicy struct {
+	// KeepLast is the number of newest revisions per entity
+	// that are always kept.
+	KeepLast int
+
+	// Bucket, if set, keeps one revision per time bucket, the
+	// newest, of the revisions older than the last KeepLast. If
+	// not set, older revisions are removed.
+	Bucket time.Duration
+}
+
+// WithHistory makes Set and BulkSet keep every revision of an
+// entity, readable with History. Revisions are removed when the
+// entity is deleted. Use CompactHistory or HistoryCompactionTask
+// to keep history from growing unbounded.
+func WithHistory() Option {
+	return func(r *RedisTKV) {
+		r.history = true
+	}
+}
+
+// History returns the revisions of an entity, newest first.
+// The ID of the returned records is the ID of the entity.
+func (r *RedisTKV) History(ctx context.Context, id ...string) ([]Record, error) {
+	revisions, err := r.client.ZRevRangeWithScores(ctx, r.historyKey(id), 0, -1).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get history: %w", err)
+	}
+
+	records := make([]Record, len(revisions))
+
+	for i, revision := range revisions {
+		member, _ := revision.Member.(string)
+		_, raw, _ := strings.Cut(member, ":")
+
+		data, err := r.decodeValue([]byte(raw))
+		if err != nil {
+			return nil, err
+		}
+
+		records[i] = Record{
+			LastModified: time.Unix(0, int64(revision.Score)),
+			ID:           id,
+			Data:         data,
+		}
+	}
+
+	return records, nil
+}
+
+// CompactHistory thins the revisions of all entities according to
+// policy. A zero policy removes nothing. Returns the number of
+// revisions removed.
+func (r *RedisTKV) CompactHistory(ctx context.Context, policy HistoryPolicy) (int64, error) {
+	if policy == (HistoryPolicy{}) {
+		return 0, nil
+	}
+
+	registry := r.internalKey(historyKeysSuffix)
+	args := []any{policy.KeepLast, policy.Bucket.Nanoseconds()}
+
+	var (
+		removed int64
+		cursor  uint64
+	)
+
+	budget := budgetFrom(ctx)
+
+	for {
+		if err := budget.spend(ctx, defaultHistoryScanCount); err != nil {
+			return removed, err
+		}
+
+		keys, next, err := r.client.SScan(ctx, registry, cursor, "", defaultHistoryScanCount).Result()
+		if err != nil {
+			return removed, fmt.Errorf("failed to scan history: %w", err)
+		}
+
+		for _, key := range keys {
+			result, err := r.evalScript(ctx, compactHistoryScript, []string{r.historyKey(r.idFromKey(key))}, args...)
+			if err != nil {
+				return removed, fmt.Errorf("failed to compact history: %w", err)
+			}
+
+			n, ok := result.(int64)
+			if !ok {
+				return removed, ErrUnexpectedScriptResult
+			}
+
+			removed += n
+		}
+
+		if cursor = next; cursor == 0 {
+			return removed, nil
+		}
+	}
+}
+
+// HistoryCompactionTask returns a janitor task that compacts
+// history according to policy.
+func HistoryCompactionTask(policy HistoryPolicy) JanitorTask {
+	return func(ctx context.Context, r *RedisTKV) error {
+		_, err := r.CompactHistory(ctx, policy)
+
+		return err
+	}
+}
+
+// historyAdd records a revision of an entity.
+func (r *RedisTKV) historyAdd(ctx context.Context, pipe redis.Pipeliner, timestamp int64, key string, data []byte) {
+	if !r.history {
+		return
+	}
+
+	pipe.ZAdd(ctx, r.historyKey(r.idFromKey(key)), redis.Z{
+		Score:  float64(timestamp),
+		Member: strconv.FormatInt(timestamp, 10) + ":" + string(data),
+	})
+	pipe.SAdd(ctx, r.internalKey(historyKeysSuffix), key)
+}
+
+// historyRemove removes all revisions of an entity.
+func (r *RedisTKV) historyRemove(ctx context.Context, pipe redis.Pipeliner, key string, id []string) {
+	if !r.history {
+		return
+	}
+
+	pipe.Del(ctx, r.historyKey(id))
+	pipe.SRem(ctx, r.internalKey(historyKeysSuffix), key)
+}
+
+func (r *RedisTKV) historyKey(id []string) string {
+	return r.internalKey(historySuffix, id...)
+}
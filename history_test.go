@@ -0,0 +1,92 @@
+// GNU AFFERO GENERAL PUBLIC LICENSE
+// Version 3, 19 November 2007
+//
+// Copyright (C) 2025 John Kleijn
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// For more details, see the full AGPL-3.0 license at:
+// https://www.gnu.org/licenses/agpl-3.0.html
+
+package rtkv_test
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/johnknl/rtkv"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedisTKV_CompactHistory(t *testing.T) {
+	ctx := context.Background()
+	store := newRTKV(t, newGoRedisClient(0)).With(rtkv.WithHistory())
+	base := time.Unix(0, 0).Add(1000 * time.Hour)
+
+	for i := range 6 {
+		_, err := store.Set(ctx, []byte("v"+strconv.Itoa(i)), base.Add(time.Duration(i)*time.Hour), "a")
+		require.NoError(t, err)
+	}
+
+	history, err := store.History(ctx, "a")
+
+	require.NoError(t, err)
+	require.Len(t, history, 6)
+	assert.Equal(t, []byte("v5"), history[0].Data)
+
+	removed, err := store.CompactHistory(ctx, rtkv.HistoryPolicy{KeepLast: 2, Bucket: 2 * time.Hour})
+
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, removed)
+
+	history, err = store.History(ctx, "a")
+	require.NoError(t, err)
+
+	var values []string
+
+	for _, record := range history {
+		values = append(values, string(record.Data))
+	}
+
+	assert.Equal(t, []string{"v5", "v4", "v3", "v1"}, values)
+
+	require.NoError(t, store.Delete(ctx, "a"))
+
+	history, err = store.History(ctx, "a")
+
+	require.NoError(t, err)
+	assert.Empty(t, history)
+}
+
+func TestRedisTKV_History_Update(t *testing.T) {
+	ctx := context.Background()
+	store := newRTKV(t, newGoRedisClient(0)).With(rtkv.WithHistory())
+
+	_, err := store.Set(ctx, []byte("v0"), time.Now(), "a")
+	require.NoError(t, err)
+
+	_, err = store.Update(ctx, []string{"a"}, func(old []byte) ([]byte, error) {
+		return append(old, '!'), nil
+	})
+	require.NoError(t, err)
+
+	history, err := store.History(ctx, "a")
+
+	require.NoError(t, err)
+	require.Len(t, history, 2, "Updates should record a revision")
+	assert.Equal(t, []byte("v0!"), history[0].Data)
+}
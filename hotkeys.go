@@ -0,0 +1,266 @@
+// GNU AFFERO GENERAL PUBLIC LICENSE
+// Version 3, 19 November 2007
+//
+// Copyright (C) 2025 John Kleijn
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// For more details, see the full AGPL-3.0 license at:
+// https://www.gnu.org/licenses/agpl-3.0.html
+
+package rtkv
+
+import (
+	"cmp"
+	"context"
+	"math"
+	"math/rand/v2"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	hotReadsSuffix  = "hotReads"
+	hotWritesSuffix = "hotWrites"
+
+	defaultHotKeySampleRate = 0.01
+	defaultHotKeyWindow     = time.Minute
+	defaultHotKeyTopN       = 10
+)
+
+// HotKeyOptions configures hot-key detection.
+type HotKeyOptions struct {
+	// SampleRate is the fraction of operations sampled.
+	// Defaults to 0.01.
+	SampleRate float64
+
+	// Window is the length of the sliding window counts are kept
+	// for. Counts cover between one and two windows. Defaults to
+	// one minute.
+	Window time.Duration
+
+	// TopN is the number of hot keys reported. Defaults to 10.
+	TopN int
+
+	// Aggregate additionally counts samples in Redis, so hot keys
+	// across all instances sharing the namespace can be queried
+	// with AggregatedHotKeys.
+	Aggregate bool
+}
+
+// HotKey is an entity and its estimated number of
+// operations within the hot-key window.
+type HotKey struct {
+	ID    []string
+	Count int64
+}
+
+// WithHotKeyTracking samples reads and writes to find the most
+// frequently accessed entities. Results are reported by Stats.
+func WithHotKeyTracking(opts HotKeyOptions) Option {
+	return func(r *RedisTKV) {
+		opts = opts.withDefaults()
+		r.hotKeys = &hotKeys{
+			opts:   opts,
+			reads:  newHotKeyWindow(),
+			writes: newHotKeyWindow(),
+		}
+	}
+}
+
+// AggregatedHotKeys returns the hot keys counted in Redis by all
+// stores sharing the namespace. Requires HotKeyOptions.Aggregate.
+func (r *RedisTKV) AggregatedHotKeys(ctx context.Context) (reads, writes []HotKey, err error) {
+	if r.hotKeys == nil || !r.hotKeys.opts.Aggregate {
+		return nil, nil, nil
+	}
+
+	if reads, err = r.aggregatedHotKeys(ctx, hotReadsSuffix); err != nil {
+		return nil, nil, err
+	}
+
+	if writes, err = r.aggregatedHotKeys(ctx, hotWritesSuffix); err != nil {
+		return nil, nil, err
+	}
+
+	return reads, writes, nil
+}
+
+type hotKeys struct {
+	opts   HotKeyOptions
+	reads  *hotKeyWindow
+	writes *hotKeyWindow
+}
+
+// hotKeyWindow counts samples in the current and previous window.
+type hotKeyWindow struct {
+	start    time.Time
+	current  map[string]int64
+	previous map[string]int64
+	mx       sync.Mutex
+}
+
+func newHotKeyWindow() *hotKeyWindow {
+	return &hotKeyWindow{
+		start:    time.Now(),
+		current:  map[string]int64{},
+		previous: map[string]int64{},
+	}
+}
+
+func (r *RedisTKV) sampleRead(ctx context.Context, key string) {
+	r.sampleHotKey(ctx, r.client, hotReadsSuffix, key)
+}
+
+// sampleWrite samples a write. Aggregated counts are queued on
+// pipe, so they are only recorded if the write succeeds.
+func (r *RedisTKV) sampleWrite(ctx context.Context, pipe redis.Pipeliner, key string) {
+	r.sampleHotKey(ctx, pipe, hotWritesSuffix, key)
+}
+
+func (r *RedisTKV) sampleHotKey(ctx context.Context, c redis.Cmdable, kind, key string) {
+	h := r.hotKeys
+	if h == nil || rand.Float64() >= h.opts.SampleRate { //nolint:gosec // sampling needs no crypto
+		return
+	}
+
+	window := h.reads
+	if kind == hotWritesSuffix {
+		window = h.writes
+	}
+
+	window.add(key, h.opts.Window)
+
+	if !h.opts.Aggregate {
+		return
+	}
+
+	bucket := r.hotKeyBucket(kind, time.Now())
+
+	c.ZIncrBy(ctx, bucket, 1, key)
+
+	if err := c.Expire(ctx, bucket, 2*h.opts.Window).Err(); err != nil {
+		r.logger.WarnContext(ctx, "failed to record hot key sample",
+			"namespace", r.namespace,
+			"error", err,
+		)
+	}
+}
+
+func (r *RedisTKV) hotKeyBucket(kind string, t time.Time) string {
+	window := r.hotKeys.opts.Window
+	start := t.Truncate(window).UnixNano()
+
+	return r.internalKey(kind, strconv.FormatInt(start, 10))
+}
+
+func (r *RedisTKV) aggregatedHotKeys(ctx context.Context, kind string) ([]HotKey, error) {
+	now := time.Now()
+	topN := int64(r.hotKeys.opts.TopN)
+	counts := map[string]int64{}
+
+	for _, t := range []time.Time{now, now.Add(-r.hotKeys.opts.Window)} {
+		entries, err := r.client.ZRevRangeWithScores(ctx, r.hotKeyBucket(kind, t), 0, topN-1).Result()
+		if err != nil {
+			return nil, err //nolint:wrapcheck // wrapped by caller
+		}
+
+		for _, entry := range entries {
+			counts[entry.Member.(string)] += int64(entry.Score)
+		}
+	}
+
+	return r.topHotKeys(counts), nil
+}
+
+func (w *hotKeyWindow) add(key string, window time.Duration) {
+	w.mx.Lock()
+	defer w.mx.Unlock()
+
+	w.rotate(window)
+	w.current[key]++
+}
+
+func (w *hotKeyWindow) counts(window time.Duration) map[string]int64 {
+	w.mx.Lock()
+	defer w.mx.Unlock()
+
+	w.rotate(window)
+
+	counts := make(map[string]int64, len(w.current)+len(w.previous))
+
+	for key, n := range w.previous {
+		counts[key] += n
+	}
+
+	for key, n := range w.current {
+		counts[key] += n
+	}
+
+	return counts
+}
+
+func (w *hotKeyWindow) rotate(window time.Duration) {
+	elapsed := time.Since(w.start)
+
+	switch {
+	case elapsed >= 2*window:
+		w.previous = map[string]int64{}
+		w.current = map[string]int64{}
+		w.start = time.Now()
+	case elapsed >= window:
+		w.previous = w.current
+		w.current = map[string]int64{}
+		w.start = w.start.Add(window)
+	}
+}
+
+// topHotKeys converts sampled counts to estimated counts
+// and returns the top N, highest first.
+func (r *RedisTKV) topHotKeys(counts map[string]int64) []HotKey {
+	hot := make([]HotKey, 0, len(counts))
+
+	for key, n := range counts {
+		hot = append(hot, HotKey{
+			ID:    r.idFromKey(key),
+			Count: int64(math.Round(float64(n) / r.hotKeys.opts.SampleRate)),
+		})
+	}
+
+	slices.SortFunc(hot, func(a, b HotKey) int {
+		return cmp.Compare(b.Count, a.Count)
+	})
+
+	return hot[:min(len(hot), r.hotKeys.opts.TopN)]
+}
+
+func (o HotKeyOptions) withDefaults() HotKeyOptions {
+	if o.SampleRate <= 0 {
+		o.SampleRate = defaultHotKeySampleRate
+	}
+
+	if o.Window <= 0 {
+		o.Window = defaultHotKeyWindow
+	}
+
+	if o.TopN <= 0 {
+		o.TopN = defaultHotKeyTopN
+	}
+
+	return o
+}
@@ -0,0 +1,86 @@
+// GNU AFFERO GENERAL PUBLIC LICENSE
+// Version 3, 19 November 2007
+//
+// Copyright (C) 2025 John Kleijn
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// For more details, see the full AGPL-3.0 license at:
+// https://www.gnu.org/licenses/agpl-3.0.html
+
+package rtkv_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/johnknl/rtkv"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedisTKV_HotKeys(t *testing.T) {
+	ctx := context.Background()
+
+	redisClient := newGoRedisClient(0)
+
+	t.Cleanup(func() {
+		redisClient.FlushDB(ctx).Err()
+	})
+
+	store := newRTKV(t, redisClient).With(rtkv.WithHotKeyTracking(rtkv.HotKeyOptions{
+		SampleRate: 1,
+		TopN:       1,
+		Aggregate:  true,
+	}))
+
+	for range 3 {
+		_, err := store.Set(ctx, []byte("b"), time.Now(), "b")
+		require.NoError(t, err)
+	}
+
+	_, err := store.Set(ctx, []byte("a"), time.Now(), "a")
+	require.NoError(t, err)
+
+	for range 5 {
+		_, err = store.Get(ctx, "a")
+		require.NoError(t, err)
+	}
+
+	_, err = store.Get(ctx, "b")
+	require.NoError(t, err)
+
+	stats := store.Stats()
+
+	assert.Equal(t, []rtkv.HotKey{{ID: []string{"a"}, Count: 5}}, stats.HotReads)
+	assert.Equal(t, []rtkv.HotKey{{ID: []string{"b"}, Count: 3}}, stats.HotWrites)
+
+	reads, writes, err := store.AggregatedHotKeys(ctx)
+
+	require.NoError(t, err)
+	assert.Equal(t, stats.HotReads, reads)
+	assert.Equal(t, stats.HotWrites, writes)
+}
+
+func TestRedisTKV_HotKeys_Disabled(t *testing.T) {
+	store := newRTKV(t, newGoRedisClient(0))
+
+	reads, writes, err := store.AggregatedHotKeys(context.Background())
+
+	require.NoError(t, err)
+	assert.Nil(t, reads)
+	assert.Nil(t, writes)
+	assert.Nil(t, store.Stats().HotReads)
+}
@@ -0,0 +1,195 @@
+// GNU AFFERO GENERAL PUBLIC LICENSE
+// Version 3, 19 November 2007
+//
+// Copyright (C) 2025 John Kleijn
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// For more details, see the full AGPL-3.0 license at:
+// https://www.gnu.org/licenses/agpl-3.0.html
+
+package rtkv
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	idIdxSuffix = "ids"
+
+	defaultIDIndexRebuildCount = 1000
+
+	// idPageScript reads a page of entities in ID order. Returns
+	// the keys, lastModified scores and values of up to limit
+	// entities, and whether there are more.
+	idPageScript = `
+local ids = KEYS[1] -- the ID index
+local index = KEYS[2] -- the lastModified index
+local min = ARGV[1] -- the lex range start
+local limit = tonumber(ARGV[2]) -- the page size
+
+local keys = redis.call("ZRANGEBYLEX", ids, min, "+", "LIMIT", 0, limit + 1)
+local more = 0
+
+if #keys > limit then
+  table.remove(keys)
+  more = 1
+end
+
+if #keys == 0 then
+  return { keys, {}, {}, more }
+end
+
+local scores = {}
+
+for i, key in ipairs(keys) do
+  scores[i] = redis.call("ZSCORE", index, key) or "0"
+end
+
+return { keys, scores, redis.call("MGET", unpack(keys)), more }
+`
+)
+
+// WithIDIndex maintains a lexical index of entity IDs, so entities
+// can be read in ID order with FetchPageByID. This gives exports that
+// must be deterministic by ID, rather than by time, a supported path.
+// Entities written before the index was enabled are added with
+// RebuildIDIndex.
+func WithIDIndex() Option {
+	return func(r *RedisTKV) {
+		r.idIndex = true
+	}
+}
+
+// FetchPageByID reads up to limit entities in ID order, starting
+// after the entity with ID after, and returns the ID to pass as after
+// for the next page. Pass no ID for the first page. The next ID is
+// nil after the last page. Each page is read atomically.
+//
+// IDs are ordered by their namespaced keys, byte by byte, so an ID
+// sorts right before the IDs it is a prefix of.
+func (r *RedisTKV) FetchPageByID(
+	ctx context.Context,
+	after []string,
+	limit int,
+) (iter.Seq2[Record, error], []string, error) {
+	defer r.observe(ctx, "fetchPageByID", time.Now())
+
+	start := "-"
+	if len(after) > 0 {
+		start = "(" + r.namespacedKey(after...)
+	}
+
+	release, err := r.acquireFetch(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	defer release()
+
+	keys := []string{r.internalKey(idIdxSuffix), r.namespacedKey(lastModifiedIdxSuffix)}
+
+	result, err := r.evalScript(ctx, idPageScript, keys, start, limit)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to fetch page: %w", err)
+	}
+
+	parts, ok := result.([]any)
+	if !ok || len(parts) != 4 { //nolint:mnd // keys, scores, values, more
+		return nil, nil, ErrUnexpectedScriptResult
+	}
+
+	members, _ := parts[0].([]any)
+	scores, _ := parts[1].([]any)
+	values, _ := parts[2].([]any)
+
+	var next []string
+
+	if more, _ := parts[3].(int64); more == 1 {
+		last, _ := members[len(members)-1].(string)
+		next = r.idFromKey(last)
+	}
+
+	memberKeys := make([]string, len(members))
+	nanos := make([]float64, len(scores))
+
+	for i := range members {
+		memberKeys[i], _ = members[i].(string)
+		score, _ := scores[i].(string)
+		nanos[i], _ = strconv.ParseFloat(score, 64)
+	}
+
+	return r.yieldRecords(memberKeys, nanos, values), next, nil
+}
+
+// RebuildIDIndex adds all entities in the lastModified index to the
+// ID index. Returns the number of entities added.
+func (r *RedisTKV) RebuildIDIndex(ctx context.Context) (int64, error) {
+	var (
+		added  int64
+		cursor uint64
+	)
+
+	for {
+		entries, next, err := r.client.ZScan(ctx, r.namespacedKey(lastModifiedIdxSuffix), cursor, "",
+			defaultIDIndexRebuildCount).Result()
+		if err != nil {
+			return added, fmt.Errorf("failed to scan index: %w", err)
+		}
+
+		// ZSCAN returns members and scores interleaved.
+		members := make([]redis.Z, 0, len(entries)/2) //nolint:mnd // member, score
+
+		for i := 0; i < len(entries); i += 2 {
+			members = append(members, redis.Z{Member: entries[i]})
+		}
+
+		if len(members) > 0 {
+			n, err := r.client.ZAdd(ctx, r.internalKey(idIdxSuffix), members...).Result()
+			if err != nil {
+				return added, fmt.Errorf("failed to add to ID index: %w", err)
+			}
+
+			added += n
+		}
+
+		if cursor = next; cursor == 0 {
+			return added, nil
+		}
+	}
+}
+
+// idsAdd adds an entity to the ID index.
+func (r *RedisTKV) idsAdd(ctx context.Context, pipe redis.Pipeliner, key string) {
+	if !r.idIndex {
+		return
+	}
+
+	pipe.ZAdd(ctx, r.internalKey(idIdxSuffix), redis.Z{Member: key})
+}
+
+// idsRemove removes an entity from the ID index.
+func (r *RedisTKV) idsRemove(ctx context.Context, pipe redis.Pipeliner, key string) {
+	if !r.idIndex {
+		return
+	}
+
+	pipe.ZRem(ctx, r.internalKey(idIdxSuffix), key)
+}
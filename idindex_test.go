@@ -0,0 +1,84 @@
+// GNU AFFERO GENERAL PUBLIC LICENSE
+// Version 3, 19 November 2007
+//
+// Copyright (C) 2025 John Kleijn
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// For more details, see the full AGPL-3.0 license at:
+// https://www.gnu.org/licenses/agpl-3.0.html
+
+package rtkv_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/johnknl/rtkv"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedisTKV_FetchPageByID(t *testing.T) {
+	ctx := context.Background()
+	plain := newRTKV(t, newGoRedisClient(0))
+	store := plain.With(rtkv.WithIDIndex())
+	base := time.Date(2025, 1, 10, 12, 0, 0, 0, time.UTC)
+
+	_, err := plain.Set(ctx, []byte("b"), base, "b")
+	require.NoError(t, err)
+
+	added, err := store.RebuildIDIndex(ctx)
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, added)
+
+	require.NoError(t, store.BulkSet(ctx, []rtkv.BulkSetRecord{
+		{LastModified: base.Add(3 * time.Hour), ID: []string{"a", "2"}, Data: []byte("a2")},
+		{LastModified: base.Add(2 * time.Hour), ID: []string{"a", "1"}, Data: []byte("a1")},
+		{LastModified: base.Add(time.Hour), ID: []string{"c"}, Data: []byte("c")},
+		{LastModified: base, ID: []string{"d"}, Data: []byte("d")},
+	}))
+	require.NoError(t, store.Delete(ctx, "d"))
+
+	var (
+		ids   [][]string
+		after []string
+		pages int
+	)
+
+	for {
+		records, next, err := store.FetchPageByID(ctx, after, 2)
+		require.NoError(t, err)
+
+		for record, err := range records {
+			require.NoError(t, err)
+
+			ids = append(ids, record.ID)
+
+			if record.ID[0] == "c" {
+				assert.WithinDuration(t, base.Add(time.Hour), record.LastModified, time.Microsecond)
+			}
+		}
+
+		pages++
+
+		if after = next; after == nil {
+			break
+		}
+	}
+
+	assert.Equal(t, [][]string{{"a", "1"}, {"a", "2"}, {"b"}, {"c"}}, ids)
+	assert.Equal(t, 2, pages)
+}
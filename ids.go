@@ -0,0 +1,271 @@
+// GNU AFFERO GENERAL PUBLIC LICENSE
+// Version 3, 19 November 2007
+//
+// Copyright (C) 2025 John Kleijn
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// For more details, see the full AGPL-3.0 license at:
+// https://www.gnu.org/licenses/agpl-3.0.html
+
+package rtkv
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidID is returned for composite IDs that don't match
+// their IDScheme.
+var ErrInvalidID = errors.New("invalid ID")
+
+// Segment is a single part of a composite ID.
+type Segment struct {
+	// Name describes the segment in errors.
+	Name string
+
+	// Validate returns an error for invalid values.
+	Validate func(value string) error
+}
+
+// StringSegment is a segment holding any non-empty string.
+func StringSegment(name string) Segment {
+	return Segment{Name: name, Validate: func(value string) error {
+		if value == "" {
+			return errors.New("empty") //nolint:err113 // wrapped with ErrInvalidID
+		}
+
+		return nil
+	}}
+}
+
+// IntSegment is a segment holding a base 10 integer in canonical
+// form, without a plus sign or leading zeros, so every integer maps
+// to a single key.
+func IntSegment(name string) Segment {
+	return Segment{Name: name, Validate: func(value string) error {
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || strconv.FormatInt(n, 10) != value {
+			return fmt.Errorf("%q is not an integer in canonical form", value) //nolint:err113 // wrapped with ErrInvalidID
+		}
+
+		return nil
+	}}
+}
+
+// UUIDSegment is a segment holding a UUID in its canonical
+// lowercase form, such as 123e4567-e89b-12d3-a456-426614174000.
+func UUIDSegment(name string) Segment {
+	return Segment{Name: name, Validate: func(value string) error {
+		if !isUUID(value) {
+			return fmt.Errorf("%q is not a lowercase UUID", value) //nolint:err113 // wrapped with ErrInvalidID
+		}
+
+		return nil
+	}}
+}
+
+// IDScheme describes the composite IDs of a namespace: a fixed
+// number of typed segments.
+type IDScheme struct {
+	segments []Segment
+}
+
+// NewIDScheme returns a scheme of IDs made up of segments.
+func NewIDScheme(segments ...Segment) IDScheme {
+	return IDScheme{segments: segments}
+}
+
+// Len returns the number of segments of IDs.
+func (s IDScheme) Len() int {
+	return len(s.segments)
+}
+
+// Validate returns an error wrapping ErrInvalidID if id
+// doesn't match the scheme.
+func (s IDScheme) Validate(id []string) error {
+	if len(id) != len(s.segments) {
+		return fmt.Errorf("%w: %v has %d parts, expected %d (%s)", ErrInvalidID, id, len(id), len(s.segments), s)
+	}
+
+	for i, segment := range s.segments {
+		if segment.Validate == nil {
+			continue
+		}
+
+		if err := segment.Validate(id[i]); err != nil {
+			return fmt.Errorf("%w: %v: %s: %w", ErrInvalidID, id, segment.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// Format formats parts as an ID and validates it. Strings are used
+// as is, integers in base 10 and fmt.Stringers, such as UUID types,
+// through their String method.
+func (s IDScheme) Format(parts ...any) ([]string, error) {
+	id := make([]string, len(parts))
+
+	for i, part := range parts {
+		switch v := part.(type) {
+		case string:
+			id[i] = v
+		case int:
+			id[i] = strconv.Itoa(v)
+		case int64:
+			id[i] = strconv.FormatInt(v, 10)
+		case uint64:
+			id[i] = strconv.FormatUint(v, 10)
+		case fmt.Stringer:
+			id[i] = v.String()
+		default:
+			return nil, fmt.Errorf("%w: unsupported part type %T", ErrInvalidID, part)
+		}
+	}
+
+	if err := s.Validate(id); err != nil {
+		return nil, err
+	}
+
+	return id, nil
+}
+
+// String describes the scheme, such as "customer/order".
+func (s IDScheme) String() string {
+	names := make([]string, len(s.segments))
+
+	for i, segment := range s.segments {
+		names[i] = segment.Name
+	}
+
+	return strings.Join(names, "/")
+}
+
+// ID converts between a typed identifier T, typically a struct with
+// a field per segment, and the composite IDs of a scheme. Using it
+// instead of building []string IDs by hand means the parts of an ID
+// are checked by the compiler.
+type ID[T any] struct {
+	scheme IDScheme
+	format func(T) []string
+	parse  func(id []string) (T, error)
+}
+
+// NewID returns an ID for scheme. format converts a T to its
+// composite ID and parse converts it back; parse is only called
+// with IDs that match the scheme.
+func NewID[T any](scheme IDScheme, format func(T) []string, parse func(id []string) (T, error)) ID[T] {
+	return ID[T]{scheme: scheme, format: format, parse: parse}
+}
+
+// Parts returns the composite ID of v, validated against the scheme.
+func (i ID[T]) Parts(v T) ([]string, error) {
+	id := i.format(v)
+
+	if err := i.scheme.Validate(id); err != nil {
+		return nil, err
+	}
+
+	return id, nil
+}
+
+// MustParts is like Parts but panics on invalid IDs, for IDs
+// that are known to be valid.
+func (i ID[T]) MustParts(v T) []string {
+	id, err := i.Parts(v)
+	if err != nil {
+		panic(err)
+	}
+
+	return id
+}
+
+// Parse converts a composite ID, such as the ID of a Record,
+// back to a T.
+func (i ID[T]) Parse(id []string) (T, error) {
+	if err := i.scheme.Validate(id); err != nil {
+		var zero T
+
+		return zero, err
+	}
+
+	return i.parse(id)
+}
+
+// WithIDScheme rejects IDs that don't match scheme with an error
+// wrapping ErrInvalidID, on reads and writes by ID, so bugs such as
+// passing the wrong number of ID parts fail loudly instead of
+// writing stray keys.
+func WithIDScheme(scheme IDScheme) Option {
+	return func(r *RedisTKV) {
+		r.idScheme = &scheme
+	}
+}
+
+// internalKeyPrefix starts the keys the store keeps next to
+// entities, other than the lastModified index, after the namespace
+// prefix. IDs can't start with it, so those keys never collide with
+// entities, whatever features are enabled.
+const internalKeyPrefix = "\x00rtkv:"
+
+// internalKey returns the key of internal state, such as an index.
+func (r *RedisTKV) internalKey(suffix string, parts ...string) string {
+	return r.namespacedKey(append([]string{internalKeyPrefix + suffix}, parts...)...)
+}
+
+// isInternalID reports whether id is that of an internal key
+// rather than an entity.
+func isInternalID(id []string) bool {
+	return len(id) > 0 && (id[0] == lastModifiedIdxSuffix || strings.HasPrefix(id[0], internalKeyPrefix))
+}
+
+// checkID rejects IDs of internal keys, and validates
+// id against the ID scheme of the store, if any.
+func (r *RedisTKV) checkID(id []string) error {
+	if isInternalID(id) {
+		return fmt.Errorf("%w: %q is reserved", ErrInvalidID, id[0])
+	}
+
+	if r.idScheme == nil {
+		return nil
+	}
+
+	return r.idScheme.Validate(id)
+}
+
+func isUUID(s string) bool {
+	if len(s) != 36 { //nolint:mnd // length of a UUID
+		return false
+	}
+
+	for i := range len(s) {
+		c := s[i]
+
+		switch i {
+		case 8, 13, 18, 23: //nolint:mnd // positions of the dashes
+			if c != '-' {
+				return false
+			}
+		default:
+			if (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+				return false
+			}
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,132 @@
+// GNU AFFERO GENERAL PUBLIC LICENSE
+// Version 3, 19 November 2007
+//
+// Copyright (C) 2025 John Kleijn
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// For more details, see the full AGPL-3.0 license at:
+// https://www.gnu.org/licenses/agpl-3.0.html
+
+package rtkv_test
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/johnknl/rtkv"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type orderID struct {
+	Customer int
+	Order    string
+}
+
+var orderScheme = rtkv.NewIDScheme(rtkv.IntSegment("customer"), rtkv.UUIDSegment("order"))
+
+var orderIDs = rtkv.NewID(orderScheme,
+	func(id orderID) []string { return []string{strconv.Itoa(id.Customer), id.Order} },
+	func(id []string) (orderID, error) {
+		customer, err := strconv.Atoi(id[0])
+
+		return orderID{Customer: customer, Order: id[1]}, err
+	},
+)
+
+func TestIDScheme_Validate(t *testing.T) {
+	const order = "123e4567-e89b-12d3-a456-426614174000"
+
+	require.NoError(t, orderScheme.Validate([]string{"42", order}))
+	assert.Equal(t, 2, orderScheme.Len())
+	assert.Equal(t, "customer/order", orderScheme.String())
+
+	for _, id := range [][]string{
+		{"42"},
+		{"42", order, "extra"},
+		{"042", order},
+		{"+42", order},
+		{"x", order},
+		{"42", "123E4567-E89B-12D3-A456-426614174000"},
+		{"42", "not-a-uuid"},
+	} {
+		assert.ErrorIs(t, orderScheme.Validate(id), rtkv.ErrInvalidID, id)
+	}
+
+	id, err := orderScheme.Format(42, order)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"42", order}, id)
+
+	_, err = orderScheme.Format(42, 1.5)
+	require.ErrorIs(t, err, rtkv.ErrInvalidID)
+}
+
+func TestID(t *testing.T) {
+	ctx := context.Background()
+	store := newRTKV(t, newGoRedisClient(0)).With(rtkv.WithIDScheme(orderScheme))
+	order := orderID{Customer: 42, Order: "123e4567-e89b-12d3-a456-426614174000"}
+
+	_, err := store.Set(ctx, []byte("v"), time.Now(), orderIDs.MustParts(order)...)
+	require.NoError(t, err)
+
+	records, _, err := store.FetchPageRecords(ctx, nil, nil, 0, 1)
+	require.NoError(t, err)
+
+	for record, err := range records {
+		require.NoError(t, err)
+
+		parsed, err := orderIDs.Parse(record.ID)
+		require.NoError(t, err)
+		assert.Equal(t, order, parsed)
+	}
+
+	_, err = orderIDs.Parts(orderID{Customer: 42, Order: "x"})
+	require.ErrorIs(t, err, rtkv.ErrInvalidID)
+
+	_, err = store.Set(ctx, []byte("v"), time.Now(), "42")
+	require.ErrorIs(t, err, rtkv.ErrInvalidID)
+
+	_, err = store.Get(ctx, "42", order.Order, "x")
+	require.ErrorIs(t, err, rtkv.ErrInvalidID)
+
+	err = store.BulkSet(ctx, []rtkv.BulkSetRecord{{LastModified: time.Now(), ID: []string{"x", order.Order}}})
+	require.ErrorIs(t, err, rtkv.ErrInvalidID)
+
+	require.ErrorIs(t, store.Delete(ctx, "42"), rtkv.ErrInvalidID)
+}
+
+func TestRedisTKV_ReservedIDs(t *testing.T) {
+	ctx := context.Background()
+	store := newRTKV(t, newGoRedisClient(0)).With(rtkv.WithVersioning(), rtkv.WithChildIndex())
+
+	for _, id := range []string{"stream", "versions", "expiry", "frozen", "capabilities"} {
+		_, err := store.Set(ctx, []byte(id), time.Now(), id, "1")
+		require.NoError(t, err, "IDs named like internal keys should be valid")
+
+		value, err := store.Get(ctx, id, "1")
+		require.NoError(t, err)
+		assert.Equal(t, id, string(value))
+
+		require.NoError(t, store.Delete(ctx, id, "1"))
+	}
+
+	_, err := store.Set(ctx, []byte("v"), time.Now(), "lmIdx")
+	require.ErrorIs(t, err, rtkv.ErrInvalidID)
+
+	_, err = store.Set(ctx, []byte("v"), time.Now(), "\x00rtkv:versions")
+	require.ErrorIs(t, err, rtkv.ErrInvalidID)
+}
@@ -0,0 +1,101 @@
+// GNU AFFERO GENERAL PUBLIC LICENSE
+// Version 3, 19 November 2007
+//
+// Copyright (C) 2025 John Kleijn
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// For more details, see the full AGPL-3.0 license at:
+// https://www.gnu.org/licenses/agpl-3.0.html
+
+package rtkv
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// AddToIndex adds an entity whose value was written directly to
+// Redis, bypassing the store, to the lastModified index and the
+// secondary indexes enabled on the store, so it can be fetched like
+// any other entity. The value itself is neither read nor checked.
+func (r *RedisTKV) AddToIndex(ctx context.Context, id []string, lastModified time.Time) error {
+	defer r.observe(ctx, "addToIndex", time.Now())
+
+	if err := r.checkID(id); err != nil {
+		return err
+	}
+
+	if err := r.checkWritable(ctx); err != nil {
+		return err
+	}
+
+	_, err := r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		r.writerAdd(ctx, pipe)
+		r.indexAdd(ctx, pipe, float64(lastModified.UnixNano()), r.namespacedKey(id...), id)
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to add to index: %w", err)
+	}
+
+	return r.compactedRemove(ctx, false, r.namespacedKey(id...))
+}
+
+// RemoveFromIndex removes entities whose values were deleted
+// directly from Redis, bypassing the store, from all indexes in a
+// single transaction, so FetchPage stops returning them. The values
+// are left alone, and the reference policy is not applied.
+func (r *RedisTKV) RemoveFromIndex(ctx context.Context, ids ...[]string) error {
+	defer r.observe(ctx, "removeFromIndex", time.Now())
+
+	for _, id := range ids {
+		if err := r.checkID(id); err != nil {
+			return err
+		}
+	}
+
+	if err := r.checkWritable(ctx); err != nil {
+		return err
+	}
+
+	if len(ids) == 0 {
+		return nil
+	}
+
+	_, err := r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		r.writerAdd(ctx, pipe)
+
+		for _, id := range ids {
+			r.indexRemove(ctx, pipe, r.namespacedKey(id...), id)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to remove from index: %w", err)
+	}
+
+	keys := make([]string, len(ids))
+
+	for i, id := range ids {
+		keys[i] = r.namespacedKey(id...)
+	}
+
+	return r.compactedRemove(ctx, true, keys...)
+}
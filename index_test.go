@@ -0,0 +1,79 @@
+// GNU AFFERO GENERAL PUBLIC LICENSE
+// Version 3, 19 November 2007
+//
+// Copyright (C) 2025 John Kleijn
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// For more details, see the full AGPL-3.0 license at:
+// https://www.gnu.org/licenses/agpl-3.0.html
+
+package rtkv_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/johnknl/rtkv"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedisTKV_AddToIndex_RemoveFromIndex(t *testing.T) {
+	ctx := context.Background()
+	client := newGoRedisClient(0)
+	store := newRTKV(t, client).With(rtkv.WithChildIndex())
+	lastModified := time.Date(2025, 1, 10, 12, 0, 0, 0, time.UTC)
+	key := func(id ...string) string { return t.Name() + rtkv.DelimUnit + id[0] + rtkv.DelimUnit + id[1] }
+
+	_, err := store.Clear(ctx)
+	require.NoError(t, err)
+
+	// A legacy job writes values directly.
+	require.NoError(t, client.Set(ctx, key("jobs", "1"), "a", 0).Err())
+	require.NoError(t, client.Set(ctx, key("jobs", "2"), "b", 0).Err())
+
+	require.NoError(t, store.AddToIndex(ctx, []string{"jobs", "1"}, lastModified))
+	require.NoError(t, store.AddToIndex(ctx, []string{"jobs", "2"}, lastModified.Add(time.Minute)))
+
+	records, total, err := store.FetchPageRecords(ctx, nil, nil, 0, 10)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), total)
+
+	var ids [][]string
+
+	for record, err := range records {
+		require.NoError(t, err)
+
+		ids = append(ids, record.ID)
+	}
+
+	assert.Equal(t, [][]string{{"jobs", "1"}, {"jobs", "2"}}, ids)
+
+	children, err := store.GetChildren(ctx, "jobs")
+	require.NoError(t, err)
+	assert.Len(t, children, 2)
+
+	require.NoError(t, client.Del(ctx, key("jobs", "1"), key("jobs", "2")).Err())
+	require.NoError(t, store.RemoveFromIndex(ctx, []string{"jobs", "1"}, []string{"jobs", "2"}))
+
+	count, err := store.Count(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, count)
+
+	children, err = store.GetChildren(ctx, "jobs")
+	require.NoError(t, err)
+	assert.Empty(t, children)
+}
@@ -0,0 +1,262 @@
+// GNU AFFERO GENERAL PUBLIC LICENSE
+// Version 3, 19 November 2007
+//
+// Copyright (C) 2025 John Kleijn
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// For more details, see the full AGPL-3.0 license at:
+// https://www.gnu.org/licenses/agpl-3.0.html
+
+package rtkv
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"strconv"
+	"time"
+
+	"github.com/buger/jsonparser"
+)
+
+const (
+	// TimestampUnix is a timestamp layout for integer seconds
+	// since the Unix epoch.
+	TimestampUnix = "unix"
+
+	// TimestampUnixMilli is a timestamp layout for integer
+	// milliseconds since the Unix epoch.
+	TimestampUnixMilli = "unixmilli"
+)
+
+// ErrMissingField is returned when an input row lacks a mapped field.
+var ErrMissingField = errors.New("missing field")
+
+// IngestMapping describes how input rows are turned into records.
+type IngestMapping struct {
+	// IDFields are the names of the fields that make up
+	// the composite ID, in order.
+	IDFields []string
+
+	// TimestampField is the name of the field holding the
+	// lastModified time. If empty, the time of reading is used.
+	TimestampField string
+
+	// TimestampLayout is the time.Parse layout of the timestamp
+	// field, or TimestampUnix or TimestampUnixMilli. Defaults to
+	// time.RFC3339Nano.
+	TimestampLayout string
+
+	// PayloadField is the name of the field holding the value,
+	// NDJSON only. If empty, the whole line is used.
+	PayloadField string
+
+	// Payload assembles the value from the columns of a row, CSV
+	// only. If nil, the row is encoded as a JSON object of strings
+	// keyed by column name.
+	Payload func(row map[string]string) ([]byte, error)
+}
+
+// ReadNDJSON returns an iterator over records parsed from newline
+// delimited JSON, suitable for BulkSetSeq. Fields are top level
+// object keys. Empty lines are skipped.
+func ReadNDJSON(rd io.Reader, m IngestMapping) iter.Seq2[BulkSetRecord, error] {
+	return func(yield func(BulkSetRecord, error) bool) {
+		scanner := bufio.NewScanner(rd)
+		scanner.Buffer(nil, 64<<20) //nolint:mnd // allow values up to 64MB
+
+		for line := 1; scanner.Scan(); line++ {
+			data := bytes.TrimSpace(scanner.Bytes())
+			if len(data) == 0 {
+				continue
+			}
+
+			record, err := m.ndjsonRecord(data)
+			if err != nil {
+				err = fmt.Errorf("line %d: %w", line, err)
+			}
+
+			if !yield(record, err) || err != nil {
+				return
+			}
+		}
+
+		if err := scanner.Err(); err != nil {
+			yield(BulkSetRecord{}, fmt.Errorf("failed to read input: %w", err))
+		}
+	}
+}
+
+// ReadCSV returns an iterator over records parsed from CSV with a
+// header row, suitable for BulkSetSeq. Fields are column names.
+func ReadCSV(rd io.Reader, m IngestMapping) iter.Seq2[BulkSetRecord, error] {
+	return func(yield func(BulkSetRecord, error) bool) {
+		reader := csv.NewReader(rd)
+
+		header, err := reader.Read()
+		if err != nil {
+			yield(BulkSetRecord{}, fmt.Errorf("failed to read header: %w", err))
+
+			return
+		}
+
+		for line := 2; ; line++ {
+			columns, err := reader.Read()
+			if errors.Is(err, io.EOF) {
+				return
+			}
+
+			var record BulkSetRecord
+
+			if err == nil {
+				record, err = m.csvRecord(header, columns)
+			}
+
+			if err != nil {
+				err = fmt.Errorf("line %d: %w", line, err)
+			}
+
+			if !yield(record, err) || err != nil {
+				return
+			}
+		}
+	}
+}
+
+func (m IngestMapping) ndjsonRecord(data []byte) (BulkSetRecord, error) {
+	field := func(name string) (string, error) {
+		value, dataType, _, err := jsonparser.Get(data, name)
+		if errors.Is(err, jsonparser.KeyPathNotFoundError) {
+			return "", fmt.Errorf("%w %q", ErrMissingField, name)
+		} else if err != nil {
+			return "", fmt.Errorf("invalid JSON: %w", err)
+		}
+
+		if dataType == jsonparser.String {
+			return jsonparser.ParseString(value) //nolint:wrapcheck // parse errors are descriptive
+		}
+
+		return string(value), nil
+	}
+
+	record, err := m.record(field)
+	if err != nil {
+		return record, err
+	}
+
+	record.Data = bytes.Clone(data)
+
+	if m.PayloadField != "" {
+		value, _, _, err := jsonparser.Get(data, m.PayloadField)
+		if err != nil {
+			return record, fmt.Errorf("%w %q", ErrMissingField, m.PayloadField)
+		}
+
+		record.Data = bytes.Clone(value)
+	}
+
+	return record, nil
+}
+
+func (m IngestMapping) csvRecord(header, columns []string) (BulkSetRecord, error) {
+	row := make(map[string]string, len(header))
+
+	for i, name := range header {
+		if i < len(columns) {
+			row[name] = columns[i]
+		}
+	}
+
+	record, err := m.record(func(name string) (string, error) {
+		value, ok := row[name]
+		if !ok {
+			return "", fmt.Errorf("%w %q", ErrMissingField, name)
+		}
+
+		return value, nil
+	})
+	if err != nil {
+		return record, err
+	}
+
+	if m.Payload != nil {
+		record.Data, err = m.Payload(row)
+	} else {
+		record.Data, err = json.Marshal(row)
+	}
+
+	if err != nil {
+		return record, fmt.Errorf("failed to assemble payload: %w", err)
+	}
+
+	return record, nil
+}
+
+// record builds a record without data from the mapped fields.
+func (m IngestMapping) record(field func(name string) (string, error)) (BulkSetRecord, error) {
+	record := BulkSetRecord{
+		ID:           make([]string, len(m.IDFields)),
+		LastModified: time.Now(),
+	}
+
+	for i, name := range m.IDFields {
+		value, err := field(name)
+		if err != nil {
+			return record, err
+		}
+
+		record.ID[i] = value
+	}
+
+	if m.TimestampField == "" {
+		return record, nil
+	}
+
+	value, err := field(m.TimestampField)
+	if err != nil {
+		return record, err
+	}
+
+	if record.LastModified, err = parseTimestamp(value, m.TimestampLayout); err != nil {
+		return record, fmt.Errorf("invalid timestamp %q: %w", value, err)
+	}
+
+	return record, nil
+}
+
+func parseTimestamp(value, layout string) (time.Time, error) {
+	switch layout {
+	case TimestampUnix, TimestampUnixMilli:
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return time.Time{}, err //nolint:wrapcheck // wrapped by caller
+		}
+
+		if layout == TimestampUnix {
+			return time.Unix(n, 0), nil
+		}
+
+		return time.UnixMilli(n), nil
+	case "":
+		layout = time.RFC3339Nano
+	}
+
+	return time.Parse(layout, value) //nolint:wrapcheck // wrapped by caller
+}
@@ -0,0 +1,136 @@
+// GNU AFFERO GENERAL PUBLIC LICENSE
+// Version 3, 19 November 2007
+//
+// Copyright (C) 2025 John Kleijn
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// For more details, see the full AGPL-3.0 license at:
+// https://www.gnu.org/licenses/agpl-3.0.html
+
+package rtkv_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/johnknl/rtkv"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadNDJSON(t *testing.T) {
+	input := `{"tenant": "t1", "id": 1, "ts": 1700000000000, "body": {"name": "a"}}
+
+{"tenant": "t2", "id": 2, "ts": 1700000001000, "body": {"name": "b"}}
+`
+	mapping := rtkv.IngestMapping{
+		IDFields:        []string{"tenant", "id"},
+		TimestampField:  "ts",
+		TimestampLayout: rtkv.TimestampUnixMilli,
+		PayloadField:    "body",
+	}
+
+	var records []rtkv.BulkSetRecord
+
+	for record, err := range rtkv.ReadNDJSON(strings.NewReader(input), mapping) {
+		require.NoError(t, err)
+		records = append(records, record)
+	}
+
+	require.Len(t, records, 2)
+	assert.Equal(t, []string{"t1", "1"}, records[0].ID)
+	assert.Equal(t, []string{"t2", "2"}, records[1].ID)
+	assert.Equal(t, time.UnixMilli(1700000001000), records[1].LastModified)
+	assert.Equal(t, `{"name": "b"}`, string(records[1].Data))
+
+	t.Run("MissingField", func(t *testing.T) {
+		for _, err := range rtkv.ReadNDJSON(strings.NewReader(`{"id": 1}`), mapping) {
+			require.ErrorIs(t, err, rtkv.ErrMissingField)
+			assert.Contains(t, err.Error(), "line 1")
+		}
+	})
+}
+
+func TestReadCSV(t *testing.T) {
+	input := "id,modified,name\na,2025-01-01T00:00:00Z,Alice\nb,2025-01-02T00:00:00Z,Bob\n"
+	mapping := rtkv.IngestMapping{
+		IDFields:       []string{"id"},
+		TimestampField: "modified",
+	}
+
+	var records []rtkv.BulkSetRecord
+
+	for record, err := range rtkv.ReadCSV(strings.NewReader(input), mapping) {
+		require.NoError(t, err)
+		records = append(records, record)
+	}
+
+	require.Len(t, records, 2)
+	assert.Equal(t, []string{"b"}, records[1].ID)
+	assert.Equal(t, time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC), records[1].LastModified)
+	assert.JSONEq(t, `{"id": "b", "modified": "2025-01-02T00:00:00Z", "name": "Bob"}`, string(records[1].Data))
+
+	t.Run("Payload", func(t *testing.T) {
+		mapping.Payload = func(row map[string]string) ([]byte, error) {
+			return []byte(row["name"]), nil
+		}
+
+		for record, err := range rtkv.ReadCSV(strings.NewReader(input), mapping) {
+			require.NoError(t, err)
+			assert.Contains(t, []string{"Alice", "Bob"}, string(record.Data))
+		}
+	})
+
+	t.Run("InvalidTimestamp", func(t *testing.T) {
+		for _, err := range rtkv.ReadCSV(strings.NewReader("id,modified\na,yesterday\n"), mapping) {
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "invalid timestamp")
+		}
+	})
+}
+
+func TestRedisTKV_BulkSetSeq(t *testing.T) {
+	ctx := context.Background()
+
+	redisClient := newGoRedisClient(0)
+
+	t.Cleanup(func() {
+		redisClient.FlushDB(ctx).Err()
+	})
+
+	store := newRTKV(t, redisClient)
+	input := "id\na\nb\nc\n"
+
+	written, err := store.BulkSetSeq(ctx, rtkv.ReadCSV(strings.NewReader(input), rtkv.IngestMapping{
+		IDFields: []string{"id"},
+	}), 2)
+
+	require.NoError(t, err)
+	assert.Equal(t, 3, written)
+
+	data, err := store.Get(ctx, "c")
+
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"id": "c"}`, string(data))
+
+	written, err = store.BulkSetSeq(ctx, rtkv.ReadCSV(strings.NewReader("id\na\nb,c,d\n"), rtkv.IngestMapping{
+		IDFields: []string{"id"},
+	}), 0)
+
+	require.Error(t, err)
+	assert.Zero(t, written)
+}
@@ -0,0 +1,371 @@
+// GNU AFFERO GENERAL PUBLIC LICENSE
+// Version 3, 19 November 2007
+//
+// Copyright (C) 2025 John Kleijn
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// For more details, see the full AGPL-3.0 license at:
+// https://www.gnu.org/licenses/agpl-3.0.html
+
+// Package bridge serves the connections of a go-redis client in
+// memory, executing their commands with another Redis client, so
+// stores can run on clients other than go-redis v9.
+//
+// go-redis derives the deadline of every command from its context,
+// and sets it on the connection the command is written to. The
+// deadline is passed on to the other client with the command, which
+// is canceled when go-redis gives up on the connection. Other values
+// of the context, such as trace spans, don't cross the bridge.
+package bridge
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/redis/go-redis/v9/maintnotifications"
+)
+
+// writeTimeout is the write timeout of bridged clients. Writes to
+// in-memory connections don't block for long, so it only serves to
+// have go-redis set the deadline of the context of every command.
+const writeTimeout = 24 * time.Hour
+
+// Backend is the client a bridged client executes its commands with.
+type Backend interface {
+	// Conn returns the connection to execute the commands
+	// of a new connection of the bridged client with.
+	Conn(ctx context.Context) (Conn, error)
+}
+
+// Conn executes the commands of a connection of a bridged client.
+type Conn interface {
+	// Do executes commands in order and writes their replies to w.
+	// Errors other than those returned by Redis close the connection,
+	// so go-redis can retry on another.
+	Do(ctx context.Context, w *Writer, cmds [][]string) error
+
+	// Close releases the connection.
+	Close()
+}
+
+// Options configures a bridged client.
+type Options struct {
+	// Name names the backend in errors.
+	Name string
+
+	// Protocol is the RESP version the backend replies in.
+	Protocol int
+
+	// PoolSize is the number of connections of the bridged client.
+	// Defaults to that of go-redis.
+	PoolSize int
+
+	// Unsupported lists the commands to reject, in addition to
+	// those that need a connection to Redis of their own.
+	Unsupported []string
+}
+
+// unsupported are the commands that need a connection to
+// Redis of their own, which bridged connections are not.
+var unsupported = []string{ //nolint:gochecknoglobals // constant list
+	"MONITOR", "SUBSCRIBE", "PSUBSCRIBE", "SSUBSCRIBE", "CLIENT TRACKING", "CLIENT REPLY",
+}
+
+// NewClient returns a go-redis client whose commands
+// are executed by backend.
+func NewClient(backend Backend, opts Options) *redis.Client {
+	return redis.NewClient(&redis.Options{
+		Addr:     opts.Name,
+		Protocol: opts.Protocol,
+		PoolSize: opts.PoolSize,
+		Dialer: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			conn, err := backend.Conn(ctx)
+			if err != nil {
+				return nil, err //nolint:wrapcheck // wrapped by backends
+			}
+
+			local, remote := net.Pipe()
+			client := newClientConn(local)
+
+			go (&server{
+				conn:   conn,
+				client: client,
+				opts:   opts,
+				rd:     bufio.NewReader(remote),
+				wr:     &Writer{wr: bufio.NewWriter(remote), resp3: opts.Protocol == 3}, //nolint:mnd // RESP3
+			}).serve(remote)
+
+			return client, nil
+		},
+		WriteTimeout:             writeTimeout,
+		ContextTimeoutEnabled:    true,
+		DisableIdentity:          true,
+		MaintNotificationsConfig: &maintnotifications.Config{Mode: maintnotifications.ModeDisabled},
+	})
+}
+
+// clientConn is the end of an in-memory connection go-redis uses.
+// It keeps the deadline go-redis set for the commands written last,
+// and cancels the commands in progress when go-redis closes it.
+type clientConn struct {
+	net.Conn
+
+	ctx      context.Context //nolint:containedctx // lives as long as the connection
+	cancel   context.CancelFunc
+	deadline atomic.Pointer[time.Time]
+}
+
+func newClientConn(conn net.Conn) *clientConn {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &clientConn{Conn: conn, ctx: ctx, cancel: cancel}
+}
+
+func (c *clientConn) SetDeadline(t time.Time) error {
+	c.deadline.Store(&t)
+
+	return c.Conn.SetDeadline(t) //nolint:wrapcheck // passed through
+}
+
+func (c *clientConn) SetWriteDeadline(t time.Time) error {
+	c.deadline.Store(&t)
+
+	return c.Conn.SetWriteDeadline(t) //nolint:wrapcheck // passed through
+}
+
+func (c *clientConn) Close() error {
+	c.cancel()
+
+	return c.Conn.Close() //nolint:wrapcheck // passed through
+}
+
+// context returns the context to execute the commands written
+// last with, which ends at the deadline go-redis set for them.
+func (c *clientConn) context() (context.Context, context.CancelFunc) {
+	if deadline := c.deadline.Load(); deadline != nil && !deadline.IsZero() {
+		return context.WithDeadline(c.ctx, *deadline)
+	}
+
+	return context.WithCancel(c.ctx)
+}
+
+// server serves an in-memory connection of the bridged client.
+type server struct {
+	conn   Conn
+	client *clientConn
+	opts   Options
+	rd     *bufio.Reader
+	wr     *Writer
+}
+
+func (s *server) serve(nc net.Conn) {
+	defer nc.Close()
+	defer s.conn.Close()
+
+	for {
+		batch, err := s.readBatch()
+		if err != nil {
+			return
+		}
+
+		if err = s.do(batch); err != nil {
+			return
+		}
+
+		if err = s.wr.wr.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+// readBatch reads the commands go-redis sent in one go, and at least
+// one, so pipelines are forwarded as pipelines. Transactions are read
+// up to their EXEC, so they are forwarded at once.
+func (s *server) readBatch() ([][]string, error) {
+	var (
+		batch [][]string
+		multi bool
+	)
+
+	for len(batch) == 0 || multi || s.rd.Buffered() > 0 {
+		args, err := readCommand(s.rd)
+		if err != nil {
+			return nil, err
+		}
+
+		switch strings.ToUpper(args[0]) {
+		case "MULTI":
+			multi = true
+		case "EXEC", "DISCARD":
+			multi = false
+		}
+
+		batch = append(batch, args)
+	}
+
+	return batch, nil
+}
+
+// do executes a batch of commands. Commands that set up the
+// connection are answered here, and the others are forwarded
+// to the backend in order.
+func (s *server) do(batch [][]string) error {
+	ctx, cancel := s.client.context()
+	defer cancel()
+
+	var pending [][]string
+
+	for _, args := range batch {
+		name := Name(args)
+
+		var reply func()
+
+		switch {
+		case name == "HELLO":
+			reply = s.hello
+		case name == "AUTH", name == "SELECT", name == "CLIENT SETNAME", name == "CLIENT SETINFO":
+			// The backend authenticates and selects the database itself.
+			reply = func() { s.wr.Status("OK") }
+		case name == "QUIT":
+			return io.EOF
+		case s.unsupported(name):
+			reply = func() { s.wr.Error(fmt.Sprintf("ERR %s is not supported by %s", name, s.opts.Name)) }
+		default:
+			pending = append(pending, args)
+
+			continue
+		}
+
+		if err := s.flush(ctx, pending); err != nil {
+			return err
+		}
+
+		pending = nil
+
+		reply()
+	}
+
+	return s.flush(ctx, pending)
+}
+
+func (s *server) flush(ctx context.Context, pending [][]string) error {
+	if len(pending) == 0 {
+		return nil
+	}
+
+	return s.conn.Do(ctx, s.wr, pending) //nolint:wrapcheck // only ends the connection
+}
+
+// hello answers HELLO, which go-redis sends to negotiate the
+// protocol, with the protocol of the backend.
+func (s *server) hello() {
+	if !s.wr.resp3 {
+		// Makes go-redis fall back to RESP2.
+		s.wr.Error("ERR unknown command 'HELLO'")
+
+		return
+	}
+
+	s.wr.Map(2) //nolint:mnd // server and proto
+	s.wr.String("server")
+	s.wr.String("redis")
+	s.wr.String("proto")
+	s.wr.Int(3) //nolint:mnd // RESP3
+}
+
+func (s *server) unsupported(name string) bool {
+	for _, list := range [][]string{unsupported, s.opts.Unsupported} {
+		for _, cmd := range list {
+			if cmd == name {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// Name returns the upper case name of a command, including
+// the subcommand for CLIENT.
+func Name(args []string) string {
+	name := strings.ToUpper(args[0])
+	if name == "CLIENT" && len(args) > 1 {
+		name += " " + strings.ToUpper(args[1])
+	}
+
+	return name
+}
+
+// readCommand reads a command, which go-redis sends as an
+// array of bulk strings.
+func readCommand(rd *bufio.Reader) ([]string, error) {
+	n, err := readLength(rd, '*')
+	if err != nil {
+		return nil, err
+	}
+
+	args := make([]string, n)
+
+	for i := range args {
+		size, err := readLength(rd, '$')
+		if err != nil {
+			return nil, err
+		}
+
+		buf := make([]byte, size+2) //nolint:mnd // CRLF
+		if _, err = io.ReadFull(rd, buf); err != nil {
+			return nil, err //nolint:wrapcheck // only ends the connection
+		}
+
+		args[i] = string(buf[:size])
+	}
+
+	if n == 0 {
+		return nil, errEmptyCommand
+	}
+
+	return args, nil
+}
+
+var errEmptyCommand = errors.New("empty command")
+
+func readLength(rd *bufio.Reader, prefix byte) (int, error) {
+	line, err := rd.ReadString('\n')
+	if err != nil {
+		return 0, err //nolint:wrapcheck // only ends the connection
+	}
+
+	if len(line) < 3 || line[0] != prefix { //nolint:mnd // prefix and CRLF
+		return 0, fmt.Errorf("%w: %q", errProtocol, line)
+	}
+
+	n, err := strconv.Atoi(line[1 : len(line)-2])
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("%w: %q", errProtocol, line)
+	}
+
+	return n, nil
+}
+
+var errProtocol = errors.New("protocol error")
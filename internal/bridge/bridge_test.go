@@ -0,0 +1,114 @@
+// GNU AFFERO GENERAL PUBLIC LICENSE
+// Version 3, 19 November 2007
+//
+// Copyright (C) 2025 John Kleijn
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// For more details, see the full AGPL-3.0 license at:
+// https://www.gnu.org/licenses/agpl-3.0.html
+
+package bridge_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/johnknl/rtkv/internal/bridge"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// backend answers GET with the key, and blocks BLPOP
+// until its context ends.
+type backend struct {
+	deadlines chan time.Time
+	done      chan struct{}
+}
+
+func (b *backend) Conn(context.Context) (bridge.Conn, error) {
+	return b, nil
+}
+
+func (b *backend) Do(ctx context.Context, w *bridge.Writer, cmds [][]string) error {
+	for _, args := range cmds {
+		switch bridge.Name(args) {
+		case "GET":
+			w.String(args[1])
+		case "BLPOP":
+			deadline, _ := ctx.Deadline()
+			b.deadlines <- deadline
+
+			<-ctx.Done()
+			close(b.done)
+
+			return ctx.Err() //nolint:wrapcheck // test backend
+		default:
+			w.Error("ERR unknown command")
+		}
+	}
+
+	return nil
+}
+
+func (b *backend) Close() {}
+
+func TestNewClient(t *testing.T) {
+	for _, protocol := range []int{2, 3} {
+		b := &backend{}
+		client := bridge.NewClient(b, bridge.Options{Name: "test", Protocol: protocol, Unsupported: []string{"WAIT"}})
+		ctx := context.Background()
+
+		value, err := client.Get(ctx, "a").Result()
+		require.NoError(t, err)
+		assert.Equal(t, "a", value)
+
+		cmds, err := client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.Get(ctx, "b")
+			pipe.Do(ctx, "wait", 1, 0)
+			pipe.Get(ctx, "c")
+
+			return nil
+		})
+		require.Error(t, err)
+		require.Len(t, cmds, 3)
+		assert.Equal(t, "b", cmds[0].(*redis.StringCmd).Val())
+		require.ErrorContains(t, cmds[1].Err(), "WAIT is not supported by test")
+		assert.Equal(t, "c", cmds[2].(*redis.StringCmd).Val())
+
+		require.NoError(t, client.Close())
+	}
+}
+
+func TestNewClient_Context(t *testing.T) {
+	b := &backend{deadlines: make(chan time.Time, 1), done: make(chan struct{})}
+	client := bridge.NewClient(b, bridge.Options{Name: "test", Protocol: 3})
+	t.Cleanup(func() { _ = client.Close() })
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	require.Error(t, client.BLPop(ctx, 0, "list").Err())
+
+	deadline, _ := ctx.Deadline()
+	assert.WithinDuration(t, deadline, <-b.deadlines, 10*time.Millisecond, "The deadline should be passed on")
+
+	select {
+	case <-b.done:
+	case <-time.After(time.Second):
+		t.Fatal("The command should be canceled")
+	}
+}
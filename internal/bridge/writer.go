@@ -0,0 +1,151 @@
+// GNU AFFERO GENERAL PUBLIC LICENSE
+// Version 3, 19 November 2007
+//
+// Copyright (C) 2025 John Kleijn
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// For more details, see the full AGPL-3.0 license at:
+// https://www.gnu.org/licenses/agpl-3.0.html
+
+package bridge
+
+import (
+	"bufio"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Writer writes replies in the protocol of the bridged client.
+// Types RESP2 lacks are written as their RESP2 equivalents.
+type Writer struct {
+	wr    *bufio.Writer
+	resp3 bool
+}
+
+// Nil writes a nil reply.
+func (w *Writer) Nil() {
+	if w.resp3 {
+		_, _ = w.wr.WriteString("_\r\n")
+	} else {
+		_, _ = w.wr.WriteString("$-1\r\n")
+	}
+}
+
+// Status writes a status reply, such as OK.
+func (w *Writer) Status(s string) {
+	_, _ = fmt.Fprintf(w.wr, "+%s\r\n", s)
+}
+
+// Error writes an error reply. msg starts with the error code,
+// as returned by Redis.
+func (w *Writer) Error(msg string) {
+	_, _ = fmt.Fprintf(w.wr, "-%s\r\n", strings.ReplaceAll(msg, "\r\n", " "))
+}
+
+// Int writes an integer reply.
+func (w *Writer) Int(n int64) {
+	_, _ = fmt.Fprintf(w.wr, ":%d\r\n", n)
+}
+
+// String writes a bulk string reply.
+func (w *Writer) String(s string) {
+	_, _ = fmt.Fprintf(w.wr, "$%d\r\n%s\r\n", len(s), s)
+}
+
+// Float writes a double reply.
+func (w *Writer) Float(f float64) {
+	var s string
+
+	switch {
+	case math.IsInf(f, 1):
+		s = "inf"
+	case math.IsInf(f, -1):
+		s = "-inf"
+	default:
+		s = strconv.FormatFloat(f, 'g', -1, 64)
+	}
+
+	if w.resp3 {
+		_, _ = fmt.Fprintf(w.wr, ",%s\r\n", s)
+	} else {
+		w.String(s)
+	}
+}
+
+// Bool writes a boolean reply.
+func (w *Writer) Bool(b bool) {
+	switch {
+	case !w.resp3 && b:
+		w.Int(1)
+	case !w.resp3:
+		w.Int(0)
+	case b:
+		_, _ = w.wr.WriteString("#t\r\n")
+	default:
+		_, _ = w.wr.WriteString("#f\r\n")
+	}
+}
+
+// Array starts an array reply of n elements, which
+// are to be written next.
+func (w *Writer) Array(n int) {
+	_, _ = fmt.Fprintf(w.wr, "*%d\r\n", n)
+}
+
+// Map starts a map reply of n pairs, whose keys and values are to
+// be written next, alternately. RESP2 gets them as an array.
+func (w *Writer) Map(n int) {
+	if w.resp3 {
+		_, _ = fmt.Fprintf(w.wr, "%%%d\r\n", n)
+	} else {
+		w.Array(2 * n) //nolint:mnd // keys and values
+	}
+}
+
+// Value writes a reply decoded by go-redis v8, or any other client
+// decoding replies to the same Go types.
+func (w *Writer) Value(v any) {
+	switch v := v.(type) {
+	case nil:
+		w.Nil()
+	case error:
+		w.Error(v.Error())
+	case int64:
+		w.Int(v)
+	case string:
+		w.String(v)
+	case float64:
+		w.Float(v)
+	case bool:
+		w.Bool(v)
+	case []any:
+		w.Array(len(v))
+
+		for _, e := range v {
+			w.Value(e)
+		}
+	case map[string]any:
+		w.Map(len(v))
+
+		for k, e := range v {
+			w.String(k)
+			w.Value(e)
+		}
+	default:
+		w.Error(fmt.Sprintf("ERR unexpected reply type %T", v))
+	}
+}
@@ -0,0 +1,250 @@
+// GNU AFFERO GENERAL PUBLIC LICENSE
+// Version 3, 19 November 2007
+//
+// Copyright (C) 2025 John Kleijn
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// For more details, see the full AGPL-3.0 license at:
+// https://www.gnu.org/licenses/agpl-3.0.html
+
+package rtkv
+
+import (
+	"errors"
+	"fmt"
+	"iter"
+	"time"
+)
+
+// IterErrorPolicy determines how pagination iterators treat
+// individual values that can't be returned, such as values
+// deleted after their index entry was read.
+type IterErrorPolicy int
+
+const (
+	// IterErrorsIgnore silently skips bad values. This is the default.
+	IterErrorsIgnore IterErrorPolicy = iota
+
+	// IterErrorsFailFast yields an error for the first bad value
+	// and ends the iteration.
+	IterErrorsFailFast
+
+	// IterErrorsSkip skips bad values and, after the last value,
+	// yields a *SkippedValuesError holding the number skipped.
+	IterErrorsSkip
+
+	// IterErrorsCollect is like IterErrorsSkip, but the
+	// *SkippedValuesError also holds the error of every bad value.
+	IterErrorsCollect
+)
+
+var (
+	// ErrValueMissing is yielded for entities in the index
+	// whose value no longer exists.
+	ErrValueMissing = errors.New("value missing")
+
+	// ErrValuesSkipped is wrapped by *SkippedValuesError.
+	ErrValuesSkipped = errors.New("values skipped")
+)
+
+// SkippedValuesError is yielded at the end of an iteration that
+// skipped values under IterErrorsSkip or IterErrorsCollect.
+// Paginate yields a single one covering all pages.
+type SkippedValuesError struct {
+	Skipped int
+
+	// Errs holds the error of every skipped value
+	// under IterErrorsCollect.
+	Errs []error
+}
+
+func (e *SkippedValuesError) Error() string {
+	return fmt.Sprintf("%d values skipped", e.Skipped)
+}
+
+func (e *SkippedValuesError) Unwrap() error {
+	return ErrValuesSkipped
+}
+
+// merge adds the skipped values of other to e.
+func (e *SkippedValuesError) merge(other *SkippedValuesError) {
+	e.Skipped += other.Skipped
+	e.Errs = append(e.Errs, other.Errs...)
+}
+
+// WithIterErrorPolicy sets how pagination iterators, such as those
+// returned by FetchPage, FetchPageRecords and Paginate, treat values
+// that can't be returned, so long exports don't have to die on a
+// single bad record unless they should.
+func WithIterErrorPolicy(policy IterErrorPolicy) Option {
+	return func(r *RedisTKV) {
+		r.iterPolicy = policy
+	}
+}
+
+// badValues applies the iterator error policy to the bad
+// values of a single iteration.
+type badValues struct {
+	policy  IterErrorPolicy
+	skipped SkippedValuesError
+}
+
+// add handles a bad value. It returns whether the iteration
+// may continue and the error to yield right away, if any.
+func (b *badValues) add(err error) (bool, error) {
+	switch b.policy {
+	case IterErrorsFailFast:
+		return false, err
+	case IterErrorsSkip:
+		b.skipped.Skipped++
+	case IterErrorsCollect:
+		b.skipped.Skipped++
+		b.skipped.Errs = append(b.skipped.Errs, err)
+	case IterErrorsIgnore:
+	}
+
+	return true, nil
+}
+
+// err returns the error to yield after the last value, if any.
+func (b *badValues) err() error {
+	if b.skipped.Skipped == 0 {
+		return nil
+	}
+
+	return &b.skipped
+}
+
+// missingValue returns the error of a missing value at position
+// i of a page. keys may be nil if the keys aren't known.
+func (r *RedisTKV) missingValue(keys []string, i int) error {
+	if i < len(keys) {
+		return fmt.Errorf("%w: %v", ErrValueMissing, r.idFromKey(keys[i]))
+	}
+
+	return ErrValueMissing
+}
+
+// pageValue returns the decoded value of the i-th entity of a
+// page, or an error if it is missing or can't be decoded.
+func (r *RedisTKV) pageValue(keys []string, i int, rawValue any) ([]byte, error) {
+	s, ok := rawValue.(string)
+	if !ok {
+		return nil, r.missingValue(keys, i)
+	}
+
+	data, err := r.decodeValue(s2b(s))
+	if err != nil && i < len(keys) {
+		return nil, fmt.Errorf("%w: %v", err, r.idFromKey(keys[i]))
+	}
+
+	return data, err
+}
+
+// yieldValues returns an iterator over the values of a page,
+// applying the iterator error policy to missing values and
+// values that can't be decoded.
+func (r *RedisTKV) yieldValues(keys []string, rawValues []any) iter.Seq2[[]byte, error] {
+	return func(yield func([]byte, error) bool) {
+		bad := badValues{policy: r.iterPolicy}
+
+		for i, rawValue := range rawValues {
+			data, err := r.pageValue(keys, i, rawValue)
+			if err != nil {
+				more, err := bad.add(err)
+				if err != nil && !yield(nil, err) || !more {
+					return
+				}
+
+				continue
+			}
+
+			if !yield(data, nil) {
+				return
+			}
+		}
+
+		if err := bad.err(); err != nil {
+			yield(nil, err)
+		}
+	}
+}
+
+// yieldRecords returns an iterator over the records of a page,
+// applying the iterator error policy to missing values and
+// values that can't be decoded.
+func (r *RedisTKV) yieldRecords(keys []string, scores []float64, rawValues []any) iter.Seq2[Record, error] {
+	return func(yield func(Record, error) bool) {
+		bad := badValues{policy: r.iterPolicy}
+
+		for i, rawValue := range rawValues {
+			data, err := r.pageValue(keys, i, rawValue)
+			if err != nil {
+				more, err := bad.add(err)
+				if err != nil && !yield(Record{}, err) || !more {
+					return
+				}
+
+				continue
+			}
+
+			record := Record{
+				LastModified: time.Unix(0, int64(scores[i])),
+				ID:           r.idFromKey(keys[i]),
+				Data:         data,
+			}
+
+			if !yield(record, nil) {
+				return
+			}
+		}
+
+		if err := bad.err(); err != nil {
+			yield(Record{}, err)
+		}
+	}
+}
+
+// pageErrors merges the *SkippedValuesError of every page
+// yielded by Paginate, and notes fail fast errors.
+type pageErrors struct {
+	yield   func([]byte, error) bool
+	skipped SkippedValuesError
+	failed  bool
+}
+
+func (p *pageErrors) yieldValue(value []byte, err error) bool {
+	var skipped *SkippedValuesError
+	if errors.As(err, &skipped) {
+		p.skipped.merge(skipped)
+
+		return true
+	}
+
+	// Other errors, such as values missing or failing to decode
+	// under IterErrorsFailFast, end the iteration of their page.
+	if err != nil {
+		p.failed = true
+	}
+
+	return p.yield(value, err)
+}
+
+// yieldSkipped yields the merged *SkippedValuesError, if any.
+func (p *pageErrors) yieldSkipped() {
+	if p.skipped.Skipped > 0 {
+		p.yield(nil, &p.skipped)
+	}
+}
@@ -0,0 +1,164 @@
+// GNU AFFERO GENERAL PUBLIC LICENSE
+// Version 3, 19 November 2007
+//
+// Copyright (C) 2025 John Kleijn
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// For more details, see the full AGPL-3.0 license at:
+// https://www.gnu.org/licenses/agpl-3.0.html
+
+package rtkv_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/johnknl/rtkv"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedisTKV_IterErrorPolicy(t *testing.T) {
+	ctx := context.Background()
+	client := newGoRedisClient(0)
+	store := newRTKV(t, client)
+
+	_, err := store.Clear(ctx)
+	require.NoError(t, err)
+
+	base := time.Now()
+
+	for i, id := range []string{"a", "b", "c", "d", "e"} {
+		_, err := store.Set(ctx, []byte(id), base.Add(time.Duration(i)*time.Second), id)
+		require.NoError(t, err)
+	}
+
+	// Leave index entries without values behind.
+	require.NoError(t, client.Del(ctx, t.Name()+rtkv.DelimUnit+"b", t.Name()+rtkv.DelimUnit+"d").Err())
+
+	collect := func(store *rtkv.RedisTKV) ([]string, []error) {
+		it, err := rtkv.Paginate(ctx, store.FetchPage, nil, nil, 0, 2)
+		require.NoError(t, err)
+
+		var (
+			values []string
+			errs   []error
+		)
+
+		for value, err := range it {
+			if err != nil {
+				errs = append(errs, err)
+
+				continue
+			}
+
+			values = append(values, string(value))
+		}
+
+		return values, errs
+	}
+
+	values, errs := collect(store)
+	assert.Equal(t, []string{"a", "c", "e"}, values)
+	assert.Empty(t, errs)
+
+	values, errs = collect(store.With(rtkv.WithIterErrorPolicy(rtkv.IterErrorsFailFast)))
+	assert.Equal(t, []string{"a"}, values)
+	require.Len(t, errs, 1)
+	require.ErrorIs(t, errs[0], rtkv.ErrValueMissing)
+	assert.Contains(t, errs[0].Error(), "b")
+
+	values, errs = collect(store.With(rtkv.WithIterErrorPolicy(rtkv.IterErrorsSkip)))
+	assert.Equal(t, []string{"a", "c", "e"}, values)
+	require.Len(t, errs, 1)
+	require.ErrorIs(t, errs[0], rtkv.ErrValuesSkipped)
+
+	var skipped *rtkv.SkippedValuesError
+	require.ErrorAs(t, errs[0], &skipped)
+	assert.Equal(t, 2, skipped.Skipped)
+	assert.Empty(t, skipped.Errs)
+
+	values, errs = collect(store.With(rtkv.WithIterErrorPolicy(rtkv.IterErrorsCollect)))
+	assert.Equal(t, []string{"a", "c", "e"}, values)
+	require.Len(t, errs, 1)
+	require.True(t, errors.As(errs[0], &skipped))
+	assert.Equal(t, 2, skipped.Skipped)
+	require.Len(t, skipped.Errs, 2)
+	assert.ErrorIs(t, skipped.Errs[1], rtkv.ErrValueMissing)
+
+	records, _, err := store.With(rtkv.WithIterErrorPolicy(rtkv.IterErrorsSkip)).FetchPageRecords(ctx, nil, nil, 0, 10)
+	require.NoError(t, err)
+
+	var ids []string
+
+	for record, err := range records {
+		if err != nil {
+			require.ErrorAs(t, err, &skipped)
+			assert.Equal(t, 2, skipped.Skipped)
+
+			continue
+		}
+
+		ids = append(ids, record.ID[0])
+	}
+
+	assert.Equal(t, []string{"a", "c", "e"}, ids)
+}
+
+func TestRedisTKV_IterErrorPolicy_FailFastDecode(t *testing.T) {
+	ctx := context.Background()
+	client := newGoRedisClient(0)
+	store := newRTKV(t, client).With(
+		rtkv.WithValueCompression(rtkv.ValueZstd, 1<<20),
+		rtkv.WithIterErrorPolicy(rtkv.IterErrorsFailFast),
+	)
+
+	_, err := store.Clear(ctx)
+	require.NoError(t, err)
+
+	base := time.Now()
+
+	for i, id := range []string{"a", "b", "c", "d", "e"} {
+		_, err := store.Set(ctx, []byte(id), base.Add(time.Duration(i)*time.Second), id)
+		require.NoError(t, err)
+	}
+
+	// A value compressed with an unknown algorithm.
+	require.NoError(t, client.Set(ctx, t.Name()+rtkv.DelimUnit+"b", "\x00rtkv:z?", 0).Err())
+
+	it, err := rtkv.Paginate(ctx, store.FetchPage, nil, nil, 0, 2)
+	require.NoError(t, err)
+
+	var (
+		values []string
+		errs   []error
+	)
+
+	for value, err := range it {
+		if err != nil {
+			errs = append(errs, err)
+
+			continue
+		}
+
+		values = append(values, string(value))
+	}
+
+	assert.Equal(t, []string{"a"}, values, "Decode failures should end the iteration")
+	require.Len(t, errs, 1)
+	require.ErrorIs(t, errs[0], rtkv.ErrUnknownCompression)
+}
@@ -0,0 +1,140 @@
+// GNU AFFERO GENERAL PUBLIC LICENSE
+// Version 3, 19 November 2007
+//
+// Copyright (C) 2025 John Kleijn
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// For more details, see the full AGPL-3.0 license at:
+// https://www.gnu.org/licenses/agpl-3.0.html
+
+package rtkv
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+const defaultJanitorInterval = time.Minute
+
+// JanitorTask is a maintenance task run periodically by a Janitor.
+type JanitorTask func(ctx context.Context, r *RedisTKV) error
+
+// Janitor periodically runs maintenance tasks against a store
+// in the background. Task errors are logged through the store's
+// logger and don't stop the janitor.
+type Janitor struct {
+	store    *RedisTKV
+	interval time.Duration
+	tasks    []JanitorTask
+	cancel   context.CancelFunc
+	done     chan struct{}
+	mx       sync.Mutex
+}
+
+// NewJanitor creates a janitor that runs the given tasks against
+// store once every interval. An interval of zero or less defaults
+// to one minute.
+func NewJanitor(store *RedisTKV, interval time.Duration, tasks ...JanitorTask) *Janitor {
+	if interval <= 0 {
+		interval = defaultJanitorInterval
+	}
+
+	return &Janitor{
+		store:    store,
+		interval: interval,
+		tasks:    tasks,
+	}
+}
+
+// Start runs the janitor until Stop is called or ctx is done.
+// Calling Start on a running janitor is a no-op.
+func (j *Janitor) Start(ctx context.Context) {
+	j.mx.Lock()
+	defer j.mx.Unlock()
+
+	if j.cancel != nil {
+		return
+	}
+
+	ctx, j.cancel = context.WithCancel(ctx)
+	j.done = make(chan struct{})
+
+	go j.run(ctx, j.done)
+}
+
+// Stop stops the janitor and waits for running tasks to return.
+func (j *Janitor) Stop() {
+	_ = j.Shutdown(context.Background())
+}
+
+// Shutdown stops the janitor and waits for running tasks to return
+// until ctx is done. Tasks are cancelled right away, so they only
+// need time to drain if they ignore cancellation.
+func (j *Janitor) Shutdown(ctx context.Context) error {
+	j.mx.Lock()
+	cancel, done := j.cancel, j.done
+	j.cancel, j.done = nil, nil
+	j.mx.Unlock()
+
+	if cancel == nil {
+		return nil
+	}
+
+	cancel()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("janitor did not stop: %w", context.Cause(ctx))
+	}
+}
+
+// RunOnce runs all tasks a single time in the calling goroutine.
+func (j *Janitor) RunOnce(ctx context.Context) {
+	for _, task := range j.tasks {
+		if err := task.call(ctx, j.store); err != nil && ctx.Err() == nil {
+			j.store.logger.ErrorContext(ctx, "janitor task failed",
+				"namespace", j.store.namespace,
+				"error", err,
+			)
+		}
+	}
+}
+
+func (j *Janitor) run(ctx context.Context, done chan struct{}) {
+	defer close(done)
+
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			j.RunOnce(ctx)
+		}
+	}
+}
+
+// call runs task, converting a panic into an error.
+func (task JanitorTask) call(ctx context.Context, r *RedisTKV) (err error) {
+	defer recoverPanic(&err)
+
+	return task(ctx, r)
+}
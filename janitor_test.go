@@ -0,0 +1,81 @@
+// GNU AFFERO GENERAL PUBLIC LICENSE
+// Version 3, 19 November 2007
+//
+// Copyright (C) 2025 John Kleijn
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// For more details, see the full AGPL-3.0 license at:
+// https://www.gnu.org/licenses/agpl-3.0.html
+
+package rtkv_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/johnknl/rtkv"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJanitor(t *testing.T) {
+	var runs atomic.Int32
+
+	var logs bytes.Buffer
+
+	store := newRTKV(t, newGoRedisClient(0)).With(
+		rtkv.WithLogger(slog.New(slog.NewTextHandler(&logs, nil))),
+	)
+
+	janitor := rtkv.NewJanitor(store, 5*time.Millisecond,
+		func(context.Context, *rtkv.RedisTKV) error {
+			runs.Add(1)
+
+			return nil
+		},
+		func(context.Context, *rtkv.RedisTKV) error {
+			return errors.New("mock error")
+		},
+	)
+
+	janitor.Start(context.Background())
+	janitor.Start(context.Background())
+
+	assert.Eventually(t, func() bool {
+		return runs.Load() >= 2
+	}, time.Second, time.Millisecond)
+
+	janitor.Stop()
+	janitor.Stop()
+
+	stopped := runs.Load()
+
+	time.Sleep(20 * time.Millisecond)
+
+	assert.Equal(t, stopped, runs.Load(), "Tasks should not run after Stop")
+	assert.Contains(t, logs.String(), "mock error")
+}
+
+func TestJanitor_DefaultInterval(t *testing.T) {
+	janitor := rtkv.NewJanitor(newRTKV(t, newGoRedisClient(0)), 0)
+
+	janitor.Start(context.Background())
+
+	assert.NoError(t, janitor.Shutdown(context.Background()))
+}
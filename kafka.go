@@ -0,0 +1,87 @@
+// GNU AFFERO GENERAL PUBLIC LICENSE
+// Version 3, 19 November 2007
+//
+// Copyright (C) 2025 John Kleijn
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// For more details, see the full AGPL-3.0 license at:
+// https://www.gnu.org/licenses/agpl-3.0.html
+
+package rtkv
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// KafkaMessage is a message produced to Kafka.
+type KafkaMessage struct {
+	Topic string
+	Key   []byte
+	Value []byte
+}
+
+// KafkaProducer produces messages to Kafka. Implement it with the
+// Kafka client of your choice. Produce must only return nil once all
+// messages are acknowledged by the brokers, and must keep messages
+// with the same key in order.
+type KafkaProducer interface {
+	Produce(ctx context.Context, messages []KafkaMessage) error
+}
+
+// KafkaPublisher is a ChangePublisher that produces change events as
+// CloudEvents JSON to a Kafka topic. Messages are keyed by entity ID,
+// so all changes to an entity land on the same partition in order.
+type KafkaPublisher struct {
+	producer KafkaProducer
+	topic    string
+	source   string
+}
+
+// NewKafkaPublisher creates a publisher producing to topic. The
+// source is used as the CloudEvents source of the events.
+func NewKafkaPublisher(producer KafkaProducer, topic, source string) *KafkaPublisher {
+	return &KafkaPublisher{
+		producer: producer,
+		topic:    topic,
+		source:   source,
+	}
+}
+
+// Publish implements ChangePublisher.
+func (p *KafkaPublisher) Publish(ctx context.Context, events []ChangeEvent) error {
+	messages := make([]KafkaMessage, len(events))
+
+	for i, event := range events {
+		value, err := json.Marshal(event.CloudEvent(p.source))
+		if err != nil {
+			return fmt.Errorf("failed to encode event: %w", err)
+		}
+
+		messages[i] = KafkaMessage{
+			Topic: p.topic,
+			Key:   []byte(strings.Join(event.ID, "/")),
+			Value: value,
+		}
+	}
+
+	if err := p.producer.Produce(ctx, messages); err != nil {
+		return fmt.Errorf("failed to produce to %s: %w", p.topic, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,44 @@
+// GNU AFFERO GENERAL PUBLIC LICENSE
+// Version 3, 19 November 2007
+//
+// Copyright (C) 2025 John Kleijn
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// For more details, see the full AGPL-3.0 license at:
+// https://www.gnu.org/licenses/agpl-3.0.html
+
+package rtkv
+
+import "fmt"
+
+// KeySelector returns the ID of the key to encrypt the value of an
+// entity with, such as a key per tenant derived from the first ID
+// part. Revoking the key of a tenant makes its values unreadable.
+type KeySelector func(id []string) (keyID string, err error)
+
+// IDPartKeySelector returns a KeySelector selecting the key with the
+// ID of part i of the ID of an entity, prefixed with prefix. With IDs
+// starting with a tenant, IDPartKeySelector(0, "tenant-") selects a
+// key of its own for every tenant. IDs with fewer parts, and any ID
+// if i is negative, fail with ErrInvalidID.
+func IDPartKeySelector(i int, prefix string) KeySelector {
+	return func(id []string) (string, error) {
+		if i < 0 || i >= len(id) {
+			return "", fmt.Errorf("%w: %v has no part %d", ErrInvalidID, id, i)
+		}
+
+		return prefix + id[i], nil
+	}
+}
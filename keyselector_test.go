@@ -0,0 +1,44 @@
+// GNU AFFERO GENERAL PUBLIC LICENSE
+// Version 3, 19 November 2007
+//
+// Copyright (C) 2025 John Kleijn
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// For more details, see the full AGPL-3.0 license at:
+// https://www.gnu.org/licenses/agpl-3.0.html
+
+package rtkv_test
+
+import (
+	"testing"
+
+	"github.com/johnknl/rtkv"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIDPartKeySelector(t *testing.T) {
+	selector := rtkv.IDPartKeySelector(1, "tenant-")
+
+	keyID, err := selector([]string{"orders", "acme", "1"})
+	require.NoError(t, err)
+	assert.Equal(t, "tenant-acme", keyID)
+
+	_, err = selector([]string{"orders"})
+	require.ErrorIs(t, err, rtkv.ErrInvalidID)
+
+	_, err = rtkv.IDPartKeySelector(-1, "")([]string{"orders", "acme"})
+	require.ErrorIs(t, err, rtkv.ErrInvalidID)
+}
@@ -0,0 +1,79 @@
+// GNU AFFERO GENERAL PUBLIC LICENSE
+// Version 3, 19 November 2007
+//
+// Copyright (C) 2025 John Kleijn
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// For more details, see the full AGPL-3.0 license at:
+// https://www.gnu.org/licenses/agpl-3.0.html
+
+package rtkv
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+)
+
+// lazy is a value initialized on first use. Concurrent callers wait
+// for a single initialization, like with sync.Once, but a failed
+// initialization is retried by the next caller, and the value can be
+// reset to initialize it again, for example after a failover.
+type lazy[T any] struct {
+	init func(ctx context.Context) (T, error)
+	cur  atomic.Pointer[lazyValue[T]]
+}
+
+type lazyValue[T any] struct {
+	once  sync.Once
+	value T
+	err   error
+}
+
+func newLazy[T any](init func(ctx context.Context) (T, error)) *lazy[T] {
+	l := &lazy[T]{init: init}
+	l.cur.Store(&lazyValue[T]{})
+
+	return l
+}
+
+// get returns the value, initializing it if needed.
+func (l *lazy[T]) get(ctx context.Context) (T, error) {
+	v := l.cur.Load()
+
+	v.once.Do(func() {
+		v.value, v.err = l.init(ctx)
+	})
+
+	if v.err != nil {
+		l.cur.CompareAndSwap(v, &lazyValue[T]{})
+	}
+
+	return v.value, v.err
+}
+
+// set initializes the value with value, unless it is initialized.
+func (l *lazy[T]) set(value T) {
+	v := l.cur.Load()
+
+	v.once.Do(func() {
+		v.value = value
+	})
+}
+
+// reset discards the value, so the next get initializes it again.
+func (l *lazy[T]) reset() {
+	l.cur.Store(&lazyValue[T]{})
+}
@@ -0,0 +1,104 @@
+// GNU AFFERO GENERAL PUBLIC LICENSE
+// Version 3, 19 November 2007
+//
+// Copyright (C) 2025 John Kleijn
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// For more details, see the full AGPL-3.0 license at:
+// https://www.gnu.org/licenses/agpl-3.0.html
+
+package rtkv
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// Service is a component with background work that must be
+// drained on shutdown, such as a Janitor, a BackupScheduler or
+// a store with shadow reads.
+type Service interface {
+	// Shutdown stops the service and waits for its background
+	// work to finish until ctx is done.
+	Shutdown(ctx context.Context) error
+}
+
+// starter is implemented by services that run once started.
+type starter interface {
+	Start(ctx context.Context)
+}
+
+// Lifecycle starts and shuts down a group of services together,
+// so services embedding rtkv can shut down cleanly.
+type Lifecycle struct {
+	services     []Service
+	drainTimeout time.Duration
+}
+
+// NewLifecycle creates a lifecycle for services. Shutdown gives
+// them drainTimeout in total to finish their background work.
+// A zero drainTimeout waits indefinitely.
+func NewLifecycle(drainTimeout time.Duration, services ...Service) *Lifecycle {
+	return &Lifecycle{
+		services:     services,
+		drainTimeout: drainTimeout,
+	}
+}
+
+// Start starts all services that need starting, in order.
+func (l *Lifecycle) Start(ctx context.Context) {
+	for _, service := range l.services {
+		if s, ok := service.(starter); ok {
+			s.Start(ctx)
+		}
+	}
+}
+
+// Shutdown shuts down all services in reverse order, bounded by the
+// drain timeout and ctx. It returns the errors of all services that
+// failed to drain in time.
+func (l *Lifecycle) Shutdown(ctx context.Context) error {
+	if l.drainTimeout > 0 {
+		var cancel context.CancelFunc
+
+		ctx, cancel = context.WithTimeout(ctx, l.drainTimeout)
+		defer cancel()
+	}
+
+	errs := make([]error, 0, len(l.services))
+
+	for i := len(l.services) - 1; i >= 0; i-- {
+		errs = append(errs, l.services[i].Shutdown(ctx))
+	}
+
+	return errors.Join(errs...)
+}
+
+// Run starts all services, blocks until ctx is done and then shuts
+// them down. It fits errgroup.Group.Go and similar run loops.
+func (l *Lifecycle) Run(ctx context.Context) error {
+	l.Start(ctx)
+
+	<-ctx.Done()
+
+	return l.Shutdown(context.WithoutCancel(ctx))
+}
+
+// Shutdown waits for the store's background work, such as shadow
+// reads, to finish until ctx is done. The store remains usable.
+func (r *RedisTKV) Shutdown(ctx context.Context) error {
+	return r.shadow.wait(ctx)
+}
@@ -0,0 +1,85 @@
+// GNU AFFERO GENERAL PUBLIC LICENSE
+// Version 3, 19 November 2007
+//
+// Copyright (C) 2025 John Kleijn
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// For more details, see the full AGPL-3.0 license at:
+// https://www.gnu.org/licenses/agpl-3.0.html
+
+package rtkv_test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/johnknl/rtkv"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLifecycle(t *testing.T) {
+	store := newRTKV(t, newGoRedisClient(0))
+
+	var runs atomic.Int32
+
+	janitor := rtkv.NewJanitor(store, time.Millisecond, func(context.Context, *rtkv.RedisTKV) error {
+		runs.Add(1)
+
+		return nil
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	lifecycle := rtkv.NewLifecycle(time.Second, store, janitor)
+
+	go func() { done <- lifecycle.Run(ctx) }()
+
+	assert.Eventually(t, func() bool { return runs.Load() > 0 }, time.Second, time.Millisecond)
+
+	cancel()
+
+	require.NoError(t, <-done)
+}
+
+func TestLifecycle_DrainTimeout(t *testing.T) {
+	store := newRTKV(t, newGoRedisClient(0))
+	release := make(chan struct{})
+	started := make(chan struct{})
+
+	t.Cleanup(func() { close(release) })
+
+	janitor := rtkv.NewJanitor(store, time.Millisecond, func(context.Context, *rtkv.RedisTKV) error {
+		select {
+		case started <- struct{}{}:
+		default:
+		}
+
+		<-release // ignores cancellation
+
+		return nil
+	})
+
+	lifecycle := rtkv.NewLifecycle(10*time.Millisecond, janitor)
+	lifecycle.Start(context.Background())
+
+	<-started
+
+	err := lifecycle.Shutdown(context.Background())
+
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+}
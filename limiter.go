@@ -0,0 +1,58 @@
+// GNU AFFERO GENERAL PUBLIC LICENSE
+// Version 3, 19 November 2007
+//
+// Copyright (C) 2025 John Kleijn
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// For more details, see the full AGPL-3.0 license at:
+// https://www.gnu.org/licenses/agpl-3.0.html
+
+package rtkv
+
+import (
+	"context"
+	"fmt"
+)
+
+// WithFetchConcurrency limits the number of page fetches a store runs
+// against Redis at once to n, so dozens of consumers starting full
+// scans at the same time don't saturate it. Fetches beyond the limit
+// wait for a slot, or fail when their context is done. The limit is
+// shared with clones created with With. Zero, the default, means no
+// limit.
+func WithFetchConcurrency(n int) Option {
+	return func(r *RedisTKV) {
+		r.fetchSlots = nil
+
+		if n > 0 {
+			r.fetchSlots = make(chan struct{}, n)
+		}
+	}
+}
+
+// acquireFetch waits for a fetch slot. The returned
+// function releases it.
+func (r *RedisTKV) acquireFetch(ctx context.Context) (func(), error) {
+	if r.fetchSlots == nil {
+		return func() {}, nil
+	}
+
+	select {
+	case r.fetchSlots <- struct{}{}:
+		return func() { <-r.fetchSlots }, nil
+	case <-ctx.Done():
+		return nil, fmt.Errorf("failed to acquire fetch slot: %w", ctx.Err())
+	}
+}
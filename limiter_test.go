@@ -0,0 +1,115 @@
+// GNU AFFERO GENERAL PUBLIC LICENSE
+// Version 3, 19 November 2007
+//
+// Copyright (C) 2025 John Kleijn
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// For more details, see the full AGPL-3.0 license at:
+// https://www.gnu.org/licenses/agpl-3.0.html
+
+package rtkv_test
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/johnknl/rtkv"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// concurrencyHook tracks the maximum number of concurrent ZCOUNTs.
+type concurrencyHook struct {
+	current atomic.Int64
+	max     atomic.Int64
+}
+
+func (*concurrencyHook) DialHook(next redis.DialHook) redis.DialHook { return next }
+
+func (h *concurrencyHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		if cmd.Name() != "zcount" {
+			return next(ctx, cmd)
+		}
+
+		n := h.current.Add(1)
+		defer h.current.Add(-1)
+
+		for m := h.max.Load(); n > m && !h.max.CompareAndSwap(m, n); m = h.max.Load() {
+		}
+
+		time.Sleep(20 * time.Millisecond)
+
+		return next(ctx, cmd)
+	}
+}
+
+func (*concurrencyHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return next
+}
+
+func TestWithFetchConcurrency(t *testing.T) {
+	ctx := context.Background()
+	goRedisSetup(t, 20)
+
+	hook := &concurrencyHook{}
+	client := newGoRedisClient(0)
+	client.AddHook(hook)
+
+	store := newRTKV(t, client).With(rtkv.WithFetchConcurrency(2))
+
+	var wg sync.WaitGroup
+
+	for range 8 {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			_, total, err := store.FetchPage(ctx, nil, nil, 0, 10)
+			assert.NoError(t, err)
+			assert.EqualValues(t, 20, total)
+		}()
+	}
+
+	wg.Wait()
+
+	assert.EqualValues(t, 2, hook.max.Load())
+}
+
+func TestWithFetchConcurrency_ContextDone(t *testing.T) {
+	goRedisSetup(t, 20)
+
+	client := newGoRedisClient(0)
+	client.AddHook(&concurrencyHook{})
+
+	store := newRTKV(t, client).With(rtkv.WithFetchConcurrency(1))
+
+	go func() {
+		_, _, _ = store.FetchPage(context.Background(), nil, nil, 0, 10)
+	}()
+
+	time.Sleep(5 * time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+
+	_, _, err := store.FetchPageConsistent(ctx, nil, nil, 0, 10)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+}
@@ -0,0 +1,297 @@
+// GNU AFFERO GENERAL PUBLIC LICENSE
+// Version 3, 19 November 2007
+//
+// Copyright (C) 2025 John Kleijn
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// For more details, see the full AGPL-3.0 license at:
+// https://www.gnu.org/licenses/agpl-3.0.html
+
+package rtkv
+
+import (
+	"bytes"
+	"context"
+	"iter"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MemoryTKV is a TKV backed by a map and a slice sorted by
+// lastModified time, for unit tests and small deployments that
+// don't need Redis or persistence. It orders and pages entities
+// like RedisTKV, including ties broken by ID, and expires entities
+// written with a TTL. It is safe for concurrent use.
+type MemoryTKV struct {
+	idDelimiter string
+
+	mx         sync.Mutex
+	entries    map[string]*memoryEntry
+	index      []*memoryEntry
+	nextExpiry time.Time
+}
+
+type memoryEntry struct {
+	key       string
+	data      []byte
+	score     float64
+	expiresAt time.Time
+}
+
+// NewMemoryTKV creates an empty MemoryTKV. The idDelimiter packs
+// composite IDs into a single key, as with NewRedisTKV.
+func NewMemoryTKV(idDelimiter string) *MemoryTKV {
+	return &MemoryTKV{
+		idDelimiter: idDelimiter,
+		entries:     make(map[string]*memoryEntry),
+	}
+}
+
+// Get an entity by ID. Returns a nil value if the entity doesn't exist.
+func (m *MemoryTKV) Get(_ context.Context, id ...string) ([]byte, error) {
+	m.mx.Lock()
+	defer m.mx.Unlock()
+
+	m.expire()
+
+	if e, ok := m.entries[m.key(id)]; ok {
+		return bytes.Clone(e.data), nil
+	}
+
+	return nil, nil
+}
+
+// Set an entity, returning whether it existed before.
+func (m *MemoryTKV) Set(_ context.Context, data []byte, lastModified time.Time, id ...string) (bool, error) {
+	m.mx.Lock()
+	defer m.mx.Unlock()
+
+	m.expire()
+
+	return m.set(BulkSetRecord{LastModified: lastModified, ID: id, Data: data}), nil
+}
+
+// BulkSet sets multiple entities at once.
+func (m *MemoryTKV) BulkSet(_ context.Context, records []BulkSetRecord) error {
+	m.mx.Lock()
+	defer m.mx.Unlock()
+
+	m.expire()
+
+	for i := range records {
+		m.set(records[i])
+	}
+
+	return nil
+}
+
+// Delete an entity by ID.
+func (m *MemoryTKV) Delete(_ context.Context, id ...string) error {
+	m.mx.Lock()
+	defer m.mx.Unlock()
+
+	if e, ok := m.entries[m.key(id)]; ok {
+		m.remove(e)
+	}
+
+	return nil
+}
+
+// Exists reports whether an entity exists.
+func (m *MemoryTKV) Exists(_ context.Context, id ...string) (bool, error) {
+	m.mx.Lock()
+	defer m.mx.Unlock()
+
+	m.expire()
+
+	_, ok := m.entries[m.key(id)]
+
+	return ok, nil
+}
+
+// FetchPage yields the values of entities last modified in the given
+// range, oldest first, along with the number of entities in the range.
+// Nil means unbounded. Values are read when the page is fetched, so
+// pages are always consistent.
+func (m *MemoryTKV) FetchPage(
+	_ context.Context,
+	from, to *time.Time, //nolint:varnamelen // from and to are clear
+	offset, limit int,
+) (iter.Seq2[[]byte, error], int64, error) {
+	m.mx.Lock()
+	defer m.mx.Unlock()
+
+	m.expire()
+
+	entries := m.scoreRange(from, to)
+	total := int64(len(entries))
+
+	// Like ZRANGEBYSCORE, a zero offset and limit return the whole
+	// range, and a negative limit the rest of it.
+	if offset != 0 || limit != 0 {
+		entries = entries[min(max(offset, 0), len(entries)):]
+
+		if limit >= 0 {
+			entries = entries[:min(limit, len(entries))]
+		}
+	}
+
+	values := make([][]byte, len(entries))
+	for i, e := range entries {
+		values[i] = bytes.Clone(e.data)
+	}
+
+	return func(yield func([]byte, error) bool) {
+		for _, v := range values {
+			if !yield(v, nil) {
+				return
+			}
+		}
+	}, total, nil
+}
+
+// FetchPageConsistent is FetchPage, as pages of a MemoryTKV
+// are always consistent.
+func (m *MemoryTKV) FetchPageConsistent(
+	ctx context.Context,
+	from, to *time.Time, //nolint:varnamelen // from and to are clear
+	offset, limit int,
+) (iter.Seq2[[]byte, error], int64, error) {
+	return m.FetchPage(ctx, from, to, offset, limit)
+}
+
+func (m *MemoryTKV) key(id []string) string {
+	return strings.Join(id, m.idDelimiter)
+}
+
+// set writes a record, returning whether it replaced an entity.
+func (m *MemoryTKV) set(record BulkSetRecord) bool {
+	key := m.key(record.ID)
+
+	old, existed := m.entries[key]
+	if existed {
+		m.remove(old)
+	}
+
+	// Scores are floats, as in the sorted sets of RedisTKV,
+	// so times that collide there collide here too.
+	e := &memoryEntry{
+		key:   key,
+		data:  bytes.Clone(record.Data),
+		score: float64(record.LastModified.UnixNano()),
+	}
+
+	if record.TTL > 0 {
+		e.expiresAt = time.Now().Add(record.TTL)
+
+		if m.nextExpiry.IsZero() || e.expiresAt.Before(m.nextExpiry) {
+			m.nextExpiry = e.expiresAt
+		}
+	}
+
+	i, _ := slices.BinarySearchFunc(m.index, e, compareMemoryEntries)
+	m.index = slices.Insert(m.index, i, e)
+	m.entries[key] = e
+
+	return existed
+}
+
+func (m *MemoryTKV) remove(e *memoryEntry) {
+	if i, found := slices.BinarySearchFunc(m.index, e, compareMemoryEntries); found {
+		m.index = slices.Delete(m.index, i, i+1)
+	}
+
+	delete(m.entries, e.key)
+}
+
+// expire removes expired entities, once the first of them expired.
+func (m *MemoryTKV) expire() {
+	now := time.Now()
+
+	if m.nextExpiry.IsZero() || now.Before(m.nextExpiry) {
+		return
+	}
+
+	m.nextExpiry = time.Time{}
+
+	m.index = slices.DeleteFunc(m.index, func(e *memoryEntry) bool {
+		if e.expiresAt.IsZero() {
+			return false
+		}
+
+		if !now.Before(e.expiresAt) {
+			delete(m.entries, e.key)
+
+			return true
+		}
+
+		if m.nextExpiry.IsZero() || e.expiresAt.Before(m.nextExpiry) {
+			m.nextExpiry = e.expiresAt
+		}
+
+		return false
+	})
+}
+
+// scoreRange returns the entities with scores in the given range.
+func (m *MemoryTKV) scoreRange(from, to *time.Time) []*memoryEntry { //nolint:varnamelen // from and to are clear
+	start, end := 0, len(m.index)
+
+	if from != nil {
+		score := float64(from.UnixNano())
+		start, _ = slices.BinarySearchFunc(m.index, score, func(e *memoryEntry, s float64) int {
+			return cmpFloat(e.score, s, 0)
+		})
+	}
+
+	if to != nil {
+		score := float64(to.UnixNano())
+		end, _ = slices.BinarySearchFunc(m.index, score, func(e *memoryEntry, s float64) int {
+			return cmpFloat(e.score, s, -1)
+		})
+	}
+
+	if end < start {
+		return nil
+	}
+
+	return m.index[start:end]
+}
+
+// cmpFloat compares a and b, returning tie for equal values, so
+// binary searches find the first of them, or past the last of them
+// with a tie of -1.
+func cmpFloat(a, b float64, tie int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+
+	return tie
+}
+
+// compareMemoryEntries orders entities like a sorted set,
+// by score and then by key.
+func compareMemoryEntries(a, b *memoryEntry) int {
+	if c := cmpFloat(a.score, b.score, 0); c != 0 {
+		return c
+	}
+
+	return strings.Compare(a.key, b.key)
+}
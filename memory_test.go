@@ -0,0 +1,126 @@
+// GNU AFFERO GENERAL PUBLIC LICENSE
+// Version 3, 19 November 2007
+//
+// Copyright (C) 2025 John Kleijn
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// For more details, see the full AGPL-3.0 license at:
+// https://www.gnu.org/licenses/agpl-3.0.html
+
+package rtkv_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/johnknl/rtkv"
+	"github.com/johnknl/rtkv/rtkvtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestTKV runs the same scenario on every TKV implementation,
+// so MemoryTKV stays interchangeable with RedisTKV.
+func TestTKV(t *testing.T) {
+	backends := map[string]func(t *testing.T) rtkv.TKV{
+		"redis":  func(t *testing.T) rtkv.TKV { return rtkvtest.NewMiniredisTKV(t) },
+		"memory": func(*testing.T) rtkv.TKV { return rtkv.NewMemoryTKV(rtkv.DelimUnit) },
+	}
+
+	for name, newTKV := range backends {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			store := newTKV(t)
+			start := time.Unix(1700000000, 0)
+
+			existed, err := store.Set(ctx, []byte("b"), start.Add(time.Second), "b")
+			require.NoError(t, err)
+			assert.False(t, existed)
+
+			existed, err = store.Set(ctx, []byte("a"), start.Add(time.Second), "a")
+			require.NoError(t, err)
+			assert.False(t, existed)
+
+			require.NoError(t, store.BulkSet(ctx, []rtkv.BulkSetRecord{
+				{ID: []string{"c"}, Data: []byte("c"), LastModified: start.Add(2 * time.Second)},
+				{ID: []string{"d", "1"}, Data: []byte("d"), LastModified: start},
+			}))
+
+			existed, err = store.Set(ctx, []byte("c2"), start.Add(3*time.Second), "c")
+			require.NoError(t, err)
+			assert.True(t, existed)
+
+			value, err := store.Get(ctx, "d", "1")
+			require.NoError(t, err)
+			assert.Equal(t, []byte("d"), value)
+
+			value, err = store.Get(ctx, "missing")
+			require.NoError(t, err)
+			assert.Nil(t, value)
+
+			values, total, err := store.FetchPage(ctx, nil, nil, 0, 10)
+			require.NoError(t, err)
+			assert.EqualValues(t, 4, total)
+			assert.Equal(t, []string{"d", "a", "b", "c2"}, collect(t, values), "Ties should be ordered by ID")
+
+			from, to := start.Add(time.Second), start.Add(2*time.Second)
+
+			values, total, err = store.FetchPageConsistent(ctx, &from, &to, 1, 1)
+			require.NoError(t, err)
+			assert.EqualValues(t, 2, total, "Bounds should be inclusive")
+			assert.Equal(t, []string{"b"}, collect(t, values))
+
+			require.NoError(t, store.Delete(ctx, "a"))
+
+			exists, err := store.Exists(ctx, "a")
+			require.NoError(t, err)
+			assert.False(t, exists)
+
+			values, total, err = store.FetchPage(ctx, &from, nil, 0, -1)
+			require.NoError(t, err)
+			assert.EqualValues(t, 2, total)
+			assert.Equal(t, []string{"b", "c2"}, collect(t, values))
+		})
+	}
+}
+
+func TestMemoryTKV_TTL(t *testing.T) {
+	ctx := context.Background()
+	store := rtkv.NewMemoryTKV(rtkv.DelimUnit)
+
+	require.NoError(t, store.BulkSet(ctx, []rtkv.BulkSetRecord{
+		{ID: []string{"short"}, Data: []byte("short"), LastModified: time.Now(), TTL: 10 * time.Millisecond},
+		{ID: []string{"long"}, Data: []byte("long"), LastModified: time.Now(), TTL: time.Hour},
+		{ID: []string{"cleared"}, Data: []byte("cleared"), LastModified: time.Now(), TTL: 10 * time.Millisecond},
+	}))
+
+	_, err := store.Set(ctx, []byte("cleared"), time.Now(), "cleared")
+	require.NoError(t, err)
+
+	time.Sleep(20 * time.Millisecond)
+
+	exists, err := store.Exists(ctx, "short")
+	require.NoError(t, err)
+	assert.False(t, exists)
+
+	exists, err = store.Exists(ctx, "cleared")
+	require.NoError(t, err)
+	assert.True(t, exists, "Set should clear the TTL")
+
+	_, total, err := store.FetchPage(ctx, nil, nil, 0, 10)
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, total)
+}
@@ -0,0 +1,44 @@
+// GNU AFFERO GENERAL PUBLIC LICENSE
+// Version 3, 19 November 2007
+//
+// Copyright (C) 2025 John Kleijn
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// For more details, see the full AGPL-3.0 license at:
+// https://www.gnu.org/licenses/agpl-3.0.html
+
+// Package msgpackcodec provides an rtkv.Codec that encodes values
+// as MessagePack, which is typically smaller and faster to decode
+// than JSON.
+package msgpackcodec
+
+import (
+	"github.com/johnknl/rtkv"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// Codec encodes values with github.com/vmihailenco/msgpack/v5.
+// Struct fields are named by their msgpack tags.
+type Codec struct{}
+
+var _ rtkv.Codec = Codec{}
+
+func (Codec) Marshal(v any) ([]byte, error) {
+	return msgpack.Marshal(v) //nolint:wrapcheck // the caller adds context
+}
+
+func (Codec) Unmarshal(data []byte, v any) error {
+	return msgpack.Unmarshal(data, v) //nolint:wrapcheck // the caller adds context
+}
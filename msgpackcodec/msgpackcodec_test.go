@@ -0,0 +1,51 @@
+// GNU AFFERO GENERAL PUBLIC LICENSE
+// Version 3, 19 November 2007
+//
+// Copyright (C) 2025 John Kleijn
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// For more details, see the full AGPL-3.0 license at:
+// https://www.gnu.org/licenses/agpl-3.0.html
+
+package msgpackcodec_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/johnknl/rtkv"
+	"github.com/johnknl/rtkv/msgpackcodec"
+	"github.com/johnknl/rtkv/rtkvtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCodec(t *testing.T) {
+	type user struct {
+		Name  string `msgpack:"name"`
+		Admin bool   `msgpack:"admin"`
+	}
+
+	ctx := context.Background()
+	store := rtkv.NewStore[user](rtkvtest.NewMiniredisTKV(t), msgpackcodec.Codec{})
+
+	_, err := store.Set(ctx, user{Name: "a", Admin: true}, time.Now(), "users", "a")
+	require.NoError(t, err)
+
+	got, err := store.Get(ctx, "users", "a")
+	require.NoError(t, err)
+	assert.Equal(t, user{Name: "a", Admin: true}, got)
+}
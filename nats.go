@@ -0,0 +1,118 @@
+// GNU AFFERO GENERAL PUBLIC LICENSE
+// Version 3, 19 November 2007
+//
+// Copyright (C) 2025 John Kleijn
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// For more details, see the full AGPL-3.0 license at:
+// https://www.gnu.org/licenses/agpl-3.0.html
+
+package rtkv
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// NATSMessage is a message published to NATS JetStream.
+type NATSMessage struct {
+	Subject string
+	Data    []byte
+
+	// MsgID is the stream ID of the event. Pass it as the
+	// Nats-Msg-Id header, so JetStream drops events the bridge
+	// delivers more than once.
+	MsgID string
+}
+
+// JetStreamProducer publishes messages to NATS JetStream. Implement
+// it with nats.go, returning nil only once JetStream acknowledged the
+// message.
+type JetStreamProducer interface {
+	Publish(ctx context.Context, msg NATSMessage) error
+}
+
+// NATSPublisher is a ChangePublisher that publishes change events as
+// CloudEvents JSON to NATS JetStream. The subject of an event is the
+// subject prefix followed by one token per ID part, so consumers can
+// subscribe to sub-trees of the namespace: with prefix "rtkv.users",
+// a change to ID {"eu", "42"} is published on "rtkv.users.eu.42" and
+// "rtkv.users.eu.>" receives all changes below "eu".
+type NATSPublisher struct {
+	producer JetStreamProducer
+	prefix   string
+	source   string
+}
+
+// NewNATSPublisher creates a publisher for subjects starting with
+// prefix. The source is used as the CloudEvents source of the events.
+func NewNATSPublisher(producer JetStreamProducer, prefix, source string) *NATSPublisher {
+	return &NATSPublisher{
+		producer: producer,
+		prefix:   prefix,
+		source:   source,
+	}
+}
+
+// Publish implements ChangePublisher.
+func (p *NATSPublisher) Publish(ctx context.Context, events []ChangeEvent) error {
+	for _, event := range events {
+		data, err := json.Marshal(event.CloudEvent(p.source))
+		if err != nil {
+			return fmt.Errorf("failed to encode event: %w", err)
+		}
+
+		subject := p.Subject(event.ID)
+
+		err = p.producer.Publish(ctx, NATSMessage{Subject: subject, Data: data, MsgID: event.StreamID})
+		if err != nil {
+			return fmt.Errorf("failed to publish to %s: %w", subject, err)
+		}
+	}
+
+	return nil
+}
+
+// Subject returns the subject changes to the entity id are published
+// on. Characters that are not allowed in subject tokens are replaced
+// with underscores, and empty ID parts become a single underscore.
+func (p *NATSPublisher) Subject(id []string) string {
+	tokens := make([]string, 0, len(id)+1)
+
+	if p.prefix != "" {
+		tokens = append(tokens, p.prefix)
+	}
+
+	for _, part := range id {
+		token := strings.Map(func(c rune) rune {
+			switch c {
+			case '.', '*', '>', ' ', '\t', '\r', '\n':
+				return '_'
+			default:
+				return c
+			}
+		}, part)
+
+		if token == "" {
+			token = "_"
+		}
+
+		tokens = append(tokens, token)
+	}
+
+	return strings.Join(tokens, ".")
+}
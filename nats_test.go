@@ -0,0 +1,59 @@
+// GNU AFFERO GENERAL PUBLIC LICENSE
+// Version 3, 19 November 2007
+//
+// Copyright (C) 2025 John Kleijn
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// For more details, see the full AGPL-3.0 license at:
+// https://www.gnu.org/licenses/agpl-3.0.html
+
+package rtkv_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/johnknl/rtkv"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeJetStream []rtkv.NATSMessage
+
+func (js *fakeJetStream) Publish(_ context.Context, msg rtkv.NATSMessage) error {
+	*js = append(*js, msg)
+
+	return nil
+}
+
+func TestNATSPublisher(t *testing.T) {
+	ctx := context.Background()
+	store := newRTKV(t, newGoRedisClient(0)).With(rtkv.WithChangeFeed(rtkv.ChangeFeedOptions{}))
+	js := &fakeJetStream{}
+	publisher := rtkv.NewNATSPublisher(js, "rtkv.users", "/users")
+	bridge := rtkv.NewChangeBridge(store, publisher, rtkv.ChangeBridgeOptions{Group: "nats"})
+
+	_, err := store.Set(ctx, []byte("v"), time.Now(), "eu", "jane.doe")
+	require.NoError(t, err)
+
+	n, err := bridge.RunOnce(ctx)
+
+	require.NoError(t, err)
+	require.Equal(t, 1, n)
+	assert.Equal(t, "rtkv.users.eu.jane_doe", (*js)[0].Subject)
+	assert.NotEmpty(t, (*js)[0].MsgID)
+	assert.Equal(t, "rtkv.users._.a_b", publisher.Subject([]string{"", "a>b"}))
+}
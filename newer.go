@@ -0,0 +1,222 @@
+// GNU AFFERO GENERAL PUBLIC LICENSE
+// Version 3, 19 November 2007
+//
+// Copyright (C) 2025 John Kleijn
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// For more details, see the full AGPL-3.0 license at:
+// https://www.gnu.org/licenses/agpl-3.0.html
+
+package rtkv
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// setIfNewerScript sets an entity unless the stored lastModified time
+// is the same or newer, like ZADD GT but also guarding the value.
+// Compacted entities are compared with their score in the shard
+// holding them. Returns -1 if the write was rejected, -2 if the
+// entity was compacted into another epoch than the one passed,
+// otherwise the number of index entries added (1 if the entity is
+// new).
+const setIfNewerScript = `
+local key = KEYS[1] -- the entity key
+local index = KEYS[2] -- the lastModified index
+local expiry = KEYS[3] -- the expiry index
+local versions = KEYS[4] -- the versions hash
+local compacted = KEYS[5] -- the epoch of every compacted entity
+local shard = KEYS[6] -- the shard of the epoch, if any
+local data = ARGV[1] -- the new value
+local score = ARGV[2] -- the lastModified score
+local ttl = tonumber(ARGV[3]) -- the TTL in milliseconds, or 0
+local expireAt = ARGV[4] -- the expiry score
+local versioned = ARGV[5] == "1" -- whether to increment the version
+local epoch = ARGV[6] -- the epoch the entity was compacted into, or ""
+local compaction = ARGV[7] == "1" -- whether to look in the shards
+
+local current = redis.call("ZSCORE", index, key)
+
+if compaction then
+  if (redis.call("HGET", compacted, key) or "") ~= epoch then
+    return -2
+  end
+
+  if epoch ~= "" then
+    current = redis.call("ZSCORE", shard, key)
+  end
+end
+
+if current and tonumber(current) >= tonumber(score) then
+  return -1
+end
+
+if ttl > 0 then
+  redis.call("SET", key, data, "PX", ttl)
+  redis.call("ZADD", expiry, expireAt, key)
+else
+  redis.call("SET", key, data)
+end
+
+if versioned then
+  redis.call("HINCRBY", versions, key, 1)
+end
+
+return redis.call("ZADD", index, score, key)
+`
+
+// setIfAbsentScript creates an entity unless the key exists.
+// Returns -1 if it exists, otherwise 1.
+const setIfAbsentScript = `
+local key = KEYS[1] -- the entity key
+local index = KEYS[2] -- the lastModified index
+local expiry = KEYS[3] -- the expiry index
+local versions = KEYS[4] -- the versions hash
+local data = ARGV[1] -- the new value
+local score = ARGV[2] -- the lastModified score
+local ttl = tonumber(ARGV[3]) -- the TTL in milliseconds, or 0
+local expireAt = ARGV[4] -- the expiry score
+local versioned = ARGV[5] == "1" -- whether to increment the version
+
+if redis.call("EXISTS", key) == 1 then
+  return -1
+end
+
+if ttl > 0 then
+  redis.call("SET", key, data, "PX", ttl)
+  redis.call("ZADD", expiry, expireAt, key)
+else
+  redis.call("SET", key, data)
+end
+
+if versioned then
+  redis.call("HINCRBY", versions, key, 1)
+end
+
+redis.call("ZADD", index, score, key)
+
+return 1
+`
+
+// SetIfNewer sets an entity unless the store holds a version with the
+// same or a newer lastModified time, and returns whether it was
+// written. The check and the write are atomic, so replaying change
+// events out of order, or more than once, never moves an entity back
+// in time. Rejected writes are counted as skipped in Stats, and as
+// conflicts with WithConflictTracking.
+func (r *RedisTKV) SetIfNewer(ctx context.Context, data []byte, lastModified time.Time, id ...string) (bool, error) {
+	defer r.observe(ctx, "setIfNewer", time.Now())
+
+	return r.setConditional(ctx, setIfNewerScript, data, lastModified, id)
+}
+
+// SetIfAbsent creates an entity unless it exists, and returns whether
+// it was created. The check and the write are atomic, so of many
+// concurrent callers exactly one creates the entity, as needed for
+// claims and registrations. Rejected writes are counted as skipped
+// in Stats, and as conflicts with WithConflictTracking.
+func (r *RedisTKV) SetIfAbsent(ctx context.Context, data []byte, lastModified time.Time, id ...string) (bool, error) {
+	defer r.observe(ctx, "setIfAbsent", time.Now())
+
+	return r.setConditional(ctx, setIfAbsentScript, data, lastModified, id)
+}
+
+// setConditional writes an entity with a script that returns -1 if
+// it rejected the write, -2 if the entity was compacted meanwhile, or
+// the number of index entries added.
+func (r *RedisTKV) setConditional(
+	ctx context.Context,
+	script string,
+	data []byte,
+	lastModified time.Time,
+	id []string,
+) (bool, error) {
+	if err := r.checkID(id); err != nil {
+		return false, err
+	}
+
+	if err := r.checkWritable(ctx); err != nil {
+		return false, err
+	}
+
+	if err := r.validate(ctx, id, data); err != nil {
+		return false, err
+	}
+
+	if err := r.checkWriteConcern(ctx); err != nil {
+		return false, err
+	}
+
+	data, err := r.encodeValue(id, data)
+	if err != nil {
+		return false, err
+	}
+
+	timestamp := lastModified.UnixNano()
+	key := r.namespacedKey(id...)
+	ttl := r.ttlFor(0)
+	versions, versioned := r.versionsArgs()
+	compacted := r.internalKey(compactedSuffix, membersSuffix)
+
+	var added int64
+
+	// Retry while entities get compacted between looking up their
+	// shard and writing them, which only happens while compacting.
+	for {
+		epoch, err := r.compactedEpoch(ctx, key)
+		if err != nil {
+			return false, err
+		}
+
+		shard := compacted
+		if epoch != "" {
+			shard = r.internalKey(compactedSuffix, epoch)
+		}
+
+		keys := []string{key, r.namespacedKey(lastModifiedIdxSuffix), r.internalKey(expirySuffix), versions, compacted, shard}
+
+		result, err := r.writeScript(ctx, script, keys, data, timestamp,
+			ttl.Milliseconds(), time.Now().Add(ttl).UnixNano(), versioned, epoch, r.compaction)
+		if err != nil {
+			return false, fmt.Errorf("failed to set entity: %w", err)
+		}
+
+		var ok bool
+
+		if added, ok = result.(int64); !ok {
+			return false, ErrUnexpectedScriptResult
+		}
+
+		if added != -2 { //nolint:mnd // compacted meanwhile
+			break
+		}
+	}
+
+	r.stats.recordConditionalSet(ctx, len(data), added)
+
+	if added < 0 {
+		r.recordConflicts(ctx, key)
+
+		return false, nil
+	}
+
+	if err = r.conditionalSetIndexes(ctx, data, timestamp, key); err != nil {
+		return true, err
+	}
+
+	return true, r.verifyWrites(ctx, []string{key}, [][]byte{data})
+}
@@ -0,0 +1,111 @@
+// GNU AFFERO GENERAL PUBLIC LICENSE
+// Version 3, 19 November 2007
+//
+// Copyright (C) 2025 John Kleijn
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// For more details, see the full AGPL-3.0 license at:
+// https://www.gnu.org/licenses/agpl-3.0.html
+
+package rtkv_test
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/johnknl/rtkv"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedisTKV_SetIfNewer(t *testing.T) {
+	ctx := context.Background()
+	store := newRTKV(t, newGoRedisClient(0)).With(rtkv.WithChangeFeed(rtkv.ChangeFeedOptions{}))
+	base := time.Date(2025, 1, 10, 12, 0, 0, 0, time.UTC)
+
+	written, err := store.SetIfNewer(ctx, []byte("v2"), base.Add(time.Minute), "a")
+	require.NoError(t, err)
+	assert.True(t, written)
+
+	written, err = store.SetIfNewer(ctx, []byte("v1"), base, "a")
+	require.NoError(t, err)
+	assert.False(t, written, "Older writes should be rejected")
+
+	written, err = store.SetIfNewer(ctx, []byte("v2 again"), base.Add(time.Minute), "a")
+	require.NoError(t, err)
+	assert.False(t, written, "Replays should be rejected")
+
+	value, lastModified, err := store.GetWithLastModified(ctx, "a")
+	require.NoError(t, err)
+	assert.Equal(t, "v2", string(value))
+	assert.WithinDuration(t, base.Add(time.Minute), lastModified, time.Microsecond)
+
+	written, err = store.SetIfNewer(ctx, []byte("v3"), base.Add(time.Hour), "a")
+	require.NoError(t, err)
+	assert.True(t, written)
+
+	events, err := store.ReadChanges(ctx, "0", 10)
+	require.NoError(t, err)
+	assert.Len(t, events, 2)
+
+	stats := store.Stats().Writes
+	assert.EqualValues(t, 2, stats.Sets)
+	assert.EqualValues(t, 1, stats.Creates)
+	assert.EqualValues(t, 2, stats.Skipped)
+}
+
+func TestRedisTKV_SetIfAbsent(t *testing.T) {
+	ctx := context.Background()
+	store := newRTKV(t, newGoRedisClient(0))
+
+	_, err := store.Clear(ctx)
+	require.NoError(t, err)
+
+	var (
+		wg      sync.WaitGroup
+		created atomic.Int32
+	)
+
+	for i := range 20 {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			ok, err := store.SetIfAbsent(ctx, []byte(strconv.Itoa(i)), time.Now(), "claim")
+			assert.NoError(t, err)
+
+			if ok {
+				created.Add(1)
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	assert.EqualValues(t, 1, created.Load(), "Exactly one caller should create the entity")
+
+	count, err := store.Count(ctx)
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, count)
+
+	stats := store.Stats().Writes
+	assert.EqualValues(t, 1, stats.Creates)
+	assert.EqualValues(t, 19, stats.Skipped)
+}
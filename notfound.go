@@ -0,0 +1,52 @@
+// GNU AFFERO GENERAL PUBLIC LICENSE
+// Version 3, 19 November 2007
+//
+// Copyright (C) 2025 John Kleijn
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// For more details, see the full AGPL-3.0 license at:
+// https://www.gnu.org/licenses/agpl-3.0.html
+
+package rtkv
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrNotFound is returned by Get for entities that don't exist,
+// when WithNotFoundError is used.
+var ErrNotFound = errors.New("entity not found")
+
+// WithNotFoundError makes Get return ErrNotFound for entities that
+// don't exist, instead of a nil value and a nil error, so a missing
+// entity can be told apart from an empty value.
+func WithNotFoundError() Option {
+	return func(r *RedisTKV) {
+		r.notFoundError = true
+	}
+}
+
+// getFrom reads an entity from g, treating ErrNotFound as
+// a missing entity, so Getters are handled alike whether
+// they return ErrNotFound or not.
+func getFrom(ctx context.Context, g Getter, id []string) ([]byte, error) {
+	data, err := g.Get(ctx, id...)
+	if errors.Is(err, ErrNotFound) {
+		return nil, nil
+	}
+
+	return data, err //nolint:wrapcheck // wrapped by callers
+}
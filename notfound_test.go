@@ -0,0 +1,69 @@
+// GNU AFFERO GENERAL PUBLIC LICENSE
+// Version 3, 19 November 2007
+//
+// Copyright (C) 2025 John Kleijn
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// For more details, see the full AGPL-3.0 license at:
+// https://www.gnu.org/licenses/agpl-3.0.html
+
+package rtkv_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/johnknl/rtkv"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithNotFoundError(t *testing.T) {
+	ctx := context.Background()
+	store := newRTKV(t, newGoRedisClient(0))
+	strict := store.With(rtkv.WithNotFoundError())
+
+	_, err := store.Set(ctx, []byte{}, time.Now(), "empty")
+	require.NoError(t, err)
+
+	value, err := store.Get(ctx, "missing")
+	require.NoError(t, err)
+	assert.Nil(t, value)
+
+	_, err = strict.Get(ctx, "missing")
+	require.ErrorIs(t, err, rtkv.ErrNotFound)
+
+	value, err = strict.Get(ctx, "empty")
+	require.NoError(t, err)
+	assert.NotNil(t, value)
+	assert.Empty(t, value)
+}
+
+func TestWithNotFoundError_Archive(t *testing.T) {
+	ctx := context.Background()
+	archive := rtkv.NewRedisTKV(rtkv.DelimUnit, t.Name()+"-archive", newGoRedisClient(0), rtkv.WithNotFoundError())
+	store := newRTKV(t, newGoRedisClient(0)).With(rtkv.WithArchive(archive), rtkv.WithNotFoundError())
+
+	_, err := archive.Set(ctx, []byte("archived"), time.Now(), "a")
+	require.NoError(t, err)
+
+	value, err := store.Get(ctx, "a")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("archived"), value)
+
+	_, err = store.Get(ctx, "b")
+	require.ErrorIs(t, err, rtkv.ErrNotFound)
+}
@@ -0,0 +1,518 @@
+// GNU AFFERO GENERAL PUBLIC LICENSE
+// Version 3, 19 November 2007
+//
+// Copyright (C) 2025 John Kleijn
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// For more details, see the full AGPL-3.0 license at:
+// https://www.gnu.org/licenses/agpl-3.0.html
+
+package rtkv
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// defaultS3PartSize is the default size of the parts
+// S3Store uploads large objects in.
+const defaultS3PartSize = 8 << 20
+
+// DirStore stores snapshot files in a directory on the local
+// filesystem. It implements SnapshotSink and SnapshotSource.
+type DirStore struct {
+	Dir string
+}
+
+// Put writes a file atomically by writing to a temporary
+// file first and renaming it into place.
+func (d DirStore) Put(_ context.Context, name string, body io.Reader) error {
+	target := filepath.Join(d.Dir, filepath.FromSlash(name))
+
+	if err := os.MkdirAll(filepath.Dir(target), 0o750); err != nil { //nolint:mnd // directory permissions
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(target), ".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to create file: %w", err)
+	}
+
+	defer os.Remove(tmp.Name())
+
+	if _, err = io.Copy(tmp, body); err != nil {
+		tmp.Close()
+
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+
+	if err = tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+
+	if err = os.Rename(tmp.Name(), target); err != nil {
+		return fmt.Errorf("failed to move file into place: %w", err)
+	}
+
+	return nil
+}
+
+// Get opens a file for reading.
+func (d DirStore) Get(_ context.Context, name string) (io.ReadCloser, error) {
+	f, err := os.Open(filepath.Join(d.Dir, filepath.FromSlash(name)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+
+	return f, nil
+}
+
+// List returns the names of files starting with prefix.
+func (d DirStore) List(_ context.Context, prefix string) ([]string, error) {
+	var names []string
+
+	err := filepath.WalkDir(d.Dir, func(p string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return err
+		}
+
+		rel, err := filepath.Rel(d.Dir, p)
+		if err != nil {
+			return err //nolint:wrapcheck // wrapped below
+		}
+
+		if name := filepath.ToSlash(rel); strings.HasPrefix(name, prefix) && !strings.HasPrefix(entry.Name(), ".tmp-") {
+			names = append(names, name)
+		}
+
+		return nil
+	})
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("failed to list files: %w", err)
+	}
+
+	sort.Strings(names)
+
+	return names, nil
+}
+
+// Delete removes a file.
+func (d DirStore) Delete(_ context.Context, name string) error {
+	if err := os.Remove(filepath.Join(d.Dir, filepath.FromSlash(name))); err != nil {
+		return fmt.Errorf("failed to delete file: %w", err)
+	}
+
+	return nil
+}
+
+// S3Store stores snapshot files in a bucket of an S3 compatible
+// object store, such as AWS S3, MinIO or Cloudflare R2, using path
+// style requests signed with AWS Signature Version 4. It implements
+// SnapshotStore.
+type S3Store struct {
+	// HTTPClient is used for requests. Defaults to http.DefaultClient.
+	HTTPClient *http.Client
+
+	// Endpoint is the base URL of the object store, for example
+	// https://s3.eu-west-1.amazonaws.com or http://localhost:9000.
+	Endpoint string
+
+	// Region is the signing region, for example eu-west-1.
+	Region string
+
+	// Bucket is the name of the bucket.
+	Bucket string
+
+	// Prefix is prepended to all object names. Optional.
+	Prefix string
+
+	// PartSize is the size of the parts objects larger than it are
+	// uploaded in. Defaults to 8 MiB. S3 requires at least 5 MiB.
+	PartSize int
+
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+}
+
+// Put uploads an object. Objects up to PartSize are uploaded in a
+// single request, larger ones with a multipart upload, so at most
+// one part of the body is held in memory at a time.
+func (s *S3Store) Put(ctx context.Context, name string, body io.Reader) error {
+	partSize := s.PartSize
+	if partSize <= 0 {
+		partSize = defaultS3PartSize
+	}
+
+	part := make([]byte, partSize)
+
+	n, err := io.ReadFull(body, part)
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		resp, err := s.do(ctx, http.MethodPut, name, nil, part[:n])
+		if err != nil {
+			return err
+		}
+
+		resp.Body.Close()
+
+		return nil
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to read body: %w", err)
+	}
+
+	return s.putMultipart(ctx, name, body, part)
+}
+
+// s3Part is a part of a multipart upload.
+type s3Part struct {
+	PartNumber int    `xml:"PartNumber"`
+	ETag       string `xml:"ETag"`
+}
+
+// putMultipart uploads an object in parts, starting with
+// part, which is full, and reusing it for the rest of body.
+func (s *S3Store) putMultipart(ctx context.Context, name string, body io.Reader, part []byte) error {
+	resp, err := s.do(ctx, http.MethodPost, name, map[string]string{"uploads": ""}, nil)
+	if err != nil {
+		return err
+	}
+
+	var upload struct {
+		UploadID string `xml:"UploadId"`
+	}
+
+	err = xml.NewDecoder(resp.Body).Decode(&upload)
+	resp.Body.Close()
+
+	if err != nil {
+		return fmt.Errorf("failed to decode multipart upload: %w", err)
+	}
+
+	var complete struct {
+		XMLName xml.Name `xml:"CompleteMultipartUpload"`
+		Parts   []s3Part `xml:"Part"`
+	}
+
+	for n := len(part); n > 0; {
+		number := len(complete.Parts) + 1
+		query := map[string]string{"partNumber": strconv.Itoa(number), "uploadId": upload.UploadID}
+
+		resp, err = s.do(ctx, http.MethodPut, name, query, part[:n])
+		if err != nil {
+			s.abortMultipart(ctx, name, upload.UploadID)
+
+			return err
+		}
+
+		resp.Body.Close()
+		complete.Parts = append(complete.Parts, s3Part{PartNumber: number, ETag: resp.Header.Get("ETag")})
+
+		if n < len(part) {
+			break
+		}
+
+		n, err = io.ReadFull(body, part)
+		if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+			s.abortMultipart(ctx, name, upload.UploadID)
+
+			return fmt.Errorf("failed to read body: %w", err)
+		}
+	}
+
+	payload, _ := xml.Marshal(complete) //nolint:errchkjson // can't fail
+
+	resp, err = s.do(ctx, http.MethodPost, name, map[string]string{"uploadId": upload.UploadID}, payload)
+	if err != nil {
+		s.abortMultipart(ctx, name, upload.UploadID)
+
+		return err
+	}
+
+	resp.Body.Close()
+
+	return nil
+}
+
+// abortMultipart aborts a multipart upload, so its parts don't
+// linger. Failures are ignored, as the upload already failed.
+func (s *S3Store) abortMultipart(ctx context.Context, name, uploadID string) {
+	resp, err := s.do(context.WithoutCancel(ctx), http.MethodDelete, name, map[string]string{"uploadId": uploadID}, nil)
+	if err == nil {
+		resp.Body.Close()
+	}
+}
+
+// Get downloads an object. Returns an error wrapping
+// fs.ErrNotExist if the object does not exist.
+func (s *S3Store) Get(ctx context.Context, name string) (io.ReadCloser, error) {
+	resp, err := s.do(ctx, http.MethodGet, name, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.Body, nil
+}
+
+// List returns the names of objects starting with prefix,
+// relative to the store's Prefix.
+func (s *S3Store) List(ctx context.Context, prefix string) ([]string, error) {
+	var names []string
+
+	keyPrefix := strings.Trim(s.Prefix, "/")
+	if keyPrefix != "" {
+		keyPrefix += "/"
+	}
+
+	query := map[string]string{
+		"list-type": "2",
+		"prefix":    keyPrefix + prefix,
+	}
+
+	for {
+		resp, err := s.request(ctx, http.MethodGet, "/"+s.Bucket, query, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		var result struct {
+			Contents []struct {
+				Key string `xml:"Key"`
+			} `xml:"Contents"`
+			IsTruncated           bool   `xml:"IsTruncated"`
+			NextContinuationToken string `xml:"NextContinuationToken"`
+		}
+
+		err = xml.NewDecoder(resp.Body).Decode(&result)
+		resp.Body.Close()
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode object list: %w", err)
+		}
+
+		for _, object := range result.Contents {
+			names = append(names, strings.TrimPrefix(object.Key, keyPrefix))
+		}
+
+		if !result.IsTruncated {
+			sort.Strings(names)
+
+			return names, nil
+		}
+
+		query["continuation-token"] = result.NextContinuationToken
+	}
+}
+
+// Delete removes an object.
+func (s *S3Store) Delete(ctx context.Context, name string) error {
+	resp, err := s.do(ctx, http.MethodDelete, name, nil, nil)
+	if err != nil {
+		return err
+	}
+
+	resp.Body.Close()
+
+	return nil
+}
+
+func (s *S3Store) do(
+	ctx context.Context,
+	method, name string,
+	query map[string]string,
+	body []byte,
+) (*http.Response, error) {
+	return s.request(ctx, method, "/"+s.Bucket+"/"+path.Join(s.Prefix, name), query, body)
+}
+
+func (s *S3Store) request(
+	ctx context.Context,
+	method, objectPath string,
+	query map[string]string,
+	body []byte,
+) (*http.Response, error) {
+	name := strings.TrimPrefix(objectPath, "/"+s.Bucket+"/")
+	target := strings.TrimSuffix(s.Endpoint, "/") + s3URIEncode(objectPath)
+
+	if len(query) > 0 {
+		target += "?" + s3QueryEncode(query)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, target, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	s.sign(req, body, time.Now())
+
+	client := s.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("s3 %s %s failed: %w", method, name, err)
+	}
+
+	if resp.StatusCode >= http.StatusMultipleChoices {
+		defer resp.Body.Close()
+
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<10)) //nolint:mnd // enough for an error message
+
+		if resp.StatusCode == http.StatusNotFound {
+			return nil, fmt.Errorf("s3 object %s: %w", name, fs.ErrNotExist)
+		}
+
+		return nil, fmt.Errorf("s3 %s %s failed with %s: %s", method, name, resp.Status, msg) //nolint:err113 // no sentinel
+	}
+
+	return resp, nil
+}
+
+// sign adds AWS Signature Version 4 headers to req.
+func (s *S3Store) sign(req *http.Request, body []byte, now time.Time) {
+	amzDate := now.UTC().Format("20060102T150405Z")
+	date := amzDate[:8]
+	payloadHash := sha256Hex(body)
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	if s.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", s.SessionToken)
+	}
+
+	headers := map[string]string{"host": req.URL.Host}
+
+	for name := range req.Header {
+		if lower := strings.ToLower(name); strings.HasPrefix(lower, "x-amz-") {
+			headers[lower] = strings.TrimSpace(req.Header.Get(name))
+		}
+	}
+
+	names := make([]string, 0, len(headers))
+
+	for name := range headers {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+
+	signedHeaders := strings.Join(names, ";")
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.RawQuery,
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := date + "/" + s.Region + "/s3/aws4_request"
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + sha256Hex([]byte(canonicalRequest))
+
+	key := []byte("AWS4" + s.SecretAccessKey)
+
+	for _, part := range []string{date, s.Region, "s3", "aws4_request"} {
+		key = hmacSHA256(key, part)
+	}
+
+	req.Header.Set("Authorization", fmt.Sprintf(
+		"AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s.AccessKeyID, scope, signedHeaders, hex.EncodeToString(hmacSHA256(key, stringToSign)),
+	))
+}
+
+// s3URIEncode encodes a path as required by Signature Version 4,
+// escaping everything but unreserved characters and slashes.
+func s3URIEncode(p string) string {
+	return s3Escape(p, true)
+}
+
+// s3QueryEncode encodes a query string in the canonical form
+// required by Signature Version 4: sorted by key, with keys and
+// values escaped.
+func s3QueryEncode(query map[string]string) string {
+	keys := make([]string, 0, len(query))
+
+	for key := range query {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	pairs := make([]string, len(keys))
+
+	for i, key := range keys {
+		pairs[i] = s3Escape(key, false) + "=" + s3Escape(query[key], false)
+	}
+
+	return strings.Join(pairs, "&")
+}
+
+func s3Escape(s string, keepSlash bool) string {
+	var b strings.Builder
+
+	for i := range len(s) {
+		c := s[i]
+
+		switch {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9',
+			c == '-', c == '_', c == '.', c == '~', c == '/' && keepSlash:
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+
+	return b.String()
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+
+	return mac.Sum(nil)
+}
@@ -0,0 +1,232 @@
+// GNU AFFERO GENERAL PUBLIC LICENSE
+// Version 3, 19 November 2007
+//
+// Copyright (C) 2025 John Kleijn
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// For more details, see the full AGPL-3.0 license at:
+// https://www.gnu.org/licenses/agpl-3.0.html
+
+package rtkv_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"testing/iotest"
+
+	"github.com/johnknl/rtkv"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeS3 is a minimal in-memory S3 server that checks requests are signed.
+type fakeS3 struct {
+	objects map[string][]byte
+	parts   map[string][][]byte
+	mx      sync.Mutex
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mx.Lock()
+	defer f.mx.Unlock()
+
+	if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=key/") {
+		w.WriteHeader(http.StatusForbidden)
+
+		return
+	}
+
+	body, _ := io.ReadAll(r.Body)
+	sum := sha256.Sum256(body)
+
+	if r.Header.Get("X-Amz-Content-Sha256") != hex.EncodeToString(sum[:]) {
+		w.WriteHeader(http.StatusBadRequest)
+
+		return
+	}
+
+	uploadID := r.URL.Query().Get("uploadId")
+
+	switch r.Method {
+	case http.MethodPost:
+		if r.URL.Query().Has("uploads") {
+			if f.parts == nil {
+				f.parts = map[string][][]byte{}
+			}
+
+			f.parts[r.URL.Path] = nil
+			_, _ = w.Write([]byte("<InitiateMultipartUploadResult><UploadId>" + r.URL.Path +
+				"</UploadId></InitiateMultipartUploadResult>"))
+
+			return
+		}
+
+		f.objects[r.URL.Path] = bytes.Join(f.parts[uploadID], nil)
+		delete(f.parts, uploadID)
+	case http.MethodPut:
+		if uploadID != "" {
+			f.parts[uploadID] = append(f.parts[uploadID], body)
+			w.Header().Set("ETag", strconv.Itoa(len(f.parts[uploadID])))
+
+			return
+		}
+
+		f.objects[r.URL.Path] = body
+	case http.MethodDelete:
+		if uploadID != "" {
+			delete(f.parts, uploadID)
+
+			return
+		}
+
+		delete(f.objects, r.URL.Path)
+	case http.MethodGet:
+		if r.URL.Query().Get("list-type") == "2" {
+			f.list(w, r)
+
+			return
+		}
+
+		body, ok := f.objects[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+
+			return
+		}
+
+		_, _ = w.Write(body)
+	}
+}
+
+func (f *fakeS3) list(w http.ResponseWriter, r *http.Request) {
+	prefix := r.URL.Path + "/" + r.URL.Query().Get("prefix")
+	keys := []string{}
+
+	for key := range f.objects {
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, "<Contents><Key>"+strings.TrimPrefix(key, r.URL.Path+"/")+"</Key></Contents>")
+		}
+	}
+
+	_, _ = w.Write([]byte("<ListBucketResult>" + strings.Join(keys, "") + "</ListBucketResult>"))
+}
+
+func TestS3Store(t *testing.T) {
+	ctx := context.Background()
+	fake := &fakeS3{objects: map[string][]byte{}}
+	server := httptest.NewServer(fake)
+
+	t.Cleanup(server.Close)
+
+	s3 := &rtkv.S3Store{
+		Endpoint:        server.URL,
+		Region:          "eu-west-1",
+		Bucket:          "backups",
+		Prefix:          "rtkv",
+		AccessKeyID:     "key",
+		SecretAccessKey: "secret",
+	}
+
+	require.NoError(t, s3.Put(ctx, "snap/part 1", strings.NewReader("hello")))
+	assert.Contains(t, fake.objects, "/backups/rtkv/snap/part 1")
+
+	body, err := s3.Get(ctx, "snap/part 1")
+	require.NoError(t, err)
+
+	data, err := io.ReadAll(body)
+
+	require.NoError(t, err)
+	require.NoError(t, body.Close())
+	assert.Equal(t, "hello", string(data))
+
+	_, err = s3.Get(ctx, "snap/missing")
+
+	require.ErrorIs(t, err, fs.ErrNotExist)
+
+	require.NoError(t, s3.Put(ctx, "other/part 1", strings.NewReader("x")))
+
+	names, err := s3.List(ctx, "snap/")
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"snap/part 1"}, names)
+
+	require.NoError(t, s3.Delete(ctx, "snap/part 1"))
+	assert.NotContains(t, fake.objects, "/backups/rtkv/snap/part 1")
+
+	s3.AccessKeyID = "other"
+
+	require.ErrorContains(t, s3.Put(ctx, "snap/part 2", strings.NewReader("x")), "403")
+}
+
+func TestS3Store_Multipart(t *testing.T) {
+	ctx := context.Background()
+	fake := &fakeS3{objects: map[string][]byte{}}
+	server := httptest.NewServer(fake)
+
+	t.Cleanup(server.Close)
+
+	s3 := &rtkv.S3Store{Endpoint: server.URL, Bucket: "b", AccessKeyID: "key", PartSize: 4}
+
+	for _, data := range []string{"0123", "0123456789", "01234567"} {
+		require.NoError(t, s3.Put(ctx, data, strings.NewReader(data)))
+
+		body, err := s3.Get(ctx, data)
+		require.NoError(t, err)
+
+		got, err := io.ReadAll(body)
+
+		require.NoError(t, err)
+		require.NoError(t, body.Close())
+		assert.Equal(t, data, string(got))
+	}
+
+	assert.Empty(t, fake.parts)
+
+	err := s3.Put(ctx, "failing", io.MultiReader(strings.NewReader("01234"), iotest.ErrReader(io.ErrClosedPipe)))
+
+	require.ErrorIs(t, err, io.ErrClosedPipe)
+	assert.Empty(t, fake.parts)
+	assert.NotContains(t, fake.objects, "/b/failing")
+}
+
+func TestRedisTKV_ExportSnapshot_S3(t *testing.T) {
+	ctx := context.Background()
+	store := goRedisSetup(t, 100)
+	server := httptest.NewServer(&fakeS3{objects: map[string][]byte{}})
+
+	t.Cleanup(server.Close)
+
+	s3 := &rtkv.S3Store{Endpoint: server.URL, Bucket: "b", AccessKeyID: "key"}
+
+	_, err := store.ExportSnapshot(ctx, s3, "backup", rtkv.SnapshotOptions{})
+	require.NoError(t, err)
+
+	dst := rtkv.NewRedisTKV(rtkv.DelimUnit, t.Name()+"import", newGoRedisClient(0))
+
+	imported, err := dst.ImportSnapshot(ctx, s3, "backup", rtkv.TransferOptions{})
+
+	require.NoError(t, err)
+	assert.Equal(t, 100, imported)
+}
@@ -0,0 +1,215 @@
+// GNU AFFERO GENERAL PUBLIC LICENSE
+// Version 3, 19 November 2007
+//
+// Copyright (C) 2025 John Kleijn
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// For more details, see the full AGPL-3.0 license at:
+// https://www.gnu.org/licenses/agpl-3.0.html
+
+package rtkv
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// OpResult collects the cost of the calls made with a context
+// returned by WithResult, so applications can attach it to their own
+// request telemetry. Counters accumulate over all calls made with the
+// context; read them once the calls have returned.
+type OpResult struct {
+	// RoundTrips is the number of round trips to Redis. A pipeline
+	// or transaction counts as one. Commands setting up new
+	// connections are included.
+	RoundTrips int64
+
+	// Commands is the number of commands sent.
+	Commands int64
+
+	// Retries is the number of commands or transactions the store
+	// repeated, such as after an update conflict or a lost script.
+	Retries int64
+
+	// BytesOut is the approximate size of the command arguments sent.
+	BytesOut int64
+
+	// BytesIn is the size of the string values received.
+	BytesIn int64
+
+	// CacheHits is the number of results served
+	// without Redis, from the totals cache.
+	CacheHits int64
+
+	// RedisTime is the time spent waiting on Redis.
+	RedisTime time.Duration
+}
+
+type opResultCtxKey struct{}
+
+// WithResult returns a context that makes calls of stores created
+// with WithOpResults record their cost into res. Counters are updated
+// atomically, so res may be shared by concurrent calls.
+func WithResult(ctx context.Context, res *OpResult) context.Context {
+	return context.WithValue(ctx, opResultCtxKey{}, res)
+}
+
+// WithOpResults makes the store record the cost of calls made with a
+// context returned by WithResult. Commands sent to read replicas, and
+// reads merged by WithReadBatching, are not recorded.
+func WithOpResults() Option {
+	return func(r *RedisTKV) {
+		// Hooks can't be removed, so the hook is added to a new
+		// client rather than the caller's.
+		r.client = cloneClient(r.client)
+		r.client.AddHook(opResultHook{})
+	}
+}
+
+// opResultFrom returns the OpResult attached to ctx, or nil.
+// All OpResult methods are safe to call on nil.
+func opResultFrom(ctx context.Context) *OpResult {
+	res, _ := ctx.Value(opResultCtxKey{}).(*OpResult)
+
+	return res
+}
+
+func (o *OpResult) retry() {
+	if o == nil {
+		return
+	}
+
+	atomic.AddInt64(&o.Retries, 1)
+}
+
+func (o *OpResult) cacheHit() {
+	if o == nil {
+		return
+	}
+
+	atomic.AddInt64(&o.CacheHits, 1)
+}
+
+// record records a round trip of cmds that started at start.
+func (o *OpResult) record(start time.Time, cmds ...redis.Cmder) {
+	var out, in int64
+
+	for _, cmd := range cmds {
+		for _, arg := range cmd.Args() {
+			out += int64(argSize(arg))
+		}
+
+		in += int64(replySize(cmd))
+	}
+
+	atomic.AddInt64(&o.RoundTrips, 1)
+	atomic.AddInt64(&o.Commands, int64(len(cmds)))
+	atomic.AddInt64(&o.BytesOut, out)
+	atomic.AddInt64(&o.BytesIn, in)
+	atomic.AddInt64((*int64)(&o.RedisTime), int64(time.Since(start)))
+}
+
+func argSize(arg any) int {
+	switch v := arg.(type) {
+	case string:
+		return len(v)
+	case []byte:
+		return len(v)
+	default:
+		return len(fmt.Sprint(v))
+	}
+}
+
+// replySize returns the size of the string values in the reply of cmd.
+func replySize(cmd redis.Cmder) int {
+	switch cmd := cmd.(type) {
+	case *redis.StringCmd:
+		return len(cmd.Val())
+	case *redis.StringSliceCmd:
+		return valueSize(cmd.Val())
+	case *redis.SliceCmd:
+		return valueSize(cmd.Val())
+	case *redis.Cmd:
+		return valueSize(cmd.Val())
+	default:
+		return 0
+	}
+}
+
+func valueSize(value any) int {
+	switch v := value.(type) {
+	case string:
+		return len(v)
+	case []string:
+		var n int
+
+		for _, s := range v {
+			n += len(s)
+		}
+
+		return n
+	case []any:
+		var n int
+
+		for _, e := range v {
+			n += valueSize(e)
+		}
+
+		return n
+	default:
+		return 0
+	}
+}
+
+// opResultHook is the redis.Hook that records round trips.
+type opResultHook struct{}
+
+func (opResultHook) DialHook(next redis.DialHook) redis.DialHook {
+	return next
+}
+
+func (opResultHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		res := opResultFrom(ctx)
+		if res == nil {
+			return next(ctx, cmd)
+		}
+
+		start := time.Now()
+		err := next(ctx, cmd)
+		res.record(start, cmd)
+
+		return err
+	}
+}
+
+func (opResultHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		res := opResultFrom(ctx)
+		if res == nil {
+			return next(ctx, cmds)
+		}
+
+		start := time.Now()
+		err := next(ctx, cmds)
+		res.record(start, cmds...)
+
+		return err
+	}
+}
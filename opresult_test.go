@@ -0,0 +1,93 @@
+// GNU AFFERO GENERAL PUBLIC LICENSE
+// Version 3, 19 November 2007
+//
+// Copyright (C) 2025 John Kleijn
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// For more details, see the full AGPL-3.0 license at:
+// https://www.gnu.org/licenses/agpl-3.0.html
+
+package rtkv_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/johnknl/rtkv"
+	"github.com/johnknl/rtkv/rtkvtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithResult(t *testing.T) {
+	_, client := rtkvtest.NewMiniredis(t)
+	store := rtkv.NewRedisTKV(rtkv.DelimUnit, t.Name(), client,
+		rtkv.WithOpResults(),
+		rtkv.WithTotalsCache(time.Minute),
+	)
+	value := strings.Repeat("x", 100)
+
+	// Set up the connection, which takes round trips of its own.
+	_, err := store.Get(context.Background(), "a")
+	require.NoError(t, err)
+
+	var set rtkv.OpResult
+
+	_, err = store.Set(rtkv.WithResult(context.Background(), &set), []byte(value), time.Now(), "a")
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, set.RoundTrips, "Set should take a single transaction")
+	assert.Greater(t, set.Commands, int64(1))
+	assert.Greater(t, set.BytesOut, int64(len(value)))
+	assert.Positive(t, set.RedisTime)
+
+	var get rtkv.OpResult
+
+	ctx := rtkv.WithResult(context.Background(), &get)
+
+	_, err = store.Get(ctx, "a")
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, get.RoundTrips)
+	assert.EqualValues(t, len(value), get.BytesIn)
+
+	for range 2 {
+		_, err = store.Count(ctx)
+		require.NoError(t, err)
+	}
+
+	assert.EqualValues(t, 2, get.RoundTrips, "The second count should be cached")
+	assert.EqualValues(t, 1, get.CacheHits)
+
+	_, err = store.Get(context.Background(), "a")
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, get.RoundTrips, "Calls without the result should not be recorded")
+}
+
+func TestWithResult_Retries(t *testing.T) {
+	_, client := rtkvtest.NewMiniredis(t)
+	store := rtkv.NewRedisTKV(rtkv.DelimUnit, t.Name(), client, rtkv.WithOpResults())
+	ctx := context.Background()
+
+	_, err := store.SetIfNewer(ctx, []byte("v"), time.Now(), "a")
+	require.NoError(t, err)
+	require.NoError(t, client.ScriptFlush(ctx).Err())
+
+	var res rtkv.OpResult
+
+	_, err = store.SetIfNewer(rtkv.WithResult(ctx, &res), []byte("w"), time.Now(), "a")
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, res.Retries, "Reloading a lost script should count as a retry")
+}
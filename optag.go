@@ -0,0 +1,69 @@
+// GNU AFFERO GENERAL PUBLIC LICENSE
+// Version 3, 19 November 2007
+//
+// Copyright (C) 2025 John Kleijn
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// For more details, see the full AGPL-3.0 license at:
+// https://www.gnu.org/licenses/agpl-3.0.html
+
+package rtkv
+
+import (
+	"context"
+	"time"
+)
+
+type opTagCtxKey struct{}
+
+// WithOpTag returns a context that tags operations made with it,
+// so Stats and slow operation logs can be broken down by caller.
+// Use it to attribute load to jobs sharing a store.
+func WithOpTag(ctx context.Context, tag string) context.Context {
+	return context.WithValue(ctx, opTagCtxKey{}, tag)
+}
+
+// OpTag returns the op tag of ctx, or an empty string.
+func OpTag(ctx context.Context) string {
+	tag, _ := ctx.Value(opTagCtxKey{}).(string)
+
+	return tag
+}
+
+// WithSlowOpThreshold logs a warning, including the op tag,
+// for every operation that takes longer than threshold.
+func WithSlowOpThreshold(threshold time.Duration) Option {
+	return func(r *RedisTKV) {
+		r.slowOpThreshold = threshold
+	}
+}
+
+// observe records an operation that started at start.
+// Call it deferred at the top of an operation.
+func (r *RedisTKV) observe(ctx context.Context, op string, start time.Time) {
+	took := time.Since(start)
+	slow := r.slowOpThreshold > 0 && took > r.slowOpThreshold
+
+	r.stats.recordOp(ctx, took, slow)
+
+	if slow {
+		r.logger.WarnContext(ctx, "slow operation",
+			"namespace", r.namespace,
+			"op", op,
+			"op_tag", OpTag(ctx),
+			"duration", took,
+		)
+	}
+}
@@ -0,0 +1,65 @@
+// GNU AFFERO GENERAL PUBLIC LICENSE
+// Version 3, 19 November 2007
+//
+// Copyright (C) 2025 John Kleijn
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// For more details, see the full AGPL-3.0 license at:
+// https://www.gnu.org/licenses/agpl-3.0.html
+
+package rtkv_test
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/johnknl/rtkv"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithOpTag(t *testing.T) {
+	ctx := context.Background()
+	tagged := rtkv.WithOpTag(ctx, "sync-job")
+
+	var logs bytes.Buffer
+
+	store := newRTKV(t, newGoRedisClient(0)).With(
+		rtkv.WithLogger(slog.New(slog.NewTextHandler(&logs, nil))),
+		rtkv.WithSlowOpThreshold(time.Nanosecond),
+	)
+
+	_, err := store.Set(tagged, []byte("v"), time.Now(), "a")
+	require.NoError(t, err)
+
+	_, err = store.Get(tagged, "a")
+	require.NoError(t, err)
+
+	_, err = store.Set(ctx, []byte("w"), time.Now(), "b")
+	require.NoError(t, err)
+
+	stats := store.Stats()
+
+	assert.EqualValues(t, 3, stats.Ops.Count)
+	assert.EqualValues(t, 2, stats.Writes.Sets)
+	require.Contains(t, stats.Tags, "sync-job")
+	assert.EqualValues(t, 2, stats.Tags["sync-job"].Ops.Count)
+	assert.EqualValues(t, 2, stats.Tags["sync-job"].Ops.Slow)
+	assert.EqualValues(t, 1, stats.Tags["sync-job"].Writes.Sets)
+	assert.Contains(t, logs.String(), "op_tag=sync-job")
+}
@@ -0,0 +1,62 @@
+// GNU AFFERO GENERAL PUBLIC LICENSE
+// Version 3, 19 November 2007
+//
+// Copyright (C) 2025 John Kleijn
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// For more details, see the full AGPL-3.0 license at:
+// https://www.gnu.org/licenses/agpl-3.0.html
+
+package rtkv
+
+import "log/slog"
+
+// Option configures optional behaviour of a RedisTKV.
+type Option func(*RedisTKV)
+
+// WithLogger sets the logger used for warnings and background
+// errors. Defaults to slog.Default().
+func WithLogger(logger *slog.Logger) Option {
+	return func(r *RedisTKV) {
+		r.logger = logger
+	}
+}
+
+// WithNamespaceSeparator sets the separator between the namespace and
+// the ID in keys, which defaults to the ID delimiter. Together with a
+// namespace holding several levels joined by sep, such as
+// "legacy:users", this matches key layouts of other tools, like
+// "legacy:users:42" with ID delimiter "|" for composite IDs, so their
+// data can be adopted without a migration.
+func WithNamespaceSeparator(sep string) Option {
+	return func(r *RedisTKV) {
+		r.nsSeparator = sep
+	}
+}
+
+// With returns a shallow copy of the store with the given options
+// applied on top of the existing ones. The copy shares the Redis
+// client, namespace and loaded scripts with the original, so strict
+// and relaxed variants of a namespace can be derived from a single
+// setup.
+func (r *RedisTKV) With(opts ...Option) *RedisTKV {
+	clone := *r
+
+	for _, opt := range opts {
+		opt(&clone)
+	}
+
+	return &clone
+}
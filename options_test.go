@@ -0,0 +1,121 @@
+// GNU AFFERO GENERAL PUBLIC LICENSE
+// Version 3, 19 November 2007
+//
+// Copyright (C) 2025 John Kleijn
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// For more details, see the full AGPL-3.0 license at:
+// https://www.gnu.org/licenses/agpl-3.0.html
+
+package rtkv_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/johnknl/rtkv"
+	"github.com/johnknl/rtkv/rtkvtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedisTKV_With(t *testing.T) {
+	ctx := context.Background()
+
+	redisClient := newGoRedisClient(0)
+
+	t.Cleanup(func() {
+		redisClient.FlushDB(ctx).Err()
+	})
+
+	mismatches := make(chan rtkv.ShadowMismatch, 2)
+
+	store := rtkv.NewRedisTKV(rtkv.DelimUnit, t.Name(), redisClient)
+	shadowed := store.With(rtkv.WithShadowReads(
+		rtkv.NewRedisTKV(rtkv.DelimUnit, t.Name()+"shadow", redisClient),
+		1,
+		func(_ context.Context, m rtkv.ShadowMismatch) {
+			mismatches <- m
+		},
+	))
+
+	now := time.Now()
+
+	_, err := shadowed.Set(ctx, []byte(`{"id": "a"}`), now, "a")
+	require.NoError(t, err)
+
+	data, err := store.Get(ctx, "a")
+	require.NoError(t, err)
+	assert.Equalf(t, []byte(`{"id": "a"}`), data, "Clone should share the namespace")
+
+	select {
+	case <-mismatches:
+		t.Fatal("Original store should not have shadow reads enabled")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	_, err = shadowed.Get(ctx, "a")
+	require.NoError(t, err)
+
+	select {
+	case m := <-mismatches:
+		assert.Equal(t, []string{"a"}, m.ID)
+	case <-time.After(time.Second):
+		t.Fatal("Clone should have shadow reads enabled")
+	}
+
+	_, total, err := shadowed.FetchPageConsistent(ctx, nil, nil, 0, 10)
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, total)
+
+	_, total, err = store.FetchPageConsistent(ctx, nil, nil, 0, 10)
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, total)
+}
+
+func TestWithNamespaceSeparator(t *testing.T) {
+	ctx := context.Background()
+	mr, client := rtkvtest.NewMiniredis(t)
+	store := rtkv.NewRedisTKV(rtkv.DelimPipe, "legacy:users", client, rtkv.WithNamespaceSeparator(":"))
+
+	require.NoError(t, mr.Set("legacy:users:42", "existing"))
+
+	value, err := store.Get(ctx, "42")
+	require.NoError(t, err)
+	assert.Equal(t, "existing", string(value), "Keys of other tools should be readable as is")
+
+	_, err = store.Set(ctx, []byte("value"), time.Now(), "a", "1")
+	require.NoError(t, err)
+	assert.True(t, mr.Exists("legacy:users:a|1"))
+
+	var ids [][]string
+
+	for id, err := range store.IDs(ctx) {
+		require.NoError(t, err)
+
+		ids = append(ids, id)
+	}
+
+	assert.ElementsMatch(t, [][]string{{"42"}, {"a", "1"}}, ids)
+
+	records, _, err := store.FetchPageRecords(ctx, nil, nil, 0, 10)
+	require.NoError(t, err)
+
+	for record, err := range records {
+		require.NoError(t, err)
+		assert.Equal(t, []string{"a", "1"}, record.ID)
+	}
+}
@@ -40,31 +40,34 @@ func Paginate(
 	from, to *time.Time, //nolint:varnamelen // from and to are clear
 	offset, limit int,
 ) (iter.Seq2[[]byte, error], error) {
-	it, total, err := pageFn(ctx, from, to, offset, limit)
+	it, total, err := callPageFunc(ctx, pageFn, from, to, offset, limit)
 	if err != nil {
 		return nil, fmt.Errorf("fetching first page failed: %w", err)
 	}
 
-	if int(total) <= limit {
-		return it, nil
-	}
-
-	var b []byte
-
 	return func(yield func([]byte, error) bool) {
+		pages := pageErrors{yield: yield}
+
 		for {
-			for b, err = range it {
-				if !yield(b, err) {
-					return
-				}
+			more, err := yieldAll(it, pages.yieldValue)
+			if err != nil {
+				_ = yield(nil, err)
+
+				return
+			}
+
+			if !more || pages.failed {
+				return
 			}
 
 			offset += limit
 			if offset >= int(total) {
+				pages.yieldSkipped()
+
 				return
 			}
 
-			it, total, err = pageFn(ctx, from, to, offset, limit)
+			it, total, err = callPageFunc(ctx, pageFn, from, to, offset, limit)
 			if err != nil {
 				_ = yield(nil, fmt.Errorf("fetching next page failed: %w", err))
 				return
@@ -72,3 +75,15 @@ func Paginate(
 		}
 	}, nil
 }
+
+// callPageFunc calls pageFn, converting a panic into an error.
+func callPageFunc(
+	ctx context.Context,
+	pageFn PageFunc,
+	from, to *time.Time, //nolint:varnamelen // from and to are clear
+	offset, limit int,
+) (it iter.Seq2[[]byte, error], total int64, err error) {
+	defer recoverPanic(&err)
+
+	return pageFn(ctx, from, to, offset, limit)
+}
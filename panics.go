@@ -0,0 +1,85 @@
+// GNU AFFERO GENERAL PUBLIC LICENSE
+// Version 3, 19 November 2007
+//
+// Copyright (C) 2025 John Kleijn
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// For more details, see the full AGPL-3.0 license at:
+// https://www.gnu.org/licenses/agpl-3.0.html
+
+package rtkv
+
+import (
+	"errors"
+	"fmt"
+	"iter"
+	"runtime/debug"
+)
+
+// ErrPanic is wrapped by errors returned when a callback panicked.
+var ErrPanic = errors.New("callback panicked")
+
+// PanicError is returned in place of a panic raised by a callback,
+// such as a PageFunc, BatchFunc, UpdateManyFunc or JanitorTask, so
+// it can't take down background goroutines or leave them leaked.
+type PanicError struct {
+	Value any
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("callback panicked: %v", e.Value)
+}
+
+func (e *PanicError) Unwrap() error {
+	return ErrPanic
+}
+
+// recoverPanic stores a recovered panic in err as a *PanicError.
+// It must be deferred directly.
+func recoverPanic(err *error) {
+	if v := recover(); v != nil {
+		*err = &PanicError{Value: v, Stack: debug.Stack()}
+	}
+}
+
+// yieldAll yields every element of it, converting panics raised by
+// it into an error. Panics raised by yield belong to the consumer
+// and are propagated unchanged. Returns false if yield returned false.
+func yieldAll(it iter.Seq2[[]byte, error], yield func([]byte, error) bool) (more bool, err error) {
+	inYield := false
+
+	defer func() {
+		if v := recover(); v != nil {
+			if inYield {
+				panic(v)
+			}
+
+			err = &PanicError{Value: v, Stack: debug.Stack()}
+		}
+	}()
+
+	for b, err := range it {
+		inYield = true
+		more := yield(b, err)
+		inYield = false
+
+		if !more {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
@@ -0,0 +1,96 @@
+// GNU AFFERO GENERAL PUBLIC LICENSE
+// Version 3, 19 November 2007
+//
+// Copyright (C) 2025 John Kleijn
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// For more details, see the full AGPL-3.0 license at:
+// https://www.gnu.org/licenses/agpl-3.0.html
+
+package rtkv_test
+
+import (
+	"bytes"
+	"context"
+	"iter"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/johnknl/rtkv"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPaginate_PanicIsolation(t *testing.T) {
+	ctx := context.Background()
+	pageFn := func(_ context.Context, _, _ *time.Time, offset, _ int) (iter.Seq2[[]byte, error], int64, error) {
+		if offset > 0 {
+			panic("broken loader")
+		}
+
+		return func(yield func([]byte, error) bool) {
+			yield([]byte("a"), nil)
+		}, 2, nil
+	}
+
+	it, err := rtkv.Paginate(ctx, pageFn, nil, nil, 0, 1)
+	require.NoError(t, err)
+
+	var errs []error
+
+	for _, err := range it {
+		errs = append(errs, err)
+	}
+
+	require.Len(t, errs, 2)
+	require.ErrorIs(t, errs[1], rtkv.ErrPanic)
+
+	it, err = rtkv.Paginate(ctx, pageFn, nil, nil, 0, 1)
+	require.NoError(t, err)
+
+	assert.PanicsWithValue(t, "consumer", func() {
+		for range it {
+			panic("consumer")
+		}
+	}, "panics in the consumer should propagate")
+}
+
+func TestJanitor_PanicIsolation(t *testing.T) {
+	var logs bytes.Buffer
+
+	store := newRTKV(t, newGoRedisClient(0)).With(
+		rtkv.WithLogger(slog.New(slog.NewTextHandler(&logs, nil))),
+	)
+
+	janitor := rtkv.NewJanitor(store, time.Hour, func(context.Context, *rtkv.RedisTKV) error {
+		panic("broken task")
+	})
+
+	assert.NotPanics(t, func() { janitor.RunOnce(context.Background()) })
+	assert.Contains(t, logs.String(), "broken task")
+}
+
+func TestRedisTKV_UpdateMany_PanicIsolation(t *testing.T) {
+	store := goRedisSetup(t, 10)
+
+	_, err := store.UpdateMany(context.Background(), [][]string{{"entity", "1"}},
+		func([]string, []byte) ([]byte, bool, error) {
+			panic("broken update")
+		},
+	)
+
+	require.ErrorIs(t, err, rtkv.ErrPanic)
+}
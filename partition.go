@@ -0,0 +1,206 @@
+// GNU AFFERO GENERAL PUBLIC LICENSE
+// Version 3, 19 November 2007
+//
+// Copyright (C) 2025 John Kleijn
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// For more details, see the full AGPL-3.0 license at:
+// https://www.gnu.org/licenses/agpl-3.0.html
+
+package rtkv
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// ErrInvalidPartitions is returned by ExportPartitioned for
+// options that don't match the number of writers.
+var ErrInvalidPartitions = errors.New("invalid partitions")
+
+// PartitionOptions controls ExportPartitioned.
+type PartitionOptions struct {
+	// Transfer controls the mode, batches and compression of every
+	// part. Its State is ignored in favour of States.
+	Transfer TransferOptions
+
+	// Boundaries, if set, partitions entities by lastModified time:
+	// part 0 holds those last modified before Boundaries[0], part i
+	// those from Boundaries[i-1] up to Boundaries[i], and the last
+	// part the rest. There must be one writer more than boundaries.
+	// Otherwise, entities are partitioned by a hash of their ID.
+	Boundaries []time.Time
+
+	// States track the progress of every part. Pass the states of
+	// an interrupted export to resume the unfinished parts. If set,
+	// there must be a state per writer.
+	States []*BatchState
+}
+
+// ExportPartitioned exports all entities like Export, split over
+// writers, which are written concurrently. Every writer receives a
+// complete snapshot of its part that Import can read on its own.
+// Returns the number of entities exported.
+//
+// Partitioning by time reads only the index entries of each part,
+// while partitioning by ID hash reads the whole index once per part,
+// but spreads entities evenly regardless of when they were written.
+// Parts are independent: a failing part doesn't stop the others, and
+// can be resumed with its state.
+func (r *RedisTKV) ExportPartitioned(ctx context.Context, writers []io.Writer, opts PartitionOptions) (int, error) {
+	if len(opts.Boundaries) > 0 && len(opts.Boundaries)+1 != len(writers) {
+		return 0, fmt.Errorf("%w: %d boundaries for %d writers", ErrInvalidPartitions, len(opts.Boundaries), len(writers))
+	}
+
+	if len(opts.States) > 0 && len(opts.States) != len(writers) {
+		return 0, fmt.Errorf("%w: %d states for %d writers", ErrInvalidPartitions, len(opts.States), len(writers))
+	}
+
+	var (
+		exported atomic.Int64
+		wg       sync.WaitGroup
+	)
+
+	errs := make([]error, len(writers))
+
+	for i, w := range writers {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			n, err := r.exportPart(ctx, w, i, len(writers), opts)
+			exported.Add(int64(n))
+
+			if err != nil {
+				errs[i] = fmt.Errorf("part %d failed: %w", i, err)
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	return int(exported.Load()), errors.Join(errs...)
+}
+
+// exportPart exports part i of n to w.
+func (r *RedisTKV) exportPart(ctx context.Context, w io.Writer, i, n int, opts PartitionOptions) (int, error) {
+	var state *BatchState
+
+	if len(opts.States) > 0 {
+		state = opts.States[i]
+	}
+
+	enc, err := newSnapshotEncoder(w, opts.Transfer.Compression)
+	if err != nil {
+		return 0, err
+	}
+
+	var exported int
+
+	count, read := r.partitionReader(i, n, opts.Boundaries)
+
+	err = r.transferEntries(ctx, opts.Transfer, state, count, read, func(_ context.Context, records []snapshotRecord) error {
+		for j := range records {
+			if err := enc.encode(records[j]); err != nil {
+				return err
+			}
+
+			exported++
+		}
+
+		return nil
+	})
+	if err != nil {
+		return exported, err
+	}
+
+	return exported, enc.close()
+}
+
+// partitionReader returns the functions counting and reading
+// the index entries of part i of n.
+func (r *RedisTKV) partitionReader(i, n int, boundaries []time.Time) (func(context.Context) (int64, error), entryReader) {
+	index := r.namespacedKey(lastModifiedIdxSuffix)
+
+	if len(boundaries) == 0 {
+		count := func(ctx context.Context) (int64, error) {
+			return r.client.ZCard(ctx, index).Result() //nolint:wrapcheck // wrapped by transferEntries
+		}
+
+		read := func(ctx context.Context, offset, size int) ([]redis.Z, int, error) {
+			entries, err := r.client.ZRangeWithScores(ctx, index, int64(offset), int64(offset+size-1)).Result()
+			if err != nil {
+				return nil, 0, err //nolint:wrapcheck // wrapped by transferEntries
+			}
+
+			kept := entries[:0]
+
+			for _, entry := range entries {
+				if partitionOf(entry.Member.(string), n) == i {
+					kept = append(kept, entry)
+				}
+			}
+
+			return kept, len(entries), nil
+		}
+
+		return count, read
+	}
+
+	rangeMin, rangeMax := "-inf", "+inf"
+
+	if i > 0 {
+		rangeMin = strconv.FormatInt(boundaries[i-1].UnixNano(), 10)
+	}
+
+	if i < len(boundaries) {
+		rangeMax = "(" + strconv.FormatInt(boundaries[i].UnixNano(), 10)
+	}
+
+	count := func(ctx context.Context) (int64, error) {
+		return r.client.ZCount(ctx, index, rangeMin, rangeMax).Result() //nolint:wrapcheck // wrapped by transferEntries
+	}
+
+	read := func(ctx context.Context, offset, size int) ([]redis.Z, int, error) {
+		entries, err := r.client.ZRangeByScoreWithScores(ctx, index, &redis.ZRangeBy{
+			Min:    rangeMin,
+			Max:    rangeMax,
+			Offset: int64(offset),
+			Count:  int64(size),
+		}).Result()
+
+		return entries, len(entries), err //nolint:wrapcheck // wrapped by transferEntries
+	}
+
+	return count, read
+}
+
+// partitionOf returns the ID hash partition of key.
+func partitionOf(key string, n int) int {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(key))
+
+	return int(h.Sum32() % uint32(n)) //nolint:gosec // n is a small positive number
+}
@@ -0,0 +1,113 @@
+// GNU AFFERO GENERAL PUBLIC LICENSE
+// Version 3, 19 November 2007
+//
+// Copyright (C) 2025 John Kleijn
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// For more details, see the full AGPL-3.0 license at:
+// https://www.gnu.org/licenses/agpl-3.0.html
+
+package rtkv_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/johnknl/rtkv"
+	"github.com/johnknl/rtkv/rtkvtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newPartitionedStore(t *testing.T, n int) (*rtkv.RedisTKV, time.Time) {
+	t.Helper()
+
+	store := rtkvtest.NewMiniredisTKV(t)
+	start := time.Unix(1700000000, 0)
+
+	for i := range n {
+		_, err := store.Set(context.Background(), []byte(strconv.Itoa(i)), start.Add(time.Duration(i)*time.Second), strconv.Itoa(i))
+		require.NoError(t, err)
+	}
+
+	return store, start
+}
+
+func exportParts(t *testing.T, store *rtkv.RedisTKV, n int, opts rtkv.PartitionOptions) []*bytes.Buffer {
+	t.Helper()
+
+	parts := make([]*bytes.Buffer, n)
+	writers := make([]io.Writer, n)
+
+	for i := range parts {
+		parts[i] = &bytes.Buffer{}
+		writers[i] = parts[i]
+	}
+
+	exported, err := store.ExportPartitioned(context.Background(), writers, opts)
+	require.NoError(t, err)
+	assert.Equal(t, 50, exported)
+
+	return parts
+}
+
+func TestRedisTKV_ExportPartitioned_ByIDHash(t *testing.T) {
+	ctx := context.Background()
+	store, _ := newPartitionedStore(t, 50)
+	parts := exportParts(t, store, 3, rtkv.PartitionOptions{Transfer: rtkv.TransferOptions{Batch: rtkv.BatchOptions{Size: 7}}})
+	dst := rtkvtest.NewMiniredisTKV(t)
+
+	for _, part := range parts {
+		n, err := dst.Import(ctx, part, rtkv.TransferOptions{})
+		require.NoError(t, err)
+		assert.Positive(t, n, "Every part should hold some entities")
+	}
+
+	count, err := dst.Count(ctx)
+	require.NoError(t, err)
+	assert.EqualValues(t, 50, count)
+
+	value, err := dst.Get(ctx, "42")
+	require.NoError(t, err)
+	assert.Equal(t, "42", string(value))
+}
+
+func TestRedisTKV_ExportPartitioned_ByTime(t *testing.T) {
+	ctx := context.Background()
+	store, start := newPartitionedStore(t, 50)
+	states := []*rtkv.BatchState{{}, {}, {}}
+	parts := exportParts(t, store, 3, rtkv.PartitionOptions{
+		Boundaries: []time.Time{start.Add(10 * time.Second), start.Add(40 * time.Second)},
+		States:     states,
+	})
+
+	for i, want := range []int{10, 30, 10} {
+		dst := rtkvtest.NewMiniredisTKV(t)
+
+		n, err := dst.Import(ctx, parts[i], rtkv.TransferOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, want, n)
+		assert.True(t, states[i].Done)
+	}
+
+	_, err := store.ExportPartitioned(ctx, []io.Writer{io.Discard}, rtkv.PartitionOptions{
+		Boundaries: []time.Time{start},
+	})
+	require.ErrorIs(t, err, rtkv.ErrInvalidPartitions)
+}
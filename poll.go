@@ -0,0 +1,159 @@
+// GNU AFFERO GENERAL PUBLIC LICENSE
+// Version 3, 19 November 2007
+//
+// Copyright (C) 2025 John Kleijn
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// For more details, see the full AGPL-3.0 license at:
+// https://www.gnu.org/licenses/agpl-3.0.html
+
+package rtkv
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"time"
+)
+
+const (
+	defaultPollLimit       = 100
+	defaultPollMinInterval = 100 * time.Millisecond
+	defaultPollMaxInterval = 30 * time.Second
+	defaultPollJitter      = 0.2
+)
+
+// PollOptions configures PollChanges.
+type PollOptions struct {
+	// From is the lastModified time to start polling from, if
+	// Cursor is empty. The zero value starts at the oldest entity.
+	From time.Time
+
+	// Cursor resumes polling after the entity it was returned for.
+	Cursor string
+
+	// Limit is the maximum number of records passed to the
+	// PollFunc at once. Defaults to 100.
+	Limit int
+
+	// MinInterval is the wait after a poll that found changes,
+	// doubled after every poll that found none, up to MaxInterval.
+	// Full pages are followed up without waiting. Defaults to
+	// 100ms and 30s.
+	MinInterval time.Duration
+	MaxInterval time.Duration
+
+	// Jitter randomizes every wait by up to this fraction, so
+	// consumers started together don't poll in lockstep.
+	// Defaults to 0.2.
+	Jitter float64
+}
+
+// PollFunc handles the records found by a poll. cursor resumes
+// polling after the last of the records; persist it to continue
+// where a consumer left off after a restart. Returning an error
+// stops polling.
+type PollFunc func(ctx context.Context, records []Record, cursor string) error
+
+// PollChanges polls for entities written after opts.From or
+// opts.Cursor, oldest first, and passes them to fn until ctx is
+// done or fn or a poll fails. Polls back off while nothing changes
+// and speed up again once changes flow. An entity written again
+// before it was polled is passed once, with its latest value.
+//
+// Bad values are handled according to the iterator error policy;
+// skipped values are not reported. Returns the error that stopped
+// polling, or ctx.Err().
+func (r *RedisTKV) PollChanges(ctx context.Context, opts PollOptions, fn PollFunc) error {
+	opts = opts.withDefaults()
+	cursor := opts.Cursor
+	interval := opts.MinInterval
+
+	var from *time.Time
+	if !opts.From.IsZero() {
+		from = &opts.From
+	}
+
+	for {
+		page, err := r.fetchPageAfter(ctx, from, nil, cursor, opts.Limit)
+		if err != nil {
+			return err
+		}
+
+		records := make([]Record, 0, len(page.keys))
+
+		for record, err := range r.yieldRecords(page.keys, page.scores, page.values) {
+			if err != nil && !errors.Is(err, ErrValuesSkipped) {
+				return err
+			}
+
+			if err == nil {
+				records = append(records, record)
+			}
+		}
+
+		if len(records) > 0 {
+			if err = fn(ctx, records, page.last); err != nil {
+				return err
+			}
+		}
+
+		cursor = page.last
+
+		switch {
+		case page.more:
+			interval = 0
+		case len(page.keys) > 0:
+			interval = opts.MinInterval
+		default:
+			interval = min(max(interval*2, opts.MinInterval), opts.MaxInterval) //nolint:mnd // exponential backoff
+		}
+
+		select {
+		case <-time.After(opts.jitter(interval)):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func (o PollOptions) withDefaults() PollOptions {
+	if o.Limit <= 0 {
+		o.Limit = defaultPollLimit
+	}
+
+	if o.MinInterval <= 0 {
+		o.MinInterval = defaultPollMinInterval
+	}
+
+	if o.MaxInterval < o.MinInterval {
+		o.MaxInterval = max(defaultPollMaxInterval, o.MinInterval)
+	}
+
+	if o.Jitter <= 0 {
+		o.Jitter = defaultPollJitter
+	}
+
+	return o
+}
+
+// jitter randomizes d by up to the jitter fraction either way.
+func (o PollOptions) jitter(d time.Duration) time.Duration {
+	if d <= 0 {
+		return 0
+	}
+
+	return d + time.Duration((rand.Float64()*2-1)*o.Jitter*float64(d)) //nolint:gosec // jitter needs no crypto
+}
@@ -0,0 +1,108 @@
+// GNU AFFERO GENERAL PUBLIC LICENSE
+// Version 3, 19 November 2007
+//
+// Copyright (C) 2025 John Kleijn
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// For more details, see the full AGPL-3.0 license at:
+// https://www.gnu.org/licenses/agpl-3.0.html
+
+package rtkv_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/johnknl/rtkv"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedisTKV_PollChanges(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	store := newRTKV(t, newGoRedisClient(0))
+	base := time.Now()
+
+	_, err := store.Clear(ctx)
+	require.NoError(t, err)
+
+	_, err = store.Set(ctx, []byte("a"), base, "a")
+	require.NoError(t, err)
+
+	var (
+		mx     sync.Mutex
+		ids    []string
+		cursor string
+	)
+
+	done := make(chan error)
+
+	go func() {
+		done <- store.PollChanges(ctx, rtkv.PollOptions{
+			Limit:       2,
+			MinInterval: time.Millisecond,
+			MaxInterval: 10 * time.Millisecond,
+		}, func(_ context.Context, records []rtkv.Record, next string) error {
+			mx.Lock()
+			defer mx.Unlock()
+
+			for _, record := range records {
+				ids = append(ids, record.ID[0])
+			}
+
+			cursor = next
+
+			return nil
+		})
+	}()
+
+	for i, id := range []string{"b", "c", "d"} {
+		_, err = store.Set(ctx, []byte(id), base.Add(time.Duration(i+1)*time.Second), id)
+		require.NoError(t, err)
+	}
+
+	assert.EventuallyWithT(t, func(c *assert.CollectT) {
+		mx.Lock()
+		defer mx.Unlock()
+
+		assert.Equal(c, []string{"a", "b", "c", "d"}, ids)
+	}, time.Second, 5*time.Millisecond)
+
+	cancel()
+	require.ErrorIs(t, <-done, context.Canceled)
+
+	// Resuming from the cursor only returns later changes.
+	_, err = store.Set(context.Background(), []byte("e"), base.Add(time.Minute), "e")
+	require.NoError(t, err)
+
+	ctx, cancel = context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	errStop := errors.New("stop")
+
+	err = store.PollChanges(ctx, rtkv.PollOptions{Cursor: cursor},
+		func(_ context.Context, records []rtkv.Record, _ string) error {
+			require.Len(t, records, 1)
+			assert.Equal(t, []string{"e"}, records[0].ID)
+
+			return errStop
+		})
+	require.ErrorIs(t, err, errStop)
+}
@@ -0,0 +1,150 @@
+// GNU AFFERO GENERAL PUBLIC LICENSE
+// Version 3, 19 November 2007
+//
+// Copyright (C) 2025 John Kleijn
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// For more details, see the full AGPL-3.0 license at:
+// https://www.gnu.org/licenses/agpl-3.0.html
+
+package rtkv
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const prioritySuffix = "priority"
+
+type priorityCtxKey struct{}
+
+// WithPriority returns a context whose writes give entities the
+// given priority, for WithPriorities. Higher is more urgent.
+func WithPriority(ctx context.Context, priority int) context.Context {
+	return context.WithValue(ctx, priorityCtxKey{}, priority)
+}
+
+// Priority returns the priority of ctx, or 0.
+func Priority(ctx context.Context) int {
+	priority, _ := ctx.Value(priorityCtxKey{}).(int)
+
+	return priority
+}
+
+// WithPriorities gives every entity a priority from 0 up to levels-1,
+// set with WithPriority when writing it, so FetchPageByPriority can
+// page urgent changes ahead of bulk churn. Priorities outside the
+// range are clamped. Every priority has its own lastModified index,
+// so writes cost a command per level. Entities written before
+// priorities were enabled are not returned by FetchPageByPriority
+// until they are written again.
+func WithPriorities(levels int) Option {
+	return func(r *RedisTKV) {
+		r.priorities = max(levels, 1)
+	}
+}
+
+// FetchPageByPriority fetches a page of entities last modified in the
+// given range, most urgent first and by lastModified time within a
+// priority, and returns the total number of entities in the range.
+// Requires WithPriorities. Pages are not read atomically: an entity
+// whose priority changes mid-iteration may be skipped or returned
+// twice.
+func (r *RedisTKV) FetchPageByPriority(
+	ctx context.Context,
+	from, to *time.Time, //nolint:varnamelen // from and to are clear
+	offset, limit int,
+) (iter.Seq2[Record, error], int64, error) {
+	defer r.observe(ctx, "fetchPageByPriority", time.Now())
+
+	rangeMin, rangeMax := scoreRange(from, to)
+	counts := make([]*redis.IntCmd, r.priorities)
+	reader := r.reader(ctx)
+
+	_, err := reader.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for level := range r.priorities {
+			counts[level] = pipe.ZCount(ctx, r.priorityKey(level), rangeMin, rangeMax)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count: %w", err)
+	}
+
+	var (
+		total int64
+		page  indexPage
+	)
+
+	for _, count := range counts {
+		total += count.Val()
+	}
+
+	for level := r.priorities - 1; level >= 0 && len(page.keys) < limit; level-- {
+		count := int(counts[level].Val())
+		if offset >= count {
+			offset -= count
+
+			continue
+		}
+
+		levelPage, err := r.fetchIndexRange(ctx, r.priorityKey(level), rangeMin, rangeMax,
+			offset, limit-len(page.keys), true)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		offset = 0
+		page.keys = append(page.keys, levelPage.keys...)
+		page.scores = append(page.scores, levelPage.scores...)
+		page.values = append(page.values, levelPage.values...)
+	}
+
+	return r.yieldRecords(page.keys, page.scores, page.values), total, nil
+}
+
+// priorityAdd moves an entity to the index of the priority of ctx.
+func (r *RedisTKV) priorityAdd(ctx context.Context, pipe redis.Pipeliner, score float64, key string) {
+	if r.priorities == 0 {
+		return
+	}
+
+	current := min(max(Priority(ctx), 0), r.priorities-1)
+
+	for level := range r.priorities {
+		if level == current {
+			pipe.ZAdd(ctx, r.priorityKey(level), redis.Z{Score: score, Member: key})
+		} else {
+			pipe.ZRem(ctx, r.priorityKey(level), key)
+		}
+	}
+}
+
+// priorityRemove removes an entity from the priority indexes.
+func (r *RedisTKV) priorityRemove(ctx context.Context, pipe redis.Pipeliner, key string) {
+	for level := range r.priorities {
+		pipe.ZRem(ctx, r.priorityKey(level), key)
+	}
+}
+
+func (r *RedisTKV) priorityKey(level int) string {
+	return r.internalKey(prioritySuffix, strconv.Itoa(level))
+}
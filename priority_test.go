@@ -0,0 +1,83 @@
+// GNU AFFERO GENERAL PUBLIC LICENSE
+// Version 3, 19 November 2007
+//
+// Copyright (C) 2025 John Kleijn
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// For more details, see the full AGPL-3.0 license at:
+// https://www.gnu.org/licenses/agpl-3.0.html
+
+package rtkv_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/johnknl/rtkv"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedisTKV_FetchPageByPriority(t *testing.T) {
+	ctx := context.Background()
+	store := newRTKV(t, newGoRedisClient(0)).With(rtkv.WithPriorities(3))
+	base := time.Date(2025, 1, 10, 12, 0, 0, 0, time.UTC)
+
+	write := func(ctx context.Context, id string, offset time.Duration) {
+		t.Helper()
+
+		_, err := store.Set(ctx, []byte(id), base.Add(offset), id)
+		require.NoError(t, err)
+	}
+
+	write(ctx, "bulk1", 0)
+	write(ctx, "bulk2", time.Minute)
+	write(rtkv.WithPriority(ctx, 2), "urgent", 2*time.Minute)
+	write(rtkv.WithPriority(ctx, 1), "high", 3*time.Minute)
+	write(rtkv.WithPriority(ctx, 5), "moved", 4*time.Minute)
+	write(ctx, "moved", 5*time.Minute)
+
+	fetch := func(offset, limit int) (string, int64) {
+		t.Helper()
+
+		records, total, err := store.FetchPageByPriority(ctx, nil, nil, offset, limit)
+		require.NoError(t, err)
+
+		var ids []string
+
+		for record, err := range records {
+			require.NoError(t, err)
+
+			ids = append(ids, record.ID[0])
+		}
+
+		return strings.Join(ids, ","), total
+	}
+
+	ids, total := fetch(0, 10)
+	assert.Equal(t, "urgent,high,bulk1,bulk2,moved", ids)
+	assert.EqualValues(t, 5, total)
+
+	ids, _ = fetch(1, 2)
+	assert.Equal(t, "high,bulk1", ids)
+
+	require.NoError(t, store.Delete(ctx, "urgent"))
+
+	ids, total = fetch(0, 1)
+	assert.Equal(t, "high", ids)
+	assert.EqualValues(t, 4, total)
+}
@@ -0,0 +1,83 @@
+// GNU AFFERO GENERAL PUBLIC LICENSE
+// Version 3, 19 November 2007
+//
+// Copyright (C) 2025 John Kleijn
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// For more details, see the full AGPL-3.0 license at:
+// https://www.gnu.org/licenses/agpl-3.0.html
+
+// Package protocodec provides an rtkv.Codec that encodes protocol
+// buffer messages in their binary wire format.
+package protocodec
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/johnknl/rtkv"
+	"google.golang.org/protobuf/proto"
+)
+
+// ErrNotMessage is returned for values that aren't protocol buffer
+// messages.
+var ErrNotMessage = errors.New("value is not a proto message")
+
+// Codec encodes proto.Message values. Unmarshal accepts a message,
+// or a pointer to a message pointer, such as the *T of a
+// rtkv.Store[*pb.Message], which it allocates if nil.
+type Codec struct{}
+
+var _ rtkv.Codec = Codec{}
+
+func (Codec) Marshal(v any) ([]byte, error) {
+	m, ok := v.(proto.Message)
+	if !ok {
+		return nil, fmt.Errorf("%w: %T", ErrNotMessage, v)
+	}
+
+	return proto.Marshal(m) //nolint:wrapcheck // the caller adds context
+}
+
+func (Codec) Unmarshal(data []byte, v any) error {
+	m, ok := v.(proto.Message)
+	if !ok {
+		m, ok = allocMessage(v)
+	}
+
+	if !ok {
+		return fmt.Errorf("%w: %T", ErrNotMessage, v)
+	}
+
+	return proto.Unmarshal(data, m) //nolint:wrapcheck // the caller adds context
+}
+
+// allocMessage returns the message v points to, if v is a pointer
+// to a message pointer, allocating the message if needed.
+func allocMessage(v any) (proto.Message, bool) {
+	ptr := reflect.ValueOf(v)
+	if ptr.Kind() != reflect.Pointer || ptr.IsNil() || ptr.Elem().Kind() != reflect.Pointer {
+		return nil, false
+	}
+
+	if ptr.Elem().IsNil() {
+		ptr.Elem().Set(reflect.New(ptr.Elem().Type().Elem()))
+	}
+
+	m, ok := ptr.Elem().Interface().(proto.Message)
+
+	return m, ok
+}
@@ -0,0 +1,198 @@
+// GNU AFFERO GENERAL PUBLIC LICENSE
+// Version 3, 19 November 2007
+//
+// Copyright (C) 2025 John Kleijn
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// For more details, see the full AGPL-3.0 license at:
+// https://www.gnu.org/licenses/agpl-3.0.html
+
+package rtkv
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync/atomic"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// multiKeyLimit is the maximum number of keys per multi-key
+// command. It is shared between a store and its clones, so a
+// limit detected by one applies to all.
+type multiKeyLimit struct {
+	limit atomic.Int64
+}
+
+// proxyRejections are fragments of the errors proxies such as
+// Envoy and Twemproxy return for multi-key commands they can't
+// route.
+var proxyRejections = []string{ //nolint:gochecknoglobals // constant list
+	"CROSSSLOT",
+	"unsupported command",
+	"not supported",
+	"too many keys",
+}
+
+// WithMultiKeyLimit splits multi-key commands such as MGET and DEL
+// into pipelined commands of at most n keys each, for Redis proxies
+// that reject or limit multi-key commands. Delete and BulkDelete then
+// run as plain pipelines rather than MULTI/EXEC transactions.
+//
+// Without this option the limit is detected: when a proxy rejects a
+// multi-key command, the store retries it one key per command and
+// keeps doing so from then on.
+func WithMultiKeyLimit(n int) Option {
+	return func(r *RedisTKV) {
+		r.multiKey = &multiKeyLimit{}
+		r.multiKey.limit.Store(int64(n))
+	}
+}
+
+// withMultiKeyLimit runs fn with the multi-key limit configured or
+// detected, which is 0 if there is none. When there is none and fn
+// fails because a proxy rejected a multi-key command, the limit is
+// set to one key per command from then on, and fn runs again.
+func (r *RedisTKV) withMultiKeyLimit(ctx context.Context, fn func(limit int) error) error {
+	limit := int(r.multiKey.limit.Load())
+
+	err := fn(limit)
+	if err == nil || limit > 0 || !isProxyRejection(err) {
+		return err
+	}
+
+	r.logger.WarnContext(ctx, "multi-key command rejected, splitting into single-key commands",
+		"namespace", r.namespace,
+		"error", err,
+	)
+
+	r.multiKey.limit.Store(1)
+
+	return fn(1)
+}
+
+// mget gets the values of keys with MGET, splitting the keys into
+// pipelined commands when a multi-key limit is configured or detected.
+func (r *RedisTKV) mget(ctx context.Context, c redis.Cmdable, keys []string) ([]any, error) {
+	var values []any
+
+	err := r.withMultiKeyLimit(ctx, func(limit int) error {
+		if limit <= 0 || limit >= len(keys) {
+			var err error
+
+			values, err = c.MGet(ctx, keys...).Result()
+
+			return err //nolint:wrapcheck // wrapped by callers
+		}
+
+		cmds := make([]*redis.SliceCmd, 0, (len(keys)+limit-1)/limit)
+
+		_, err := c.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+			for start := 0; start < len(keys); start += limit {
+				cmds = append(cmds, pipe.MGet(ctx, keys[start:min(start+limit, len(keys))]...))
+			}
+
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("failed to execute split mget: %w", err)
+		}
+
+		values = make([]any, 0, len(keys))
+
+		for _, cmd := range cmds {
+			values = append(values, cmd.Val()...)
+		}
+
+		return nil
+	})
+
+	return values, err
+}
+
+// deleteEntities deletes entities and their index entries in a
+// transaction, and returns the number of keys deleted. Proxies reject
+// MULTI/EXEC, so when a multi-key limit is configured or detected, the
+// entities are deleted in a non-transactional pipeline instead, in
+// chunks of at most limit entities per DEL.
+func (r *RedisTKV) deleteEntities(ctx context.Context, ids [][]string) (int, error) {
+	keys := make([]string, len(ids))
+
+	for i, id := range ids {
+		keys[i] = r.namespacedKey(id...)
+	}
+
+	var deleted int
+
+	err := r.withMultiKeyLimit(ctx, func(limit int) error {
+		pipelined := r.client.TxPipelined
+		if limit > 0 {
+			pipelined = r.client.Pipelined
+		} else {
+			limit = max(len(ids), 1)
+		}
+
+		delRes := make([]*redis.IntCmd, 0, (len(ids)+limit-1)/limit)
+
+		_, err := pipelined(ctx, func(pipe redis.Pipeliner) error {
+			r.writerAdd(ctx, pipe)
+
+			for start := 0; start < len(ids); start += limit {
+				end := min(start+limit, len(ids))
+
+				delRes = append(delRes, pipe.Del(ctx, keys[start:end]...))
+
+				for i := start; i < end; i++ {
+					r.indexRemove(ctx, pipe, keys[i], ids[i])
+				}
+			}
+
+			return nil
+		})
+		if err != nil {
+			return err //nolint:wrapcheck // wrapped by callers
+		}
+
+		for _, res := range delRes {
+			deleted += int(res.Val())
+		}
+
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return deleted, r.compactedRemove(ctx, true, keys...)
+}
+
+func isProxyRejection(err error) bool {
+	var redisErr redis.Error
+	if !errors.As(err, &redisErr) {
+		return false
+	}
+
+	msg := redisErr.Error()
+
+	for _, fragment := range proxyRejections {
+		if strings.Contains(msg, fragment) {
+			return true
+		}
+	}
+
+	return false
+}
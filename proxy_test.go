@@ -0,0 +1,236 @@
+// GNU AFFERO GENERAL PUBLIC LICENSE
+// Version 3, 19 November 2007
+//
+// Copyright (C) 2025 John Kleijn
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// For more details, see the full AGPL-3.0 license at:
+// https://www.gnu.org/licenses/agpl-3.0.html
+
+package rtkv_test
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"github.com/johnknl/rtkv"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type proxyError string
+
+func (e proxyError) Error() string { return string(e) }
+
+func (proxyError) RedisError() {}
+
+// proxyHook rejects MGET and DEL commands with more than one key,
+// like a proxy that can't split multi-key commands.
+type proxyHook struct {
+	rejected int
+}
+
+func (h *proxyHook) reject(cmds ...redis.Cmder) error {
+	for _, cmd := range cmds {
+		if (cmd.Name() == "mget" || cmd.Name() == "del") && len(cmd.Args()) > 2 {
+			h.rejected++
+
+			err := proxyError("ERR CROSSSLOT Keys in request don't hash to the same slot")
+			cmd.SetErr(err)
+
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (*proxyHook) DialHook(next redis.DialHook) redis.DialHook { return next }
+
+func (h *proxyHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		if err := h.reject(cmd); err != nil {
+			return err
+		}
+
+		return next(ctx, cmd)
+	}
+}
+
+func (h *proxyHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		if err := h.reject(cmds...); err != nil {
+			return err
+		}
+
+		return next(ctx, cmds)
+	}
+}
+
+func TestRedisTKV_FetchPage_ProxyDetection(t *testing.T) {
+	ctx := context.Background()
+	goRedisSetup(t, 20)
+
+	hook := &proxyHook{}
+	client := newGoRedisClient(0)
+	client.AddHook(hook)
+
+	store := newRTKV(t, client)
+
+	for range 2 {
+		values, total, err := store.FetchPage(ctx, nil, nil, 0, 10)
+
+		require.NoError(t, err)
+		assert.EqualValues(t, 20, total)
+
+		var n int
+
+		for _, err := range values {
+			require.NoError(t, err)
+
+			n++
+		}
+
+		assert.Equal(t, 10, n)
+	}
+
+	assert.Equal(t, 1, hook.rejected, "the limit should be detected once")
+}
+
+func TestRedisTKV_BulkDelete_ProxyDetection(t *testing.T) {
+	ctx := context.Background()
+	goRedisSetup(t, 20)
+
+	hook := &proxyHook{}
+	client := newGoRedisClient(0)
+	client.AddHook(hook)
+
+	store := newRTKV(t, client)
+
+	for i := 0; i < 10; i += 5 {
+		var ids [][]string
+
+		for j := i; j < i+5; j++ {
+			ids = append(ids, []string{"entity", strconv.Itoa(j)})
+		}
+
+		require.NoError(t, store.BulkDelete(ctx, ids))
+	}
+
+	assert.Equal(t, 1, hook.rejected, "the limit should be detected once")
+
+	_, total, err := store.FetchPage(ctx, nil, nil, 0, 1)
+	require.NoError(t, err)
+	assert.EqualValues(t, 10, total)
+
+	exists, err := store.Exists(ctx, "entity", "0")
+	require.NoError(t, err)
+	assert.False(t, exists)
+}
+
+func TestWithMultiKeyLimit(t *testing.T) {
+	ctx := context.Background()
+	goRedisSetup(t, 20)
+
+	hook := &proxyHook{}
+	client := newGoRedisClient(0)
+	client.AddHook(hook)
+
+	store := newRTKV(t, client).With(rtkv.WithMultiKeyLimit(1))
+
+	values, _, err := store.FetchPage(ctx, nil, nil, 0, 10)
+
+	require.NoError(t, err)
+
+	var n int
+
+	for _, err := range values {
+		require.NoError(t, err)
+
+		n++
+	}
+
+	assert.Equal(t, 10, n)
+	assert.Zero(t, hook.rejected)
+}
+
+// keyCountHook records the largest number of keys passed to a
+// multi-key command, and whether MULTI was sent.
+type keyCountHook struct {
+	maxKeys int
+	multi   bool
+}
+
+func (h *keyCountHook) record(cmds ...redis.Cmder) {
+	for _, cmd := range cmds {
+		switch cmd.Name() {
+		case "multi":
+			h.multi = true
+		case "del", "unlink", "exists", "mget":
+			h.maxKeys = max(h.maxKeys, len(cmd.Args())-1)
+		}
+	}
+}
+
+func (*keyCountHook) DialHook(next redis.DialHook) redis.DialHook { return next }
+
+func (h *keyCountHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		h.record(cmd)
+
+		return next(ctx, cmd)
+	}
+}
+
+func (h *keyCountHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		h.record(cmds...)
+
+		return next(ctx, cmds)
+	}
+}
+
+func TestWithMultiKeyLimit_BulkDelete(t *testing.T) {
+	ctx := context.Background()
+	goRedisSetup(t, 20)
+
+	hook := &keyCountHook{}
+	client := newGoRedisClient(0)
+	client.AddHook(hook)
+
+	store := newRTKV(t, client).With(rtkv.WithMultiKeyLimit(2))
+
+	var ids [][]string
+
+	for i := range 5 {
+		ids = append(ids, []string{"entity", strconv.Itoa(i)})
+	}
+
+	require.NoError(t, store.BulkDelete(ctx, ids))
+	require.NoError(t, store.Delete(ctx, "entity", "5"))
+
+	assert.False(t, hook.multi, "Deletes should not run in a transaction")
+	assert.Equal(t, 2, hook.maxKeys, "No command should exceed the limit")
+
+	_, total, err := store.FetchPage(ctx, nil, nil, 0, 1)
+	require.NoError(t, err)
+	assert.EqualValues(t, 14, total)
+
+	exists, err := store.Exists(ctx, "entity", "4")
+	require.NoError(t, err)
+	assert.False(t, exists)
+}
@@ -0,0 +1,202 @@
+// GNU AFFERO GENERAL PUBLIC LICENSE
+// Version 3, 19 November 2007
+//
+// Copyright (C) 2025 John Kleijn
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// For more details, see the full AGPL-3.0 license at:
+// https://www.gnu.org/licenses/agpl-3.0.html
+
+package rtkv
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	pruneLockSuffix = "pruneLock"
+
+	defaultPruneLockTTL = time.Minute
+
+	// releaseLockScript deletes a lock if it is still held
+	// with the given token. Returns 1 if it was released.
+	releaseLockScript = `
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+  return redis.call("DEL", KEYS[1])
+end
+
+return 0
+`
+)
+
+// PrunerOptions controls a Pruner.
+type PrunerOptions struct {
+	// MaxAge is the age after which entities are deleted,
+	// based on their lastModified time. Must be positive.
+	MaxAge time.Duration
+
+	// Interval is the time between pruning runs.
+	// Defaults to one minute.
+	Interval time.Duration
+
+	// LockTTL is how long the lock that keeps other instances from
+	// pruning at the same time is held at most. It should exceed the
+	// duration of a run. Defaults to one minute.
+	LockTTL time.Duration
+
+	// OnPrune, if set, is called after every pruning attempt.
+	OnPrune func(PruneResult)
+}
+
+// PruneResult describes a single pruning attempt.
+type PruneResult struct {
+	Cutoff   time.Time
+	Deleted  int64
+	Started  time.Time
+	Duration time.Duration
+
+	// Skipped is set if another instance held the lock.
+	Skipped bool
+	Err     error
+}
+
+// Pruner periodically deletes entities older than a maximum age.
+// Pruners of the same namespace coordinate through a lock key, so
+// every application instance can run one and at most one of them
+// prunes at a time.
+type Pruner struct {
+	store   *RedisTKV
+	opts    PrunerOptions
+	janitor *Janitor
+}
+
+// NewPruner creates a pruner that deletes entities from store.
+// Returns ErrInvalidConfig if MaxAge isn't positive, as that
+// would delete every entity.
+func NewPruner(store *RedisTKV, opts PrunerOptions) (*Pruner, error) {
+	if opts.MaxAge <= 0 {
+		return nil, fmt.Errorf("%w: prune MaxAge must be positive, got %v", ErrInvalidConfig, opts.MaxAge)
+	}
+
+	if opts.LockTTL <= 0 {
+		opts.LockTTL = defaultPruneLockTTL
+	}
+
+	p := &Pruner{
+		store: store,
+		opts:  opts,
+	}
+
+	p.janitor = NewJanitor(store, opts.Interval, func(ctx context.Context, _ *RedisTKV) error {
+		return p.RunOnce(ctx).Err
+	})
+
+	return p, nil
+}
+
+// Start prunes in the background until Stop is called or ctx is done.
+func (p *Pruner) Start(ctx context.Context) {
+	p.janitor.Start(ctx)
+}
+
+// Stop stops the pruner and waits for a running prune to return.
+func (p *Pruner) Stop() {
+	p.janitor.Stop()
+}
+
+// Shutdown stops the pruner and waits for a running prune
+// to return until ctx is done.
+func (p *Pruner) Shutdown(ctx context.Context) error {
+	return p.janitor.Shutdown(ctx)
+}
+
+// RunOnce prunes in the calling goroutine, unless another
+// instance holds the lock.
+func (p *Pruner) RunOnce(ctx context.Context) PruneResult {
+	result := PruneResult{Started: time.Now()}
+	result.Cutoff = result.Started.Add(-p.opts.MaxAge)
+
+	release, err := p.lock(ctx)
+
+	switch {
+	case errors.Is(err, redis.Nil):
+		result.Skipped = true
+	case err != nil:
+		result.Err = err
+	default:
+		result.Deleted, result.Err = p.store.DeleteRange(ctx, nil, &result.Cutoff)
+
+		if err = release(); result.Err == nil {
+			result.Err = err
+		}
+	}
+
+	result.Duration = time.Since(result.Started)
+
+	if p.opts.OnPrune != nil {
+		p.opts.OnPrune(result)
+	}
+
+	return result
+}
+
+// lock takes the prune lock and returns a function that releases
+// it. Returns redis.Nil if the lock is held by another instance.
+func (p *Pruner) lock(ctx context.Context) (func() error, error) {
+	random := make([]byte, 16) //nolint:mnd // 128 bits
+	_, _ = rand.Read(random)
+	token := hex.EncodeToString(random)
+	key := p.store.internalKey(pruneLockSuffix)
+
+	ok, err := p.store.client.SetNX(ctx, key, token, p.opts.LockTTL).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to take prune lock: %w", err)
+	}
+
+	if !ok {
+		return nil, redis.Nil
+	}
+
+	return func() error {
+		ctx := context.WithoutCancel(ctx)
+
+		if _, err := p.store.evalScript(ctx, releaseLockScript, []string{key}, token); err != nil {
+			return fmt.Errorf("failed to release prune lock: %w", err)
+		}
+
+		return nil
+	}, nil
+}
+
+// PruneOlderThan deletes all entities last modified more than
+// maxAge ago. Returns the number of entities deleted. See
+// DeleteRange and Pruner. Returns ErrInvalidConfig if maxAge
+// isn't positive.
+func (r *RedisTKV) PruneOlderThan(ctx context.Context, maxAge time.Duration) (int64, error) {
+	if maxAge <= 0 {
+		return 0, fmt.Errorf("%w: prune maxAge must be positive, got %v", ErrInvalidConfig, maxAge)
+	}
+
+	cutoff := time.Now().Add(-maxAge)
+
+	return r.DeleteRange(ctx, nil, &cutoff)
+}
@@ -0,0 +1,105 @@
+// GNU AFFERO GENERAL PUBLIC LICENSE
+// Version 3, 19 November 2007
+//
+// Copyright (C) 2025 John Kleijn
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// For more details, see the full AGPL-3.0 license at:
+// https://www.gnu.org/licenses/agpl-3.0.html
+
+package rtkv_test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/johnknl/rtkv"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPruner(t *testing.T) {
+	ctx := context.Background()
+	client := newGoRedisClient(0)
+	store := newRTKV(t, client)
+	now := time.Now()
+
+	require.NoError(t, store.BulkSet(ctx, []rtkv.BulkSetRecord{
+		{LastModified: now.Add(-2 * time.Hour), ID: []string{"old"}, Data: []byte("old")},
+		{LastModified: now, ID: []string{"new"}, Data: []byte("new")},
+	}))
+
+	var runs atomic.Int32
+
+	pruner, err := rtkv.NewPruner(store, rtkv.PrunerOptions{
+		MaxAge:   time.Hour,
+		Interval: 5 * time.Millisecond,
+		OnPrune: func(rtkv.PruneResult) {
+			runs.Add(1)
+		},
+	})
+	require.NoError(t, err)
+
+	lockKey := t.Name() + rtkv.DelimUnit + "\x00rtkv:pruneLock"
+	require.NoError(t, client.Set(ctx, lockKey, "other", time.Minute).Err())
+
+	result := pruner.RunOnce(ctx)
+	require.NoError(t, result.Err)
+	assert.True(t, result.Skipped, "Pruning should be skipped while another instance holds the lock")
+
+	require.NoError(t, client.Del(ctx, lockKey).Err())
+
+	result = pruner.RunOnce(ctx)
+	require.NoError(t, result.Err)
+	assert.False(t, result.Skipped)
+	assert.EqualValues(t, 1, result.Deleted)
+	assert.WithinDuration(t, now.Add(-time.Hour), result.Cutoff, time.Second)
+
+	value, err := store.Get(ctx, "new")
+	require.NoError(t, err)
+	assert.Equal(t, "new", string(value))
+
+	exists, err := client.Exists(ctx, lockKey).Result()
+	require.NoError(t, err)
+	assert.Zero(t, exists, "The lock should be released")
+
+	pruner.Start(ctx)
+
+	assert.Eventually(t, func() bool {
+		return runs.Load() >= 4
+	}, time.Second, time.Millisecond)
+
+	pruner.Stop()
+}
+
+func TestPruner_ZeroMaxAge(t *testing.T) {
+	ctx := context.Background()
+	store := newRTKV(t, newGoRedisClient(0))
+
+	_, err := store.Set(ctx, []byte("a"), time.Now().Add(-time.Hour), "a")
+	require.NoError(t, err)
+
+	_, err = rtkv.NewPruner(store, rtkv.PrunerOptions{Interval: time.Millisecond})
+	require.ErrorIs(t, err, rtkv.ErrInvalidConfig)
+
+	_, err = store.PruneOlderThan(ctx, 0)
+	require.ErrorIs(t, err, rtkv.ErrInvalidConfig)
+
+	count, err := store.Count(ctx)
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, count, "A zero MaxAge should delete nothing")
+}
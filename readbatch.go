@@ -0,0 +1,148 @@
+// GNU AFFERO GENERAL PUBLIC LICENSE
+// Version 3, 19 November 2007
+//
+// Copyright (C) 2025 John Kleijn
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// For more details, see the full AGPL-3.0 license at:
+// https://www.gnu.org/licenses/agpl-3.0.html
+
+package rtkv
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const defaultReadBatchSize = 256
+
+// readBatcher collects concurrent Get calls into MGETs. It is
+// shared between a store and its clones.
+type readBatcher struct {
+	window  time.Duration
+	maxSize int
+	mx      sync.Mutex
+	pending map[redis.Cmdable]*readBatch // per reader
+}
+
+// readBatch is a set of keys read with a single MGET.
+type readBatch struct {
+	ctx     context.Context //nolint:containedctx // the context of the first caller
+	keys    []string
+	values  []any
+	err     error
+	flushed bool
+	done    chan struct{}
+}
+
+// WithReadBatching collects Get calls made by concurrent goroutines
+// within window into a single MGET of up to maxSize keys, to save
+// round trips when many entities are looked up at once. Every Get
+// waits for up to window, so keep it short, well below a millisecond
+// for latency sensitive services. A maxSize of zero defaults to 256.
+func WithReadBatching(window time.Duration, maxSize int) Option {
+	return func(r *RedisTKV) {
+		if maxSize <= 0 {
+			maxSize = defaultReadBatchSize
+		}
+
+		r.readBatches = &readBatcher{window: window, maxSize: maxSize, pending: map[redis.Cmdable]*readBatch{}}
+	}
+}
+
+// getValue reads the value at key, through the read batcher if
+// enabled and the read is eventually consistent. Returns redis.Nil
+// if the key doesn't exist.
+func (r *RedisTKV) getValue(ctx context.Context, key string) ([]byte, error) {
+	reader := r.reader(ctx)
+
+	if r.readBatches == nil || r.consistencyFor(ctx) == ConsistencyStrong {
+		return reader.Get(ctx, key).Bytes() //nolint:wrapcheck // wrapped by callers
+	}
+
+	batch, i, full := r.addRead(ctx, reader, key)
+	if full {
+		r.flushReads(reader, batch)
+	}
+
+	select {
+	case <-batch.done:
+	case <-ctx.Done():
+		return nil, fmt.Errorf("batched read interrupted: %w", context.Cause(ctx))
+	}
+
+	if batch.err != nil {
+		return nil, batch.err
+	}
+
+	s, ok := batch.values[i].(string)
+	if !ok {
+		return nil, redis.Nil
+	}
+
+	return s2b(s), nil
+}
+
+// addRead adds key to the pending batch of reader, starting a batch
+// if there is none. Returns the batch, the position of key in it and
+// whether the batch is full and should be flushed right away.
+func (r *RedisTKV) addRead(ctx context.Context, reader redis.Cmdable, key string) (*readBatch, int, bool) {
+	b := r.readBatches
+
+	b.mx.Lock()
+	defer b.mx.Unlock()
+
+	batch := b.pending[reader]
+	if batch == nil {
+		batch = &readBatch{ctx: context.WithoutCancel(ctx), done: make(chan struct{})}
+		b.pending[reader] = batch
+
+		time.AfterFunc(b.window, func() {
+			r.flushReads(reader, batch)
+		})
+	}
+
+	batch.keys = append(batch.keys, key)
+
+	return batch, len(batch.keys) - 1, len(batch.keys) >= b.maxSize
+}
+
+// flushReads reads the keys of batch, unless it was flushed already.
+func (r *RedisTKV) flushReads(reader redis.Cmdable, batch *readBatch) {
+	b := r.readBatches
+
+	b.mx.Lock()
+
+	if batch.flushed {
+		b.mx.Unlock()
+
+		return
+	}
+
+	batch.flushed = true
+
+	if b.pending[reader] == batch {
+		delete(b.pending, reader)
+	}
+
+	b.mx.Unlock()
+
+	batch.values, batch.err = r.mget(batch.ctx, reader, batch.keys)
+	close(batch.done)
+}
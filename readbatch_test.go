@@ -0,0 +1,98 @@
+// GNU AFFERO GENERAL PUBLIC LICENSE
+// Version 3, 19 November 2007
+//
+// Copyright (C) 2025 John Kleijn
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// For more details, see the full AGPL-3.0 license at:
+// https://www.gnu.org/licenses/agpl-3.0.html
+
+package rtkv_test
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/johnknl/rtkv"
+	"github.com/johnknl/rtkv/rtkvtest"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// readCounter counts the GET and MGET commands sent.
+type readCounter struct {
+	reads atomic.Int64
+}
+
+func (*readCounter) DialHook(next redis.DialHook) redis.DialHook { return next }
+
+func (h *readCounter) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		if cmd.Name() == "get" || cmd.Name() == "mget" {
+			h.reads.Add(1)
+		}
+
+		return next(ctx, cmd)
+	}
+}
+
+func (*readCounter) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return next
+}
+
+func TestRedisTKV_WithReadBatching(t *testing.T) {
+	ctx := context.Background()
+	_, client := rtkvtest.NewMiniredis(t)
+	counter := &readCounter{}
+	client.AddHook(counter)
+	store := rtkv.NewRedisTKV(rtkv.DelimUnit, t.Name(), client, rtkv.WithReadBatching(10*time.Millisecond, 16))
+
+	const n = 32
+
+	for i := range n {
+		_, err := store.Set(ctx, []byte(strconv.Itoa(i)), time.Now(), strconv.Itoa(i))
+		require.NoError(t, err)
+	}
+
+	before := counter.reads.Load()
+
+	var wg sync.WaitGroup
+
+	for i := range n + 1 {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			value, err := store.Get(ctx, strconv.Itoa(i))
+			assert.NoError(t, err)
+
+			if i == n {
+				assert.Nil(t, value, "Missing entities should read as nil")
+			} else {
+				assert.Equal(t, strconv.Itoa(i), string(value))
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	assert.LessOrEqual(t, counter.reads.Load()-before, int64(4), "Concurrent gets should share MGETs")
+}
@@ -0,0 +1,201 @@
+// GNU AFFERO GENERAL PUBLIC LICENSE
+// Version 3, 19 November 2007
+//
+// Copyright (C) 2025 John Kleijn
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// For more details, see the full AGPL-3.0 license at:
+// https://www.gnu.org/licenses/agpl-3.0.html
+
+package rtkv
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	lastReadIdxSuffix = "lastRead"
+
+	// readTrackerSweepSize is the number of throttled keys
+	// above which expired ones are swept.
+	readTrackerSweepSize = 100_000
+)
+
+// ColdEntity is an entity returned by FetchColdEntities.
+type ColdEntity struct {
+	ID []string
+
+	// LastRead is when the entity was last read or, if it
+	// was never read, when it was first written.
+	LastRead time.Time
+}
+
+// readTracker throttles lastRead index updates. It is shared
+// between a store and its clones.
+type readTracker struct {
+	interval time.Duration
+	seen     sync.Map // key -> last recorded read in Unix nanos
+	size     atomic.Int64
+	sweeping atomic.Bool
+}
+
+// WithReadTracking keeps a lastRead index of when entities were last
+// read with Get, BulkGet or GetWithLastModified, so entities nobody
+// reads can be found with FetchColdEntities. To limit the cost, the
+// index is updated at most once per interval per entity by each
+// process. Entities written while tracking is enabled are tracked
+// from their first write; older entities once read or written.
+func WithReadTracking(interval time.Duration) Option {
+	return func(r *RedisTKV) {
+		r.reads = &readTracker{interval: interval}
+	}
+}
+
+// FetchColdEntities returns a page of entities not read since
+// notReadSince, least recently read first, and the total number
+// of such entities.
+func (r *RedisTKV) FetchColdEntities(
+	ctx context.Context,
+	notReadSince time.Time,
+	offset, limit int,
+) ([]ColdEntity, int64, error) {
+	key := r.internalKey(lastReadIdxSuffix)
+	rangeMax := "(" + strconv.FormatInt(notReadSince.UnixNano(), 10)
+
+	total, err := r.client.ZCount(ctx, key, "-inf", rangeMax).Result()
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count: %w", err)
+	}
+
+	entries, err := r.client.ZRangeByScoreWithScores(ctx, key, &redis.ZRangeBy{
+		Min:    "-inf",
+		Max:    rangeMax,
+		Offset: int64(offset),
+		Count:  int64(limit),
+	}).Result()
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to execute zrangebyscore: %w", err)
+	}
+
+	cold := make([]ColdEntity, len(entries))
+
+	for i := range entries {
+		member, _ := entries[i].Member.(string)
+
+		cold[i] = ColdEntity{
+			ID:       r.idFromKey(member),
+			LastRead: time.Unix(0, int64(entries[i].Score)),
+		}
+	}
+
+	return cold, total, nil
+}
+
+// recordReads records reads of the entities at keys
+// in the lastRead index, if they are due.
+func (r *RedisTKV) recordReads(ctx context.Context, keys ...string) {
+	if r.reads == nil {
+		return
+	}
+
+	now := time.Now()
+	members := make([]redis.Z, 0, len(keys))
+
+	for _, key := range keys {
+		if r.reads.due(key, now) {
+			members = append(members, redis.Z{Score: float64(now.UnixNano()), Member: key})
+		}
+	}
+
+	if len(members) == 0 {
+		return
+	}
+
+	// Only update entities that are still tracked, so a read racing
+	// a delete doesn't bring back the index entry.
+	err := r.client.ZAddXX(ctx, r.internalKey(lastReadIdxSuffix), members...).Err()
+	if err != nil {
+		r.logger.WarnContext(ctx, "failed to record reads",
+			"namespace", r.namespace,
+			"error", err,
+		)
+	}
+}
+
+// readsAdd starts tracking reads of a newly written entity.
+func (r *RedisTKV) readsAdd(ctx context.Context, pipe redis.Pipeliner, key string) {
+	if r.reads == nil {
+		return
+	}
+
+	pipe.ZAddNX(ctx, r.internalKey(lastReadIdxSuffix), redis.Z{
+		Score:  float64(time.Now().UnixNano()),
+		Member: key,
+	})
+}
+
+// readsRemove stops tracking reads of a deleted entity.
+func (r *RedisTKV) readsRemove(ctx context.Context, pipe redis.Pipeliner, key string) {
+	if r.reads == nil {
+		return
+	}
+
+	pipe.ZRem(ctx, r.internalKey(lastReadIdxSuffix), key)
+}
+
+// due reports whether a read of key at now should be recorded,
+// and if so, marks it as recorded.
+func (t *readTracker) due(key string, now time.Time) bool {
+	nanos := now.UnixNano()
+
+	last, loaded := t.seen.Load(key)
+	if loaded && nanos-last.(int64) < int64(t.interval) { //nolint:forcetypeassert // only int64s are stored
+		return false
+	}
+
+	if !loaded && t.size.Add(1) > readTrackerSweepSize {
+		t.sweep(nanos)
+	}
+
+	t.seen.Store(key, nanos)
+
+	return true
+}
+
+// sweep forgets keys whose last recorded read is older than
+// the interval, as they are due anyway.
+func (t *readTracker) sweep(nanos int64) {
+	if !t.sweeping.CompareAndSwap(false, true) {
+		return
+	}
+
+	defer t.sweeping.Store(false)
+
+	t.seen.Range(func(key, last any) bool {
+		if nanos-last.(int64) >= int64(t.interval) { //nolint:forcetypeassert // only int64s are stored
+			t.seen.Delete(key)
+			t.size.Add(-1)
+		}
+
+		return true
+	})
+}
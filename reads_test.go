@@ -0,0 +1,88 @@
+// GNU AFFERO GENERAL PUBLIC LICENSE
+// Version 3, 19 November 2007
+//
+// Copyright (C) 2025 John Kleijn
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// For more details, see the full AGPL-3.0 license at:
+// https://www.gnu.org/licenses/agpl-3.0.html
+
+package rtkv_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/johnknl/rtkv"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithReadTracking(t *testing.T) {
+	ctx := context.Background()
+	store := newRTKV(t, newGoRedisClient(0)).With(rtkv.WithReadTracking(time.Hour))
+
+	for _, id := range []string{"a", "b", "c"} {
+		_, err := store.Set(ctx, []byte(id), time.Now(), id)
+		require.NoError(t, err)
+	}
+
+	time.Sleep(time.Millisecond)
+
+	cutoff := time.Now()
+
+	_, err := store.Get(ctx, "a")
+	require.NoError(t, err)
+
+	_, err = store.BulkGet(ctx, [][]string{{"b"}, {"missing"}})
+	require.NoError(t, err)
+
+	cold, total, err := store.FetchColdEntities(ctx, cutoff, 0, 10)
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, total)
+	require.Len(t, cold, 1)
+	assert.Equal(t, []string{"c"}, cold[0].ID)
+	assert.True(t, cold[0].LastRead.Before(cutoff))
+
+	require.NoError(t, store.Delete(ctx, "c"))
+
+	_, total, err = store.FetchColdEntities(ctx, time.Now().Add(time.Hour), 0, 10)
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, total)
+}
+
+func TestWithReadTracking_Throttle(t *testing.T) {
+	ctx := context.Background()
+	store := newRTKV(t, newGoRedisClient(0)).With(rtkv.WithReadTracking(time.Hour))
+
+	_, err := store.Set(ctx, []byte("v"), time.Now(), "a")
+	require.NoError(t, err)
+
+	_, err = store.Get(ctx, "a")
+	require.NoError(t, err)
+
+	time.Sleep(time.Millisecond)
+
+	cutoff := time.Now()
+
+	// Throttled, so the read is not recorded.
+	_, err = store.Get(ctx, "a")
+	require.NoError(t, err)
+
+	cold, _, err := store.FetchColdEntities(ctx, cutoff, 0, 10)
+	require.NoError(t, err)
+	require.Len(t, cold, 1)
+}
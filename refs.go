@@ -0,0 +1,253 @@
+// GNU AFFERO GENERAL PUBLIC LICENSE
+// Version 3, 19 November 2007
+//
+// Copyright (C) 2025 John Kleijn
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// For more details, see the full AGPL-3.0 license at:
+// https://www.gnu.org/licenses/agpl-3.0.html
+
+package rtkv
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	refsInSuffix  = "refsIn"
+	refsOutSuffix = "refsOut"
+)
+
+// ErrReferenced is returned when deleting an entity that is still
+// referenced by other entities and RefPolicyBlock is in effect.
+var ErrReferenced = errors.New("entity is referenced by other entities")
+
+// RefPolicy determines what happens when an entity that is
+// referenced by other entities is deleted.
+type RefPolicy int
+
+const (
+	// RefPolicyIgnore deletes the entity and leaves references to it
+	// dangling. Use DanglingRefs to find them. This is the default.
+	RefPolicyIgnore RefPolicy = iota
+
+	// RefPolicyBlock refuses to delete referenced entities.
+	RefPolicyBlock
+
+	// RefPolicyCascade also deletes all entities that directly or
+	// indirectly reference the deleted entity.
+	RefPolicyCascade
+)
+
+// WithRefPolicy sets how Delete treats references between entities
+// created with AddRef. With any policy other than RefPolicyIgnore,
+// Delete also removes the references the entity itself holds.
+func WithRefPolicy(policy RefPolicy) Option {
+	return func(r *RedisTKV) {
+		r.refPolicy = policy
+	}
+}
+
+// AddRef records that the entity from references the entity to.
+func (r *RedisTKV) AddRef(ctx context.Context, from, to []string) error {
+	_, err := r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.SAdd(ctx, r.refsKey(refsOutSuffix, from), r.namespacedKey(to...))
+		pipe.SAdd(ctx, r.refsKey(refsInSuffix, to), r.namespacedKey(from...))
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to add reference: %w", err)
+	}
+
+	return nil
+}
+
+// RemoveRef removes a reference created with AddRef.
+func (r *RedisTKV) RemoveRef(ctx context.Context, from, to []string) error {
+	_, err := r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.SRem(ctx, r.refsKey(refsOutSuffix, from), r.namespacedKey(to...))
+		pipe.SRem(ctx, r.refsKey(refsInSuffix, to), r.namespacedKey(from...))
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to remove reference: %w", err)
+	}
+
+	return nil
+}
+
+// Referrers returns the IDs of the entities that reference id.
+func (r *RedisTKV) Referrers(ctx context.Context, id ...string) ([][]string, error) {
+	return r.refIDs(ctx, r.refsKey(refsInSuffix, id))
+}
+
+// References returns the IDs of the entities referenced by id.
+func (r *RedisTKV) References(ctx context.Context, id ...string) ([][]string, error) {
+	return r.refIDs(ctx, r.refsKey(refsOutSuffix, id))
+}
+
+// DanglingRefs returns the IDs of entities referenced by id
+// that no longer exist.
+func (r *RedisTKV) DanglingRefs(ctx context.Context, id ...string) ([][]string, error) {
+	keys, err := r.client.SMembers(ctx, r.refsKey(refsOutSuffix, id)).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read references: %w", err)
+	}
+
+	exists := make([]*redis.IntCmd, len(keys))
+
+	_, err = r.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for i := range keys {
+			exists[i] = pipe.Exists(ctx, keys[i])
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to check references: %w", err)
+	}
+
+	var dangling [][]string
+
+	for i := range keys {
+		if exists[i].Val() == 0 {
+			dangling = append(dangling, r.idFromKey(keys[i]))
+		}
+	}
+
+	return dangling, nil
+}
+
+// deleteReferenced deletes an entity according to the store's
+// reference policy.
+func (r *RedisTKV) deleteReferenced(ctx context.Context, id []string) error {
+	ids := [][]string{id}
+
+	if r.refPolicy == RefPolicyCascade {
+		var err error
+
+		if ids, err = r.referrerClosure(ctx, id); err != nil {
+			return err
+		}
+	}
+
+	refsIn := r.refsKey(refsInSuffix, id)
+
+	err := r.client.Watch(ctx, func(tx *redis.Tx) error {
+		if r.refPolicy == RefPolicyBlock {
+			n, err := tx.SCard(ctx, refsIn).Result()
+			if err != nil {
+				return fmt.Errorf("failed to count referrers: %w", err)
+			}
+
+			if n > 0 {
+				return ErrReferenced
+			}
+		}
+
+		outs := make([][]string, len(ids))
+
+		for i := range ids {
+			out, err := tx.SMembers(ctx, r.refsKey(refsOutSuffix, ids[i])).Result()
+			if err != nil {
+				return fmt.Errorf("failed to read references: %w", err)
+			}
+
+			outs[i] = out
+		}
+
+		_, err := tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			r.writerAdd(ctx, pipe)
+
+			for i := range ids {
+				key := r.namespacedKey(ids[i]...)
+
+				pipe.Del(ctx, key, r.refsKey(refsInSuffix, ids[i]), r.refsKey(refsOutSuffix, ids[i]))
+				r.indexRemove(ctx, pipe, key, ids[i])
+
+				for _, target := range outs[i] {
+					pipe.SRem(ctx, r.refsKey(refsInSuffix, r.idFromKey(target)), key)
+				}
+			}
+
+			return nil
+		})
+
+		return err //nolint:wrapcheck // wrapped below
+	}, refsIn)
+	if err != nil {
+		return fmt.Errorf("failed to delete entity: %w", err)
+	}
+
+	r.stats.recordDeletes(ctx, len(ids))
+
+	keys := make([]string, len(ids))
+
+	for i := range ids {
+		keys[i] = r.namespacedKey(ids[i]...)
+	}
+
+	return r.compactedRemove(ctx, true, keys...)
+}
+
+// referrerClosure returns id and all entities that directly
+// or indirectly reference it.
+func (r *RedisTKV) referrerClosure(ctx context.Context, id []string) ([][]string, error) {
+	seen := map[string]bool{r.namespacedKey(id...): true}
+	result := [][]string{id}
+
+	for i := 0; i < len(result); i++ {
+		referrers, err := r.Referrers(ctx, result[i]...)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, referrer := range referrers {
+			key := r.namespacedKey(referrer...)
+
+			if !seen[key] {
+				seen[key] = true
+				result = append(result, referrer)
+			}
+		}
+	}
+
+	return result, nil
+}
+
+func (r *RedisTKV) refIDs(ctx context.Context, setKey string) ([][]string, error) {
+	keys, err := r.client.SMembers(ctx, setKey).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read references: %w", err)
+	}
+
+	ids := make([][]string, len(keys))
+
+	for i := range keys {
+		ids[i] = r.idFromKey(keys[i])
+	}
+
+	return ids, nil
+}
+
+func (r *RedisTKV) refsKey(suffix string, id []string) string {
+	return r.internalKey(suffix, id...)
+}
@@ -0,0 +1,120 @@
+// GNU AFFERO GENERAL PUBLIC LICENSE
+// Version 3, 19 November 2007
+//
+// Copyright (C) 2025 John Kleijn
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// For more details, see the full AGPL-3.0 license at:
+// https://www.gnu.org/licenses/agpl-3.0.html
+
+package rtkv_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/johnknl/rtkv"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedisTKV_Refs(t *testing.T) {
+	ctx := context.Background()
+
+	redisClient := newGoRedisClient(0)
+
+	t.Cleanup(func() {
+		redisClient.FlushDB(ctx).Err()
+	})
+
+	setup := func(t *testing.T, opts ...rtkv.Option) *rtkv.RedisTKV {
+		t.Helper()
+
+		store := rtkv.NewRedisTKV(rtkv.DelimUnit, t.Name(), redisClient, opts...)
+
+		for _, id := range []string{"order", "line", "product"} {
+			_, err := store.Set(ctx, []byte(id), time.Now(), id)
+			require.NoError(t, err)
+		}
+
+		require.NoError(t, store.AddRef(ctx, []string{"order"}, []string{"line"}))
+		require.NoError(t, store.AddRef(ctx, []string{"line"}, []string{"product"}))
+
+		return store
+	}
+
+	exists := func(t *testing.T, store *rtkv.RedisTKV, id string) bool {
+		t.Helper()
+
+		exists, err := store.Exists(ctx, id)
+		require.NoError(t, err)
+
+		return exists
+	}
+
+	t.Run("Ignore", func(t *testing.T) {
+		store := setup(t)
+
+		referrers, err := store.Referrers(ctx, "product")
+
+		require.NoError(t, err)
+		assert.Equal(t, [][]string{{"line"}}, referrers)
+
+		require.NoError(t, store.Delete(ctx, "product"))
+
+		dangling, err := store.DanglingRefs(ctx, "line")
+
+		require.NoError(t, err)
+		assert.Equal(t, [][]string{{"product"}}, dangling)
+
+		require.NoError(t, store.RemoveRef(ctx, []string{"line"}, []string{"product"}))
+
+		refs, err := store.References(ctx, "line")
+
+		require.NoError(t, err)
+		assert.Empty(t, refs)
+	})
+
+	t.Run("Block", func(t *testing.T) {
+		store := setup(t, rtkv.WithRefPolicy(rtkv.RefPolicyBlock))
+
+		require.ErrorIs(t, store.Delete(ctx, "product"), rtkv.ErrReferenced)
+		assert.True(t, exists(t, store, "product"))
+
+		require.NoError(t, store.Delete(ctx, "order"))
+
+		referrers, err := store.Referrers(ctx, "line")
+
+		require.NoError(t, err)
+		assert.Emptyf(t, referrers, "Deleting an entity should remove the references it holds")
+		require.NoError(t, store.Delete(ctx, "line"))
+	})
+
+	t.Run("Cascade", func(t *testing.T) {
+		store := setup(t, rtkv.WithRefPolicy(rtkv.RefPolicyCascade))
+
+		require.NoError(t, store.Delete(ctx, "product"))
+
+		for _, id := range []string{"order", "line", "product"} {
+			assert.Falsef(t, exists(t, store, id), "%s should be deleted", id)
+		}
+
+		_, total, err := store.FetchPage(ctx, nil, nil, 0, 10)
+
+		require.NoError(t, err)
+		assert.Zero(t, total)
+	})
+}
@@ -0,0 +1,189 @@
+// GNU AFFERO GENERAL PUBLIC LICENSE
+// Version 3, 19 November 2007
+//
+// Copyright (C) 2025 John Kleijn
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// For more details, see the full AGPL-3.0 license at:
+// https://www.gnu.org/licenses/agpl-3.0.html
+
+package rtkv
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+const defaultSchemaCacheFor = time.Minute
+
+// RegisteredSchema is a schema as returned by a schema registry.
+type RegisteredSchema struct {
+	Subject string `json:"subject"`
+	Version int    `json:"version"`
+	ID      int    `json:"id"`
+
+	// Type is AVRO, JSON or PROTOBUF. The registry
+	// omits it for AVRO schemas.
+	Type   string `json:"schemaType"`
+	Schema string `json:"schema"`
+}
+
+// ConfluentRegistry is a SchemaRegistry backed by the REST API of a
+// Confluent compatible schema registry. Values are validated against
+// the latest version of the subject of their namespace.
+type ConfluentRegistry struct {
+	// URL is the base URL of the registry.
+	URL string
+
+	// Username and Password, if set, are sent as basic auth.
+	Username string
+	Password string
+
+	// HTTPClient is used for requests. Defaults to http.DefaultClient.
+	HTTPClient *http.Client
+
+	// Subject maps a namespace to its subject. Defaults to the
+	// namespace followed by "-value", like the TopicNameStrategy
+	// of Kafka serializers.
+	Subject func(namespace string) string
+
+	// CacheFor is how long the latest schema of a subject is cached.
+	// Defaults to a minute.
+	CacheFor time.Duration
+
+	// Validator validates a value against a schema. Defaults to
+	// ValidateJSONSchema for JSON schemas, and rejects values of
+	// subjects with other schema types.
+	Validator func(schema RegisteredSchema, data []byte) error
+
+	mx    sync.Mutex
+	cache map[string]cachedSchema
+}
+
+type cachedSchema struct {
+	schema  RegisteredSchema
+	fetched time.Time
+}
+
+// Validate validates data against the latest schema of the
+// subject of namespace.
+func (c *ConfluentRegistry) Validate(ctx context.Context, namespace string, data []byte) error {
+	subject := namespace + "-value"
+	if c.Subject != nil {
+		subject = c.Subject(namespace)
+	}
+
+	schema, err := c.Latest(ctx, subject)
+	if err != nil {
+		return err
+	}
+
+	if c.Validator != nil {
+		return c.Validator(schema, data)
+	}
+
+	if schema.Type != "JSON" {
+		return fmt.Errorf("no validator for %s schema of subject %s", schema.typeName(), subject) //nolint:err113 // configuration error
+	}
+
+	return ValidateJSONSchema(schema.Schema, data)
+}
+
+// Latest returns the latest schema of subject.
+func (c *ConfluentRegistry) Latest(ctx context.Context, subject string) (RegisteredSchema, error) {
+	cacheFor := c.CacheFor
+	if cacheFor <= 0 {
+		cacheFor = defaultSchemaCacheFor
+	}
+
+	c.mx.Lock()
+	cached, ok := c.cache[subject]
+	c.mx.Unlock()
+
+	if ok && time.Since(cached.fetched) < cacheFor {
+		return cached.schema, nil
+	}
+
+	schema, err := c.fetchLatest(ctx, subject)
+	if err != nil {
+		return schema, err
+	}
+
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	if c.cache == nil {
+		c.cache = map[string]cachedSchema{}
+	}
+
+	c.cache[subject] = cachedSchema{schema: schema, fetched: time.Now()}
+
+	return schema, nil
+}
+
+func (c *ConfluentRegistry) fetchLatest(ctx context.Context, subject string) (RegisteredSchema, error) {
+	var schema RegisteredSchema
+
+	target := c.URL + "/subjects/" + url.PathEscape(subject) + "/versions/latest"
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return schema, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Accept", "application/vnd.schemaregistry.v1+json")
+
+	if c.Username != "" {
+		req.SetBasicAuth(c.Username, c.Password)
+	}
+
+	client := c.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return schema, fmt.Errorf("failed to fetch schema of %s: %w", subject, err)
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<10)) //nolint:mnd // enough for an error message
+
+		return schema, fmt.Errorf("failed to fetch schema of %s: %s: %s", subject, resp.Status, msg) //nolint:err113 // no sentinel
+	}
+
+	if err = json.NewDecoder(resp.Body).Decode(&schema); err != nil {
+		return schema, fmt.Errorf("failed to decode schema of %s: %w", subject, err)
+	}
+
+	return schema, nil
+}
+
+func (s RegisteredSchema) typeName() string {
+	if s.Type == "" {
+		return "AVRO"
+	}
+
+	return s.Type
+}
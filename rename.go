@@ -0,0 +1,184 @@
+// GNU AFFERO GENERAL PUBLIC LICENSE
+// Version 3, 19 November 2007
+//
+// Copyright (C) 2025 John Kleijn
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// For more details, see the full AGPL-3.0 license at:
+// https://www.gnu.org/licenses/agpl-3.0.html
+
+package rtkv
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// ErrExists is returned when writing to an ID that is taken.
+var ErrExists = errors.New("entity already exists")
+
+// renameScript renames an entity, moving its index entries along
+// with it. Returns -1 if the new key exists, 0 if the old key
+// doesn't, otherwise the lastModified score of the entity.
+const renameScript = `
+local old = KEYS[1] -- the old entity key
+local new = KEYS[2] -- the new entity key
+local index = KEYS[3] -- the lastModified index
+local versions = KEYS[4] -- the versions hash
+local oldHistory = KEYS[5] -- the history of the old key
+local newHistory = KEYS[6] -- the history of the new key
+local historyKeys = KEYS[7] -- the keys with a history
+local compacted = KEYS[8] -- the epoch of every compacted entity
+local inShard = ARGV[1] == "1" -- whether the last key is the shard holding the entity
+
+if redis.call("EXISTS", new) == 1 then
+  return -1
+end
+
+if redis.call("EXISTS", old) == 0 then
+  return 0
+end
+
+redis.call("RENAME", old, new)
+
+local score = redis.call("ZSCORE", index, old)
+local epoch = redis.call("HGET", compacted, old)
+
+if epoch then
+  redis.call("HDEL", compacted, old)
+  redis.call("HSET", compacted, new, epoch)
+else
+  score = score or "0"
+  redis.call("ZREM", index, old)
+  redis.call("ZADD", index, score, new)
+end
+
+local version = redis.call("HGET", versions, old)
+if version then
+  redis.call("HDEL", versions, old)
+  redis.call("HSET", versions, new, version + 1)
+end
+
+if redis.call("EXISTS", oldHistory) == 1 then
+  redis.call("RENAME", oldHistory, newHistory)
+  redis.call("SREM", historyKeys, old)
+  redis.call("SADD", historyKeys, new)
+end
+
+for i = 9, #KEYS do -- the secondary sorted set indexes
+  local member = redis.call("ZSCORE", KEYS[i], old)
+  if member then
+    redis.call("ZREM", KEYS[i], old)
+    redis.call("ZADD", KEYS[i], member, new)
+
+    if inShard and i == #KEYS then
+      score = member
+    end
+  end
+end
+
+return score or "0"
+`
+
+// Rename moves an entity to a new ID, keeping its value, TTL,
+// lastModified time and history, and returns ErrNotFound if it
+// doesn't exist or ErrExists if the new ID is taken. The rename is
+// atomic, unlike a copy and delete, and a compacted entity stays in
+// its archive shard. The reference policy is not
+// applied, and aliases of the old ID are left dangling. The change
+// feed records a delete of the old ID and a set of the new one.
+func (r *RedisTKV) Rename(ctx context.Context, oldID, newID []string) error {
+	defer r.observe(ctx, "rename", time.Now())
+
+	if err := r.checkID(oldID); err != nil {
+		return err
+	}
+
+	if err := r.checkID(newID); err != nil {
+		return err
+	}
+
+	if err := r.checkWritable(ctx); err != nil {
+		return err
+	}
+
+	oldKey, newKey := r.namespacedKey(oldID...), r.namespacedKey(newID...)
+
+	epoch, err := r.compactedEpoch(ctx, oldKey)
+	if err != nil {
+		return err
+	}
+
+	keys := []string{
+		oldKey,
+		newKey,
+		r.namespacedKey(lastModifiedIdxSuffix),
+		r.internalKey(versionsSuffix),
+		r.historyKey(oldID),
+		r.historyKey(newID),
+		r.internalKey(historyKeysSuffix),
+		r.internalKey(compactedSuffix, membersSuffix),
+		r.internalKey(expirySuffix),
+		r.internalKey(lastReadIdxSuffix),
+		r.internalKey(idIdxSuffix),
+	}
+
+	for level := range r.priorities {
+		keys = append(keys, r.priorityKey(level))
+	}
+
+	if epoch != "" {
+		keys = append(keys, r.internalKey(compactedSuffix, epoch))
+	}
+
+	result, err := r.evalScript(ctx, renameScript, keys, epoch != "")
+	if err != nil {
+		return fmt.Errorf("failed to rename entity: %w", err)
+	}
+
+	switch result {
+	case int64(-1):
+		return ErrExists
+	case int64(0):
+		return ErrNotFound
+	}
+
+	rawScore, _ := result.(string)
+	score, _ := strconv.ParseFloat(rawScore, 64)
+
+	_, err = r.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		r.writerAdd(ctx, pipe)
+
+		if r.childIndex {
+			r.childSetsRemove(ctx, pipe, oldKey, oldID)
+			r.childSetsAdd(ctx, pipe, newKey, newID)
+		}
+
+		r.changeAdd(ctx, pipe, ChangeDelete, oldID, time.Now().UnixNano())
+		r.changeAdd(ctx, pipe, ChangeSet, newID, int64(score))
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update indexes: %w", err)
+	}
+
+	return nil
+}
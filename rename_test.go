@@ -0,0 +1,79 @@
+// GNU AFFERO GENERAL PUBLIC LICENSE
+// Version 3, 19 November 2007
+//
+// Copyright (C) 2025 John Kleijn
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// For more details, see the full AGPL-3.0 license at:
+// https://www.gnu.org/licenses/agpl-3.0.html
+
+package rtkv_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/johnknl/rtkv"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedisTKV_Rename(t *testing.T) {
+	ctx := context.Background()
+	client := newGoRedisClient(0)
+	store := newRTKV(t, client).With(rtkv.WithChildIndex(), rtkv.WithVersioning(), rtkv.WithIDIndex())
+	lastModified := time.Date(2025, 1, 10, 12, 0, 0, 0, time.UTC)
+
+	_, err := store.Clear(ctx)
+	require.NoError(t, err)
+
+	_, err = store.SetWithTTL(ctx, []byte("order"), lastModified, time.Hour, "orders", "tmp-1")
+	require.NoError(t, err)
+
+	_, err = store.Set(ctx, []byte("other"), lastModified, "orders", "2")
+	require.NoError(t, err)
+
+	require.NoError(t, store.Rename(ctx, []string{"orders", "tmp-1"}, []string{"orders", "1"}))
+
+	value, got, err := store.GetWithLastModified(ctx, "orders", "1")
+	require.NoError(t, err)
+	assert.Equal(t, "order", string(value))
+	assert.WithinDuration(t, lastModified, got, time.Microsecond)
+	assert.Positive(t, client.PTTL(ctx, t.Name()+rtkv.DelimUnit+"orders"+rtkv.DelimUnit+"1").Val())
+
+	value, err = store.Get(ctx, "orders", "tmp-1")
+	require.NoError(t, err)
+	assert.Nil(t, value)
+
+	count, err := store.Count(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+
+	children, err := store.GetChildren(ctx, "orders")
+	require.NoError(t, err)
+	require.Len(t, children, 2)
+	assert.ElementsMatch(t, [][]string{{"orders", "1"}, {"orders", "2"}}, [][]string{children[0].ID, children[1].ID})
+
+	version, err := store.Version(ctx, "orders", "1")
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), version)
+
+	err = store.Rename(ctx, []string{"orders", "1"}, []string{"orders", "2"})
+	require.ErrorIs(t, err, rtkv.ErrExists)
+
+	err = store.Rename(ctx, []string{"orders", "tmp-1"}, []string{"orders", "3"})
+	require.ErrorIs(t, err, rtkv.ErrNotFound)
+}
@@ -0,0 +1,197 @@
+// GNU AFFERO GENERAL PUBLIC LICENSE
+// Version 3, 19 November 2007
+//
+// Copyright (C) 2025 John Kleijn
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// For more details, see the full AGPL-3.0 license at:
+// https://www.gnu.org/licenses/agpl-3.0.html
+
+package rtkv
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const heartbeatSuffix = "heartbeat"
+
+// ReplicaOptions configures reads from replicas.
+type ReplicaOptions struct {
+	// MaxLag is the replication lag above which a replica is taken
+	// out of rotation until a later probe finds it caught up. Zero
+	// keeps replicas in rotation regardless of lag, unless a probe
+	// fails.
+	MaxLag time.Duration
+}
+
+// ReplicaStats describes a replica as of its last lag probe.
+type ReplicaStats struct {
+	Addr     string
+	Lag      time.Duration
+	Healthy  bool
+	ProbedAt time.Time
+}
+
+type replica struct {
+	client *redis.Client
+	stats  ReplicaStats
+}
+
+type replicaSet struct {
+	opts     ReplicaOptions
+	replicas []*replica
+	next     atomic.Uint64
+	mx       sync.RWMutex
+}
+
+// WithReadReplicas makes eventually consistent reads (Get and
+// FetchPage) use the given replicas in turn, falling back to the
+// primary when none is healthy. Writes, scripts and strongly
+// consistent reads always use the primary. Replicas are assumed to
+// be healthy until probed; run ReplicationLagTask in a Janitor to
+// take lagging replicas out of rotation.
+func WithReadReplicas(opts ReplicaOptions, replicas ...*redis.Client) Option {
+	return func(r *RedisTKV) {
+		set := &replicaSet{opts: opts}
+
+		for _, client := range replicas {
+			set.replicas = append(set.replicas, &replica{
+				client: client,
+				stats:  ReplicaStats{Addr: client.Options().Addr, Healthy: true},
+			})
+		}
+
+		r.replicas = set
+	}
+}
+
+// ProbeReplicationLag writes a heartbeat to the primary and reads it
+// back from every replica. A replica that already sees the new
+// heartbeat lags by at most the time it took to read it; otherwise
+// its lag is the age of the last heartbeat it saw. Replicas lagging
+// by more than ReplicaOptions.MaxLag, or that can't be probed, are
+// taken out of rotation.
+func (r *RedisTKV) ProbeReplicationLag(ctx context.Context) ([]ReplicaStats, error) {
+	if r.replicas == nil {
+		return nil, nil
+	}
+
+	key := r.internalKey(heartbeatSuffix)
+	sent := time.Now()
+
+	if err := r.client.Set(ctx, key, sent.UnixNano(), 0).Err(); err != nil {
+		return nil, fmt.Errorf("failed to write heartbeat: %w", err)
+	}
+
+	var errs []error
+
+	for _, rep := range r.replicas.replicas {
+		stats := ReplicaStats{Addr: rep.stats.Addr}
+
+		seen, err := rep.client.Get(ctx, key).Int64()
+
+		stats.ProbedAt = time.Now()
+
+		switch {
+		case errors.Is(err, redis.Nil):
+			stats.Lag = stats.ProbedAt.Sub(sent)
+			errs = append(errs, fmt.Errorf("replica %s has not seen a heartbeat", stats.Addr)) //nolint:err113 // no sentinel
+		case err != nil:
+			errs = append(errs, fmt.Errorf("failed to probe replica %s: %w", stats.Addr, err))
+		case seen >= sent.UnixNano():
+			stats.Lag = stats.ProbedAt.Sub(sent)
+			stats.Healthy = true
+		default:
+			stats.Lag = stats.ProbedAt.Sub(time.Unix(0, seen))
+			stats.Healthy = r.replicas.opts.MaxLag <= 0 || stats.Lag <= r.replicas.opts.MaxLag
+		}
+
+		r.replicas.mx.Lock()
+		rep.stats = stats
+		r.replicas.mx.Unlock()
+	}
+
+	return r.replicaStats(), errors.Join(errs...)
+}
+
+// ReplicationLagTask returns a janitor task that probes replication
+// lag and logs replicas being taken out of or returned to rotation.
+func ReplicationLagTask() JanitorTask {
+	return func(ctx context.Context, r *RedisTKV) error {
+		before := r.replicaStats()
+
+		after, err := r.ProbeReplicationLag(ctx)
+
+		for i := range after {
+			if before[i].Healthy == after[i].Healthy {
+				continue
+			}
+
+			r.logger.WarnContext(ctx, "replica health changed",
+				"namespace", r.namespace,
+				"replica", after[i].Addr,
+				"healthy", after[i].Healthy,
+				"lag", after[i].Lag,
+			)
+		}
+
+		return err
+	}
+}
+
+// reader returns the client to use for an eventually consistent read.
+func (r *RedisTKV) reader(ctx context.Context) redis.Cmdable {
+	if r.replicas == nil || r.consistencyFor(ctx) == ConsistencyStrong {
+		return r.client
+	}
+
+	r.replicas.mx.RLock()
+	defer r.replicas.mx.RUnlock()
+
+	n := uint64(len(r.replicas.replicas))
+	start := r.replicas.next.Add(1)
+
+	for i := range n {
+		if rep := r.replicas.replicas[(start+i)%n]; rep.stats.Healthy {
+			return rep.client
+		}
+	}
+
+	return r.client
+}
+
+func (r *RedisTKV) replicaStats() []ReplicaStats {
+	if r.replicas == nil {
+		return nil
+	}
+
+	r.replicas.mx.RLock()
+	defer r.replicas.mx.RUnlock()
+
+	stats := make([]ReplicaStats, len(r.replicas.replicas))
+
+	for i, rep := range r.replicas.replicas {
+		stats[i] = rep.stats
+	}
+
+	return stats
+}
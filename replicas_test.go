@@ -0,0 +1,81 @@
+// GNU AFFERO GENERAL PUBLIC LICENSE
+// Version 3, 19 November 2007
+//
+// Copyright (C) 2025 John Kleijn
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// For more details, see the full AGPL-3.0 license at:
+// https://www.gnu.org/licenses/agpl-3.0.html
+
+package rtkv_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/johnknl/rtkv"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedisTKV_ProbeReplicationLag(t *testing.T) {
+	ctx := context.Background()
+	primary := newGoRedisClient(0)
+	inSync := newGoRedisClient(0)
+	lagging := newGoRedisClient(1)
+
+	t.Cleanup(func() { lagging.FlushDB(ctx) })
+
+	store := newRTKV(t, primary).With(rtkv.WithReadReplicas(
+		rtkv.ReplicaOptions{MaxLag: time.Second}, inSync, lagging,
+	))
+
+	stats, err := store.ProbeReplicationLag(ctx)
+
+	require.Error(t, err, "a replica that never saw a heartbeat should be reported")
+	require.Len(t, stats, 2)
+	assert.True(t, stats[0].Healthy)
+	assert.False(t, stats[1].Healthy)
+	assert.Equal(t, stats, store.Stats().Replicas)
+
+	// Reads now only go to the healthy replica.
+	_, err = store.Set(ctx, []byte("v"), time.Now(), "a")
+	require.NoError(t, err)
+
+	for range 4 {
+		data, err := store.Get(ctx, "a")
+
+		require.NoError(t, err)
+		assert.Equal(t, []byte("v"), data)
+	}
+
+	// A stale heartbeat on the lagging replica is measured as lag.
+	heartbeat := t.Name() + rtkv.DelimUnit + "\x00rtkv:heartbeat"
+	require.NoError(t, lagging.Set(ctx, heartbeat, time.Now().Add(-time.Minute).UnixNano(), 0).Err())
+
+	stats, err = store.ProbeReplicationLag(ctx)
+
+	require.NoError(t, err)
+	assert.False(t, stats[1].Healthy)
+	assert.Greater(t, stats[1].Lag, time.Minute)
+
+	require.NoError(t, lagging.Set(ctx, heartbeat, time.Now().UnixNano(), 0).Err())
+
+	stats, err = store.ProbeReplicationLag(ctx)
+
+	require.NoError(t, err)
+	assert.True(t, stats[1].Healthy)
+}
@@ -0,0 +1,129 @@
+// GNU AFFERO GENERAL PUBLIC LICENSE
+// Version 3, 19 November 2007
+//
+// Copyright (C) 2025 John Kleijn
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// For more details, see the full AGPL-3.0 license at:
+// https://www.gnu.org/licenses/agpl-3.0.html
+
+package rtkv
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// restoreScript writes an entity back to the hot tier unless it
+// was written in the meantime. Returns 1 if it was restored.
+const restoreScript = `
+local key = KEYS[1] -- the entity key
+local index = KEYS[2] -- the lastModified index
+local data = ARGV[1] -- the archived value
+local score = ARGV[2] -- the lastModified score
+local ttl = tonumber(ARGV[3]) -- the TTL in milliseconds, or 0
+
+local set
+if ttl > 0 then
+  set = redis.call("SET", key, data, "NX", "PX", ttl)
+else
+  set = redis.call("SET", key, data, "NX")
+end
+
+if not set then
+  return 0
+end
+
+redis.call("ZADD", index, score, key)
+
+return 1
+`
+
+// WithArchiveRestore makes Get restore entities it found in the
+// archive back into the store, so subsequent reads hit the hot
+// tier. Requires WithArchive.
+func WithArchiveRestore() Option {
+	return func(r *RedisTKV) {
+		r.restoreArchived = true
+	}
+}
+
+// RestoreFromArchive copies an archived entity back into the store.
+// The restored entity gets a fresh lastModified time so the archiver
+// doesn't move it out again right away, and the default TTL set with
+// WithDefaultTTL, if any. Entities written to the store
+// since they were archived are left untouched. Returns true if the
+// entity was restored.
+func (r *RedisTKV) RestoreFromArchive(ctx context.Context, id ...string) (bool, error) {
+	if r.archive == nil {
+		return false, nil
+	}
+
+	data, err := getFrom(ctx, r.archive, id)
+	if err != nil {
+		return false, fmt.Errorf("failed to get entity from archive: %w", err)
+	}
+
+	if data == nil {
+		return false, nil
+	}
+
+	return r.restore(ctx, data, id)
+}
+
+func (r *RedisTKV) restore(ctx context.Context, data []byte, id []string) (bool, error) {
+	if err := r.checkWritable(ctx); err != nil {
+		return false, err
+	}
+
+	data, err := r.encodeValue(id, data)
+	if err != nil {
+		return false, err
+	}
+
+	key := r.namespacedKey(id...)
+	keys := []string{key, r.namespacedKey(lastModifiedIdxSuffix)}
+	timestamp := time.Now().UnixNano()
+	ttl := r.ttlFor(0)
+
+	result, err := r.evalScript(ctx, restoreScript, keys, data, timestamp, ttl.Milliseconds())
+	if err != nil {
+		return false, fmt.Errorf("failed to restore entity: %w", err)
+	}
+
+	restored, ok := result.(int64)
+	if !ok {
+		return false, ErrUnexpectedScriptResult
+	}
+
+	if restored == 0 {
+		return false, nil
+	}
+
+	_, err = r.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		r.expiryAdd(ctx, pipe, key, ttl)
+		r.indexAdd(ctx, pipe, float64(timestamp), key, id)
+
+		return nil
+	})
+	if err != nil {
+		return true, fmt.Errorf("failed to update indexes: %w", err)
+	}
+
+	return true, r.compactedRemove(ctx, false, key)
+}
@@ -0,0 +1,145 @@
+// GNU AFFERO GENERAL PUBLIC LICENSE
+// Version 3, 19 November 2007
+//
+// Copyright (C) 2025 John Kleijn
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// For more details, see the full AGPL-3.0 license at:
+// https://www.gnu.org/licenses/agpl-3.0.html
+
+package rtkv_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/johnknl/rtkv"
+	"github.com/johnknl/rtkv/rtkvtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedisTKV_RestoreFromArchive(t *testing.T) {
+	ctx := context.Background()
+
+	redisClient := newGoRedisClient(0)
+
+	t.Cleanup(func() {
+		redisClient.FlushDB(ctx).Err()
+	})
+
+	archive := rtkv.NewRedisTKV(rtkv.DelimUnit, t.Name()+"archive", redisClient)
+	store := rtkv.NewRedisTKV(rtkv.DelimUnit, t.Name(), redisClient, rtkv.WithArchive(archive))
+	old := time.Now().Add(-time.Hour)
+
+	_, err := archive.Set(ctx, []byte(`{"id": "a"}`), old, "a")
+	require.NoError(t, err)
+
+	_, err = archive.Set(ctx, []byte(`{"id": "b"}`), old, "b")
+	require.NoError(t, err)
+
+	_, err = store.Set(ctx, []byte(`{"id": "b", "v": 2}`), time.Now(), "b")
+	require.NoError(t, err)
+
+	t.Run("Restore", func(t *testing.T) {
+		restored, err := store.RestoreFromArchive(ctx, "a")
+
+		require.NoError(t, err)
+		assert.True(t, restored)
+
+		_, total, err := store.FetchPage(ctx, &old, nil, 0, 10)
+
+		require.NoError(t, err)
+		assert.EqualValues(t, 2, total)
+	})
+
+	t.Run("KeepNewer", func(t *testing.T) {
+		restored, err := store.RestoreFromArchive(ctx, "b")
+
+		require.NoError(t, err)
+		assert.False(t, restored)
+
+		data, err := store.Get(ctx, "b")
+
+		require.NoError(t, err)
+		assert.Equal(t, []byte(`{"id": "b", "v": 2}`), data)
+	})
+
+	t.Run("NotArchived", func(t *testing.T) {
+		restored, err := store.RestoreFromArchive(ctx, "c")
+
+		require.NoError(t, err)
+		assert.False(t, restored)
+	})
+
+	t.Run("OnGet", func(t *testing.T) {
+		_, err := archive.Set(ctx, []byte(`{"id": "d"}`), old, "d")
+		require.NoError(t, err)
+
+		restoring := store.With(rtkv.WithArchiveRestore())
+
+		data, err := restoring.Get(ctx, "d")
+
+		require.NoError(t, err)
+		assert.Equal(t, []byte(`{"id": "d"}`), data)
+
+		exists, err := store.Exists(ctx, "d")
+
+		require.NoError(t, err)
+		assert.True(t, exists, "Get should restore archived entities")
+	})
+}
+
+func TestRedisTKV_RestoreFromArchive_Indexes(t *testing.T) {
+	ctx := context.Background()
+	_, client := rtkvtest.NewMiniredis(t)
+	archive := rtkv.NewRedisTKV(rtkv.DelimUnit, t.Name()+"archive", client)
+	store := rtkv.NewRedisTKV(rtkv.DelimUnit, t.Name(), client,
+		rtkv.WithArchive(archive),
+		rtkv.WithDefaultTTL(time.Hour),
+		rtkv.WithChangeFeed(rtkv.ChangeFeedOptions{}),
+		rtkv.WithIDIndex(),
+	)
+
+	_, err := archive.Set(ctx, []byte("a"), time.Now().Add(-time.Hour), "a")
+	require.NoError(t, err)
+
+	restored, err := store.RestoreFromArchive(ctx, "a")
+	require.NoError(t, err)
+	require.True(t, restored)
+
+	key := t.Name() + rtkv.DelimUnit + "a"
+	assert.Greater(t, client.PTTL(ctx, key).Val(), time.Minute, "Restored entities should get a fresh TTL")
+	assert.NoError(t, client.ZScore(ctx, t.Name()+rtkv.DelimUnit+"\x00rtkv:expiry", key).Err(),
+		"Restored entities should be in the expiry index")
+
+	events, err := store.ReadChanges(ctx, "0", 10)
+	require.NoError(t, err)
+	assert.Len(t, events, 1, "Restores should be published")
+
+	records, _, err := store.FetchPageByID(ctx, nil, 10)
+	require.NoError(t, err)
+
+	var n int
+
+	for _, err := range records {
+		require.NoError(t, err)
+
+		n++
+	}
+
+	assert.Equal(t, 1, n, "Restored entities should be in the ID index")
+}
@@ -0,0 +1,63 @@
+// GNU AFFERO GENERAL PUBLIC LICENSE
+// Version 3, 19 November 2007
+//
+// Copyright (C) 2025 John Kleijn
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// For more details, see the full AGPL-3.0 license at:
+// https://www.gnu.org/licenses/agpl-3.0.html
+
+// Package rtkvtest provides stores for unit tests that don't need
+// a Redis server, backed by an in-process miniredis.
+//
+// miniredis implements most, but not all, commands rtkv uses, and
+// doesn't expire keys on its own. Use the server returned by
+// NewMiniredis to fast forward time with FastForward.
+package rtkvtest
+
+import (
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/johnknl/rtkv"
+	"github.com/redis/go-redis/v9"
+)
+
+// NewMiniredis starts a miniredis server and returns it along
+// with a client connected to it. Both are closed when the test
+// ends.
+func NewMiniredis(tb testing.TB) (*miniredis.Miniredis, *redis.Client) {
+	tb.Helper()
+
+	server := miniredis.RunT(tb)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+
+	tb.Cleanup(func() {
+		_ = client.Close()
+	})
+
+	return server, client
+}
+
+// NewMiniredisTKV returns a store backed by a fresh miniredis server,
+// using the name of the test as namespace. The server is closed when
+// the test ends.
+func NewMiniredisTKV(tb testing.TB, opts ...rtkv.Option) *rtkv.RedisTKV {
+	tb.Helper()
+
+	_, client := NewMiniredis(tb)
+
+	return rtkv.NewRedisTKV(rtkv.DelimUnit, tb.Name(), client, opts...)
+}
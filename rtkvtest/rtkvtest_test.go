@@ -0,0 +1,57 @@
+// GNU AFFERO GENERAL PUBLIC LICENSE
+// Version 3, 19 November 2007
+//
+// Copyright (C) 2025 John Kleijn
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// For more details, see the full AGPL-3.0 license at:
+// https://www.gnu.org/licenses/agpl-3.0.html
+
+package rtkvtest_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/johnknl/rtkv"
+	"github.com/johnknl/rtkv/rtkvtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewMiniredisTKV(t *testing.T) {
+	ctx := context.Background()
+	store := rtkvtest.NewMiniredisTKV(t, rtkv.WithNotFoundError())
+
+	_, err := store.Set(ctx, []byte("value"), time.Now(), "a", "1")
+	require.NoError(t, err)
+
+	value, err := store.Get(ctx, "a", "1")
+	require.NoError(t, err)
+	assert.Equal(t, "value", string(value))
+
+	_, err = store.Get(ctx, "b")
+	require.ErrorIs(t, err, rtkv.ErrNotFound)
+
+	values, total, err := store.FetchPage(ctx, nil, nil, 0, 10)
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, total)
+
+	for value, err := range values {
+		require.NoError(t, err)
+		assert.Equal(t, "value", string(value))
+	}
+}
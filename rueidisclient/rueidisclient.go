@@ -0,0 +1,349 @@
+// GNU AFFERO GENERAL PUBLIC LICENSE
+// Version 3, 19 November 2007
+//
+// Copyright (C) 2025 John Kleijn
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// For more details, see the full AGPL-3.0 license at:
+// https://www.gnu.org/licenses/agpl-3.0.html
+
+// Package rueidisclient runs rtkv stores on a rueidis client, to
+// benefit from its automatic pipelining and client side caching.
+//
+// The store still talks to a go-redis client, but one whose
+// connections are served in memory by rueidis: commands from all of
+// them share the pipelined connection of rueidis instead of each
+// taking a round trip on a pooled connection of their own, so up to
+// Options.PoolSize callers are pipelined together. The deadline and
+// cancellation of the context of a command are passed on to rueidis,
+// but its other values, such as trace spans, aren't. Every store
+// feature works, with these exceptions:
+//
+//   - WAIT and WAITAOF are not supported and fail, as the writes they
+//     wait for may have gone out on another connection of rueidis,
+//     so WriteConcern.Replicas can't be used.
+//   - Pub/sub, MONITOR and CLIENT TRACKING are rejected.
+//   - Cluster clients aren't supported, as the slots of arbitrary
+//     commands are unknown.
+package rueidisclient
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/johnknl/rtkv"
+	"github.com/johnknl/rtkv/internal/bridge"
+	"github.com/redis/go-redis/v9"
+	"github.com/redis/rueidis"
+)
+
+// ErrUnsupportedMode is returned for rueidis cluster clients.
+var ErrUnsupportedMode = errors.New("rueidis cluster clients are not supported")
+
+// Options configures the go-redis client served by rueidis.
+type Options struct {
+	// CacheTTL enables client side caching of GET and MGET outside
+	// transactions, keeping values for up to this long. Redis
+	// invalidates cached values when they change, but a read right
+	// after a write may still see the old value, so enable it for
+	// read mostly namespaces only. Requires rueidis to be created
+	// without DisableCache.
+	CacheTTL time.Duration
+
+	// PoolSize is the number of in-memory connections of the go-redis
+	// client. They are cheap, as they don't map to connections to
+	// Redis. Defaults to that of go-redis.
+	PoolSize int
+}
+
+// NewClient returns a go-redis client whose commands are executed by
+// client. Closing it doesn't close client. WAIT and WAITAOF fail with
+// an error, as they are not supported.
+func NewClient(client rueidis.Client, opts Options) (*redis.Client, error) {
+	if client.Mode() == rueidis.ClientModeCluster {
+		return nil, ErrUnsupportedMode
+	}
+
+	return bridge.NewClient(backend{client: client, opts: opts}, bridge.Options{
+		Name:        "rueidisclient",
+		Protocol:    3, //nolint:mnd // RESP3, which rueidis speaks
+		PoolSize:    opts.PoolSize,
+		Unsupported: []string{"WAIT", "WAITAOF"},
+	}), nil
+}
+
+// NewRedisTKV creates a store on client.
+func NewRedisTKV(
+	idDelimiter, namespace string,
+	client rueidis.Client,
+	opts Options,
+	tkvOpts ...rtkv.Option,
+) (*rtkv.RedisTKV, error) {
+	c, err := NewClient(client, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return rtkv.NewRedisTKV(idDelimiter, namespace, c, tkvOpts...), nil
+}
+
+// backend executes the commands of the go-redis client with rueidis.
+type backend struct {
+	client rueidis.Client
+	opts   Options
+}
+
+func (b backend) Conn(context.Context) (bridge.Conn, error) {
+	return &conn{client: b.client, opts: b.opts}, nil
+}
+
+// conn executes the commands of a connection of the go-redis client.
+type conn struct {
+	client rueidis.Client
+	opts   Options
+
+	// dedicated is the connection used from WATCH until the
+	// transaction ends, so no other writes come in between.
+	dedicated rueidis.DedicatedClient
+	release   func()
+
+	inMulti bool
+	pending []command
+}
+
+// command is a command to forward to rueidis, which is
+// cacheable if cached is set.
+type command struct {
+	cmd       rueidis.Completed
+	cacheable rueidis.Cacheable
+	cached    bool
+}
+
+func (c *conn) Do(ctx context.Context, w *bridge.Writer, cmds [][]string) error {
+	for _, args := range cmds {
+		name := bridge.Name(args)
+
+		switch name {
+		case "UNWATCH":
+			if c.dedicated == nil {
+				if err := c.flush(ctx, w); err != nil {
+					return err
+				}
+
+				w.Status("OK")
+
+				continue
+			}
+		case "WATCH":
+			if c.dedicated == nil {
+				if err := c.flush(ctx, w); err != nil {
+					return err
+				}
+
+				c.dedicated, c.release = c.client.Dedicate()
+			}
+		}
+
+		if blocking(name, args) && c.dedicated == nil {
+			if err := c.flush(ctx, w); err != nil {
+				return err
+			}
+
+			if err := reply(w, c.client.Do(ctx, c.client.B().Arbitrary(args...).Blocking())); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		c.pending = append(c.pending, c.command(name, args))
+
+		switch name {
+		case "MULTI":
+			c.inMulti = true
+		case "EXEC", "DISCARD", "UNWATCH":
+			c.inMulti = false
+
+			if c.dedicated != nil {
+				if err := c.flush(ctx, w); err != nil {
+					return err
+				}
+
+				c.releaseDedicated()
+			}
+		}
+	}
+
+	return c.flush(ctx, w)
+}
+
+func (c *conn) Close() {
+	c.releaseDedicated()
+}
+
+// command builds the rueidis command of args.
+func (c *conn) command(name string, args []string) command {
+	if c.opts.CacheTTL > 0 && c.dedicated == nil && !c.inMulti {
+		switch {
+		case name == "GET" && len(args) == 2: //nolint:mnd // GET key
+			return command{cacheable: c.client.B().Get().Key(args[1]).Cache(), cached: true}
+		case name == "MGET" && len(args) > 1:
+			return command{cacheable: c.client.B().Mget().Key(args[1:]...).Cache(), cached: true}
+		}
+	}
+
+	return command{cmd: c.client.B().Arbitrary(args...).Build()}
+}
+
+// flush forwards the pending commands and writes their replies.
+// Consecutive cacheable commands are sent together, as are the
+// others, preserving their order.
+func (c *conn) flush(ctx context.Context, w *bridge.Writer) error {
+	for len(c.pending) > 0 {
+		n := 1
+		for n < len(c.pending) && c.pending[n].cached == c.pending[0].cached {
+			n++
+		}
+
+		run := c.pending[:n]
+		c.pending = c.pending[n:]
+
+		var results []rueidis.RedisResult
+
+		switch {
+		case run[0].cached:
+			multi := make([]rueidis.CacheableTTL, len(run))
+			for i := range run {
+				multi[i] = rueidis.CT(run[i].cacheable, c.opts.CacheTTL)
+			}
+
+			results = c.client.DoMultiCache(ctx, multi...)
+		case c.dedicated != nil:
+			results = c.dedicated.DoMulti(ctx, commands(run)...)
+		default:
+			results = c.client.DoMulti(ctx, commands(run)...)
+		}
+
+		for _, result := range results {
+			if err := reply(w, result); err != nil {
+				c.pending = nil
+
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func commands(run []command) []rueidis.Completed {
+	cmds := make([]rueidis.Completed, len(run))
+	for i := range run {
+		cmds[i] = run[i].cmd
+	}
+
+	return cmds
+}
+
+// reply writes the reply of a command. Errors other than those
+// returned by Redis close the connection, so go-redis can retry
+// on another.
+func reply(w *bridge.Writer, result rueidis.RedisResult) error {
+	if err := result.NonRedisError(); err != nil {
+		return err //nolint:wrapcheck // only ends the connection
+	}
+
+	msg, _ := result.ToMessage()
+
+	return writeMessage(w, &msg)
+}
+
+// writeMessage writes a message of rueidis. Verbatim strings and big
+// numbers are written as bulk strings, and sets as arrays.
+func writeMessage(w *bridge.Writer, msg *rueidis.RedisMessage) error {
+	switch {
+	case msg.IsNil():
+		w.Nil()
+	case msg.IsInt64():
+		n, _ := msg.AsInt64()
+		w.Int(n)
+	case msg.IsFloat64():
+		f, _ := msg.AsFloat64()
+		w.Float(f)
+	case msg.IsBool():
+		b, _ := msg.AsBool()
+		w.Bool(b)
+	case msg.IsArray():
+		values, _ := msg.ToArray()
+		w.Array(len(values))
+
+		for i := range values {
+			if err := writeMessage(w, &values[i]); err != nil {
+				return err
+			}
+		}
+	case msg.IsMap():
+		values, err := msg.ToMap()
+		if err != nil {
+			return err //nolint:wrapcheck // only ends the connection
+		}
+
+		w.Map(len(values))
+
+		for k, v := range values {
+			w.String(k)
+
+			if err = writeMessage(w, &v); err != nil {
+				return err
+			}
+		}
+	default:
+		// Unlike the error, ToString keeps the error code.
+		s, err := msg.ToString()
+		if err != nil {
+			w.Error(s)
+		} else {
+			w.String(s)
+		}
+	}
+
+	return nil
+}
+
+func (c *conn) releaseDedicated() {
+	if c.dedicated != nil {
+		c.release()
+		c.dedicated, c.release = nil, nil
+	}
+}
+
+// blocking reports whether a command blocks, so rueidis runs it
+// on a connection of its own instead of the shared pipeline.
+func blocking(name string, args []string) bool {
+	switch name {
+	case "BLPOP", "BRPOP", "BLMOVE", "BRPOPLPUSH", "BLMPOP", "BZPOPMIN", "BZPOPMAX", "BZMPOP":
+		return true
+	case "XREAD", "XREADGROUP":
+		for _, arg := range args[1:] {
+			if strings.EqualFold(arg, "BLOCK") {
+				return true
+			}
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,150 @@
+// GNU AFFERO GENERAL PUBLIC LICENSE
+// Version 3, 19 November 2007
+//
+// Copyright (C) 2025 John Kleijn
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// For more details, see the full AGPL-3.0 license at:
+// https://www.gnu.org/licenses/agpl-3.0.html
+
+package rueidisclient_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/johnknl/rtkv"
+	"github.com/johnknl/rtkv/rtkvtest"
+	"github.com/johnknl/rtkv/rueidisclient"
+	"github.com/redis/rueidis"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newStore(t *testing.T, opts rueidisclient.Options, tkvOpts ...rtkv.Option) *rtkv.RedisTKV {
+	t.Helper()
+
+	server, _ := rtkvtest.NewMiniredis(t)
+
+	// miniredis implements CLUSTER SLOTS, but not CLIENT TRACKING.
+	client, err := rueidis.NewClient(rueidis.ClientOption{InitAddress: []string{server.Addr()}, DisableCache: true, ForceSingleClient: true})
+	require.NoError(t, err)
+	t.Cleanup(client.Close)
+
+	store, err := rueidisclient.NewRedisTKV(rtkv.DelimUnit, t.Name(), client, opts, tkvOpts...)
+	require.NoError(t, err)
+
+	return store
+}
+
+func TestNewRedisTKV(t *testing.T) {
+	ctx := context.Background()
+	store := newStore(t, rueidisclient.Options{CacheTTL: time.Minute}, rtkv.WithVersioning(), rtkv.WithNotFoundError())
+	now := time.Now()
+
+	_, err := store.Set(ctx, []byte("a"), now, "a")
+	require.NoError(t, err)
+
+	err = store.BulkSet(ctx, []rtkv.BulkSetRecord{
+		{ID: []string{"b"}, Data: []byte("b"), LastModified: now.Add(time.Second)},
+		{ID: []string{"c"}, Data: []byte("c"), LastModified: now.Add(2 * time.Second), TTL: time.Hour},
+	})
+	require.NoError(t, err)
+
+	value, err := store.Get(ctx, "a")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("a"), value)
+
+	_, err = store.Get(ctx, "missing")
+	require.ErrorIs(t, err, rtkv.ErrNotFound)
+
+	entries, err := store.BulkGet(ctx, [][]string{{"a"}, {"missing"}, {"c"}})
+	require.NoError(t, err)
+	require.Len(t, entries, 3)
+	assert.Equal(t, []byte("a"), entries[0].Data)
+	assert.False(t, entries[1].Exists)
+	assert.Equal(t, []byte("c"), entries[2].Data)
+
+	records, total, err := store.FetchPageRecords(ctx, nil, nil, 0, 10)
+	require.NoError(t, err)
+	assert.EqualValues(t, 3, total)
+
+	var ids []string
+
+	for record, err := range records {
+		require.NoError(t, err)
+
+		ids = append(ids, record.ID[0])
+	}
+
+	assert.Equal(t, []string{"a", "b", "c"}, ids)
+
+	_, version, err := store.GetVersioned(ctx, "a")
+	require.NoError(t, err)
+
+	_, err = store.CompareAndSet(ctx, []byte("a2"), version, "a")
+	require.NoError(t, err, "Scripts should run")
+
+	require.NoError(t, store.Delete(ctx, "b"))
+
+	count, err := store.Count(ctx)
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, count)
+}
+
+func TestNewRedisTKV_Watch(t *testing.T) {
+	ctx := context.Background()
+	store := newStore(t, rueidisclient.Options{})
+
+	_, err := store.Set(ctx, []byte("1"), time.Now(), "a")
+	require.NoError(t, err)
+
+	changed, err := store.UpdateMany(ctx, [][]string{{"a"}}, func(_ []string, old []byte) ([]byte, bool, error) {
+		return append(old, '1'), true, nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 1, changed)
+
+	value, err := store.Get(ctx, "a")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("11"), value)
+}
+
+func TestNewRedisTKV_Wait(t *testing.T) {
+	store := newStore(t, rueidisclient.Options{})
+	ctx := rtkv.ContextWithWriteConcern(context.Background(), rtkv.WriteConcern{Replicas: 1})
+
+	_, err := store.Set(ctx, []byte("a"), time.Now(), "a")
+	require.ErrorContains(t, err, "not supported by rueidisclient")
+}
+
+func TestNewClient_Context(t *testing.T) {
+	server, _ := rtkvtest.NewMiniredis(t)
+
+	client, err := rueidis.NewClient(rueidis.ClientOption{InitAddress: []string{server.Addr()}, DisableCache: true, ForceSingleClient: true})
+	require.NoError(t, err)
+	t.Cleanup(client.Close)
+
+	c, err := rueidisclient.NewClient(client, rueidisclient.Options{PoolSize: 1})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = c.Close() })
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	require.Error(t, c.BLPop(ctx, 0, "list").Err(), "BLPOP should end at the deadline")
+	require.NoError(t, c.RPush(context.Background(), "list", "a").Err(), "The client should still work")
+}
@@ -0,0 +1,199 @@
+// GNU AFFERO GENERAL PUBLIC LICENSE
+// Version 3, 19 November 2007
+//
+// Copyright (C) 2025 John Kleijn
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// For more details, see the full AGPL-3.0 license at:
+// https://www.gnu.org/licenses/agpl-3.0.html
+
+package rtkv
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const defaultIDScanCount = 1000
+
+// IDs returns an iterator over the IDs of all entities, found by
+// SCANning the keys under the namespace rather than through the
+// lastModified index, so it also finds entities missing from the
+// index. IDs are yielded in no particular order, and an ID may be
+// yielded more than once if keys are added or removed while
+// iterating. Iteration stops at the first error.
+//
+// Internal keys, such as the freeze marker and the writers registry,
+// are skipped. Entities can't be named like them, as checkID rejects
+// such IDs.
+func (r *RedisTKV) IDs(ctx context.Context) iter.Seq2[[]string, error] {
+	return func(yield func([]string, error) bool) {
+		match := escapeGlob(r.keyPrefix()) + "*"
+
+		clients, err := r.scanClients(ctx)
+		if err != nil {
+			yield(nil, err)
+
+			return
+		}
+
+		for _, client := range clients {
+			var cursor uint64
+
+			for {
+				keys, next, err := client.ScanType(ctx, cursor, match, defaultIDScanCount, "string").Result()
+				if err != nil {
+					yield(nil, fmt.Errorf("failed to scan keys: %w", err))
+
+					return
+				}
+
+				for _, key := range keys {
+					id := r.idFromKey(key)
+
+					if isInternalID(id) {
+						continue
+					}
+
+					if !yield(id, nil) {
+						return
+					}
+				}
+
+				if cursor = next; cursor == 0 {
+					break
+				}
+			}
+		}
+	}
+}
+
+// Clear deletes every key in the namespace: all entities, their
+// indexes and all other state the store keeps. Keys are found with
+// SCAN and deleted with UNLINK in batches rather than with FLUSHDB,
+// so namespaces sharing a database are left untouched. Returns the
+// number of keys deleted.
+//
+// Clear is not atomic: entities written while clearing may survive.
+// Clear honours the budget set with WithCommandBudget.
+func (r *RedisTKV) Clear(ctx context.Context) (int64, error) {
+	defer r.observe(ctx, "clear", time.Now())
+
+	if err := r.checkWritable(ctx); err != nil {
+		return 0, err
+	}
+
+	match := escapeGlob(r.keyPrefix()) + "*"
+	budget := budgetFrom(ctx)
+
+	clients, err := r.scanClients(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	var deleted int64
+
+	for _, client := range clients {
+		var cursor uint64
+
+		for {
+			if err := budget.spend(ctx, defaultIDScanCount); err != nil {
+				return deleted, err
+			}
+
+			keys, next, err := client.Scan(ctx, cursor, match, defaultIDScanCount).Result()
+			if err != nil {
+				return deleted, fmt.Errorf("failed to scan keys: %w", err)
+			}
+
+			if len(keys) > 0 {
+				n, err := r.client.Unlink(ctx, keys...).Result()
+				if err != nil {
+					return deleted, fmt.Errorf("failed to unlink keys: %w", err)
+				}
+
+				deleted += n
+			}
+
+			if cursor = next; cursor == 0 {
+				break
+			}
+		}
+	}
+
+	r.freeze.set(false)
+	r.totals.invalidate()
+
+	return deleted, nil
+}
+
+// scanClients returns the clients to SCAN the keyspace with: those
+// of all primaries of a Cluster or shards of a Ring, as SCAN only
+// covers the node it is sent to, or the client of the store otherwise.
+func (r *RedisTKV) scanClients(ctx context.Context) ([]redis.Cmdable, error) {
+	var (
+		clients []redis.Cmdable
+		mx      sync.Mutex
+	)
+
+	add := func(_ context.Context, client *redis.Client) error {
+		mx.Lock()
+		defer mx.Unlock()
+
+		clients = append(clients, client)
+
+		return nil
+	}
+
+	var err error
+
+	switch c := r.client.(type) {
+	case *redis.ClusterClient:
+		err = c.ForEachMaster(ctx, add)
+	case *redis.Ring:
+		err = c.ForEachShard(ctx, add)
+	default:
+		return []redis.Cmdable{r.client}, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to list primaries: %w", err)
+	}
+
+	return clients, nil
+}
+
+// escapeGlob escapes the characters that have
+// a special meaning in a SCAN MATCH pattern.
+func escapeGlob(s string) string {
+	var b strings.Builder
+
+	for _, c := range s {
+		switch c {
+		case '*', '?', '[', ']', '\\':
+			b.WriteRune('\\')
+		}
+
+		b.WriteRune(c)
+	}
+
+	return b.String()
+}
@@ -0,0 +1,91 @@
+// GNU AFFERO GENERAL PUBLIC LICENSE
+// Version 3, 19 November 2007
+//
+// Copyright (C) 2025 John Kleijn
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// For more details, see the full AGPL-3.0 license at:
+// https://www.gnu.org/licenses/agpl-3.0.html
+
+package rtkv_test
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/johnknl/rtkv"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedisTKV_IDs(t *testing.T) {
+	ctx := context.Background()
+	client := newGoRedisClient(0)
+	store := rtkv.NewRedisTKV(rtkv.DelimUnit, "TestRedisTKV_IDs[*]", client).
+		With(rtkv.WithHistory(), rtkv.WithWriterTracking(time.Hour))
+	other := rtkv.NewRedisTKV(rtkv.DelimUnit, "TestRedisTKV_IDs[x]", client)
+
+	_, err := store.Set(ctx, []byte("a"), time.Now(), "a", "1")
+	require.NoError(t, err)
+	_, err = store.Set(ctx, []byte("b"), time.Now(), "b")
+	require.NoError(t, err)
+	_, err = other.Set(ctx, []byte("c"), time.Now(), "c")
+	require.NoError(t, err)
+	require.NoError(t, store.Freeze(ctx, time.Minute))
+
+	var ids [][]string
+
+	for id, err := range store.IDs(ctx) {
+		require.NoError(t, err)
+
+		ids = append(ids, id)
+	}
+
+	assert.ElementsMatch(t, [][]string{{"a", "1"}, {"b"}}, ids)
+}
+
+func TestRedisTKV_Clear(t *testing.T) {
+	ctx := context.Background()
+	client := newGoRedisClient(0)
+	store := newRTKV(t, client).With(rtkv.WithHistory())
+	other := rtkv.NewRedisTKV(rtkv.DelimUnit, t.Name()+"Other", client)
+
+	var records []rtkv.BulkSetRecord
+
+	for i := range 2500 {
+		records = append(records, rtkv.BulkSetRecord{
+			LastModified: time.Now(),
+			ID:           []string{"entity", strconv.Itoa(i)},
+			Data:         []byte("value"),
+		})
+	}
+
+	require.NoError(t, store.BulkSet(ctx, records))
+	require.NoError(t, other.BulkSet(ctx, records[:1]))
+
+	deleted, err := store.Clear(ctx)
+	require.NoError(t, err)
+	assert.EqualValues(t, 2500*2+2, deleted, "Entities, revisions and both indexes should be deleted")
+
+	count, err := store.Count(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, count)
+
+	count, err = other.Count(ctx)
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, count, "Other namespaces should be left untouched")
+}
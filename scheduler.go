@@ -0,0 +1,211 @@
+// GNU AFFERO GENERAL PUBLIC LICENSE
+// Version 3, 19 November 2007
+//
+// Copyright (C) 2025 John Kleijn
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// For more details, see the full AGPL-3.0 license at:
+// https://www.gnu.org/licenses/agpl-3.0.html
+
+package rtkv
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"time"
+)
+
+const (
+	defaultBackupPrefix   = "backup-"
+	defaultBackupInterval = 24 * time.Hour
+	backupTimeLayout      = "20060102T150405.000000000Z"
+)
+
+// BackupOptions controls a BackupScheduler.
+type BackupOptions struct {
+	// Interval is the time between backups. Defaults to 24 hours.
+	Interval time.Duration
+
+	// Retain is the number of complete snapshots to keep. Older
+	// snapshots are deleted after each successful backup. Zero
+	// keeps all snapshots.
+	Retain int
+
+	// Prefix is prepended to snapshot names, which are otherwise
+	// the UTC time the backup started. Defaults to "backup-".
+	Prefix string
+
+	// Snapshot controls how each snapshot is exported. Its State
+	// is not used, as every backup is a new snapshot.
+	Snapshot SnapshotOptions
+
+	// OnBackup, if set, is called after every backup attempt.
+	OnBackup func(BackupResult)
+}
+
+// BackupResult describes a single backup attempt.
+type BackupResult struct {
+	Name     string
+	Manifest SnapshotManifest
+	Started  time.Time
+	Duration time.Duration
+
+	// Deleted lists snapshots removed by retention.
+	Deleted []string
+	Err     error
+}
+
+// BackupScheduler periodically exports snapshots of a store
+// to a SnapshotStore and prunes old snapshots.
+type BackupScheduler struct {
+	store   *RedisTKV
+	dst     SnapshotStore
+	opts    BackupOptions
+	janitor *Janitor
+}
+
+// NewBackupScheduler creates a scheduler that backs up store to dst.
+func NewBackupScheduler(store *RedisTKV, dst SnapshotStore, opts BackupOptions) *BackupScheduler {
+	if opts.Prefix == "" {
+		opts.Prefix = defaultBackupPrefix
+	}
+
+	if opts.Interval <= 0 {
+		opts.Interval = defaultBackupInterval
+	}
+
+	b := &BackupScheduler{
+		store: store,
+		dst:   dst,
+		opts:  opts,
+	}
+
+	b.janitor = NewJanitor(store, opts.Interval, func(ctx context.Context, _ *RedisTKV) error {
+		return b.RunOnce(ctx).Err
+	})
+
+	return b
+}
+
+// Start runs backups in the background until Stop is called or ctx is done.
+func (b *BackupScheduler) Start(ctx context.Context) {
+	b.janitor.Start(ctx)
+}
+
+// Stop stops the scheduler and waits for a running backup to return.
+func (b *BackupScheduler) Stop() {
+	b.janitor.Stop()
+}
+
+// Shutdown stops the scheduler and waits for a running backup
+// to return until ctx is done.
+func (b *BackupScheduler) Shutdown(ctx context.Context) error {
+	return b.janitor.Shutdown(ctx)
+}
+
+// RunOnce takes a backup and applies retention in the calling goroutine.
+func (b *BackupScheduler) RunOnce(ctx context.Context) BackupResult {
+	result := BackupResult{Started: time.Now()}
+	result.Name = b.opts.Prefix + result.Started.UTC().Format(backupTimeLayout)
+
+	opts := b.opts.Snapshot
+	opts.State = nil
+
+	result.Manifest, result.Err = b.store.ExportSnapshot(ctx, b.dst, result.Name, opts)
+	if result.Err == nil && b.opts.Retain > 0 {
+		result.Deleted, result.Err = b.prune(ctx)
+	}
+
+	result.Duration = time.Since(result.Started)
+
+	if b.opts.OnBackup != nil {
+		b.opts.OnBackup(result)
+	}
+
+	return result
+}
+
+// Snapshots returns the names of all complete snapshots, oldest first.
+func (b *BackupScheduler) Snapshots(ctx context.Context) ([]string, error) {
+	files, err := b.dst.List(ctx, b.opts.Prefix)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list snapshots: %w", err)
+	}
+
+	var names []string
+
+	for _, file := range files {
+		if path.Base(file) == manifestName {
+			names = append(names, path.Dir(file))
+		}
+	}
+
+	return names, nil
+}
+
+// Restore imports the named snapshot into the store. An empty
+// name restores the most recent complete snapshot.
+func (b *BackupScheduler) Restore(ctx context.Context, name string, opts TransferOptions) (int, error) {
+	if name == "" {
+		names, err := b.Snapshots(ctx)
+		if err != nil {
+			return 0, err
+		}
+
+		if len(names) == 0 {
+			return 0, fmt.Errorf("failed to restore: no snapshots with prefix %q", b.opts.Prefix) //nolint:err113 // no sentinel
+		}
+
+		name = names[len(names)-1]
+	}
+
+	return b.store.ImportSnapshot(ctx, b.dst, name, opts)
+}
+
+// prune deletes all but the most recent Retain complete snapshots,
+// along with any incomplete snapshots older than the oldest kept one.
+func (b *BackupScheduler) prune(ctx context.Context) ([]string, error) {
+	names, err := b.Snapshots(ctx)
+	if err != nil || len(names) <= b.opts.Retain {
+		return nil, err
+	}
+
+	oldestKept := names[len(names)-b.opts.Retain]
+
+	files, err := b.dst.List(ctx, b.opts.Prefix)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list snapshots: %w", err)
+	}
+
+	var deleted []string
+
+	for _, file := range files {
+		name := path.Dir(file)
+		if name >= oldestKept {
+			continue
+		}
+
+		if err := b.dst.Delete(ctx, file); err != nil {
+			return deleted, fmt.Errorf("failed to delete snapshot %s: %w", name, err)
+		}
+
+		if len(deleted) == 0 || deleted[len(deleted)-1] != name {
+			deleted = append(deleted, name)
+		}
+	}
+
+	return deleted, nil
+}
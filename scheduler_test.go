@@ -0,0 +1,77 @@
+// GNU AFFERO GENERAL PUBLIC LICENSE
+// Version 3, 19 November 2007
+//
+// Copyright (C) 2025 John Kleijn
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// For more details, see the full AGPL-3.0 license at:
+// https://www.gnu.org/licenses/agpl-3.0.html
+
+package rtkv_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/johnknl/rtkv"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBackupScheduler(t *testing.T) {
+	ctx := context.Background()
+	store := goRedisSetup(t, 30)
+	dst := rtkv.DirStore{Dir: t.TempDir()}
+
+	var results []rtkv.BackupResult
+
+	scheduler := rtkv.NewBackupScheduler(store, dst, rtkv.BackupOptions{
+		Retain:   2,
+		Snapshot: rtkv.SnapshotOptions{ChunkRecords: 10},
+		OnBackup: func(result rtkv.BackupResult) { results = append(results, result) },
+	})
+
+	// Without an interval, the scheduler runs daily rather than panicking.
+	scheduler.Start(ctx)
+	require.NoError(t, scheduler.Shutdown(ctx))
+
+	for range 3 {
+		require.NoError(t, scheduler.RunOnce(ctx).Err)
+	}
+
+	require.Len(t, results, 3)
+	assert.Equal(t, 30, results[2].Manifest.Records)
+	assert.Equal(t, []string{results[0].Name}, results[2].Deleted)
+
+	names, err := scheduler.Snapshots(ctx)
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{results[1].Name, results[2].Name}, names)
+
+	files, err := dst.List(ctx, results[0].Name)
+
+	require.NoError(t, err)
+	assert.Empty(t, files)
+
+	require.NoError(t, store.Delete(ctx, "entity", "1"))
+
+	restored, err := scheduler.Restore(ctx, "", rtkv.TransferOptions{})
+
+	require.NoError(t, err)
+	assert.Equal(t, 30, restored)
+
+	_, err = store.Get(ctx, "entity", "1")
+	require.NoError(t, err)
+}
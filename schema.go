@@ -0,0 +1,186 @@
+// GNU AFFERO GENERAL PUBLIC LICENSE
+// Version 3, 19 November 2007
+//
+// Copyright (C) 2025 John Kleijn
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// For more details, see the full AGPL-3.0 license at:
+// https://www.gnu.org/licenses/agpl-3.0.html
+
+package rtkv
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ErrSchemaViolation is returned for writes whose value doesn't
+// match the schema of the namespace.
+var ErrSchemaViolation = errors.New("value violates schema")
+
+// SchemaRegistry validates values against the schema registered
+// for a namespace, so producers sharing a namespace can't write
+// incompatible values. Validate returns an error wrapping
+// ErrSchemaViolation for invalid values.
+type SchemaRegistry interface {
+	Validate(ctx context.Context, namespace string, data []byte) error
+}
+
+// WithSchemaRegistry validates the values written with Set,
+// SetWithTTL, BulkSet and UpdateMany against registry. A batch
+// with an invalid value is rejected as a whole. Imports and
+// restores from the archive are not validated.
+func WithSchemaRegistry(registry SchemaRegistry) Option {
+	return func(r *RedisTKV) {
+		r.schemas = registry
+	}
+}
+
+// validate validates a value with the schema registry, if any.
+func (r *RedisTKV) validate(ctx context.Context, id []string, data []byte) error {
+	if r.schemas == nil {
+		return nil
+	}
+
+	if err := r.schemas.Validate(ctx, r.namespace, data); err != nil {
+		return fmt.Errorf("invalid value for entity %v: %w", id, err)
+	}
+
+	return nil
+}
+
+// jsonSchema is the subset of JSON Schema ValidateJSONSchema supports.
+type jsonSchema struct {
+	Type                 json.RawMessage        `json:"type"`
+	Required             []string               `json:"required"`
+	Properties           map[string]*jsonSchema `json:"properties"`
+	AdditionalProperties *bool                  `json:"additionalProperties"`
+	Items                *jsonSchema            `json:"items"`
+}
+
+// ValidateJSONSchema validates a JSON value against a JSON Schema.
+// It supports the type, required, properties, additionalProperties
+// (as a boolean) and items keywords, and ignores all others.
+func ValidateJSONSchema(schema string, data []byte) error {
+	var s jsonSchema
+
+	if err := json.Unmarshal([]byte(schema), &s); err != nil {
+		return fmt.Errorf("failed to parse schema: %w", err)
+	}
+
+	dec := json.NewDecoder(strings.NewReader(string(data)))
+	dec.UseNumber()
+
+	var value any
+
+	if err := dec.Decode(&value); err != nil {
+		return fmt.Errorf("%w: invalid JSON: %w", ErrSchemaViolation, err)
+	}
+
+	return s.validate("$", value)
+}
+
+func (s *jsonSchema) validate(path string, value any) error {
+	if err := s.validateType(path, value); err != nil {
+		return err
+	}
+
+	switch v := value.(type) {
+	case map[string]any:
+		for _, name := range s.Required {
+			if _, ok := v[name]; !ok {
+				return fmt.Errorf("%w: %s: missing required property %q", ErrSchemaViolation, path, name)
+			}
+		}
+
+		for name, property := range v {
+			schema, ok := s.Properties[name]
+
+			switch {
+			case ok:
+				if err := schema.validate(path+"."+name, property); err != nil {
+					return err
+				}
+			case s.AdditionalProperties != nil && !*s.AdditionalProperties:
+				return fmt.Errorf("%w: %s: unexpected property %q", ErrSchemaViolation, path, name)
+			}
+		}
+	case []any:
+		if s.Items == nil {
+			return nil
+		}
+
+		for i, item := range v {
+			if err := s.Items.validate(path+"["+strconv.Itoa(i)+"]", item); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (s *jsonSchema) validateType(path string, value any) error {
+	if len(s.Type) == 0 {
+		return nil
+	}
+
+	var types []string
+
+	if err := json.Unmarshal(s.Type, &types); err != nil {
+		var single string
+
+		if err = json.Unmarshal(s.Type, &single); err != nil {
+			return fmt.Errorf("invalid type in schema at %s: %w", path, err)
+		}
+
+		types = []string{single}
+	}
+
+	actual := jsonType(value)
+
+	for _, t := range types {
+		if t == actual || (t == "number" && actual == "integer") {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%w: %s: expected %s, got %s", ErrSchemaViolation, path, strings.Join(types, " or "), actual)
+}
+
+func jsonType(value any) string {
+	switch v := value.(type) {
+	case map[string]any:
+		return "object"
+	case []any:
+		return "array"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case json.Number:
+		if _, err := v.Int64(); err == nil {
+			return "integer"
+		}
+
+		return "number"
+	default:
+		return "null"
+	}
+}
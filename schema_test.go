@@ -0,0 +1,124 @@
+// GNU AFFERO GENERAL PUBLIC LICENSE
+// Version 3, 19 November 2007
+//
+// Copyright (C) 2025 John Kleijn
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// For more details, see the full AGPL-3.0 license at:
+// https://www.gnu.org/licenses/agpl-3.0.html
+
+package rtkv_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/johnknl/rtkv"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const personSchema = `{
+	"type": "object",
+	"required": ["name"],
+	"additionalProperties": false,
+	"properties": {
+		"name": {"type": "string"},
+		"age": {"type": "integer"},
+		"tags": {"type": "array", "items": {"type": "string"}}
+	}
+}`
+
+func TestValidateJSONSchema(t *testing.T) {
+	for _, value := range []string{
+		`{"name": "a"}`,
+		`{"name": "a", "age": 3, "tags": ["x"]}`,
+	} {
+		assert.NoError(t, rtkv.ValidateJSONSchema(personSchema, []byte(value)), value)
+	}
+
+	for _, value := range []string{
+		`{"age": 3}`,
+		`{"name": 1}`,
+		`{"name": "a", "age": 3.5}`,
+		`{"name": "a", "tags": [1]}`,
+		`{"name": "a", "other": true}`,
+		`[]`,
+		`not json`,
+	} {
+		assert.ErrorIs(t, rtkv.ValidateJSONSchema(personSchema, []byte(value)), rtkv.ErrSchemaViolation, value)
+	}
+}
+
+func TestConfluentRegistry(t *testing.T) {
+	ctx := context.Background()
+
+	var requests atomic.Int32
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+
+		if r.URL.Path != "/subjects/TestConfluentRegistry-value/versions/latest" {
+			http.NotFound(w, r)
+
+			return
+		}
+
+		_ = json.NewEncoder(w).Encode(rtkv.RegisteredSchema{
+			Subject: "TestConfluentRegistry-value",
+			Version: 1,
+			ID:      7,
+			Type:    "JSON",
+			Schema:  personSchema,
+		})
+	}))
+	t.Cleanup(srv.Close)
+
+	store := newRTKV(t, newGoRedisClient(0)).With(rtkv.WithSchemaRegistry(&rtkv.ConfluentRegistry{URL: srv.URL}))
+
+	_, err := store.Set(ctx, []byte(`{"name": "a"}`), time.Now(), "a")
+	require.NoError(t, err)
+
+	_, err = store.Set(ctx, []byte(`{"age": 1}`), time.Now(), "b")
+	require.ErrorIs(t, err, rtkv.ErrSchemaViolation)
+
+	err = store.BulkSet(ctx, []rtkv.BulkSetRecord{
+		{LastModified: time.Now(), ID: []string{"c"}, Data: []byte(`{"name": "c"}`)},
+		{LastModified: time.Now(), ID: []string{"d"}, Data: []byte(`{}`)},
+	})
+	require.ErrorIs(t, err, rtkv.ErrSchemaViolation)
+
+	_, err = store.UpdateMany(ctx, [][]string{{"a"}}, func(_ []string, _ []byte) ([]byte, bool, error) {
+		return []byte(`{"name": false}`), true, nil
+	})
+	require.ErrorIs(t, err, rtkv.ErrSchemaViolation)
+
+	_, total, err := store.FetchPage(ctx, nil, nil, 0, 10)
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, total)
+	assert.EqualValues(t, 1, requests.Load(), "the schema should be cached")
+
+	other := rtkv.NewRedisTKV(rtkv.DelimUnit, "unregistered", newGoRedisClient(0),
+		rtkv.WithSchemaRegistry(&rtkv.ConfluentRegistry{URL: srv.URL}))
+
+	_, err = other.Set(ctx, []byte(`{}`), time.Now(), "a")
+	require.Error(t, err)
+	assert.NotErrorIs(t, err, rtkv.ErrSchemaViolation)
+}
@@ -0,0 +1,142 @@
+// GNU AFFERO GENERAL PUBLIC LICENSE
+// Version 3, 19 November 2007
+//
+// Copyright (C) 2025 John Kleijn
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// For more details, see the full AGPL-3.0 license at:
+// https://www.gnu.org/licenses/agpl-3.0.html
+
+package rtkv
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// scripts are the Lua scripts of the package, loaded by Preload.
+// New scripts must be added here.
+var scripts = []string{
+	compactHistoryScript,
+	compactScript,
+	compareAndSetScript,
+	copyScript,
+	cursorScript,
+	deleteRangeScript,
+	evictScript,
+	getDelScript,
+	getSetScript,
+	idPageScript,
+	rangeScript,
+	releaseLockScript,
+	removeExpiredScript,
+	renameScript,
+	restoreScript,
+	setIfAbsentScript,
+	setIfChangedScript,
+	setIfNewerScript,
+	snapshotScript,
+	snapshotViewScript,
+	swapMembersScript,
+	swapRenameScript,
+	touchScript,
+	writeIfNotNewerScript,
+}
+
+// ScriptStats counts Lua script loads.
+type ScriptStats struct {
+	// Loads is the number of scripts loaded into Redis.
+	Loads int64
+
+	// Resets is the number of times Redis lost the loaded scripts,
+	// for example after a restart or failover, so all of them had
+	// to be loaded again.
+	Resets int64
+}
+
+// scriptCache holds the SHAs of loaded scripts, each loaded once on
+// first use. It is shared between a store and its clones.
+type scriptCache struct {
+	shas   sync.Map // script source -> *lazy[string]
+	loads  atomic.Int64
+	resets atomic.Int64
+}
+
+// Preload loads all scripts of the package in a single round trip,
+// so the first operations using them don't pay for loading them.
+func (r *RedisTKV) Preload(ctx context.Context) error {
+	cmds := make([]*redis.StringCmd, len(scripts))
+
+	_, err := r.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for i, src := range scripts {
+			cmds[i] = pipe.ScriptLoad(ctx, src)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to load scripts: %w", err)
+	}
+
+	r.scripts.loads.Add(int64(len(scripts)))
+
+	for i, src := range scripts {
+		r.script(src).set(cmds[i].Val())
+	}
+
+	return nil
+}
+
+func (r *RedisTKV) getScriptSHA(ctx context.Context, src string) (string, error) {
+	sha, err := r.script(src).get(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to load lua script: %w", err)
+	}
+
+	return sha, nil
+}
+
+// forgetScript is called when Redis no longer knows a script. As
+// Redis loses all scripts at once, all of them are loaded again.
+func (r *RedisTKV) forgetScript(_ string) {
+	r.scripts.resets.Add(1)
+
+	r.scripts.shas.Range(func(_, l any) bool {
+		l.(*lazy[string]).reset() //nolint:forcetypeassert // only *lazy[string]s are stored
+
+		return true
+	})
+}
+
+func (r *RedisTKV) script(src string) *lazy[string] {
+	l, ok := r.scripts.shas.Load(src)
+	if !ok {
+		l, _ = r.scripts.shas.LoadOrStore(src, newLazy(func(ctx context.Context) (string, error) {
+			r.scripts.loads.Add(1)
+
+			return r.client.ScriptLoad(ctx, src).Result() //nolint:wrapcheck // wrapped by getScriptSHA
+		}))
+	}
+
+	return l.(*lazy[string]) //nolint:forcetypeassert // only *lazy[string]s are stored
+}
+
+func (c *scriptCache) stats() ScriptStats {
+	return ScriptStats{Loads: c.loads.Load(), Resets: c.resets.Load()}
+}
@@ -0,0 +1,62 @@
+// GNU AFFERO GENERAL PUBLIC LICENSE
+// Version 3, 19 November 2007
+//
+// Copyright (C) 2025 John Kleijn
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// For more details, see the full AGPL-3.0 license at:
+// https://www.gnu.org/licenses/agpl-3.0.html
+
+package rtkv_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/johnknl/rtkv"
+	"github.com/johnknl/rtkv/rtkvtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedisTKV_Preload(t *testing.T) {
+	ctx := context.Background()
+	_, client := rtkvtest.NewMiniredis(t)
+	store := rtkv.NewRedisTKV(rtkv.DelimUnit, t.Name(), client)
+
+	require.NoError(t, store.Preload(ctx))
+
+	loads := store.Stats().Scripts.Loads
+	assert.Positive(t, loads)
+
+	_, err := store.SetIfAbsent(ctx, []byte("a"), time.Now(), "a")
+	require.NoError(t, err)
+	assert.Equal(t, loads, store.Stats().Scripts.Loads, "Preloaded scripts should not be loaded again")
+
+	// Redis loses all scripts on a restart or failover.
+	require.NoError(t, client.ScriptFlush(ctx).Err())
+
+	value, err := store.GetDel(ctx, "a")
+	require.NoError(t, err)
+	assert.Equal(t, "a", string(value))
+
+	_, err = store.SetIfAbsent(ctx, []byte("b"), time.Now(), "b")
+	require.NoError(t, err)
+
+	stats := store.Stats().Scripts
+	assert.Equal(t, int64(1), stats.Resets, "All scripts should be reset at once")
+	assert.Equal(t, loads+2, stats.Loads)
+}
@@ -0,0 +1,147 @@
+// GNU AFFERO GENERAL PUBLIC LICENSE
+// Version 3, 19 November 2007
+//
+// Copyright (C) 2025 John Kleijn
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// For more details, see the full AGPL-3.0 license at:
+// https://www.gnu.org/licenses/agpl-3.0.html
+
+package rtkv
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"runtime/debug"
+	"sync"
+)
+
+// Getter is implemented by anything entities can be read from by ID,
+// such as another RedisTKV in a different namespace or on a different
+// Redis instance.
+type Getter interface {
+	Get(ctx context.Context, id ...string) ([]byte, error)
+}
+
+// ShadowMismatch describes a read where the shadow backend
+// disagreed with the primary.
+type ShadowMismatch struct {
+	ID      []string
+	Primary []byte
+	Shadow  []byte
+	Err     error
+}
+
+// ShadowMismatchFunc is called for every sampled read where the
+// shadow backend returned different data or failed.
+type ShadowMismatchFunc func(ctx context.Context, mismatch ShadowMismatch)
+
+// maxShadowReads is the number of shadow reads in flight at once.
+// Reads sampled while all are busy are dropped.
+const maxShadowReads = 16
+
+type shadowReads struct {
+	backend    Getter
+	rate       float64
+	onMismatch ShadowMismatchFunc
+	inflight   sync.WaitGroup
+	slots      chan struct{}
+}
+
+// WithShadowReads mirrors a fraction of successful Get calls to a second
+// backend and reports any differences through onMismatch. The rate is
+// the fraction of reads to sample, between 0 and 1.
+//
+// Shadow reads run in the background and never affect the result
+// returned to the caller. At most 16 run at once; reads sampled
+// while all of them are busy aren't mirrored. Use this to verify
+// a new storage layout before cutting over to it.
+func WithShadowReads(backend Getter, rate float64, onMismatch ShadowMismatchFunc) Option {
+	return func(r *RedisTKV) {
+		r.shadow = &shadowReads{
+			backend:    backend,
+			rate:       rate,
+			onMismatch: onMismatch,
+			slots:      make(chan struct{}, maxShadowReads),
+		}
+	}
+}
+
+// wait waits for in-flight shadow reads until ctx is done.
+func (s *shadowReads) wait(ctx context.Context) error {
+	if s == nil {
+		return nil
+	}
+
+	done := make(chan struct{})
+
+	go func() {
+		s.inflight.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("shadow reads did not finish: %w", context.Cause(ctx))
+	}
+}
+
+func (s *shadowReads) sample(ctx context.Context, logger *slog.Logger, primary []byte, id []string) {
+	if s == nil || s.onMismatch == nil || s.rate <= 0 ||
+		rand.Float64() >= s.rate { //nolint:gosec // sampling needs no crypto
+		return
+	}
+
+	select {
+	case s.slots <- struct{}{}:
+	default:
+		return
+	}
+
+	ctx = context.WithoutCancel(ctx)
+	primary = bytes.Clone(primary)
+	id = append([]string(nil), id...)
+
+	s.inflight.Add(1)
+
+	go func() {
+		defer s.inflight.Done()
+		defer func() { <-s.slots }()
+		defer func() {
+			if v := recover(); v != nil {
+				logger.ErrorContext(ctx, "shadow read panicked",
+					"error", &PanicError{Value: v, Stack: debug.Stack()},
+				)
+			}
+		}()
+
+		shadow, err := getFrom(ctx, s.backend, id)
+		if err == nil && bytes.Equal(primary, shadow) {
+			return
+		}
+
+		s.onMismatch(ctx, ShadowMismatch{
+			ID:      id,
+			Primary: primary,
+			Shadow:  shadow,
+			Err:     err,
+		})
+	}()
+}
@@ -0,0 +1,136 @@
+// GNU AFFERO GENERAL PUBLIC LICENSE
+// Version 3, 19 November 2007
+//
+// Copyright (C) 2025 John Kleijn
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// For more details, see the full AGPL-3.0 license at:
+// https://www.gnu.org/licenses/agpl-3.0.html
+
+package rtkv_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/johnknl/rtkv"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedisTKV_ShadowReads(t *testing.T) {
+	ctx := context.Background()
+
+	redisClient := newGoRedisClient(0)
+
+	t.Cleanup(func() {
+		redisClient.FlushDB(ctx).Err()
+	})
+
+	mismatches := make(chan rtkv.ShadowMismatch, 1)
+
+	shadow := rtkv.NewRedisTKV(rtkv.DelimUnit, t.Name()+"shadow", redisClient)
+	store := rtkv.NewRedisTKV(rtkv.DelimUnit, t.Name(), redisClient,
+		rtkv.WithShadowReads(shadow, 1, func(_ context.Context, m rtkv.ShadowMismatch) {
+			mismatches <- m
+		}),
+	)
+
+	now := time.Now()
+
+	_, err := store.Set(ctx, []byte(`{"id": "a"}`), now, "a")
+	require.NoError(t, err)
+
+	_, err = shadow.Set(ctx, []byte(`{"id": "a"}`), now, "a")
+	require.NoError(t, err)
+
+	_, err = store.Set(ctx, []byte(`{"id": "b"}`), now, "b")
+	require.NoError(t, err)
+
+	_, err = store.Get(ctx, "a")
+	require.NoError(t, err)
+
+	data, err := store.Get(ctx, "b")
+	require.NoError(t, err)
+	assert.Equal(t, []byte(`{"id": "b"}`), data, "Primary result should not be affected by shadow reads")
+
+	select {
+	case m := <-mismatches:
+		assert.Equal(t, []string{"b"}, m.ID)
+		assert.Equal(t, []byte(`{"id": "b"}`), m.Primary)
+		assert.Nil(t, m.Shadow)
+		require.NoError(t, m.Err)
+	case <-time.After(time.Second):
+		t.Fatal("Expected a shadow mismatch to be reported")
+	}
+
+	select {
+	case m := <-mismatches:
+		t.Fatalf("Unexpected shadow mismatch for %v", m.ID)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+// blockingGetter blocks Get until release is closed,
+// tracking the most calls in flight at once.
+type blockingGetter struct {
+	release  chan struct{}
+	mx       sync.Mutex
+	inflight int
+	peak     int
+}
+
+func (g *blockingGetter) Get(context.Context, ...string) ([]byte, error) {
+	g.mx.Lock()
+	g.inflight++
+	g.peak = max(g.peak, g.inflight)
+	g.mx.Unlock()
+
+	<-g.release
+
+	g.mx.Lock()
+	g.inflight--
+	g.mx.Unlock()
+
+	return nil, nil
+}
+
+func TestRedisTKV_ShadowReads_Bounded(t *testing.T) {
+	ctx := context.Background()
+	backend := &blockingGetter{release: make(chan struct{})}
+	store := newRTKV(t, newGoRedisClient(0)).With(
+		rtkv.WithShadowReads(backend, 1, func(context.Context, rtkv.ShadowMismatch) {}),
+	)
+
+	_, err := store.Set(ctx, []byte("a"), time.Now(), "a")
+	require.NoError(t, err)
+
+	for range 100 {
+		_, err = store.Get(ctx, "a")
+		require.NoError(t, err)
+	}
+
+	close(backend.release)
+	require.NoError(t, store.Shutdown(ctx))
+	assert.LessOrEqual(t, backend.peak, 16, "Shadow reads in flight should be bounded")
+
+	withoutCallback := store.With(rtkv.WithShadowReads(backend, 1, nil))
+
+	_, err = withoutCallback.Get(ctx, "a")
+	require.NoError(t, err)
+	require.NoError(t, withoutCallback.Shutdown(ctx))
+}
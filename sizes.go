@@ -0,0 +1,154 @@
+// GNU AFFERO GENERAL PUBLIC LICENSE
+// Version 3, 19 November 2007
+//
+// Copyright (C) 2025 John Kleijn
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// For more details, see the full AGPL-3.0 license at:
+// https://www.gnu.org/licenses/agpl-3.0.html
+
+package rtkv
+
+import (
+	"context"
+	"fmt"
+	"math/bits"
+	"sort"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// sizeBuckets is the number of power of two buckets in the value
+// size histogram: up to 16 bytes, up to 32 bytes, ..., above 128 MiB.
+const sizeBuckets = 25
+
+// EntitySize is the memory used by an entity.
+type EntitySize struct {
+	ID    []string
+	Bytes int64
+}
+
+// SizeBucket is a bucket of the value size histogram.
+type SizeBucket struct {
+	// UpTo is the inclusive upper bound of the bucket in bytes.
+	// The last bucket has no upper bound and UpTo is -1.
+	UpTo  int64
+	Count int64
+}
+
+// LargestEntitiesOptions controls LargestEntities.
+type LargestEntitiesOptions struct {
+	// Samples, if set, measures only this many randomly chosen
+	// entities instead of all of them, for large namespaces.
+	Samples int
+
+	// ChunkSize is the number of entities measured per round trip.
+	// Defaults to 1000.
+	ChunkSize int
+}
+
+// LargestEntities returns the topN entities using the most memory,
+// largest first, as reported by MEMORY USAGE. Use it to find the
+// blobs dominating Redis memory. Entities compacted out of the
+// index are not considered.
+func (r *RedisTKV) LargestEntities(ctx context.Context, topN int, opts LargestEntitiesOptions) ([]EntitySize, error) {
+	if opts.ChunkSize <= 0 {
+		opts.ChunkSize = defaultTransferBatchSize
+	}
+
+	index := r.namespacedKey(lastModifiedIdxSuffix)
+
+	var sizes []EntitySize
+
+	measure := func(keys []string) error {
+		cmds := make([]*redis.IntCmd, len(keys))
+
+		_, err := r.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+			for i, key := range keys {
+				cmds[i] = pipe.MemoryUsage(ctx, key)
+			}
+
+			return nil
+		})
+		if err != nil && err != redis.Nil { //nolint:errorlint // redis.Nil is never wrapped
+			return fmt.Errorf("failed to measure entities: %w", err)
+		}
+
+		for i, cmd := range cmds {
+			if cmd.Err() == nil {
+				sizes = append(sizes, EntitySize{ID: r.idFromKey(keys[i]), Bytes: cmd.Val()})
+			}
+		}
+
+		sort.Slice(sizes, func(i, j int) bool { return sizes[i].Bytes > sizes[j].Bytes })
+
+		if len(sizes) > topN {
+			sizes = sizes[:topN]
+		}
+
+		return nil
+	}
+
+	if opts.Samples > 0 {
+		keys, err := r.client.ZRandMember(ctx, index, opts.Samples).Result()
+		if err != nil {
+			return nil, fmt.Errorf("failed to sample entities: %w", err)
+		}
+
+		for start := 0; start < len(keys); start += opts.ChunkSize {
+			if err = measure(keys[start:min(start+opts.ChunkSize, len(keys))]); err != nil {
+				return nil, err
+			}
+		}
+
+		return sizes, nil
+	}
+
+	for offset := int64(0); ; offset += int64(opts.ChunkSize) {
+		keys, err := r.client.ZRange(ctx, index, offset, offset+int64(opts.ChunkSize)-1).Result()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read index: %w", err)
+		}
+
+		if err = measure(keys); err != nil {
+			return nil, err
+		}
+
+		if len(keys) < opts.ChunkSize {
+			return sizes, nil
+		}
+	}
+}
+
+// sizeBucket returns the histogram bucket of a value size.
+func sizeBucket(size int) int {
+	if size <= 16 { //nolint:mnd // first bucket
+		return 0
+	}
+
+	return min(bits.Len(uint(size-1))-4, sizeBuckets-1) //nolint:mnd // 2^4 is the first bucket
+}
+
+func sizeHistogram(counts *[sizeBuckets]int64) []SizeBucket {
+	histogram := make([]SizeBucket, sizeBuckets)
+
+	for i := range histogram {
+		histogram[i] = SizeBucket{UpTo: 16 << i, Count: counts[i]} //nolint:mnd // first bucket
+	}
+
+	histogram[sizeBuckets-1].UpTo = -1
+
+	return histogram
+}
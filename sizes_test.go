@@ -0,0 +1,63 @@
+// GNU AFFERO GENERAL PUBLIC LICENSE
+// Version 3, 19 November 2007
+//
+// Copyright (C) 2025 John Kleijn
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// For more details, see the full AGPL-3.0 license at:
+// https://www.gnu.org/licenses/agpl-3.0.html
+
+package rtkv_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/johnknl/rtkv"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedisTKV_LargestEntities(t *testing.T) {
+	ctx := context.Background()
+	store := newRTKV(t, newGoRedisClient(0))
+
+	for id, size := range map[string]int{"small": 10, "medium": 1000, "large": 100_000, "tiny": 1} {
+		_, err := store.Set(ctx, []byte(strings.Repeat("x", size)), time.Now(), id)
+		require.NoError(t, err)
+	}
+
+	largest, err := store.LargestEntities(ctx, 2, rtkv.LargestEntitiesOptions{ChunkSize: 3})
+
+	require.NoError(t, err)
+	require.Len(t, largest, 2)
+	assert.Equal(t, []string{"large"}, largest[0].ID)
+	assert.Equal(t, []string{"medium"}, largest[1].ID)
+	assert.GreaterOrEqual(t, largest[0].Bytes, int64(100_000))
+
+	sampled, err := store.LargestEntities(ctx, 1, rtkv.LargestEntitiesOptions{Samples: 10})
+
+	require.NoError(t, err)
+	assert.Equal(t, largest[:1], sampled)
+
+	histogram := store.Stats().ValueSizes
+
+	assert.EqualValues(t, 16, histogram[0].UpTo)
+	assert.EqualValues(t, 2, histogram[0].Count)
+	assert.EqualValues(t, 1, histogram[6].Count, "1000 bytes are in the 1 KiB bucket")
+	assert.EqualValues(t, -1, histogram[len(histogram)-1].UpTo)
+}
@@ -0,0 +1,219 @@
+// GNU AFFERO GENERAL PUBLIC LICENSE
+// Version 3, 19 November 2007
+//
+// Copyright (C) 2025 John Kleijn
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// For more details, see the full AGPL-3.0 license at:
+// https://www.gnu.org/licenses/agpl-3.0.html
+
+package rtkv
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// contentHashesSuffix names the hash holding the content hashes
+// of entities written with WithSkipIdenticalWrites.
+const contentHashesSuffix = "contentHashes"
+
+// setIfChangedScript sets entities unless their stored values are
+// byte-identical, in which case nothing is written at all. Returns for
+// every entity -1 if the write was skipped, otherwise the number of
+// index entries added (1 if the entity is new).
+//
+// Values are compared by their SHA-256, stored with the lastModified
+// score it was written at. A write through any other path moves the
+// score or removes the entity, which invalidates the stored hash.
+// Without a valid hash, as after such writes, the stored value is
+// read and compared instead, until the entity is written again.
+const setIfChangedScript = `
+local index = KEYS[1] -- the lastModified index
+local expiry = KEYS[2] -- the expiry index
+local versions = KEYS[3] -- the versions hash
+local hashes = KEYS[4] -- the content hashes
+local versioned = ARGV[1] == "1" -- whether to increment versions
+local results = {}
+
+for i = 5, #KEYS do
+  local key = KEYS[i] -- the entity key
+  local offset = 1 + (i - 5) * 5
+  local data = ARGV[offset + 1] -- the new value
+  local hash = ARGV[offset + 2] -- the SHA-256 of the new value
+  local score = ARGV[offset + 3] -- the lastModified score
+  local ttl = tonumber(ARGV[offset + 4]) -- the TTL in milliseconds, or 0
+  local expireAt = ARGV[offset + 5] -- the expiry score
+  local stored = redis.call("HGET", hashes, key)
+  local current = redis.call("ZSCORE", index, key)
+  local unchanged
+
+  if stored and current and string.sub(stored, 1, #current + 1) == current .. ":" then
+    unchanged = stored == current .. ":" .. hash and redis.call("EXISTS", key) == 1
+  else
+    unchanged = redis.call("GET", key) == data
+  end
+
+  if unchanged then
+    results[#results + 1] = -1
+  else
+    if ttl > 0 then
+      redis.call("SET", key, data, "PX", ttl)
+      redis.call("ZADD", expiry, expireAt, key)
+    else
+      redis.call("SET", key, data)
+    end
+
+    if versioned then
+      redis.call("HINCRBY", versions, key, 1)
+    end
+
+    results[#results + 1] = redis.call("ZADD", index, score, key)
+    redis.call("HSET", hashes, key, redis.call("ZSCORE", index, key) .. ":" .. hash)
+  end
+end
+
+return results
+`
+
+// WithSkipIdenticalWrites makes Set and BulkSet compare a hash of new
+// values with that of the stored ones, and skip writes that would not
+// change them. Skipped writes are no-ops: they don't update the
+// lastModified time or extend the TTL, so unchanged upstream records
+// don't show up as changes when fetching pages.
+func WithSkipIdenticalWrites() Option {
+	return func(r *RedisTKV) {
+		r.skipIdentical = true
+	}
+}
+
+// setIfChanged writes an entity using setIfChangedScript and
+// returns whether it existed before.
+func (r *RedisTKV) setIfChanged(
+	ctx context.Context,
+	data []byte,
+	timestamp int64,
+	ttl time.Duration,
+	key string,
+) (bool, error) {
+	record := BulkSetRecord{LastModified: time.Unix(0, timestamp), ID: r.idFromKey(key), Data: data, TTL: ttl}
+
+	added, err := r.writeIfChanged(ctx, []BulkSetRecord{record})
+	if err != nil {
+		return false, fmt.Errorf("failed to set entity: %w", err)
+	}
+
+	return added[0] != 1, nil
+}
+
+// conditionalSetIndexes updates the secondary indexes after
+// a script wrote an entity.
+func (r *RedisTKV) conditionalSetIndexes(ctx context.Context, data []byte, timestamp int64, key string) error {
+	_, err := r.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		r.writerAdd(ctx, pipe)
+		r.secondaryIndexAdd(ctx, pipe, float64(timestamp), key, r.idFromKey(key))
+		r.historyAdd(ctx, pipe, timestamp, key, data)
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update indexes: %w", err)
+	}
+
+	return r.compactedRemove(ctx, false, key)
+}
+
+// bulkSetIfChanged writes records using setIfChangedScript
+// in a single call.
+func (r *RedisTKV) bulkSetIfChanged(ctx context.Context, records []BulkSetRecord) error {
+	if _, err := r.writeIfChanged(ctx, records); err != nil {
+		return fmt.Errorf("failed to bulk insert records: %w", err)
+	}
+
+	return nil
+}
+
+// writeIfChanged runs setIfChangedScript over records, already
+// encoded and with their TTLs resolved for setIfChanged, and updates
+// the secondary indexes of those written. Returns the result of the
+// script for every record.
+func (r *RedisTKV) writeIfChanged(ctx context.Context, records []BulkSetRecord) ([]int64, error) {
+	versions, versioned := r.versionsArgs()
+	keys := make([]string, 4, 4+len(records)) //nolint:mnd // index, expiry, versions and hashes
+	keys[0], keys[1], keys[2] = r.namespacedKey(lastModifiedIdxSuffix), r.internalKey(expirySuffix), versions
+	keys[3] = r.internalKey(contentHashesSuffix)
+	args := make([]any, 1, 1+len(records)*5) //nolint:mnd // arguments per record
+	args[0] = versioned
+
+	for i := range records {
+		ttl := r.ttlFor(records[i].TTL)
+		hash := sha256.Sum256(records[i].Data)
+		keys = append(keys, r.namespacedKey(records[i].ID...))
+		args = append(args, records[i].Data, hex.EncodeToString(hash[:]), records[i].LastModified.UnixNano(),
+			ttl.Milliseconds(), time.Now().Add(ttl).UnixNano())
+	}
+
+	result, err := r.evalScript(ctx, setIfChangedScript, keys, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	results, ok := result.([]any)
+	if !ok || len(results) != len(records) {
+		return nil, ErrUnexpectedScriptResult
+	}
+
+	added := make([]int64, len(records))
+	written := make([]string, 0, len(records))
+
+	_, err = r.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		r.writerAdd(ctx, pipe)
+
+		for i := range records {
+			added[i], _ = results[i].(int64)
+			r.stats.recordConditionalSet(ctx, len(records[i].Data), added[i])
+
+			if added[i] >= 0 {
+				timestamp := records[i].LastModified.UnixNano()
+				key := keys[4+i]
+
+				r.secondaryIndexAdd(ctx, pipe, float64(timestamp), key, records[i].ID)
+				r.historyAdd(ctx, pipe, timestamp, key, records[i].Data)
+				written = append(written, key)
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return added, fmt.Errorf("failed to update indexes: %w", err)
+	}
+
+	return added, r.compactedRemove(ctx, false, written...)
+}
+
+// contentHashRemove removes the content hash of a deleted entity.
+func (r *RedisTKV) contentHashRemove(ctx context.Context, pipe redis.Pipeliner, key string) {
+	if !r.skipIdentical {
+		return
+	}
+
+	pipe.HDel(ctx, r.internalKey(contentHashesSuffix), key)
+}
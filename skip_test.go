@@ -0,0 +1,143 @@
+// GNU AFFERO GENERAL PUBLIC LICENSE
+// Version 3, 19 November 2007
+//
+// Copyright (C) 2025 John Kleijn
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// For more details, see the full AGPL-3.0 license at:
+// https://www.gnu.org/licenses/agpl-3.0.html
+
+package rtkv_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/johnknl/rtkv"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedisTKV_SkipIdenticalWrites(t *testing.T) {
+	ctx := context.Background()
+
+	redisClient := newGoRedisClient(0)
+
+	t.Cleanup(func() {
+		redisClient.FlushDB(ctx).Err()
+	})
+
+	store := newRTKV(t, redisClient).With(rtkv.WithSkipIdenticalWrites())
+	then := time.Now().Add(-time.Hour)
+	now := time.Now()
+
+	existed, err := store.Set(ctx, []byte(`{"id": "a"}`), then, "a")
+
+	require.NoError(t, err)
+	assert.False(t, existed)
+
+	existed, err = store.Set(ctx, []byte(`{"id": "a"}`), now, "a")
+
+	require.NoError(t, err)
+	assert.True(t, existed)
+
+	require.NoError(t, store.BulkSet(ctx, []rtkv.BulkSetRecord{
+		{ID: []string{"a"}, Data: []byte(`{"id": "a"}`), LastModified: now},
+		{ID: []string{"b"}, Data: []byte(`{"id": "b"}`), LastModified: now},
+	}))
+
+	_, total, err := store.FetchPage(ctx, &now, nil, 0, 10)
+
+	require.NoError(t, err)
+	assert.EqualValuesf(t, 1, total, "Identical writes should not bump lastModified")
+
+	existed, err = store.Set(ctx, []byte(`{"id": "a", "v": 2}`), now, "a")
+
+	require.NoError(t, err)
+	assert.True(t, existed)
+
+	_, total, err = store.FetchPage(ctx, &now, nil, 0, 10)
+
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, total)
+
+	stats := store.Stats().Writes
+
+	assert.EqualValues(t, 2, stats.Skipped)
+	assert.EqualValues(t, 3, stats.Sets)
+	assert.EqualValues(t, 2, stats.Creates)
+}
+
+func TestRedisTKV_SkipIdenticalWrites_ScriptFlush(t *testing.T) {
+	ctx := context.Background()
+	client := newGoRedisClient(0)
+	store := newRTKV(t, client).With(
+		rtkv.WithSkipIdenticalWrites(),
+		rtkv.WithChangeFeed(rtkv.ChangeFeedOptions{}),
+	)
+	now := time.Now()
+
+	_, err := store.Clear(ctx)
+	require.NoError(t, err)
+
+	require.NoError(t, store.BulkSet(ctx, []rtkv.BulkSetRecord{
+		{LastModified: now, ID: []string{"a"}, Data: []byte("a")},
+	}))
+	require.NoError(t, client.ScriptFlush(ctx).Err())
+
+	err = store.BulkSet(ctx, []rtkv.BulkSetRecord{
+		{LastModified: now, ID: []string{"a"}, Data: []byte("a")},
+		{LastModified: now, ID: []string{"b"}, Data: []byte("b")},
+	})
+	require.NoError(t, err, "A flushed script should be reloaded")
+	assert.EqualValues(t, 1, store.Stats().Writes.Skipped)
+
+	events, err := store.ReadChanges(ctx, "0", 10)
+	require.NoError(t, err)
+	assert.Len(t, events, 2, "Only written entities should be published")
+}
+
+func TestRedisTKV_SkipIdenticalWrites_OtherWriters(t *testing.T) {
+	ctx := context.Background()
+	client := newGoRedisClient(0)
+	plain := newRTKV(t, client)
+	store := plain.With(rtkv.WithSkipIdenticalWrites())
+	now := time.Now()
+
+	_, err := store.Clear(ctx)
+	require.NoError(t, err)
+
+	_, err = store.Set(ctx, []byte("v1"), now, "a")
+	require.NoError(t, err)
+
+	hashes := t.Name() + rtkv.DelimUnit + "\x00rtkv:contentHashes"
+	assert.True(t, client.HExists(ctx, hashes, t.Name()+rtkv.DelimUnit+"a").Val(), "The content hash should be stored")
+
+	// A write without the option leaves a stale hash behind.
+	_, err = plain.Set(ctx, []byte("v2"), now.Add(time.Second), "a")
+	require.NoError(t, err)
+
+	_, err = store.Set(ctx, []byte("v1"), now.Add(2*time.Second), "a")
+	require.NoError(t, err)
+	assert.Zero(t, store.Stats().Writes.Skipped, "A stale hash should not skip writes")
+
+	value, err := store.Get(ctx, "a")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("v1"), value)
+
+	require.NoError(t, store.Delete(ctx, "a"))
+	assert.Zero(t, client.HLen(ctx, hashes).Val(), "Deletes should remove the content hash")
+}
@@ -0,0 +1,162 @@
+// GNU AFFERO GENERAL PUBLIC LICENSE
+// Version 3, 19 November 2007
+//
+// Copyright (C) 2025 John Kleijn
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// For more details, see the full AGPL-3.0 license at:
+// https://www.gnu.org/licenses/agpl-3.0.html
+
+package rtkv
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// snapshotScript reads a set of entities and their lastModified
+// scores atomically. For every key it returns either { 0 } if the
+// entity is missing, or { 1, value, score }.
+const snapshotScript = `
+local index = KEYS[1] -- the lastModified index
+
+local values = redis.call("MGET", unpack(KEYS, 2))
+local result = {}
+
+for i, value in ipairs(values) do
+  if value then
+    result[i] = { 1, value, redis.call("ZSCORE", index, KEYS[i + 1]) or "0" }
+  else
+    result[i] = { 0 }
+  end
+end
+
+return result
+`
+
+// SnapshotEntry is a single entity in a snapshot.
+type SnapshotEntry struct {
+	Record
+
+	// Exists reports whether the entity existed when the
+	// snapshot was taken.
+	Exists bool
+}
+
+// GetSnapshot reads the given entities atomically, so invariants
+// spanning multiple entities can be checked against a consistent
+// point-in-time view. Entries are returned in the order of ids.
+// Aliases are followed after the snapshot is taken, so their targets
+// aren't part of it.
+func (r *RedisTKV) GetSnapshot(ctx context.Context, ids ...[]string) ([]SnapshotEntry, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	keys := make([]string, 0, len(ids)+1)
+	keys = append(keys, r.namespacedKey(lastModifiedIdxSuffix))
+
+	for _, id := range ids {
+		keys = append(keys, r.namespacedKey(id...))
+	}
+
+	result, err := r.evalScript(ctx, snapshotScript, keys)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read snapshot: %w", err)
+	}
+
+	rawEntries, ok := result.([]any)
+	if !ok || len(rawEntries) != len(ids) {
+		return nil, ErrUnexpectedScriptResult
+	}
+
+	entries := make([]SnapshotEntry, len(ids))
+
+	for i, rawEntry := range rawEntries {
+		entries[i].ID = ids[i]
+
+		fields, ok := rawEntry.([]any)
+		if !ok || len(fields) == 0 {
+			return nil, ErrUnexpectedScriptResult
+		}
+
+		if exists, _ := fields[0].(int64); exists == 0 {
+			continue
+		}
+
+		if len(fields) != 3 { //nolint:mnd // exists, value, score
+			return nil, ErrUnexpectedScriptResult
+		}
+
+		value, _ := fields[1].(string)
+		score, _ := fields[2].(string)
+
+		nanos, err := strconv.ParseFloat(score, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid lastModified score %q: %w", score, err)
+		}
+
+		data, err := r.readValue(ctx, []byte(value))
+		if err != nil {
+			return nil, err
+		}
+
+		if data == nil {
+			// A dangling alias.
+			continue
+		}
+
+		entries[i].Exists = true
+		entries[i].Data = data
+		entries[i].LastModified = time.Unix(0, int64(nanos))
+	}
+
+	return entries, nil
+}
+
+// GetWithLastModified reads an entity and its lastModified time
+// atomically. Returns a nil value and a zero time if the entity
+// doesn't exist, or ErrNotFound when WithNotFoundError is used.
+// Unlike Get, it doesn't fall back to the archive, which doesn't
+// keep lastModified times.
+func (r *RedisTKV) GetWithLastModified(ctx context.Context, id ...string) ([]byte, time.Time, error) {
+	defer r.observe(ctx, "getWithLastModified", time.Now())
+
+	if err := r.checkID(id); err != nil {
+		return nil, time.Time{}, err
+	}
+
+	entries, err := r.GetSnapshot(ctx, id)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+
+	if !entries[0].Exists {
+		if r.notFoundError {
+			return nil, time.Time{}, ErrNotFound
+		}
+
+		return nil, time.Time{}, nil
+	}
+
+	key := r.namespacedKey(id...)
+
+	r.sampleRead(ctx, key)
+	r.recordReads(ctx, key)
+
+	return entries[0].Data, entries[0].LastModified, nil
+}
@@ -0,0 +1,125 @@
+// GNU AFFERO GENERAL PUBLIC LICENSE
+// Version 3, 19 November 2007
+//
+// Copyright (C) 2025 John Kleijn
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// For more details, see the full AGPL-3.0 license at:
+// https://www.gnu.org/licenses/agpl-3.0.html
+
+package rtkv_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/johnknl/rtkv"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedisTKV_GetSnapshot(t *testing.T) {
+	ctx := context.Background()
+
+	redisClient := newGoRedisClient(0)
+
+	t.Cleanup(func() {
+		redisClient.FlushDB(ctx).Err()
+	})
+
+	store := newRTKV(t, redisClient)
+	now := time.Now()
+
+	_, err := store.Set(ctx, []byte("a"), now, "a")
+	require.NoError(t, err)
+
+	_, err = store.Set(ctx, []byte{}, now, "b")
+	require.NoError(t, err)
+
+	entries, err := store.GetSnapshot(ctx, []string{"a"}, []string{"c"}, []string{"b"})
+
+	require.NoError(t, err)
+	require.Len(t, entries, 3)
+
+	assert.True(t, entries[0].Exists)
+	assert.Equal(t, []string{"a"}, entries[0].ID)
+	assert.Equal(t, []byte("a"), entries[0].Data)
+	assert.WithinDuration(t, now, entries[0].LastModified, time.Microsecond)
+
+	assert.False(t, entries[1].Exists)
+	assert.Equal(t, []string{"c"}, entries[1].ID)
+	assert.Nil(t, entries[1].Data)
+
+	assert.Truef(t, entries[2].Exists, "Empty values should be distinguishable from missing ones")
+	assert.Empty(t, entries[2].Data)
+
+	entries, err = store.GetSnapshot(ctx)
+
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+func TestRedisTKV_GetWithLastModified(t *testing.T) {
+	ctx := context.Background()
+	store := newRTKV(t, newGoRedisClient(0))
+	lastModified := time.Date(2025, 1, 10, 12, 0, 0, 0, time.UTC)
+
+	_, err := store.Set(ctx, []byte("v"), lastModified, "a")
+	require.NoError(t, err)
+
+	value, at, err := store.GetWithLastModified(ctx, "a")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("v"), value)
+	assert.WithinDuration(t, lastModified, at, time.Microsecond)
+
+	value, at, err = store.GetWithLastModified(ctx, "missing")
+	require.NoError(t, err)
+	assert.Nil(t, value)
+	assert.True(t, at.IsZero())
+
+	_, _, err = store.With(rtkv.WithNotFoundError()).GetWithLastModified(ctx, "missing")
+	require.ErrorIs(t, err, rtkv.ErrNotFound)
+}
+
+func TestRedisTKV_GetSnapshot_Alias(t *testing.T) {
+	ctx := context.Background()
+	store := newRTKV(t, newGoRedisClient(0))
+
+	_, err := store.Set(ctx, []byte("target"), time.Now(), "target")
+	require.NoError(t, err)
+	require.NoError(t, store.Alias(ctx, []string{"alias"}, store, []string{"target"}))
+	require.NoError(t, store.Alias(ctx, []string{"dangling"}, store, []string{"missing"}))
+
+	entries, err := store.GetSnapshot(ctx, []string{"alias"}, []string{"dangling"})
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.True(t, entries[0].Exists)
+	assert.Equal(t, []byte("target"), entries[0].Data, "Aliases should be followed")
+	assert.False(t, entries[1].Exists, "Dangling aliases should read as missing")
+}
+
+func TestRedisTKV_GetWithLastModified_Alias(t *testing.T) {
+	ctx := context.Background()
+	store := newRTKV(t, newGoRedisClient(0))
+
+	_, err := store.Set(ctx, []byte("target"), time.Now(), "target")
+	require.NoError(t, err)
+	require.NoError(t, store.Alias(ctx, []string{"alias"}, store, []string{"target"}))
+
+	value, _, err := store.GetWithLastModified(ctx, "alias")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("target"), value, "Aliases should be followed")
+}
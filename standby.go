@@ -0,0 +1,268 @@
+// GNU AFFERO GENERAL PUBLIC LICENSE
+// Version 3, 19 November 2007
+//
+// Copyright (C) 2025 John Kleijn
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// For more details, see the full AGPL-3.0 license at:
+// https://www.gnu.org/licenses/agpl-3.0.html
+
+package rtkv
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	defaultPromoteFreezeTTL      = time.Minute
+	defaultPromoteCatchUpTimeout = 10 * time.Second
+	defaultPromoteSamples        = 100
+
+	catchUpPollInterval = 10 * time.Millisecond
+)
+
+var (
+	// ErrNoStandby is returned by PromoteStandby on
+	// stores without read replicas.
+	ErrNoStandby = errors.New("no standby to promote")
+
+	// ErrStandbyNotReady is returned by PromoteStandby when no
+	// replica caught up with the primary, or the one that did
+	// failed validation.
+	ErrStandbyNotReady = errors.New("standby is not ready for promotion")
+)
+
+// PromoteFunc turns a replica into a primary.
+type PromoteFunc func(ctx context.Context, standby *redis.Client) error
+
+// PromoteOptions controls PromoteStandby.
+type PromoteOptions struct {
+	// FreezeTTL bounds the freeze of the old primary. It should
+	// outlast the time it takes every process to switch over to
+	// the promoted store. Defaults to a minute.
+	FreezeTTL time.Duration
+
+	// CatchUpTimeout is how long to wait for a replica to catch up
+	// with the frozen primary. Defaults to 10 seconds.
+	CatchUpTimeout time.Duration
+
+	// Samples is the number of index entries checked to exist on
+	// the standby. Defaults to 100.
+	Samples int
+
+	// Force promotes the first replica even if the primary can't be
+	// reached, so it can't be frozen or compared with. Writes not
+	// yet replicated are lost.
+	Force bool
+
+	// Promote turns the standby into a primary. Defaults to
+	// REPLICAOF NO ONE; set it for deployments that promote replicas
+	// through Sentinel or a provider API instead.
+	Promote PromoteFunc
+}
+
+// PromoteStandby fails the store over to one of its read replicas.
+// It freezes writes to the namespace on the primary, waits for a
+// replica to catch up, validates it and promotes it. It returns a
+// store routed to the promoted replica, with the freeze lifted and
+// without read replicas; the receiver keeps using the old primary,
+// which stays frozen for PromoteOptions.FreezeTTL so processes that
+// haven't switched over can't write to it.
+//
+// A replica is validated by comparing its index size and the last
+// event of the change feed with the primary, and by checking that
+// sampled index entries exist. On failure the primary is unfrozen
+// and ErrStandbyNotReady is returned.
+func (r *RedisTKV) PromoteStandby(ctx context.Context, opts PromoteOptions) (*RedisTKV, error) {
+	defer r.observe(ctx, "promoteStandby", time.Now())
+
+	if r.replicas == nil || len(r.replicas.replicas) == 0 {
+		return nil, ErrNoStandby
+	}
+
+	opts = opts.withDefaults()
+
+	standby := r.replicas.replicas[0].client
+
+	if err := r.Freeze(ctx, opts.FreezeTTL); err != nil {
+		if !opts.Force {
+			return nil, err
+		}
+	} else {
+		var err error
+		if standby, err = r.catchUp(ctx, opts); err != nil {
+			return nil, errors.Join(err, r.Unfreeze(ctx))
+		}
+	}
+
+	promoted := r.With()
+	promoted.client = standby
+	promoted.replicas = nil
+
+	if r.freeze != nil {
+		promoted.freeze = &freezeState{interval: r.freeze.interval}
+	}
+
+	if err := promoted.checkStandby(ctx, opts.Samples); err != nil {
+		return nil, errors.Join(err, r.Unfreeze(ctx))
+	}
+
+	if err := opts.Promote(ctx, standby); err != nil {
+		return nil, errors.Join(fmt.Errorf("failed to promote standby: %w", err), r.Unfreeze(ctx))
+	}
+
+	// The freeze replicated to the standby along with everything else.
+	if err := promoted.Unfreeze(ctx); err != nil {
+		return nil, err
+	}
+
+	return promoted, nil
+}
+
+func (opts PromoteOptions) withDefaults() PromoteOptions {
+	if opts.FreezeTTL <= 0 {
+		opts.FreezeTTL = defaultPromoteFreezeTTL
+	}
+
+	if opts.CatchUpTimeout <= 0 {
+		opts.CatchUpTimeout = defaultPromoteCatchUpTimeout
+	}
+
+	if opts.Samples <= 0 {
+		opts.Samples = defaultPromoteSamples
+	}
+
+	if opts.Promote == nil {
+		opts.Promote = func(ctx context.Context, standby *redis.Client) error {
+			return standby.Do(ctx, "REPLICAOF", "NO", "ONE").Err()
+		}
+	}
+
+	return opts
+}
+
+// catchUp writes a heartbeat to the frozen primary and returns the
+// first replica to see it whose index and change feed match the
+// primary.
+func (r *RedisTKV) catchUp(ctx context.Context, opts PromoteOptions) (*redis.Client, error) {
+	key := r.internalKey(heartbeatSuffix)
+	sent := time.Now().UnixNano()
+
+	if err := r.client.Set(ctx, key, sent, 0).Err(); err != nil {
+		return nil, fmt.Errorf("failed to write heartbeat: %w", err)
+	}
+
+	want, err := standbyPosition(ctx, r, r.client)
+	if err != nil {
+		return nil, err
+	}
+
+	deadline := time.Now().Add(opts.CatchUpTimeout)
+
+	for {
+		for _, rep := range r.replicas.replicas {
+			if seen, err := rep.client.Get(ctx, key).Int64(); err != nil || seen < sent {
+				continue
+			}
+
+			if got, err := standbyPosition(ctx, r, rep.client); err == nil && got == want {
+				return rep.client, nil
+			}
+		}
+
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("%w: no replica caught up within %v", ErrStandbyNotReady, opts.CatchUpTimeout)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err() //nolint:wrapcheck // context errors are returned as is
+		case <-time.After(catchUpPollInterval):
+		}
+	}
+}
+
+// position is how far a server got replicating the namespace.
+type position struct {
+	indexed    int64
+	lastChange string
+}
+
+func standbyPosition(ctx context.Context, r *RedisTKV, c redis.Cmdable) (position, error) {
+	var pos position
+
+	indexed, err := c.ZCard(ctx, r.namespacedKey(lastModifiedIdxSuffix)).Result()
+	if err != nil {
+		return pos, fmt.Errorf("failed to count index: %w", err)
+	}
+
+	pos.indexed = indexed
+
+	if r.changes != nil {
+		last, err := c.XRevRangeN(ctx, r.ChangeStreamKey(), "+", "-", 1).Result()
+		if err != nil {
+			return pos, fmt.Errorf("failed to read change feed: %w", err)
+		}
+
+		if len(last) > 0 {
+			pos.lastChange = last[0].ID
+		}
+	}
+
+	return pos, nil
+}
+
+// checkStandby checks that sampled index entries exist, unless
+// they expired.
+func (r *RedisTKV) checkStandby(ctx context.Context, samples int) error {
+	keys, err := r.client.ZRandMember(ctx, r.namespacedKey(lastModifiedIdxSuffix), samples).Result()
+	if err != nil {
+		return fmt.Errorf("failed to sample index: %w", err)
+	}
+
+	exists := make([]*redis.IntCmd, len(keys))
+	expiry := make([]*redis.FloatCmd, len(keys))
+
+	_, err = r.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for i, key := range keys {
+			exists[i] = pipe.Exists(ctx, key)
+			expiry[i] = pipe.ZScore(ctx, r.internalKey(expirySuffix), key)
+		}
+
+		return nil
+	})
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return fmt.Errorf("failed to check index: %w", err)
+	}
+
+	var missing int
+
+	for i := range keys {
+		if exists[i].Val() == 0 && expiry[i].Err() != nil {
+			missing++
+		}
+	}
+
+	if missing > 0 {
+		return fmt.Errorf("%w: %d of %d sampled index entries are missing", ErrStandbyNotReady, missing, len(keys))
+	}
+
+	return nil
+}
@@ -0,0 +1,139 @@
+// GNU AFFERO GENERAL PUBLIC LICENSE
+// Version 3, 19 November 2007
+//
+// Copyright (C) 2025 John Kleijn
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// For more details, see the full AGPL-3.0 license at:
+// https://www.gnu.org/licenses/agpl-3.0.html
+
+package rtkv_test
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/johnknl/rtkv"
+	"github.com/johnknl/rtkv/rtkvtest"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// replicationHook replays the commands of a client on another,
+// like a replica following a primary.
+type replicationHook struct {
+	replica *redis.Client
+}
+
+func (h replicationHook) replay(ctx context.Context, cmds ...redis.Cmder) {
+	for _, cmd := range cmds {
+		name := cmd.Name()
+		if cmd.Err() != nil || name == "multi" || name == "exec" {
+			continue
+		}
+
+		args := cmd.Args()
+
+		// Stream IDs generated by the primary must be kept, as a
+		// replica would, so the change feeds of both match.
+		if added, ok := cmd.(*redis.StringCmd); ok && name == "xadd" {
+			args = slices.Clone(args)
+
+			for i, arg := range args {
+				if arg == "*" {
+					args[i] = added.Val()
+				}
+			}
+		}
+
+		_ = h.replica.Do(ctx, args...).Err()
+	}
+}
+
+func (replicationHook) DialHook(next redis.DialHook) redis.DialHook { return next }
+
+func (h replicationHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		err := next(ctx, cmd)
+		h.replay(ctx, cmd)
+
+		return err
+	}
+}
+
+func (h replicationHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		err := next(ctx, cmds)
+		h.replay(ctx, cmds...)
+
+		return err
+	}
+}
+
+func noPromote(context.Context, *redis.Client) error { return nil }
+
+func TestRedisTKV_PromoteStandby(t *testing.T) {
+	ctx := context.Background()
+	_, primary := rtkvtest.NewMiniredis(t)
+	_, standby := rtkvtest.NewMiniredis(t)
+	primary.AddHook(replicationHook{replica: standby})
+
+	store := rtkv.NewRedisTKV(rtkv.DelimUnit, t.Name(), primary,
+		rtkv.WithWriteFreeze(0),
+		rtkv.WithChangeFeed(rtkv.ChangeFeedOptions{}),
+		rtkv.WithReadReplicas(rtkv.ReplicaOptions{}, standby),
+	)
+
+	for _, id := range []string{"a", "b", "c"} {
+		_, err := store.Set(ctx, []byte(id), time.Now(), id)
+		require.NoError(t, err)
+	}
+
+	promoted, err := store.PromoteStandby(ctx, rtkv.PromoteOptions{Promote: noPromote})
+	require.NoError(t, err)
+
+	_, err = store.Set(ctx, []byte("lost"), time.Now(), "d")
+	require.ErrorIs(t, err, rtkv.ErrFrozen, "The old primary should stay frozen")
+
+	_, err = promoted.Set(ctx, []byte("d"), time.Now(), "d")
+	require.NoError(t, err, "The promoted store should be writable")
+
+	total, err := promoted.Count(ctx)
+	require.NoError(t, err)
+	assert.EqualValues(t, 4, total)
+}
+
+func TestRedisTKV_PromoteStandby_NotCaughtUp(t *testing.T) {
+	ctx := context.Background()
+	_, primary := rtkvtest.NewMiniredis(t)
+	_, standby := rtkvtest.NewMiniredis(t)
+
+	store := rtkv.NewRedisTKV(rtkv.DelimUnit, t.Name(), primary,
+		rtkv.WithWriteFreeze(0),
+		rtkv.WithReadReplicas(rtkv.ReplicaOptions{}, standby),
+	)
+
+	_, err := store.PromoteStandby(ctx, rtkv.PromoteOptions{CatchUpTimeout: 50 * time.Millisecond, Promote: noPromote})
+	require.ErrorIs(t, err, rtkv.ErrStandbyNotReady)
+
+	_, err = store.Set(ctx, []byte("a"), time.Now(), "a")
+	require.NoError(t, err, "The primary should be unfrozen")
+
+	_, err = rtkvtest.NewMiniredisTKV(t).PromoteStandby(ctx, rtkv.PromoteOptions{})
+	require.ErrorIs(t, err, rtkv.ErrNoStandby)
+}
@@ -0,0 +1,281 @@
+// GNU AFFERO GENERAL PUBLIC LICENSE
+// Version 3, 19 November 2007
+//
+// Copyright (C) 2025 John Kleijn
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// For more details, see the full AGPL-3.0 license at:
+// https://www.gnu.org/licenses/agpl-3.0.html
+
+package rtkv
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Stats is a snapshot of the statistics a store collected
+// since it was created. Clones created with With share
+// their statistics with the original store.
+type Stats struct {
+	Namespace string
+	Writes    WriteStats
+	Ops       OpStats
+	Scripts   ScriptStats
+
+	// ValueSizes is a histogram of the sizes of values written,
+	// with power of two buckets.
+	ValueSizes []SizeBucket
+
+	// Tags breaks the statistics down by the tags set
+	// with WithOpTag. Untagged operations are not included.
+	Tags map[string]TagStats
+
+	// HotReads and HotWrites are the most read and written
+	// entities. Only set when WithHotKeyTracking is used.
+	HotReads  []HotKey
+	HotWrites []HotKey
+
+	// Replicas describes the read replicas as of their last
+	// lag probe. Only set when WithReadReplicas is used.
+	Replicas []ReplicaStats
+}
+
+// WriteStats counts writes made through a store.
+type WriteStats struct {
+	// Sets is the number of entities written.
+	Sets int64
+
+	// Creates is the number of writes that created a new entity.
+	Creates int64
+
+	// Overwrites is the number of writes that replaced an existing entity.
+	Overwrites int64
+
+	// Skipped is the number of writes skipped because the value
+	// was unchanged, see WithSkipIdenticalWrites, or because the
+	// stored value was newer, see SetIfNewer.
+	Skipped int64
+
+	// Coalesced is the number of writes replaced by a newer write
+	// of the same entity before reaching Redis. See Coalescer.
+	Coalesced int64
+
+	// Deletes is the number of entities deleted.
+	Deletes int64
+
+	// BytesWritten is the total size of all values written.
+	BytesWritten int64
+}
+
+// OverwriteRatio returns the fraction of writes that replaced
+// an existing entity. A ratio close to 1 for a producer that
+// rarely changes data suggests it rewrites unchanged values.
+func (s WriteStats) OverwriteRatio() float64 {
+	if s.Sets == 0 {
+		return 0
+	}
+
+	return float64(s.Overwrites) / float64(s.Sets)
+}
+
+// AvgValueSize returns the average size in bytes of values written.
+func (s WriteStats) AvgValueSize() float64 {
+	if s.Sets == 0 {
+		return 0
+	}
+
+	return float64(s.BytesWritten) / float64(s.Sets)
+}
+
+// IndexChurn returns the number of lastModified index entries
+// that were added, moved or removed.
+func (s WriteStats) IndexChurn() int64 {
+	return s.Sets + s.Deletes
+}
+
+// OpStats counts operations made through a store.
+type OpStats struct {
+	// Count is the number of operations.
+	Count int64
+
+	// Slow is the number of operations that took longer
+	// than the threshold set with WithSlowOpThreshold.
+	Slow int64
+
+	// TotalTime is the time spent in all operations.
+	TotalTime time.Duration
+}
+
+// AvgTime returns the average duration of an operation.
+func (s OpStats) AvgTime() time.Duration {
+	if s.Count == 0 {
+		return 0
+	}
+
+	return s.TotalTime / time.Duration(s.Count)
+}
+
+// TagStats are the statistics of operations tagged with WithOpTag.
+type TagStats struct {
+	Writes WriteStats
+	Ops    OpStats
+}
+
+type statsCounters struct {
+	sets         atomic.Int64
+	creates      atomic.Int64
+	overwrites   atomic.Int64
+	skipped      atomic.Int64
+	coalesced    atomic.Int64
+	deletes      atomic.Int64
+	bytesWritten atomic.Int64
+	ops          atomic.Int64
+	slowOps      atomic.Int64
+	opNanos      atomic.Int64
+	sizes        [sizeBuckets]atomic.Int64
+
+	// tags holds a *statsCounters per op tag.
+	tags sync.Map
+}
+
+// Stats returns the statistics collected by the store.
+func (r *RedisTKV) Stats() Stats {
+	stats := Stats{
+		Namespace: r.namespace,
+		Writes:    r.stats.writeStats(),
+		Ops:       r.stats.opStats(),
+		Scripts:   r.scripts.stats(),
+	}
+
+	var sizes [sizeBuckets]int64
+
+	for i := range sizes {
+		sizes[i] = r.stats.sizes[i].Load()
+	}
+
+	stats.ValueSizes = sizeHistogram(&sizes)
+
+	r.stats.tags.Range(func(tag, counters any) bool {
+		if stats.Tags == nil {
+			stats.Tags = map[string]TagStats{}
+		}
+
+		c := counters.(*statsCounters) //nolint:forcetypeassert // only *statsCounters are stored
+
+		stats.Tags[tag.(string)] = TagStats{Writes: c.writeStats(), Ops: c.opStats()} //nolint:forcetypeassert // tags are strings
+
+		return true
+	})
+
+	stats.Replicas = r.replicaStats()
+
+	if h := r.hotKeys; h != nil {
+		stats.HotReads = r.topHotKeys(h.reads.counts(h.opts.Window))
+		stats.HotWrites = r.topHotKeys(h.writes.counts(h.opts.Window))
+	}
+
+	return stats
+}
+
+func (c *statsCounters) writeStats() WriteStats {
+	return WriteStats{
+		Sets:         c.sets.Load(),
+		Creates:      c.creates.Load(),
+		Overwrites:   c.overwrites.Load(),
+		Skipped:      c.skipped.Load(),
+		Coalesced:    c.coalesced.Load(),
+		Deletes:      c.deletes.Load(),
+		BytesWritten: c.bytesWritten.Load(),
+	}
+}
+
+func (c *statsCounters) opStats() OpStats {
+	return OpStats{
+		Count:     c.ops.Load(),
+		Slow:      c.slowOps.Load(),
+		TotalTime: time.Duration(c.opNanos.Load()),
+	}
+}
+
+// each calls fn with the store counters and, if ctx
+// carries an op tag, with the counters for that tag.
+func (c *statsCounters) each(ctx context.Context, fn func(c *statsCounters)) {
+	fn(c)
+
+	tag := OpTag(ctx)
+	if tag == "" {
+		return
+	}
+
+	counters, ok := c.tags.Load(tag)
+	if !ok {
+		counters, _ = c.tags.LoadOrStore(tag, &statsCounters{})
+	}
+
+	fn(counters.(*statsCounters)) //nolint:forcetypeassert // only *statsCounters are stored
+}
+
+func (c *statsCounters) recordSet(ctx context.Context, size int, created bool) {
+	c.each(ctx, func(c *statsCounters) {
+		c.sets.Add(1)
+		c.bytesWritten.Add(int64(size))
+		c.sizes[sizeBucket(size)].Add(1)
+
+		if created {
+			c.creates.Add(1)
+		} else {
+			c.overwrites.Add(1)
+		}
+	})
+}
+
+// recordConditionalSet records the result of setIfChangedScript.
+func (c *statsCounters) recordConditionalSet(ctx context.Context, size int, added int64) {
+	if added < 0 {
+		c.each(ctx, func(c *statsCounters) {
+			c.skipped.Add(1)
+		})
+
+		return
+	}
+
+	c.recordSet(ctx, size, added == 1)
+}
+
+func (c *statsCounters) recordCoalesced(ctx context.Context) {
+	c.each(ctx, func(c *statsCounters) {
+		c.coalesced.Add(1)
+	})
+}
+
+func (c *statsCounters) recordDeletes(ctx context.Context, n int) {
+	c.each(ctx, func(c *statsCounters) {
+		c.deletes.Add(int64(n))
+	})
+}
+
+func (c *statsCounters) recordOp(ctx context.Context, took time.Duration, slow bool) {
+	c.each(ctx, func(c *statsCounters) {
+		c.ops.Add(1)
+		c.opNanos.Add(int64(took))
+
+		if slow {
+			c.slowOps.Add(1)
+		}
+	})
+}
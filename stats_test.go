@@ -0,0 +1,80 @@
+// GNU AFFERO GENERAL PUBLIC LICENSE
+// Version 3, 19 November 2007
+//
+// Copyright (C) 2025 John Kleijn
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// For more details, see the full AGPL-3.0 license at:
+// https://www.gnu.org/licenses/agpl-3.0.html
+
+package rtkv_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/johnknl/rtkv"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedisTKV_Stats(t *testing.T) {
+	ctx := context.Background()
+
+	redisClient := newGoRedisClient(0)
+
+	t.Cleanup(func() {
+		redisClient.FlushDB(ctx).Err()
+	})
+
+	store := newRTKV(t, redisClient)
+	now := time.Now()
+
+	_, err := store.Set(ctx, []byte("1234"), now, "a")
+	require.NoError(t, err)
+
+	_, err = store.Set(ctx, []byte("12"), now, "a")
+	require.NoError(t, err)
+
+	require.NoError(t, store.BulkSet(ctx, []rtkv.BulkSetRecord{
+		{ID: []string{"a"}, Data: []byte("12"), LastModified: now},
+		{ID: []string{"b"}, Data: []byte("1234"), LastModified: now},
+	}))
+
+	require.NoError(t, store.Delete(ctx, "b"))
+	require.NoError(t, store.Delete(ctx, "c"))
+
+	stats := store.With().Stats()
+
+	assert.Equal(t, t.Name(), stats.Namespace)
+	assert.Equal(t, rtkv.WriteStats{
+		Sets:         4,
+		Creates:      2,
+		Overwrites:   2,
+		Deletes:      1,
+		BytesWritten: 12,
+	}, stats.Writes)
+	assert.InDelta(t, 0.5, stats.Writes.OverwriteRatio(), 0.001)
+	assert.InDelta(t, 3, stats.Writes.AvgValueSize(), 0.001)
+	assert.EqualValues(t, 5, stats.Writes.IndexChurn())
+}
+
+func TestWriteStats_Empty(t *testing.T) {
+	var stats rtkv.WriteStats
+
+	assert.Zero(t, stats.OverwriteRatio())
+	assert.Zero(t, stats.AvgValueSize())
+}
@@ -0,0 +1,301 @@
+// GNU AFFERO GENERAL PUBLIC LICENSE
+// Version 3, 19 November 2007
+//
+// Copyright (C) 2025 John Kleijn
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// For more details, see the full AGPL-3.0 license at:
+// https://www.gnu.org/licenses/agpl-3.0.html
+
+package rtkv
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// streamSuffix prefixes the temporary keys of
+	// values being written by SetFromReader.
+	streamSuffix = "stream"
+
+	defaultStreamChunkSize = 1 << 20
+
+	// streamUploadTTL is how long the partial value of an
+	// abandoned SetFromReader lingers.
+	streamUploadTTL = time.Hour
+)
+
+var (
+	// ErrStreamingUnsupported is returned by GetReader and
+	// SetFromReader on stores that transform or validate values,
+	// which needs the whole value.
+	ErrStreamingUnsupported = errors.New("streaming is not supported with value transforms or schemas")
+
+	// ErrValueChanged is returned by the reader of GetReader
+	// if the entity is written while it is being read.
+	ErrValueChanged = errors.New("value changed while reading")
+)
+
+// WithStreamChunkSize sets the size of the chunks GetReader and
+// SetFromReader transfer per round trip. Defaults to 1 MiB.
+func WithStreamChunkSize(size int) Option {
+	return func(r *RedisTKV) {
+		r.streamChunkSize = size
+	}
+}
+
+// GetReader returns a reader that streams the value of an entity in
+// chunks read with GETRANGE, so large values are never held in
+// memory in full. Returns ErrNotFound if the entity doesn't exist.
+// Aliases are not followed.
+//
+// Reads are not atomic: if the lastModified time of the entity
+// changes between chunks, the reader fails with ErrValueChanged,
+// but writes that keep the lastModified time go unnoticed.
+func (r *RedisTKV) GetReader(ctx context.Context, id ...string) (io.Reader, error) {
+	defer r.observe(ctx, "getReader", time.Now())
+
+	if err := r.checkID(id); err != nil {
+		return nil, err
+	}
+
+	if r.streamingUnsupported() {
+		return nil, ErrStreamingUnsupported
+	}
+
+	reader := &streamReader{
+		ctx:   ctx,
+		store: r,
+		key:   r.namespacedKey(id...),
+		index: r.namespacedKey(lastModifiedIdxSuffix),
+	}
+
+	var (
+		exists *redis.IntCmd
+		score  *redis.FloatCmd
+	)
+
+	_, err := r.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		exists = pipe.Exists(ctx, reader.key)
+		score = pipe.ZScore(ctx, reader.index, reader.key)
+
+		return nil
+	})
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return nil, fmt.Errorf("failed to get entity: %w", err)
+	}
+
+	if exists.Val() == 0 {
+		return nil, ErrNotFound
+	}
+
+	reader.score = score.Val()
+
+	return reader, nil
+}
+
+// SetFromReader sets an entity to the contents of src, written in
+// chunks, so large values are never held in memory in full. Returns
+// the number of bytes written.
+//
+// Chunks are appended to a temporary key, which replaces the entity
+// atomically once src is exhausted, so readers never observe a
+// partial value. The temporary key of a write that fails midway
+// expires after an hour. The value is not recorded in the history.
+func (r *RedisTKV) SetFromReader(
+	ctx context.Context,
+	src io.Reader,
+	lastModified time.Time,
+	id ...string,
+) (int64, error) {
+	defer r.observe(ctx, "setFromReader", time.Now())
+
+	if err := r.checkID(id); err != nil {
+		return 0, err
+	}
+
+	if err := r.checkWritable(ctx); err != nil {
+		return 0, err
+	}
+
+	if r.streamingUnsupported() {
+		return 0, ErrStreamingUnsupported
+	}
+
+	if err := r.checkWriteConcern(ctx); err != nil {
+		return 0, err
+	}
+
+	// Verifying would mean reading the whole value back.
+	if writeConcernFrom(ctx).verifies() {
+		return 0, ErrWriteConcernUnsupported
+	}
+
+	random := make([]byte, 16) //nolint:mnd // 128 bits
+	_, _ = rand.Read(random)
+	tmp := r.internalKey(streamSuffix, hex.EncodeToString(random))
+
+	written, err := r.upload(ctx, src, tmp)
+	if err != nil {
+		_ = r.client.Del(context.WithoutCancel(ctx), tmp).Err()
+
+		return written, err
+	}
+
+	key := r.namespacedKey(id...)
+	ttl := r.ttlFor(0)
+
+	var zaddRes *redis.IntCmd
+
+	err = r.writeTx(ctx, func(pipe redis.Pipeliner) error {
+		r.writerAdd(ctx, pipe)
+		pipe.Rename(ctx, tmp, key)
+
+		if ttl > 0 {
+			pipe.PExpire(ctx, key, ttl)
+		} else {
+			pipe.Persist(ctx, key)
+		}
+
+		r.expiryAdd(ctx, pipe, key, ttl)
+		zaddRes = r.indexAdd(ctx, pipe, float64(lastModified.UnixNano()), key, id)
+
+		return nil
+	})
+	if err != nil {
+		_ = r.client.Del(context.WithoutCancel(ctx), tmp).Err()
+
+		return written, fmt.Errorf("failed to set entity: %w", err)
+	}
+
+	r.stats.recordSet(ctx, int(written), zaddRes.Val() == 1)
+
+	return written, r.compactedRemove(ctx, false, key)
+}
+
+// upload appends the contents of src to key in chunks.
+func (r *RedisTKV) upload(ctx context.Context, src io.Reader, key string) (int64, error) {
+	chunk := make([]byte, r.streamChunk())
+
+	var written int64
+
+	for {
+		n, readErr := io.ReadFull(src, chunk)
+
+		if n > 0 || written == 0 {
+			_, err := r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+				pipe.Append(ctx, key, string(chunk[:n]))
+				pipe.Expire(ctx, key, streamUploadTTL)
+
+				return nil
+			})
+			if err != nil {
+				return written, fmt.Errorf("failed to write chunk: %w", err)
+			}
+
+			written += int64(n)
+		}
+
+		switch {
+		case errors.Is(readErr, io.EOF), errors.Is(readErr, io.ErrUnexpectedEOF):
+			return written, nil
+		case readErr != nil:
+			return written, fmt.Errorf("failed to read value: %w", readErr)
+		}
+	}
+}
+
+func (r *RedisTKV) streamingUnsupported() bool {
+	return len(r.transforms) > 0 || r.schemas != nil
+}
+
+func (r *RedisTKV) streamChunk() int {
+	if r.streamChunkSize <= 0 {
+		return defaultStreamChunkSize
+	}
+
+	return r.streamChunkSize
+}
+
+// streamReader reads a value in chunks with GETRANGE.
+type streamReader struct {
+	ctx    context.Context //nolint:containedctx // io.Reader has no context
+	store  *RedisTKV
+	key    string
+	index  string
+	score  float64
+	offset int64
+	buf    []byte
+	done   bool
+}
+
+func (s *streamReader) Read(p []byte) (int, error) {
+	if len(s.buf) == 0 {
+		if s.done {
+			return 0, io.EOF
+		}
+
+		if err := s.fetch(); err != nil {
+			return 0, err
+		}
+
+		if len(s.buf) == 0 {
+			return 0, io.EOF
+		}
+	}
+
+	n := copy(p, s.buf)
+	s.buf = s.buf[n:]
+
+	return n, nil
+}
+
+// fetch reads the next chunk, checking that the value is unchanged.
+func (s *streamReader) fetch() error {
+	size := int64(s.store.streamChunk())
+
+	var (
+		chunk *redis.StringCmd
+		score *redis.FloatCmd
+	)
+
+	_, err := s.store.client.Pipelined(s.ctx, func(pipe redis.Pipeliner) error {
+		chunk = pipe.GetRange(s.ctx, s.key, s.offset, s.offset+size-1)
+		score = pipe.ZScore(s.ctx, s.index, s.key)
+
+		return nil
+	})
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return fmt.Errorf("failed to read chunk: %w", err)
+	}
+
+	if score.Val() != s.score {
+		return ErrValueChanged
+	}
+
+	s.buf = []byte(chunk.Val())
+	s.offset += int64(len(s.buf))
+	s.done = int64(len(s.buf)) < size
+
+	return nil
+}
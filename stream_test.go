@@ -0,0 +1,104 @@
+// GNU AFFERO GENERAL PUBLIC LICENSE
+// Version 3, 19 November 2007
+//
+// Copyright (C) 2025 John Kleijn
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// For more details, see the full AGPL-3.0 license at:
+// https://www.gnu.org/licenses/agpl-3.0.html
+
+package rtkv_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/johnknl/rtkv"
+	"github.com/johnknl/rtkv/rtkvtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedisTKV_SetFromReader(t *testing.T) {
+	ctx := context.Background()
+	mr, client := rtkvtest.NewMiniredis(t)
+	store := rtkv.NewRedisTKV(rtkv.DelimUnit, t.Name(), client, rtkv.WithStreamChunkSize(10))
+	value := strings.Repeat("0123456789", 10) + "tail"
+
+	n, err := store.SetFromReader(ctx, strings.NewReader(value), time.Now(), "big")
+	require.NoError(t, err)
+	assert.EqualValues(t, len(value), n)
+
+	got, err := store.Get(ctx, "big")
+	require.NoError(t, err)
+	assert.Equal(t, value, string(got))
+
+	count, err := store.Count(ctx)
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, count)
+	assert.Len(t, mr.Keys(), 2, "Only the entity and the index should remain")
+
+	reader, err := store.GetReader(ctx, "big")
+	require.NoError(t, err)
+
+	streamed, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	assert.Equal(t, value, string(streamed))
+
+	n, err = store.SetFromReader(ctx, bytes.NewReader(nil), time.Now(), "empty")
+	require.NoError(t, err)
+	assert.Zero(t, n)
+
+	got, err = store.Get(ctx, "empty")
+	require.NoError(t, err)
+	assert.Equal(t, []byte{}, got)
+
+	_, err = store.GetReader(ctx, "missing")
+	require.ErrorIs(t, err, rtkv.ErrNotFound)
+}
+
+func TestRedisTKV_GetReader_ValueChanged(t *testing.T) {
+	ctx := context.Background()
+	_, client := rtkvtest.NewMiniredis(t)
+	store := rtkv.NewRedisTKV(rtkv.DelimUnit, t.Name(), client, rtkv.WithStreamChunkSize(4))
+
+	_, err := store.Set(ctx, []byte("aaaabbbbcccc"), time.Unix(1, 0), "a")
+	require.NoError(t, err)
+
+	reader, err := store.GetReader(ctx, "a")
+	require.NoError(t, err)
+
+	chunk := make([]byte, 4)
+	_, err = io.ReadFull(reader, chunk)
+	require.NoError(t, err)
+
+	_, err = store.Set(ctx, []byte("xxxxyyyyzzzz"), time.Unix(2, 0), "a")
+	require.NoError(t, err)
+
+	_, err = io.ReadAll(reader)
+	require.ErrorIs(t, err, rtkv.ErrValueChanged)
+}
+
+func TestRedisTKV_SetFromReader_Unsupported(t *testing.T) {
+	_, client := rtkvtest.NewMiniredis(t)
+	store := rtkv.NewRedisTKV(rtkv.DelimUnit, t.Name(), client, rtkv.WithValueCompression(rtkv.ValueZstd, 0))
+
+	_, err := store.SetFromReader(context.Background(), strings.NewReader("x"), time.Now(), "a")
+	require.ErrorIs(t, err, rtkv.ErrStreamingUnsupported)
+}
@@ -0,0 +1,481 @@
+// GNU AFFERO GENERAL PUBLIC LICENSE
+// Version 3, 19 November 2007
+//
+// Copyright (C) 2025 John Kleijn
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// For more details, see the full AGPL-3.0 license at:
+// https://www.gnu.org/licenses/agpl-3.0.html
+
+package rtkv
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// swapRenameScript renames the keys in the first half of KEYS to
+	// the keys in the second half, skipping keys that no longer exist.
+	// Returns the number of keys renamed.
+	swapRenameScript = `
+local n = #KEYS / 2
+local renamed = 0
+
+for i = 1, n do
+  if redis.call("EXISTS", KEYS[i]) == 1 then
+    redis.call("RENAME", KEYS[i], KEYS[n + i])
+    renamed = renamed + 1
+  end
+end
+
+return renamed
+`
+
+	// swapMembersScript rewrites a page of the members of a sorted set
+	// or set, or the fields of a hash, that start with one prefix to
+	// start with another. Returns the cursor of the next page, which
+	// is "0" after the last page.
+	swapMembersScript = `
+local key = KEYS[1] -- the sorted set, set or hash
+local from = ARGV[1] -- the prefix to replace
+local to = ARGV[2] -- the prefix to replace it with
+local cursor = ARGV[3] -- the scan cursor
+local count = ARGV[4] -- the page size
+
+local function swap(s)
+  if string.sub(s, 1, #from) == from then
+    return to .. string.sub(s, #from + 1)
+  end
+
+  return nil
+end
+
+local t = redis.call("TYPE", key)["ok"]
+
+if t == "zset" then
+  local result = redis.call("ZSCAN", key, cursor, "COUNT", count)
+  local entries = result[2]
+
+  for i = 1, #entries, 2 do
+    local member = swap(entries[i])
+
+    if member then
+      redis.call("ZREM", key, entries[i])
+      redis.call("ZADD", key, entries[i + 1], member)
+    end
+  end
+
+  return result[1]
+elseif t == "set" then
+  local result = redis.call("SSCAN", key, cursor, "COUNT", count)
+
+  for _, member in ipairs(result[2]) do
+    local to = swap(member)
+
+    if to then
+      redis.call("SREM", key, member)
+      redis.call("SADD", key, to)
+    end
+  end
+
+  return result[1]
+elseif t == "hash" then
+  local result = redis.call("HSCAN", key, cursor, "COUNT", count)
+  local fields = result[2]
+
+  for i = 1, #fields, 2 do
+    local field = swap(fields[i])
+
+    if field then
+      redis.call("HDEL", key, fields[i])
+      redis.call("HSET", key, field, fields[i + 1])
+    end
+  end
+
+  return result[1]
+end
+
+return "0"
+`
+
+	defaultSwapBatchSize = 1000
+)
+
+// Phases of a swap, as recorded in its journal.
+const (
+	swapPhaseStash   = iota // moving the keys of a aside
+	swapPhaseFill           // moving the keys of b to a
+	swapPhaseDrain          // moving the stashed keys to b
+	swapPhaseMembers        // rewriting the keys held by indexes
+	swapPhaseDone
+)
+
+const swapSuffix = "swap"
+
+// ErrIncompatibleNamespaces is returned by SwapNamespaces
+// for namespaces that can't be swapped.
+var ErrIncompatibleNamespaces = errors.New("namespaces can't be swapped")
+
+// SwapNamespaces exchanges the contents of the namespaces of stores
+// a and b, which must use the same Redis database, ID delimiter and
+// namespace separator. All keys of both namespaces are renamed, and
+// the keys held by their indexes are rewritten. This enables
+// build-then-swap patterns: rebuild a dataset in a staging namespace,
+// then swap it with the live one. Returns the number of keys renamed.
+//
+// Keys are found with SCAN and renamed in batches, and index members
+// are rewritten a page at a time, so Redis is never blocked for long,
+// but the swap is not atomic: readers may see a mix of both datasets
+// while it runs, and keys written meanwhile may end up in either
+// namespace, so writers to both should be stopped first. On a Cluster
+// or Ring, both namespaces must share a hash tag, such as
+// "{users}live" and "{users}staging".
+//
+// The progress of the swap is recorded in a journal in both
+// namespaces. A swap that was interrupted, by an error, a canceled
+// context or a crash, is resumed by calling SwapNamespaces again with
+// the same namespaces, in either order. Until then, swapping either
+// namespace with a third one fails with ErrIncompatibleNamespaces.
+// SwapNamespaces honours the budget set with WithCommandBudget, and
+// fails with ErrFrozen if either namespace is frozen.
+//
+// Members of sorted sets and sets and hash fields that start with the
+// prefix of either namespace are treated as keys, so entity values
+// are never changed, but other state holding keys, such as pub/sub
+// channels and stream entries, is not.
+func SwapNamespaces(ctx context.Context, a, b *RedisTKV) (int64, error) {
+	defer a.observe(ctx, "swapNamespaces", time.Now())
+
+	prefixA, prefixB := a.keyPrefix(), b.keyPrefix()
+
+	if a.idDelimiter != b.idDelimiter || a.nsSeparator != b.nsSeparator ||
+		strings.HasPrefix(prefixA, prefixB) || strings.HasPrefix(prefixB, prefixA) {
+		return 0, fmt.Errorf("%w: %q and %q", ErrIncompatibleNamespaces, a.namespace, b.namespace)
+	}
+
+	for _, store := range []*RedisTKV{a, b} {
+		if err := store.checkWritable(ctx); err != nil {
+			return 0, err
+		}
+	}
+
+	journal, err := readSwapJournal(ctx, a, b)
+	if err != nil {
+		return 0, err
+	}
+
+	// Resume the swap in the order it was started with.
+	a, b = journal.a, journal.b
+	prefixA, prefixB = a.keyPrefix(), b.keyPrefix()
+
+	defer a.totals.invalidate()
+	defer b.totals.invalidate()
+
+	// Stashed keys stay in namespace a, so they share its hash tag,
+	// and under its journal, so they are never taken for its own.
+	stash := a.internalKey(swapSuffix) + a.idDelimiter
+	budget := budgetFrom(ctx)
+
+	var renamed int64
+
+	for journal.phase < swapPhaseDone {
+		var n int64
+
+		switch journal.phase {
+		case swapPhaseStash:
+			n, err = a.moveKeys(ctx, budget, prefixA, stash, a.internalKey(swapSuffix))
+		case swapPhaseFill:
+			n, err = a.moveKeys(ctx, budget, prefixB, prefixA, b.internalKey(swapSuffix))
+		case swapPhaseDrain:
+			n, err = a.moveKeys(ctx, budget, stash, prefixB, "")
+		case swapPhaseMembers:
+			err = journal.swapMembers(ctx, budget)
+		}
+
+		if renamed += n; err != nil {
+			return renamed, err
+		}
+
+		if err = journal.advance(ctx); err != nil {
+			return renamed, err
+		}
+	}
+
+	return renamed, nil
+}
+
+// swapJournal records the progress of a swap in both
+// namespaces, so an interrupted swap can be resumed.
+type swapJournal struct {
+	a, b   *RedisTKV
+	phase  int
+	key    string // the key whose members were rewritten last
+	cursor string // the cursor to continue key at, "0" once done
+}
+
+// readSwapJournal returns the journal of the swap of a and b in
+// progress, starting one if there is none.
+func readSwapJournal(ctx context.Context, a, b *RedisTKV) (*swapJournal, error) {
+	for _, key := range []string{a.internalKey(swapSuffix), b.internalKey(swapSuffix)} {
+		fields, err := a.client.HGetAll(ctx, key).Result()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read swap journal: %w", err)
+		}
+
+		if len(fields) == 0 {
+			continue
+		}
+
+		phase, err := strconv.Atoi(fields["phase"])
+		if err != nil {
+			return nil, fmt.Errorf("invalid swap journal %q: %w", key, err)
+		}
+
+		journal := &swapJournal{a: a, b: b, phase: phase, key: fields["key"], cursor: fields["cursor"]}
+
+		switch {
+		case fields["a"] == a.namespace && fields["b"] == b.namespace:
+		case fields["a"] == b.namespace && fields["b"] == a.namespace:
+			journal.a, journal.b = b, a
+		default:
+			return nil, fmt.Errorf("%w: %q is being swapped with %q",
+				ErrIncompatibleNamespaces, fields["a"], fields["b"])
+		}
+
+		return journal, nil
+	}
+
+	journal := &swapJournal{a: a, b: b, phase: swapPhaseStash}
+
+	return journal, journal.write(ctx, "a", a.namespace, "b", b.namespace, "phase", journal.phase)
+}
+
+// write sets fields of the journal in both namespaces at once.
+func (j *swapJournal) write(ctx context.Context, values ...any) error {
+	if _, err := j.a.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		for _, store := range []*RedisTKV{j.a, j.b} {
+			if j.phase == swapPhaseDone {
+				pipe.Del(ctx, store.internalKey(swapSuffix))
+			} else {
+				pipe.HSet(ctx, store.internalKey(swapSuffix), values...)
+			}
+		}
+
+		return nil
+	}); err != nil {
+		return fmt.Errorf("failed to write swap journal: %w", err)
+	}
+
+	return nil
+}
+
+// advance records that the current phase is done,
+// removing the journal once the swap is done.
+func (j *swapJournal) advance(ctx context.Context) error {
+	j.phase++
+
+	return j.write(ctx, "phase", j.phase)
+}
+
+// swapMembers rewrites the members and fields of the collections
+// in both namespaces that hold keys of the other namespace. Keys
+// are handled in order, and the key and cursor of the last page
+// done are recorded, so a resumed swap continues after it.
+func (j *swapJournal) swapMembers(ctx context.Context, budget *commandBudget) error {
+	prefixA, prefixB := j.a.keyPrefix(), j.b.keyPrefix()
+
+	keysA, err := j.a.swapKeys(ctx, prefixA, j.a.internalKey(swapSuffix))
+	if err != nil {
+		return err
+	}
+
+	keysB, err := j.b.swapKeys(ctx, prefixB, j.b.internalKey(swapSuffix))
+	if err != nil {
+		return err
+	}
+
+	keys := append(keysA, keysB...)
+	slices.Sort(keys)
+
+	// Continue within the last key if it was left partway.
+	next, _ := slices.BinarySearch(keys, j.key)
+	if j.cursor == "0" && next < len(keys) && keys[next] == j.key {
+		next++
+	}
+
+	keys = keys[next:]
+
+	for i := 0; i < len(keys); i += defaultSwapBatchSize {
+		batch := keys[i:min(i+defaultSwapBatchSize, len(keys))]
+		types := make([]*redis.StatusCmd, len(batch))
+
+		// Only collections hold keys, so skip the
+		// entities rather than spending budget on them.
+		if _, err = j.a.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+			for k, key := range batch {
+				types[k] = pipe.Type(ctx, key)
+			}
+
+			return nil
+		}); err != nil {
+			return fmt.Errorf("failed to get key types: %w", err)
+		}
+
+		for k, key := range batch {
+			switch types[k].Val() {
+			case "zset", "set", "hash":
+			default:
+				continue
+			}
+
+			from, to := prefixA, prefixB
+			if strings.HasPrefix(key, prefixA) {
+				from, to = prefixB, prefixA
+			}
+
+			cursor := "0"
+			if key == j.key {
+				cursor = j.cursor
+			}
+
+			for {
+				if err = budget.spend(ctx, defaultSwapBatchSize); err != nil {
+					return err
+				}
+
+				if cursor, err = j.a.swapMembersPage(ctx, key, from, to, cursor); err != nil {
+					return err
+				}
+
+				if err = j.write(ctx, "key", key, "cursor", cursor); err != nil {
+					return err
+				}
+
+				if j.key, j.cursor = key, cursor; cursor == "0" {
+					break
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// swapKeys returns all keys that start with prefix,
+// except those that start with skip, if set.
+func (r *RedisTKV) swapKeys(ctx context.Context, prefix, skip string) ([]string, error) {
+	match := escapeGlob(prefix) + "*"
+
+	clients, err := r.scanClients(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var keys []string
+
+	for _, client := range clients {
+		var cursor uint64
+
+		for {
+			page, next, err := client.Scan(ctx, cursor, match, defaultSwapBatchSize).Result()
+			if err != nil {
+				return nil, fmt.Errorf("failed to scan keys: %w", err)
+			}
+
+			for _, key := range page {
+				if skip == "" || !strings.HasPrefix(key, skip) {
+					keys = append(keys, key)
+				}
+			}
+
+			if cursor = next; cursor == 0 {
+				break
+			}
+		}
+	}
+
+	return keys, nil
+}
+
+// moveKeys renames all keys that start with prefix from, except
+// those that start with skip, to start with prefix to instead.
+// Returns the number of keys renamed.
+func (r *RedisTKV) moveKeys(ctx context.Context, budget *commandBudget, from, to, skip string) (int64, error) {
+	keys, err := r.swapKeys(ctx, from, skip)
+	if err != nil {
+		return 0, err
+	}
+
+	renamed := make([]string, len(keys))
+
+	for i, key := range keys {
+		renamed[i] = to + strings.TrimPrefix(key, from)
+	}
+
+	return r.renameKeys(ctx, budget, keys, renamed)
+}
+
+// renameKeys renames keys from[i] to to[i] in batches. Returns
+// the number of keys renamed.
+func (r *RedisTKV) renameKeys(ctx context.Context, budget *commandBudget, from, to []string) (int64, error) {
+	var renamed int64
+
+	for i := 0; i < len(from); i += defaultSwapBatchSize {
+		end := min(i+defaultSwapBatchSize, len(from))
+
+		if err := budget.spend(ctx, end-i); err != nil {
+			return renamed, err
+		}
+		keys := append(append(make([]string, 0, 2*(end-i)), from[i:end]...), to[i:end]...)
+
+		result, err := r.evalScript(ctx, swapRenameScript, keys)
+		if err != nil {
+			return renamed, fmt.Errorf("failed to rename keys: %w", err)
+		}
+
+		n, ok := result.(int64)
+		if !ok {
+			return renamed, ErrUnexpectedScriptResult
+		}
+
+		renamed += n
+	}
+
+	return renamed, nil
+}
+
+// swapMembersPage rewrites a page of the members or fields of key
+// that start with prefix from to start with prefix to. Returns the
+// cursor of the next page, which is "0" after the last page.
+func (r *RedisTKV) swapMembersPage(ctx context.Context, key, from, to, cursor string) (string, error) {
+	result, err := r.evalScript(ctx, swapMembersScript, []string{key}, from, to, cursor, defaultSwapBatchSize)
+	if err != nil {
+		return "", fmt.Errorf("failed to rewrite members: %w", err)
+	}
+
+	if cursor, _ = result.(string); cursor == "" {
+		cursor = "0"
+	}
+
+	return cursor, nil
+}
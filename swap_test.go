@@ -0,0 +1,200 @@
+// GNU AFFERO GENERAL PUBLIC LICENSE
+// Version 3, 19 November 2007
+//
+// Copyright (C) 2025 John Kleijn
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// For more details, see the full AGPL-3.0 license at:
+// https://www.gnu.org/licenses/agpl-3.0.html
+
+package rtkv_test
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/johnknl/rtkv"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSwapNamespaces(t *testing.T) {
+	ctx := context.Background()
+	client := newGoRedisClient(0)
+	opts := []rtkv.Option{rtkv.WithChildIndex(), rtkv.WithVersioning()}
+	live := rtkv.NewRedisTKV(rtkv.DelimUnit, t.Name()+"Live", client, opts...)
+	staging := rtkv.NewRedisTKV(rtkv.DelimUnit, t.Name()+"Staging", client, opts...)
+	base := time.Date(2025, 1, 10, 12, 0, 0, 0, time.UTC)
+
+	for _, store := range []*rtkv.RedisTKV{live, staging} {
+		_, err := store.Clear(ctx)
+		require.NoError(t, err)
+	}
+
+	_, err := live.Set(ctx, []byte("old"), base, "parent", "old")
+	require.NoError(t, err)
+
+	require.NoError(t, staging.BulkSet(ctx, []rtkv.BulkSetRecord{
+		{LastModified: base.Add(time.Hour), ID: []string{"parent", "a"}, Data: []byte("a")},
+		{LastModified: base.Add(2 * time.Hour), ID: []string{"parent", "b"}, Data: []byte("b")},
+	}))
+
+	renamed, err := rtkv.SwapNamespaces(ctx, live, staging)
+	require.NoError(t, err)
+	assert.Positive(t, renamed)
+
+	records, total, err := live.FetchPageRecords(ctx, nil, nil, 0, 10)
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, total)
+
+	var ids [][]string
+
+	for record, err := range records {
+		require.NoError(t, err)
+
+		ids = append(ids, record.ID)
+	}
+
+	assert.Equal(t, [][]string{{"parent", "a"}, {"parent", "b"}}, ids)
+
+	children, err := live.GetChildren(ctx, "parent")
+	require.NoError(t, err)
+	assert.Len(t, children, 2)
+
+	version, err := live.Version(ctx, "parent", "b")
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, version)
+
+	value, err := staging.Get(ctx, "parent", "old")
+	require.NoError(t, err)
+	assert.Equal(t, "old", string(value))
+
+	count, err := staging.Count(ctx)
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, count)
+
+	nested := rtkv.NewRedisTKV(rtkv.DelimUnit, t.Name()+"Live"+rtkv.DelimUnit+"x", client)
+
+	_, err = rtkv.SwapNamespaces(ctx, live, nested)
+	require.ErrorIs(t, err, rtkv.ErrIncompatibleNamespaces)
+}
+
+func TestSwapNamespaces_Batches(t *testing.T) {
+	ctx := context.Background()
+	client := newGoRedisClient(0)
+	live := rtkv.NewRedisTKV(rtkv.DelimUnit, t.Name()+"Live", client)
+	staging := rtkv.NewRedisTKV(rtkv.DelimUnit, t.Name()+"Staging", client)
+	base := time.Date(2025, 1, 10, 12, 0, 0, 0, time.UTC)
+
+	for _, store := range []*rtkv.RedisTKV{live, staging} {
+		_, err := store.Clear(ctx)
+		require.NoError(t, err)
+	}
+
+	records := make([]rtkv.BulkSetRecord, 2500)
+
+	for i := range records {
+		id := strconv.Itoa(i)
+		records[i] = rtkv.BulkSetRecord{LastModified: base, ID: []string{id}, Data: []byte(id)}
+	}
+
+	require.NoError(t, staging.BulkSet(ctx, records))
+
+	_, err := rtkv.SwapNamespaces(ctx, live, staging)
+	require.NoError(t, err)
+
+	count, err := live.Count(ctx)
+	require.NoError(t, err)
+	assert.EqualValues(t, len(records), count)
+
+	values, _, err := live.FetchPage(ctx, nil, nil, 0, len(records))
+	require.NoError(t, err)
+	assert.Len(t, collect(t, values), len(records), "Every index member should point at a renamed key")
+
+	count, err = staging.Count(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, count)
+}
+
+func TestSwapNamespaces_Resume(t *testing.T) {
+	ctx := context.Background()
+	client := newGoRedisClient(0)
+	live := rtkv.NewRedisTKV(rtkv.DelimUnit, t.Name()+"Live", client)
+	staging := rtkv.NewRedisTKV(rtkv.DelimUnit, t.Name()+"Staging", client)
+	other := rtkv.NewRedisTKV(rtkv.DelimUnit, t.Name()+"Other", client)
+	base := time.Date(2025, 1, 10, 12, 0, 0, 0, time.UTC)
+
+	for _, store := range []*rtkv.RedisTKV{live, staging, other} {
+		_, err := store.Clear(ctx)
+		require.NoError(t, err)
+	}
+
+	_, err := live.Set(ctx, []byte("old"), base, "old")
+	require.NoError(t, err)
+
+	records := make([]rtkv.BulkSetRecord, 2500)
+
+	for i := range records {
+		id := strconv.Itoa(i)
+		records[i] = rtkv.BulkSetRecord{LastModified: base, ID: []string{id}, Data: []byte(id)}
+	}
+
+	require.NoError(t, staging.BulkSet(ctx, records))
+
+	budgeted := rtkv.WithCommandBudget(ctx, rtkv.CommandBudget{PerCall: 1})
+
+	_, err = rtkv.SwapNamespaces(budgeted, live, staging)
+	require.ErrorIs(t, err, rtkv.ErrCommandBudgetExhausted)
+
+	_, err = rtkv.SwapNamespaces(ctx, live, other)
+	require.ErrorIs(t, err, rtkv.ErrIncompatibleNamespaces, "A namespace being swapped should not be swapped with another")
+
+	// Resume in alternating order, a step at a time.
+	stores := []*rtkv.RedisTKV{staging, live}
+
+	for calls := 0; ; calls++ {
+		require.Less(t, calls, 100, "The swap should make progress")
+
+		_, err = rtkv.SwapNamespaces(budgeted, stores[0], stores[1])
+		if err == nil {
+			break
+		}
+
+		require.ErrorIs(t, err, rtkv.ErrCommandBudgetExhausted)
+
+		stores[0], stores[1] = stores[1], stores[0]
+	}
+
+	count, err := live.Count(ctx)
+	require.NoError(t, err)
+	assert.EqualValues(t, len(records), count)
+
+	values, _, err := live.FetchPage(ctx, nil, nil, 0, len(records))
+	require.NoError(t, err)
+	assert.Len(t, collect(t, values), len(records), "Every index member should point at a renamed key")
+
+	value, err := staging.Get(ctx, "old")
+	require.NoError(t, err)
+	assert.Equal(t, "old", string(value))
+
+	count, err = staging.Count(ctx)
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, count)
+
+	_, err = rtkv.SwapNamespaces(ctx, live, other)
+	require.NoError(t, err, "The journal should be gone once the swap is done")
+}
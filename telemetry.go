@@ -0,0 +1,104 @@
+// GNU AFFERO GENERAL PUBLIC LICENSE
+// Version 3, 19 November 2007
+//
+// Copyright (C) 2025 John Kleijn
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// For more details, see the full AGPL-3.0 license at:
+// https://www.gnu.org/licenses/agpl-3.0.html
+
+package rtkv
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// IndexUsage is a point-in-time measurement of the lastModified index.
+type IndexUsage struct {
+	MeasuredAt  time.Time
+	Members     int64
+	MemoryBytes int64
+}
+
+// IndexThresholds are the limits above which the index is considered
+// to be under memory pressure. Zero values disable a threshold.
+type IndexThresholds struct {
+	MaxMembers     int64
+	MaxMemoryBytes int64
+}
+
+// IndexUsage measures the size of the lastModified index using
+// ZCARD and MEMORY USAGE. Redis estimates the memory usage of
+// large sorted sets by sampling a handful of members.
+func (r *RedisTKV) IndexUsage(ctx context.Context) (IndexUsage, error) {
+	key := r.namespacedKey(lastModifiedIdxSuffix)
+
+	var card, mem *redis.IntCmd
+
+	_, err := r.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		card = pipe.ZCard(ctx, key)
+		mem = pipe.MemoryUsage(ctx, key)
+
+		return nil
+	})
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return IndexUsage{}, fmt.Errorf("failed to measure index: %w", err)
+	}
+
+	return IndexUsage{
+		MeasuredAt:  time.Now(),
+		Members:     card.Val(),
+		MemoryBytes: mem.Val(),
+	}, nil
+}
+
+// IndexPressureTask returns a janitor task that measures the index,
+// reports the measurement to onUsage (if not nil) and logs a warning
+// when any of the thresholds is exceeded. Use onUsage to feed gauges
+// in your metrics system.
+func IndexPressureTask(thresholds IndexThresholds, onUsage func(IndexUsage)) JanitorTask {
+	return func(ctx context.Context, r *RedisTKV) error {
+		usage, err := r.IndexUsage(ctx)
+		if err != nil {
+			return err
+		}
+
+		if onUsage != nil {
+			onUsage(usage)
+		}
+
+		if thresholds.exceededBy(usage) {
+			r.logger.WarnContext(ctx, "lastModified index is under memory pressure",
+				"namespace", r.namespace,
+				"members", usage.Members,
+				"memory_bytes", usage.MemoryBytes,
+				"max_members", thresholds.MaxMembers,
+				"max_memory_bytes", thresholds.MaxMemoryBytes,
+			)
+		}
+
+		return nil
+	}
+}
+
+func (t IndexThresholds) exceededBy(u IndexUsage) bool {
+	return (t.MaxMembers > 0 && u.Members > t.MaxMembers) ||
+		(t.MaxMemoryBytes > 0 && u.MemoryBytes > t.MaxMemoryBytes)
+}
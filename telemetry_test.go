@@ -0,0 +1,78 @@
+// GNU AFFERO GENERAL PUBLIC LICENSE
+// Version 3, 19 November 2007
+//
+// Copyright (C) 2025 John Kleijn
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// For more details, see the full AGPL-3.0 license at:
+// https://www.gnu.org/licenses/agpl-3.0.html
+
+package rtkv_test
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/johnknl/rtkv"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedisTKV_IndexUsage(t *testing.T) {
+	ctx := context.Background()
+	store := goRedisSetup(t, 100)
+
+	usage, err := store.IndexUsage(ctx)
+
+	require.NoError(t, err)
+	assert.EqualValues(t, 100, usage.Members)
+	assert.False(t, usage.MeasuredAt.IsZero())
+}
+
+func TestIndexPressureTask(t *testing.T) {
+	ctx := context.Background()
+
+	var logs bytes.Buffer
+
+	goRedisSetup(t, 100)
+
+	store := newRTKV(t, newGoRedisClient(0)).With(
+		rtkv.WithLogger(slog.New(slog.NewTextHandler(&logs, nil))),
+	)
+
+	var measured []rtkv.IndexUsage
+
+	onUsage := func(u rtkv.IndexUsage) {
+		measured = append(measured, u)
+	}
+
+	t.Run("BelowThreshold", func(t *testing.T) {
+		task := rtkv.IndexPressureTask(rtkv.IndexThresholds{MaxMembers: 100}, onUsage)
+
+		require.NoError(t, task(ctx, store))
+		assert.Empty(t, logs.String())
+	})
+
+	t.Run("AboveThreshold", func(t *testing.T) {
+		task := rtkv.IndexPressureTask(rtkv.IndexThresholds{MaxMembers: 99}, onUsage)
+
+		require.NoError(t, task(ctx, store))
+		assert.Contains(t, logs.String(), "under memory pressure")
+	})
+
+	assert.Len(t, measured, 2)
+}
@@ -30,8 +30,8 @@ import (
 	"testing"
 	"time"
 
-	"github.com/go-redis/redis/v8"
 	"github.com/johnknl/rtkv"
+	"github.com/redis/go-redis/v9"
 )
 
 func goRedisSetup(tb testing.TB, records int) *rtkv.RedisTKV {
@@ -26,13 +26,13 @@ import (
 	"errors"
 	"fmt"
 	"iter"
+	"log/slog"
 	"strconv"
 	"strings"
-	"sync"
 	"time"
 	"unsafe"
 
-	"github.com/go-redis/redis/v8"
+	"github.com/redis/go-redis/v9"
 )
 
 var ErrUnexpectedScriptResult = errors.New("unexpected result from lua script")
@@ -80,17 +80,89 @@ type BulkSetRecord struct {
 	LastModified time.Time
 	ID           []string
 	Data         []byte
+
+	// TTL, if set, makes the entity expire. See SetWithTTL.
+	TTL time.Duration
 }
 
+// Record is an entity read from the store
+// along with its ID and lastModified time.
+type Record struct {
+	LastModified time.Time
+	ID           []string
+	Data         []byte
+}
+
+// TKV is the core API of a time indexed k/v store, implemented by
+// RedisTKV and MemoryTKV. Accept it where code only needs the core
+// API, so it can run without Redis in unit tests.
+type TKV interface {
+	Get(ctx context.Context, id ...string) ([]byte, error)
+	Set(ctx context.Context, data []byte, lastModified time.Time, id ...string) (bool, error)
+	BulkSet(ctx context.Context, records []BulkSetRecord) error
+	Delete(ctx context.Context, id ...string) error
+	Exists(ctx context.Context, id ...string) (bool, error)
+	FetchPage(ctx context.Context, from, to *time.Time, offset, limit int) (iter.Seq2[[]byte, error], int64, error)
+	FetchPageConsistent(
+		ctx context.Context,
+		from, to *time.Time,
+		offset, limit int,
+	) (iter.Seq2[[]byte, error], int64, error)
+}
+
+var (
+	_ TKV = (*RedisTKV)(nil)
+	_ TKV = (*MemoryTKV)(nil)
+)
+
 // RedisTKV is a k/v store backed by Redis.
 // It uses a sorted set to keep track of last
 // modified time and enable range queries.
 type RedisTKV struct {
-	client      *redis.Client
+	client      redis.UniversalClient
 	namespace   string
 	idDelimiter string
-	scriptSHA   string
-	shaMx       sync.Mutex
+	nsSeparator string
+	scripts     *scriptCache
+	shadow      *shadowReads
+	consistency Consistency
+	logger      *slog.Logger
+	archive     Getter
+	stats       *statsCounters
+
+	restoreArchived bool
+	skipIdentical   bool
+	childIndex      bool
+	refPolicy       RefPolicy
+	trackConflicts  bool
+	hotKeys         *hotKeys
+	replicas        *replicaSet
+	multiKey        *multiKeyLimit
+	slowOpThreshold time.Duration
+	history         bool
+	changes         *ChangeFeedOptions
+	defaultTTL      time.Duration
+	fetchSlots      chan struct{}
+	notFoundError   bool
+	schemas         SchemaRegistry
+	writers         *time.Duration
+	reads           *readTracker
+	idScheme        *IDScheme
+	updateQueue     *keyQueue
+	idIndex         bool
+	freeze          *freezeState
+	versioned       bool
+	compaction      bool
+	updateRetries   UpdateRetryPolicy
+	priorities      int
+	iterPolicy      IterErrorPolicy
+	totals          *totalsCache
+	expiry          ExpiryOptions
+	readBatches     *readBatcher
+	transforms      []valueTransform
+	streamChunkSize int
+	dedup           *writeDedup
+	aliases         *aliasTargets
 }
 
 // NewRedisTKV creates a new RedisTKV instance.
@@ -101,43 +173,197 @@ type RedisTKV struct {
 //
 // The `namespace` argument prevents key collisions
 // for different entitiy types.
-func NewRedisTKV(idDelimiter, namespace string, c *redis.Client) *RedisTKV {
-	return &RedisTKV{
+//
+// The client can be a Client, including one created with
+// NewFailoverClient, a ClusterClient or a Ring. Transactions, scripts
+// and multi-key commands require all keys of a namespace to be in a
+// single hash slot, so on a Cluster or Ring the namespace must be a
+// hash tag, such as "{users}".
+//
+// Optional behaviour can be enabled by passing one or more options.
+func NewRedisTKV(idDelimiter, namespace string, c redis.UniversalClient, opts ...Option) *RedisTKV {
+	r := &RedisTKV{
 		client:      c,
 		namespace:   namespace,
 		idDelimiter: idDelimiter,
+		nsSeparator: idDelimiter,
+		scripts:     &scriptCache{},
+		logger:      slog.Default(),
+		stats:       &statsCounters{},
+		multiKey:    &multiKeyLimit{},
+		aliases:     &aliasTargets{},
+		updateRetries: UpdateRetryPolicy{
+			MaxRetries: defaultUpdateMaxRetries,
+			Backoff:    defaultUpdateBackoff,
+			MaxBackoff: defaultUpdateMaxBackoff,
+		},
+	}
+
+	for _, opt := range opts {
+		opt(r)
 	}
+
+	return r
 }
 
-// Get an entity by ID.
+// Get an entity by ID. Returns a nil value if the entity doesn't
+// exist, or ErrNotFound when WithNotFoundError is used.
 func (r *RedisTKV) Get(ctx context.Context, id ...string) ([]byte, error) {
-	data, err := r.client.Get(ctx, r.namespacedKey(id...)).Bytes()
+	defer r.observe(ctx, "get", time.Now())
+
+	if err := r.checkID(id); err != nil {
+		return nil, err
+	}
+
+	key := r.namespacedKey(id...)
+	data, err := r.getValue(ctx, key)
 
 	if errors.Is(err, redis.Nil) {
-		return nil, nil
-	} else if err != nil {
+		data, err = r.getArchived(ctx, id)
+	}
+
+	if err != nil {
 		return nil, fmt.Errorf("failed to get entity: %w", err)
 	}
 
+	if data, err = r.readValue(ctx, data); err != nil {
+		return nil, err
+	}
+
+	r.shadow.sample(ctx, r.logger, data, id)
+
+	if data == nil && r.notFoundError {
+		return nil, ErrNotFound
+	}
+
+	r.sampleRead(ctx, key)
+
+	if data != nil {
+		r.recordReads(ctx, key)
+	}
+
 	return data, nil
 }
 
+// BulkGetEntry is a single entity read by BulkGet.
+type BulkGetEntry struct {
+	ID   []string
+	Data []byte
+
+	// Exists reports whether the entity was found.
+	Exists bool
+}
+
+// BulkGet reads multiple entities with a single MGET. Entries are
+// returned in the order of ids, with Exists set to false for
+// entities that don't exist.
+func (r *RedisTKV) BulkGet(ctx context.Context, ids [][]string) ([]BulkGetEntry, error) {
+	defer r.observe(ctx, "bulkGet", time.Now())
+
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	keys := make([]string, len(ids))
+
+	for i := range ids {
+		if err := r.checkID(ids[i]); err != nil {
+			return nil, err
+		}
+
+		keys[i] = r.namespacedKey(ids[i]...)
+	}
+
+	values, err := r.mget(ctx, r.reader(ctx), keys)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute mget: %w", err)
+	}
+
+	entries := make([]BulkGetEntry, len(ids))
+	read := make([]string, 0, len(ids))
+
+	for i, value := range values {
+		entries[i].ID = ids[i]
+
+		var data []byte
+
+		if s, ok := value.(string); ok {
+			data = []byte(s)
+		} else if data, err = r.getArchived(ctx, ids[i]); err != nil {
+			return nil, fmt.Errorf("failed to get entity: %w", err)
+		}
+
+		if data, err = r.readValue(ctx, data); err != nil {
+			return nil, err
+		}
+
+		if data == nil {
+			continue
+		}
+
+		entries[i].Data = data
+		entries[i].Exists = true
+
+		r.shadow.sample(ctx, r.logger, data, ids[i])
+		r.sampleRead(ctx, keys[i])
+		read = append(read, keys[i])
+	}
+
+	r.recordReads(ctx, read...)
+
+	return entries, nil
+}
+
 // BulkSet sets multiple entities in the store.
 func (r *RedisTKV) BulkSet(ctx context.Context, records []BulkSetRecord) error {
+	defer r.observe(ctx, "bulkSet", time.Now())
+
 	if len(records) == 0 {
 		return nil
 	}
 
-	_, err := r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+	if err := r.checkWritable(ctx); err != nil {
+		return err
+	}
+
+	for i := range records {
+		if err := r.checkID(records[i].ID); err != nil {
+			return err
+		}
+
+		if err := r.validate(ctx, records[i].ID, records[i].Data); err != nil {
+			return err
+		}
+	}
+
+	if err := r.checkWriteConcern(ctx); err != nil {
+		return err
+	}
+
+	records, err := r.encodeRecords(records)
+	if err != nil {
+		return err
+	}
+
+	if r.skipIdentical {
+		if err = r.bulkSetIfChanged(ctx, records); err != nil {
+			return err
+		}
+
+		return r.verifyRecords(ctx, records)
+	}
+
+	zaddRes := make([]*redis.IntCmd, len(records))
+
+	err = r.writeTx(ctx, func(pipe redis.Pipeliner) error {
+		r.writerAdd(ctx, pipe)
+
 		for i := range records {
 			timestamp := records[i].LastModified.UnixNano()
 			key := r.namespacedKey(records[i].ID...)
+			ttl := r.ttlFor(records[i].TTL)
 
-			pipe.Set(ctx, key, records[i].Data, 0)
-			pipe.ZAdd(ctx, r.namespacedKey(lastModifiedIdxSuffix), &redis.Z{
-				Score:  float64(timestamp),
-				Member: key,
-			})
+			zaddRes[i] = r.setEntity(ctx, pipe, records[i].Data, timestamp, ttl, key, records[i].ID)
 		}
 
 		return nil
@@ -146,25 +372,112 @@ func (r *RedisTKV) BulkSet(ctx context.Context, records []BulkSetRecord) error {
 		return fmt.Errorf("failed to bulk insert records: %w", err)
 	}
 
-	return nil
+	keys := make([]string, len(records))
+
+	for i := range records {
+		keys[i] = r.namespacedKey(records[i].ID...)
+		r.stats.recordSet(ctx, len(records[i].Data), zaddRes[i].Val() == 1)
+	}
+
+	if err = r.compactedRemove(ctx, false, keys...); err != nil {
+		return err
+	}
+
+	return r.verifyRecords(ctx, records)
+}
+
+// BulkSetSeq consumes records from seq and writes them to the store
+// using BulkSet, batchSize records at a time. It stops at the first
+// error yielded by seq or returned by BulkSet. Returns the number of
+// records written.
+func (r *RedisTKV) BulkSetSeq(ctx context.Context, seq iter.Seq2[BulkSetRecord, error], batchSize int) (int, error) {
+	if batchSize <= 0 {
+		batchSize = defaultTransferBatchSize
+	}
+
+	batch := make([]BulkSetRecord, 0, batchSize)
+
+	var written int
+
+	for record, err := range seq {
+		if err != nil {
+			return written, err
+		}
+
+		batch = append(batch, record)
+
+		if len(batch) == batchSize {
+			if err = r.BulkSet(ctx, batch); err != nil {
+				return written, err
+			}
+
+			written += len(batch)
+			batch = batch[:0]
+		}
+	}
+
+	if err := r.BulkSet(ctx, batch); err != nil {
+		return written, err
+	}
+
+	return written + len(batch), nil
 }
 
 // Set an entity in the store by ID.
 // If the entity already exists, it will be overwritten.
 // Returns boolean true if entity already existed.
 func (r *RedisTKV) Set(ctx context.Context, data []byte, lastModified time.Time, id ...string) (bool, error) {
+	defer r.observe(ctx, "set", time.Now())
+
+	return r.set(ctx, data, lastModified, 0, id)
+}
+
+func (r *RedisTKV) set(
+	ctx context.Context,
+	data []byte,
+	lastModified time.Time,
+	ttl time.Duration,
+	id []string,
+) (bool, error) {
+	if err := r.checkID(id); err != nil {
+		return false, err
+	}
+
+	if err := r.checkWritable(ctx); err != nil {
+		return false, err
+	}
+
+	if err := r.validate(ctx, id, data); err != nil {
+		return false, err
+	}
+
+	if err := r.checkWriteConcern(ctx); err != nil {
+		return false, err
+	}
+
+	data, err := r.encodeValue(id, data)
+	if err != nil {
+		return false, err
+	}
+
 	timestamp := lastModified.UnixNano()
 	key := r.namespacedKey(id...)
+	ttl = r.ttlFor(ttl)
 
-	var zaddRes *redis.IntCmd
+	if r.skipIdentical {
+		existed, err := r.setIfChanged(ctx, data, timestamp, ttl, key)
+		if err != nil {
+			return existed, err
+		}
 
-	_, err := r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
-		pipe.Set(ctx, key, data, 0)
+		return existed, r.verifyWrites(ctx, []string{key}, [][]byte{data})
+	}
+
+	var zaddRes *redis.IntCmd
 
-		zaddRes = pipe.ZAdd(ctx, r.namespacedKey(lastModifiedIdxSuffix), &redis.Z{
-			Score:  float64(timestamp),
-			Member: key,
-		})
+	err = r.writeTx(ctx, func(pipe redis.Pipeliner) error {
+		r.writerAdd(ctx, pipe)
+		zaddRes = r.setEntity(ctx, pipe, data, timestamp, ttl, key, id)
 
 		return nil
 	})
@@ -172,10 +485,18 @@ func (r *RedisTKV) Set(ctx context.Context, data []byte, lastModified time.Time,
 		return false, fmt.Errorf("failed to set entity: %w", err)
 	}
 
-	return zaddRes.Val() == 0, nil
+	if err = r.compactedRemove(ctx, false, key); err != nil {
+		return zaddRes.Val() == 0, err
+	}
+
+	r.stats.recordSet(ctx, len(data), zaddRes.Val() == 1)
+
+	return zaddRes.Val() == 0, r.verifyWrites(ctx, []string{key}, [][]byte{data})
 }
 
 func (r *RedisTKV) Exists(ctx context.Context, id ...string) (bool, error) {
+	defer r.observe(ctx, "exists", time.Now())
+
 	result, err := r.client.Exists(ctx, r.namespacedKey(id...)).Result()
 	if err != nil {
 		return false, fmt.Errorf("failed to check if entity exists: %w", err)
@@ -185,16 +506,69 @@ func (r *RedisTKV) Exists(ctx context.Context, id ...string) (bool, error) {
 }
 
 func (r *RedisTKV) Delete(ctx context.Context, id ...string) error {
-	_, err := r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
-		pipe.Del(ctx, r.namespacedKey(id...))
-		pipe.ZRem(ctx, r.namespacedKey(lastModifiedIdxSuffix), id)
+	defer r.observe(ctx, "delete", time.Now())
 
-		return nil
-	})
+	if err := r.checkID(id); err != nil {
+		return err
+	}
+
+	if err := r.checkWritable(ctx); err != nil {
+		return err
+	}
+
+	if r.refPolicy != RefPolicyIgnore {
+		return r.deleteReferenced(ctx, id)
+	}
+
+	deleted, err := r.deleteEntities(ctx, [][]string{id})
 	if err != nil {
 		return fmt.Errorf("failed to delete entity: %w", err)
 	}
 
+	r.stats.recordDeletes(ctx, deleted)
+
+	return nil
+}
+
+// BulkDelete deletes multiple entities and their index entries in a
+// single transaction, or in a pipeline when a multi-key limit is
+// configured or detected. Entities that don't exist are ignored. When a
+// RefPolicy other than RefPolicyIgnore is set, entities are deleted
+// one at a time as with Delete, stopping at the first error.
+func (r *RedisTKV) BulkDelete(ctx context.Context, ids [][]string) error {
+	defer r.observe(ctx, "bulkDelete", time.Now())
+
+	for _, id := range ids {
+		if err := r.checkID(id); err != nil {
+			return err
+		}
+	}
+
+	if err := r.checkWritable(ctx); err != nil {
+		return err
+	}
+
+	if r.refPolicy != RefPolicyIgnore {
+		for _, id := range ids {
+			if err := r.deleteReferenced(ctx, id); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	if len(ids) == 0 {
+		return nil
+	}
+
+	deleted, err := r.deleteEntities(ctx, ids)
+	if err != nil {
+		return fmt.Errorf("failed to delete entities: %w", err)
+	}
+
+	r.stats.recordDeletes(ctx, deleted)
+
 	return nil
 }
 
@@ -203,52 +577,148 @@ func (r *RedisTKV) FetchPage(
 	from, to *time.Time, //nolint:varnamelen // from and to are clear
 	offset, limit int,
 ) (iter.Seq2[[]byte, error], int64, error) {
-	var rangeMin, rangeMax string
-	if from != nil {
-		rangeMin = strconv.Itoa(int(from.UnixNano()))
-	} else {
-		rangeMin = "-inf"
+	defer r.observe(ctx, "fetchPage", time.Now())
+
+	rangeMin, rangeMax := scoreRange(from, to)
+
+	return r.fetchIndexPage(ctx, r.namespacedKey(lastModifiedIdxSuffix), rangeMin, rangeMax, offset, limit)
+}
+
+// Count returns the number of entities in the store.
+func (r *RedisTKV) Count(ctx context.Context) (int64, error) {
+	defer r.observe(ctx, "count", time.Now())
+
+	return r.countIndex(ctx, r.reader(ctx), r.namespacedKey(lastModifiedIdxSuffix), "-inf", "+inf")
+}
+
+// CountRange returns the number of entities last modified
+// in the given range. Nil means unbounded.
+func (r *RedisTKV) CountRange(ctx context.Context, from, to *time.Time) (int64, error) { //nolint:varnamelen // from and to are clear
+	defer r.observe(ctx, "countRange", time.Now())
+
+	rangeMin, rangeMax := scoreRange(from, to)
+
+	return r.countIndex(ctx, r.reader(ctx), r.namespacedKey(lastModifiedIdxSuffix), rangeMin, rangeMax)
+}
+
+func (r *RedisTKV) fetchIndexPage(
+	ctx context.Context,
+	key, rangeMin, rangeMax string,
+	offset, limit int,
+) (iter.Seq2[[]byte, error], int64, error) {
+	page, err := r.fetchIndexRange(ctx, key, rangeMin, rangeMax, offset, limit, false)
+	if err != nil {
+		return nil, 0, err
 	}
 
-	if to != nil {
-		rangeMax = strconv.Itoa(int(to.UnixNano()))
-	} else {
-		rangeMax = "+inf"
+	return r.yieldValues(page.keys, page.values), page.total, nil
+}
+
+// indexPage is a page of entities read through an index.
+type indexPage struct {
+	total  int64
+	keys   []string
+	scores []float64
+	values []any
+}
+
+// fetchIndexRange reads a page of entities through the index at
+// key, including the scores of the entities if withScores is set.
+func (r *RedisTKV) fetchIndexRange(
+	ctx context.Context,
+	key, rangeMin, rangeMax string,
+	offset, limit int,
+	withScores bool,
+) (indexPage, error) {
+	var page indexPage
+
+	explain := explainFrom(ctx)
+	explain.start(ExplainPathPipeline)
+
+	start := time.Now()
+
+	release, err := r.acquireFetch(ctx)
+	if err != nil {
+		return page, err
 	}
 
-	key := r.namespacedKey(lastModifiedIdxSuffix)
+	defer release()
 
-	total, err := r.client.ZCount(ctx, key, rangeMin, rangeMax).Result()
+	if r.fetchSlots != nil {
+		explain.stage("queue", start)
+	}
+
+	reader := r.reader(ctx)
+	start = time.Now()
+
+	page.total, err = r.countIndex(ctx, reader, key, rangeMin, rangeMax)
 	if err != nil {
-		return nil, 0, fmt.Errorf("failed to count: %w", err)
+		return page, err
 	}
 
-	result, err := r.client.ZRangeByScore(ctx, key, &redis.ZRangeBy{
+	explain.stage("zcount", start)
+	start = time.Now()
+
+	rangeBy := &redis.ZRangeBy{
 		Min:    rangeMin,
 		Max:    rangeMax,
 		Offset: int64(offset),
 		Count:  int64(limit),
-	}).Result()
-	if err != nil {
-		return nil, 0, fmt.Errorf("failed to execute zrangebyscore: %w", err)
 	}
 
-	if len(result) == 0 {
-		return func(func([]byte, error) bool) {}, total, nil
+	if withScores {
+		entries, err := reader.ZRangeByScoreWithScores(ctx, key, rangeBy).Result()
+		if err != nil {
+			return page, fmt.Errorf("failed to execute zrangebyscore: %w", err)
+		}
+
+		page.keys = make([]string, len(entries))
+		page.scores = make([]float64, len(entries))
+
+		for i := range entries {
+			page.keys[i], _ = entries[i].Member.(string)
+			page.scores[i] = entries[i].Score
+		}
+	} else if page.keys, err = reader.ZRangeByScore(ctx, key, rangeBy).Result(); err != nil {
+		return page, fmt.Errorf("failed to execute zrangebyscore: %w", err)
+	}
+
+	explain.stage("zrangebyscore", start)
+
+	if len(page.keys) == 0 {
+		return page, nil
 	}
 
-	mGetResult, err := r.client.MGet(ctx, result...).Result()
+	start = time.Now()
+
+	page.values, err = r.mget(ctx, reader, page.keys)
 	if err != nil {
-		return nil, 0, fmt.Errorf("failed to execute mget: %w", err)
+		return page, fmt.Errorf("failed to execute mget: %w", err)
 	}
 
-	return func(yield func([]byte, error) bool) {
-		for _, rawValue := range mGetResult {
-			if !yield(s2b(rawValue.(string)), nil) {
-				break
-			}
-		}
-	}, total, nil
+	explain.stage("mget", start)
+	explain.values(len(page.keys), page.values)
+
+	return page, nil
+}
+
+// FetchPageRecords fetches a page like FetchPage, yielding records
+// with the ID and lastModified time of every entity.
+func (r *RedisTKV) FetchPageRecords(
+	ctx context.Context,
+	from, to *time.Time, //nolint:varnamelen // from and to are clear
+	offset, limit int,
+) (iter.Seq2[Record, error], int64, error) {
+	defer r.observe(ctx, "fetchPageRecords", time.Now())
+
+	rangeMin, rangeMax := scoreRange(from, to)
+
+	page, err := r.fetchIndexRange(ctx, r.namespacedKey(lastModifiedIdxSuffix), rangeMin, rangeMax, offset, limit, true)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return r.yieldRecords(page.keys, page.scores, page.values), page.total, nil
 }
 
 func (r *RedisTKV) FetchPageConsistent(
@@ -256,32 +726,38 @@ func (r *RedisTKV) FetchPageConsistent(
 	from, to *time.Time, //nolint:varnamelen // from and to are clear
 	offset, limit int,
 ) (iter.Seq2[[]byte, error], int64, error) {
-	var rangeMin, rangeMax string
-	if from != nil {
-		rangeMin = strconv.Itoa(int(from.UnixNano()))
-	} else {
-		rangeMin = "-inf"
-	}
+	defer r.observe(ctx, "fetchPageConsistent", time.Now())
 
-	if to != nil {
-		rangeMax = strconv.Itoa(int(to.UnixNano()))
-	} else {
-		rangeMax = "+inf"
-	}
+	rangeMin, rangeMax := scoreRange(from, to)
 
 	keys := []string{r.namespacedKey(lastModifiedIdxSuffix)}
 	args := []any{rangeMin, rangeMax, offset, limit}
 
-	sha, err := r.getScriptSHA(ctx)
+	explain := explainFrom(ctx)
+	explain.start(ExplainPathScript)
+
+	start := time.Now()
+
+	release, err := r.acquireFetch(ctx)
 	if err != nil {
-		return nil, 0, fmt.Errorf("failed to load script: %w", err)
+		return nil, 0, err
 	}
 
-	result, err := r.client.EvalSha(ctx, sha, keys, args...).Result()
+	defer release()
+
+	if r.fetchSlots != nil {
+		explain.stage("queue", start)
+	}
+
+	start = time.Now()
+
+	result, err := r.evalScript(ctx, rangeScript, keys, args...)
 	if err != nil {
 		return nil, 0, fmt.Errorf("failed to execute search.lua script: %w", err)
 	}
 
+	explain.stage("evalsha", start)
+
 	resultSlice, ok := result.([]any)
 
 	if !ok || len(resultSlice) != 2 {
@@ -291,34 +767,165 @@ func (r *RedisTKV) FetchPageConsistent(
 	total := resultSlice[0].(int64)
 	rawValues := resultSlice[1].([]any)
 
-	return func(yield func([]byte, error) bool) {
-		for _, rawValue := range rawValues {
-			if !yield(s2b(rawValue.(string)), nil) {
-				break
-			}
-		}
-	}, total, nil
+	if explain != nil {
+		explain.ScriptSHA, _ = r.getScriptSHA(ctx, rangeScript)
+		explain.values(len(rawValues), rawValues)
+	}
+
+	return r.yieldValues(nil, rawValues), total, nil
+}
+
+// scoreRange converts an optional time range to
+// sorted set score boundaries.
+func scoreRange(from, to *time.Time) (string, string) { //nolint:varnamelen // from and to are clear
+	rangeMin, rangeMax := "-inf", "+inf"
+
+	if from != nil {
+		rangeMin = strconv.Itoa(int(from.UnixNano()))
+	}
+
+	if to != nil {
+		rangeMax = strconv.Itoa(int(to.UnixNano()))
+	}
+
+	return rangeMin, rangeMax
 }
 
 func (r *RedisTKV) namespacedKey(key ...string) string {
-	return r.namespace + r.idDelimiter + strings.Join(key, r.idDelimiter)
+	return r.keyPrefix() + strings.Join(key, r.idDelimiter)
 }
 
-func (r *RedisTKV) getScriptSHA(ctx context.Context) (string, error) {
-	r.shaMx.Lock()
-	defer r.shaMx.Unlock()
+// keyPrefix returns the prefix of all keys of the namespace.
+func (r *RedisTKV) keyPrefix() string {
+	return r.namespace + r.nsSeparator
+}
 
-	if r.scriptSHA != "" {
-		return r.scriptSHA, nil
+// setEntity writes an entity along with its expiry, index and history
+// entries. Returns the result of adding it to the lastModified index,
+// which is 1 if the entity is new.
+func (r *RedisTKV) setEntity(
+	ctx context.Context,
+	pipe redis.Pipeliner,
+	data []byte,
+	timestamp int64,
+	ttl time.Duration,
+	key string,
+	id []string,
+) *redis.IntCmd {
+	pipe.Set(ctx, key, data, ttl)
+	r.expiryAdd(ctx, pipe, key, ttl)
+
+	zaddRes := r.indexAdd(ctx, pipe, float64(timestamp), key, id)
+	r.historyAdd(ctx, pipe, timestamp, key, data)
+
+	return zaddRes
+}
+
+// indexAdd adds an entity to the lastModified index, and to
+// the secondary indexes enabled on the store.
+func (r *RedisTKV) indexAdd(
+	ctx context.Context,
+	pipe redis.Pipeliner,
+	score float64,
+	key string,
+	id []string,
+) *redis.IntCmd {
+	r.versionsAdd(ctx, pipe, key)
+
+	recent := r.secondaryIndexAdd(ctx, pipe, score, key, id)
+	entry := redis.Z{Score: score, Member: key}
+
+	if recent && r.dedup.skipIndexBump() {
+		return pipe.ZAddNX(ctx, r.namespacedKey(lastModifiedIdxSuffix), entry)
 	}
-	var err error
 
-	r.scriptSHA, err = r.client.ScriptLoad(ctx, rangeScript).Result()
+	return pipe.ZAdd(ctx, r.namespacedKey(lastModifiedIdxSuffix), entry)
+}
+
+// secondaryIndexAdd adds an entity to the secondary indexes enabled
+// on the store and publishes the change, unless it was written
+// recently, see WithWriteDedup. It leaves the lastModified index and
+// versions to the caller, for scripts that update them atomically
+// with the value. Returns whether the entity was written recently.
+func (r *RedisTKV) secondaryIndexAdd(
+	ctx context.Context,
+	pipe redis.Pipeliner,
+	score float64,
+	key string,
+	id []string,
+) bool {
+	if r.childIndex {
+		r.childSetsAdd(ctx, pipe, key, id)
+	}
+
+	r.sampleWrite(ctx, pipe, key)
+	r.readsAdd(ctx, pipe, key)
+	r.idsAdd(ctx, pipe, key)
+	r.priorityAdd(ctx, pipe, score, key)
+
+	recent := r.dedup.recent(key)
+	if !recent {
+		r.changeAdd(ctx, pipe, ChangeSet, id, int64(score))
+	}
+
+	return recent
+}
+
+// indexRemove removes an entity from all indexes.
+func (r *RedisTKV) indexRemove(ctx context.Context, pipe redis.Pipeliner, key string, id []string) {
+	r.dedup.forget(key)
+	r.secondaryIndexRemove(ctx, pipe, key, id)
+	r.changeAdd(ctx, pipe, ChangeDelete, id, time.Now().UnixNano())
+	pipe.ZRem(ctx, r.namespacedKey(lastModifiedIdxSuffix), key)
+}
+
+// secondaryIndexRemove removes an entity from the secondary
+// indexes enabled on the store.
+func (r *RedisTKV) secondaryIndexRemove(ctx context.Context, pipe redis.Pipeliner, key string, id []string) {
+	if r.childIndex {
+		r.childSetsRemove(ctx, pipe, key, id)
+	}
+
+	r.historyRemove(ctx, pipe, key, id)
+	r.readsRemove(ctx, pipe, key)
+	r.idsRemove(ctx, pipe, key)
+	r.versionsRemove(ctx, pipe, key)
+	r.priorityRemove(ctx, pipe, key)
+	r.contentHashRemove(ctx, pipe, key)
+}
+
+// idFromKey returns the composite ID of a namespaced key.
+func (r *RedisTKV) idFromKey(key string) []string {
+	return strings.Split(strings.TrimPrefix(key, r.keyPrefix()), r.idDelimiter)
+}
+
+// evalScript runs a Lua script by SHA, loading it first if needed.
+// If Redis lost the script, for example after a restart or failover,
+// it is loaded again and the call is retried once.
+func (r *RedisTKV) evalScript(ctx context.Context, src string, keys []string, args ...any) (any, error) {
+	return r.evalScriptOn(ctx, r.client, src, keys, args...)
+}
+
+// evalScriptOn runs a Lua script like evalScript, with c.
+func (r *RedisTKV) evalScriptOn(ctx context.Context, c redis.Scripter, src string, keys []string, args ...any) (any, error) {
+	sha, err := r.getScriptSHA(ctx, src)
 	if err != nil {
-		return "", fmt.Errorf("failed to load lua range script: %w", err)
+		return nil, fmt.Errorf("failed to load script: %w", err)
+	}
+
+	result, err := c.EvalSha(ctx, sha, keys, args...).Result()
+	if err != nil && strings.HasPrefix(err.Error(), "NOSCRIPT") {
+		r.forgetScript(src)
+		opResultFrom(ctx).retry()
+
+		if sha, err = r.getScriptSHA(ctx, src); err != nil {
+			return nil, fmt.Errorf("failed to reload script: %w", err)
+		}
+
+		result, err = c.EvalSha(ctx, sha, keys, args...).Result()
 	}
 
-	return r.scriptSHA, nil
+	return result, err //nolint:wrapcheck // callers wrap with context
 }
 
 func s2b(s string) (b []byte) {
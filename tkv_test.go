@@ -95,3 +95,208 @@ func TestRedisTKV_CRUD(t *testing.T) {
 		assert.Falsef(t, exists, "Entity should not exist after being deleted")
 	})
 }
+
+func TestRedisTKV_BulkGet(t *testing.T) {
+	ctx := context.Background()
+	store := newRTKV(t, newGoRedisClient(0))
+
+	err := store.BulkSet(ctx, []rtkv.BulkSetRecord{
+		{LastModified: time.Now(), ID: []string{"a", "1"}, Data: []byte("a1")},
+		{LastModified: time.Now(), ID: []string{"b"}, Data: []byte{}},
+	})
+	require.NoError(t, err)
+
+	entries, err := store.BulkGet(ctx, [][]string{{"b"}, {"missing"}, {"a", "1"}})
+	require.NoError(t, err)
+	require.Len(t, entries, 3)
+
+	assert.Equal(t, rtkv.BulkGetEntry{ID: []string{"b"}, Data: []byte{}, Exists: true}, entries[0])
+	assert.Equal(t, rtkv.BulkGetEntry{ID: []string{"missing"}}, entries[1])
+	assert.Equal(t, rtkv.BulkGetEntry{ID: []string{"a", "1"}, Data: []byte("a1"), Exists: true}, entries[2])
+
+	entries, err = store.BulkGet(ctx, nil)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+func TestRedisTKV_BulkDelete(t *testing.T) {
+	ctx := context.Background()
+	store := goRedisSetup(t, 20)
+
+	err := store.BulkDelete(ctx, [][]string{{"entity", "0"}, {"entity", "1"}, {"missing"}})
+	require.NoError(t, err)
+
+	_, total, err := store.FetchPage(ctx, nil, nil, 0, 1)
+	require.NoError(t, err)
+	assert.EqualValues(t, 18, total)
+
+	value, err := store.Get(ctx, "entity", "0")
+	require.NoError(t, err)
+	assert.Nil(t, value)
+
+	assert.EqualValues(t, 2, store.Stats().Writes.Deletes)
+	require.NoError(t, store.BulkDelete(ctx, nil))
+}
+
+func TestRedisTKV_FetchPageRecords(t *testing.T) {
+	ctx := context.Background()
+	store := newRTKV(t, newGoRedisClient(0))
+	base := time.Date(2025, 1, 10, 12, 0, 0, 0, time.UTC)
+
+	err := store.BulkSet(ctx, []rtkv.BulkSetRecord{
+		{LastModified: base, ID: []string{"a", "1"}, Data: []byte("a1")},
+		{LastModified: base.Add(time.Hour), ID: []string{"b"}, Data: []byte("b")},
+		{LastModified: base.Add(2 * time.Hour), ID: []string{"c"}, Data: []byte("c")},
+	})
+	require.NoError(t, err)
+
+	records, total, err := store.FetchPageRecords(ctx, nil, nil, 0, 2)
+	require.NoError(t, err)
+	assert.EqualValues(t, 3, total)
+
+	var got []rtkv.Record
+
+	for record, err := range records {
+		require.NoError(t, err)
+
+		got = append(got, record)
+	}
+
+	require.Len(t, got, 2)
+	assert.Equal(t, []string{"a", "1"}, got[0].ID)
+	assert.Equal(t, []byte("a1"), got[0].Data)
+	assert.WithinDuration(t, base, got[0].LastModified, time.Microsecond)
+	assert.Equal(t, []string{"b"}, got[1].ID)
+	assert.WithinDuration(t, base.Add(time.Hour), got[1].LastModified, time.Microsecond)
+}
+
+func TestRedisTKV_Count(t *testing.T) {
+	ctx := context.Background()
+	store := newRTKV(t, newGoRedisClient(0))
+	base := time.Date(2025, 1, 10, 12, 0, 0, 0, time.UTC)
+
+	count, err := store.Count(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, count)
+
+	err = store.BulkSet(ctx, []rtkv.BulkSetRecord{
+		{LastModified: base, ID: []string{"a"}, Data: []byte("a")},
+		{LastModified: base.Add(time.Hour), ID: []string{"b"}, Data: []byte("b")},
+		{LastModified: base.Add(2 * time.Hour), ID: []string{"c"}, Data: []byte("c")},
+	})
+	require.NoError(t, err)
+
+	count, err = store.Count(ctx)
+	require.NoError(t, err)
+	assert.EqualValues(t, 3, count)
+
+	from, to := base.Add(time.Hour), base.Add(2*time.Hour)
+
+	count, err = store.CountRange(ctx, &from, nil)
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, count)
+
+	count, err = store.CountRange(ctx, nil, &from)
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, count)
+
+	count, err = store.CountRange(ctx, &from, &to)
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, count)
+}
+
+func TestRedisTKV_GetDel(t *testing.T) {
+	ctx := context.Background()
+	store := newRTKV(t, newGoRedisClient(0)).With(rtkv.WithChildIndex())
+
+	_, err := store.Set(ctx, []byte("job"), time.Now(), "queue", "1")
+	require.NoError(t, err)
+
+	value, err := store.GetDel(ctx, "queue", "1")
+	require.NoError(t, err)
+	assert.Equal(t, "job", string(value))
+
+	value, err = store.GetDel(ctx, "queue", "1")
+	require.NoError(t, err)
+	assert.Nil(t, value, "A claimed entity should only be returned once")
+
+	count, err := store.Count(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, count)
+
+	children, err := store.GetChildren(ctx, "queue")
+	require.NoError(t, err)
+	assert.Empty(t, children)
+
+	_, err = store.With(rtkv.WithNotFoundError()).GetDel(ctx, "queue", "1")
+	require.ErrorIs(t, err, rtkv.ErrNotFound)
+}
+
+func TestRedisTKV_Touch(t *testing.T) {
+	ctx := context.Background()
+	store := newRTKV(t, newGoRedisClient(0)).With(rtkv.WithPriorities(2))
+	base := time.Date(2025, 1, 10, 12, 0, 0, 0, time.UTC)
+
+	_, err := store.Clear(ctx)
+	require.NoError(t, err)
+
+	_, err = store.Set(rtkv.WithPriority(ctx, 1), []byte("a"), base, "a")
+	require.NoError(t, err)
+
+	_, err = store.Set(ctx, []byte("b"), base.Add(time.Minute), "b")
+	require.NoError(t, err)
+
+	require.NoError(t, store.Touch(ctx, base.Add(time.Hour), "a"))
+
+	value, lastModified, err := store.GetWithLastModified(ctx, "a")
+	require.NoError(t, err)
+	assert.Equal(t, "a", string(value))
+	assert.WithinDuration(t, base.Add(time.Hour), lastModified, time.Microsecond)
+
+	from := base.Add(30 * time.Minute)
+
+	records, total, err := store.FetchPageByPriority(ctx, &from, nil, 0, 10)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), total, "The priority index should be touched")
+
+	for record, err := range records {
+		require.NoError(t, err)
+		assert.Equal(t, []string{"a"}, record.ID)
+	}
+
+	require.ErrorIs(t, store.Touch(ctx, base, "missing"), rtkv.ErrNotFound)
+
+	count, err := store.Count(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+}
+
+func TestRedisTKV_GetSet(t *testing.T) {
+	ctx := context.Background()
+	store := newRTKV(t, newGoRedisClient(0))
+	base := time.Date(2025, 1, 10, 12, 0, 0, 0, time.UTC)
+
+	_, err := store.Clear(ctx)
+	require.NoError(t, err)
+
+	old, err := store.GetSet(ctx, []byte("pending"), base, "order")
+	require.NoError(t, err)
+	assert.Nil(t, old)
+
+	old, err = store.GetSet(ctx, []byte("paid"), base.Add(time.Minute), "order")
+	require.NoError(t, err)
+	assert.Equal(t, "pending", string(old))
+
+	value, lastModified, err := store.GetWithLastModified(ctx, "order")
+	require.NoError(t, err)
+	assert.Equal(t, "paid", string(value))
+	assert.WithinDuration(t, base.Add(time.Minute), lastModified, time.Microsecond)
+
+	count, err := store.Count(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+
+	stats := store.Stats().Writes
+	assert.Equal(t, int64(1), stats.Creates)
+	assert.Equal(t, int64(1), stats.Overwrites)
+}
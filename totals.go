@@ -0,0 +1,143 @@
+// GNU AFFERO GENERAL PUBLIC LICENSE
+// Version 3, 19 November 2007
+//
+// Copyright (C) 2025 John Kleijn
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// For more details, see the full AGPL-3.0 license at:
+// https://www.gnu.org/licenses/agpl-3.0.html
+
+package rtkv
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// totalsCacheSize is the number of cached totals above
+// which expired ones are swept.
+const totalsCacheSize = 1024
+
+// totalsKey identifies a cached total.
+type totalsKey struct {
+	index, rangeMin, rangeMax string
+}
+
+type cachedTotal struct {
+	total   int64
+	expires time.Time
+}
+
+// totalsCache caches index counts. It is shared between
+// a store and its clones.
+type totalsCache struct {
+	ttl     time.Duration
+	entries map[totalsKey]cachedTotal
+	mx      sync.Mutex
+}
+
+// WithTotalsCache caches the totals counted by FetchPage,
+// FetchPageRecords, Count and CountRange for ttl, so dashboards
+// polling the same count don't each issue a ZCOUNT. Totals are
+// cached per exact range, so callers sharing a count should
+// truncate their range, for example to the second. Writes through
+// the store or its clones clear the cache; writes by other
+// processes are reflected once the cached total expires.
+func WithTotalsCache(ttl time.Duration) Option {
+	return func(r *RedisTKV) {
+		r.totals = &totalsCache{ttl: ttl, entries: map[totalsKey]cachedTotal{}}
+	}
+}
+
+// countIndex counts the entities in the index at key within the
+// given score range, using the totals cache if enabled and the read
+// is eventually consistent.
+func (r *RedisTKV) countIndex(ctx context.Context, reader redis.Cmdable, key, rangeMin, rangeMax string) (int64, error) {
+	cacheKey := totalsKey{index: key, rangeMin: rangeMin, rangeMax: rangeMax}
+
+	if r.consistencyFor(ctx) != ConsistencyStrong {
+		if total, ok := r.totals.get(cacheKey); ok {
+			opResultFrom(ctx).cacheHit()
+
+			return total, nil
+		}
+	}
+
+	total, err := reader.ZCount(ctx, key, rangeMin, rangeMax).Result()
+	if err != nil {
+		return 0, fmt.Errorf("failed to count: %w", err)
+	}
+
+	r.totals.put(cacheKey, total)
+
+	return total, nil
+}
+
+func (c *totalsCache) get(key totalsKey) (int64, bool) {
+	if c == nil {
+		return 0, false
+	}
+
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	cached, ok := c.entries[key]
+	if !ok || time.Now().After(cached.expires) {
+		return 0, false
+	}
+
+	return cached.total, true
+}
+
+func (c *totalsCache) put(key totalsKey, total int64) {
+	if c == nil {
+		return
+	}
+
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	now := time.Now()
+
+	if len(c.entries) >= totalsCacheSize {
+		for k, cached := range c.entries {
+			if now.After(cached.expires) {
+				delete(c.entries, k)
+			}
+		}
+
+		if len(c.entries) >= totalsCacheSize {
+			clear(c.entries)
+		}
+	}
+
+	c.entries[key] = cachedTotal{total: total, expires: now.Add(c.ttl)}
+}
+
+// invalidate forgets all cached totals.
+func (c *totalsCache) invalidate() {
+	if c == nil {
+		return
+	}
+
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	clear(c.entries)
+}
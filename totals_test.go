@@ -0,0 +1,69 @@
+// GNU AFFERO GENERAL PUBLIC LICENSE
+// Version 3, 19 November 2007
+//
+// Copyright (C) 2025 John Kleijn
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// For more details, see the full AGPL-3.0 license at:
+// https://www.gnu.org/licenses/agpl-3.0.html
+
+package rtkv_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/johnknl/rtkv"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedisTKV_WithTotalsCache(t *testing.T) {
+	ctx := context.Background()
+	client := newGoRedisClient(0)
+	store := newRTKV(t, client).With(rtkv.WithTotalsCache(time.Hour))
+
+	_, err := store.Clear(ctx)
+	require.NoError(t, err)
+
+	_, err = store.Set(ctx, []byte("a"), time.Now(), "a")
+	require.NoError(t, err)
+
+	count, err := store.Count(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+
+	// Writes by other processes aren't seen until the total expires.
+	require.NoError(t, client.ZAdd(ctx, t.Name()+rtkv.DelimUnit+"lmIdx",
+		redis.Z{Score: 1, Member: t.Name() + rtkv.DelimUnit + "b"}).Err())
+
+	count, err = store.Count(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), count, "The total should be cached")
+
+	_, total, err := store.FetchPage(ctx, nil, nil, 0, 10)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), total, "FetchPage should share the cached total")
+
+	// Local writes clear the cache.
+	_, err = store.Set(ctx, []byte("c"), time.Now(), "c")
+	require.NoError(t, err)
+
+	count, err = store.Count(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), count)
+}
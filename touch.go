@@ -0,0 +1,103 @@
+// GNU AFFERO GENERAL PUBLIC LICENSE
+// Version 3, 19 November 2007
+//
+// Copyright (C) 2025 John Kleijn
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// For more details, see the full AGPL-3.0 license at:
+// https://www.gnu.org/licenses/agpl-3.0.html
+
+package rtkv
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// touchScript moves an existing entity in the lastModified index,
+// and in the priority index holding it, if any. Returns 0 if the
+// entity doesn't exist, otherwise 1.
+const touchScript = `
+local key = KEYS[1] -- the entity key
+local index = KEYS[2] -- the lastModified index
+local score = ARGV[1] -- the lastModified score
+
+if redis.call("EXISTS", key) == 0 then
+  return 0
+end
+
+redis.call("ZADD", index, score, key)
+
+for i = 3, #KEYS do -- the priority indexes
+  redis.call("ZADD", KEYS[i], "XX", score, key)
+end
+
+return 1
+`
+
+// Touch sets the lastModified time of an existing entity without
+// rewriting its value, to bump recency without sending the value.
+// Returns ErrNotFound if the entity doesn't exist. The version of
+// the entity is unchanged; the change feed records a set.
+func (r *RedisTKV) Touch(ctx context.Context, lastModified time.Time, id ...string) error {
+	defer r.observe(ctx, "touch", time.Now())
+
+	if err := r.checkID(id); err != nil {
+		return err
+	}
+
+	if err := r.checkWritable(ctx); err != nil {
+		return err
+	}
+
+	key := r.namespacedKey(id...)
+	keys := []string{key, r.namespacedKey(lastModifiedIdxSuffix)}
+
+	for level := range r.priorities {
+		keys = append(keys, r.priorityKey(level))
+	}
+
+	result, err := r.evalScript(ctx, touchScript, keys, lastModified.UnixNano())
+	if err != nil {
+		return fmt.Errorf("failed to touch entity: %w", err)
+	}
+
+	if touched, _ := result.(int64); touched == 0 {
+		return ErrNotFound
+	}
+
+	if err = r.compactedRemove(ctx, false, key); err != nil {
+		return err
+	}
+
+	if r.changes == nil && r.writers == nil {
+		return nil
+	}
+
+	_, err = r.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		r.writerAdd(ctx, pipe)
+		r.changeAdd(ctx, pipe, ChangeSet, id, lastModified.UnixNano())
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update indexes: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,133 @@
+// GNU AFFERO GENERAL PUBLIC LICENSE
+// Version 3, 19 November 2007
+//
+// Copyright (C) 2025 John Kleijn
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// For more details, see the full AGPL-3.0 license at:
+// https://www.gnu.org/licenses/agpl-3.0.html
+
+package rtkv
+
+import (
+	"context"
+	"fmt"
+	"slices"
+)
+
+// valueHeader starts the values of transforms, and aliases, so
+// they can't be confused with plain values.
+const valueHeader = "\x00rtkv:"
+
+// valueTransform converts values between the form the application
+// sees and the form stored in Redis, such as by compressing them.
+// decode must return values without its header unchanged, so values
+// written before the transform was enabled keep working.
+type valueTransform interface {
+	encode(id []string, data []byte) ([]byte, error)
+	decode(data []byte) ([]byte, error)
+}
+
+// withTransform adds t to the transforms of the store. Transforms
+// encode in the order they were added, and decode in reverse.
+func (r *RedisTKV) withTransform(t valueTransform) {
+	r.transforms = append(slices.Clip(r.transforms), t)
+}
+
+// encodeValue converts a value to the form stored in Redis.
+func (r *RedisTKV) encodeValue(id []string, data []byte) ([]byte, error) {
+	for _, t := range r.transforms {
+		var err error
+
+		if data, err = t.encode(id, data); err != nil {
+			return nil, fmt.Errorf("failed to encode value of %v: %w", id, err)
+		}
+	}
+
+	return data, nil
+}
+
+// decodeValue converts a value read from Redis back to
+// the form the application sees.
+func (r *RedisTKV) decodeValue(data []byte) ([]byte, error) {
+	if data == nil {
+		return nil, nil
+	}
+
+	for i := len(r.transforms) - 1; i >= 0; i-- {
+		var err error
+
+		if data, err = r.transforms[i].decode(data); err != nil {
+			return nil, fmt.Errorf("failed to decode value: %w", err)
+		}
+	}
+
+	return data, nil
+}
+
+// encodeRecords returns records with their values encoded,
+// leaving records itself untouched.
+func (r *RedisTKV) encodeRecords(records []BulkSetRecord) ([]BulkSetRecord, error) {
+	if len(r.transforms) == 0 {
+		return records, nil
+	}
+
+	encoded := slices.Clone(records)
+
+	for i := range encoded {
+		var err error
+
+		if encoded[i].Data, err = r.encodeValue(encoded[i].ID, encoded[i].Data); err != nil {
+			return nil, err
+		}
+	}
+
+	return encoded, nil
+}
+
+// readValue returns the value an entity read from Redis refers
+// to, following aliases, in the form the application sees. Values
+// of other namespaces are decoded by their own store.
+func (r *RedisTKV) readValue(ctx context.Context, data []byte) ([]byte, error) {
+	store, data, err := r.followAlias(ctx, data)
+	if err != nil {
+		return nil, err
+	}
+
+	return store.decodeValue(data)
+}
+
+// decodeRaw decodes the string values of an MGET result in place.
+func (r *RedisTKV) decodeRaw(values []any) error {
+	if len(r.transforms) == 0 {
+		return nil
+	}
+
+	for i, raw := range values {
+		s, ok := raw.(string)
+		if !ok {
+			continue
+		}
+
+		data, err := r.decodeValue([]byte(s))
+		if err != nil {
+			return err
+		}
+
+		values[i] = string(data)
+	}
+
+	return nil
+}
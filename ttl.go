@@ -0,0 +1,169 @@
+// GNU AFFERO GENERAL PUBLIC LICENSE
+// Version 3, 19 November 2007
+//
+// Copyright (C) 2025 John Kleijn
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// For more details, see the full AGPL-3.0 license at:
+// https://www.gnu.org/licenses/agpl-3.0.html
+
+package rtkv
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	expirySuffix = "expiry"
+
+	defaultExpiryChunkSize = 1000
+
+	// removeExpiredScript removes entities that expired from the
+	// lastModified index. Entities that were written again without
+	// a TTL since are only removed from the expiry index. Returns
+	// the number of expiry entries processed, followed by the keys
+	// removed, or found compacted, and their expiry scores.
+	removeExpiredScript = `
+local index = KEYS[1] -- the lastModified index
+local expiry = KEYS[2] -- the expiry index
+local compacted = KEYS[3] -- the epoch of every compacted entity
+local now = ARGV[1] -- the current time as a score
+local count = tonumber(ARGV[2]) -- the max number of entries to process
+
+local entries = redis.call("ZRANGE", expiry, "-inf", now, "BYSCORE", "LIMIT", 0, count, "WITHSCORES")
+local removed = {}
+
+for i = 1, #entries, 2 do
+  local key = entries[i]
+
+  if redis.call("EXISTS", key) == 0 and
+    (redis.call("ZREM", index, key) == 1 or redis.call("HEXISTS", compacted, key) == 1) then
+    removed[#removed + 1] = key
+    removed[#removed + 1] = entries[i + 1]
+  end
+
+  redis.call("ZREM", expiry, key)
+end
+
+return {#entries / 2, removed}
+`
+)
+
+// WithDefaultTTL sets the TTL of entities written without one.
+// Zero, the default, keeps entities until they are deleted.
+func WithDefaultTTL(ttl time.Duration) Option {
+	return func(r *RedisTKV) {
+		r.defaultTTL = ttl
+	}
+}
+
+// SetWithTTL sets an entity like Set, letting it expire after ttl.
+// Expired entities are removed from the lastModified index by
+// RemoveExpired. Until then, FetchPage skips them but still counts
+// them in the total.
+func (r *RedisTKV) SetWithTTL(
+	ctx context.Context,
+	data []byte,
+	lastModified time.Time,
+	ttl time.Duration,
+	id ...string,
+) (bool, error) {
+	defer r.observe(ctx, "set", time.Now())
+
+	return r.set(ctx, data, lastModified, ttl, id)
+}
+
+// RemoveExpired removes expired entities from the lastModified index,
+// and from the secondary indexes, recording them as configured with
+// WithExpirySemantics. Returns the number of entities removed or found
+// written again without a TTL.
+func (r *RedisTKV) RemoveExpired(ctx context.Context) (int64, error) {
+	if err := r.checkWritable(ctx); err != nil {
+		return 0, err
+	}
+
+	keys := []string{
+		r.namespacedKey(lastModifiedIdxSuffix),
+		r.internalKey(expirySuffix),
+		r.internalKey(compactedSuffix, membersSuffix),
+	}
+
+	var removed int64
+
+	budget := budgetFrom(ctx)
+
+	for {
+		if err := budget.spend(ctx, defaultExpiryChunkSize); err != nil {
+			return removed, err
+		}
+
+		result, err := r.evalScript(ctx, removeExpiredScript, keys, time.Now().UnixNano(), defaultExpiryChunkSize)
+		if err != nil {
+			return removed, fmt.Errorf("failed to remove expired entities: %w", err)
+		}
+
+		parts, ok := result.([]any)
+		if !ok || len(parts) != 2 { //nolint:mnd // processed, removed
+			return removed, ErrUnexpectedScriptResult
+		}
+
+		n, _ := parts[0].(int64)
+		entries, _ := parts[1].([]any)
+		removed += n
+
+		if err = r.expiredIndexes(ctx, entries); err != nil {
+			return removed, err
+		}
+
+		if n < defaultExpiryChunkSize {
+			return removed, nil
+		}
+	}
+}
+
+// ExpiryCleanupTask returns a janitor task that removes expired
+// entities from the lastModified index.
+func ExpiryCleanupTask() JanitorTask {
+	return func(ctx context.Context, r *RedisTKV) error {
+		_, err := r.RemoveExpired(ctx)
+
+		return err
+	}
+}
+
+// ttlFor returns the TTL to write an entity with.
+func (r *RedisTKV) ttlFor(ttl time.Duration) time.Duration {
+	if ttl > 0 {
+		return ttl
+	}
+
+	return r.defaultTTL
+}
+
+// expiryAdd records when an entity written with a TTL expires.
+func (r *RedisTKV) expiryAdd(ctx context.Context, pipe redis.Pipeliner, key string, ttl time.Duration) {
+	if ttl <= 0 {
+		return
+	}
+
+	pipe.ZAdd(ctx, r.internalKey(expirySuffix), redis.Z{
+		Score:  float64(time.Now().Add(ttl).UnixNano()),
+		Member: key,
+	})
+}
@@ -0,0 +1,205 @@
+// GNU AFFERO GENERAL PUBLIC LICENSE
+// Version 3, 19 November 2007
+//
+// Copyright (C) 2025 John Kleijn
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// For more details, see the full AGPL-3.0 license at:
+// https://www.gnu.org/licenses/agpl-3.0.html
+
+package rtkv_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/johnknl/rtkv"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedisTKV_SetWithTTL(t *testing.T) {
+	ctx := context.Background()
+	client := newGoRedisClient(0)
+	store := newRTKV(t, client)
+	now := time.Now()
+
+	_, err := store.SetWithTTL(ctx, []byte("short"), now, 50*time.Millisecond, "a")
+	require.NoError(t, err)
+
+	err = store.BulkSet(ctx, []rtkv.BulkSetRecord{
+		{LastModified: now, ID: []string{"b"}, Data: []byte("long"), TTL: time.Hour},
+		{LastModified: now, ID: []string{"c"}, Data: []byte("forever")},
+	})
+	require.NoError(t, err)
+
+	key := func(id string) string { return t.Name() + rtkv.DelimUnit + id }
+
+	assert.Positive(t, client.PTTL(ctx, key("a")).Val())
+	assert.Positive(t, client.PTTL(ctx, key("b")).Val())
+	assert.EqualValues(t, -1, client.PTTL(ctx, key("c")).Val())
+
+	removed, err := store.RemoveExpired(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, removed)
+
+	// Let Redis expire the key, as miniredis doesn't.
+	time.Sleep(100 * time.Millisecond)
+	require.NoError(t, client.Del(ctx, key("a")).Err())
+
+	removed, err = store.RemoveExpired(ctx)
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, removed)
+
+	values, total, err := store.FetchPage(ctx, nil, nil, 0, 10)
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, total)
+
+	var n int
+
+	for _, err := range values {
+		require.NoError(t, err)
+
+		n++
+	}
+
+	assert.Equal(t, 2, n)
+}
+
+func TestRedisTKV_RemoveExpired_Rewritten(t *testing.T) {
+	ctx := context.Background()
+	store := newRTKV(t, newGoRedisClient(0))
+
+	_, err := store.SetWithTTL(ctx, []byte("v1"), time.Now(), 50*time.Millisecond, "a")
+	require.NoError(t, err)
+
+	time.Sleep(100 * time.Millisecond)
+
+	_, err = store.Set(ctx, []byte("v2"), time.Now(), "a")
+	require.NoError(t, err)
+
+	removed, err := store.RemoveExpired(ctx)
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, removed)
+
+	_, total, err := store.FetchPage(ctx, nil, nil, 0, 10)
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, total)
+}
+
+func TestRedisTKV_WithDefaultTTL_SkipIdentical(t *testing.T) {
+	ctx := context.Background()
+	client := newGoRedisClient(0)
+	store := newRTKV(t, client).With(
+		rtkv.WithDefaultTTL(time.Hour),
+		rtkv.WithSkipIdenticalWrites(),
+	)
+	key := t.Name() + rtkv.DelimUnit + "a"
+
+	_, err := store.Set(ctx, []byte("v"), time.Now(), "a")
+	require.NoError(t, err)
+	require.NoError(t, client.PExpire(ctx, key, time.Minute).Err())
+
+	_, err = store.Set(ctx, []byte("v"), time.Now(), "a")
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, store.Stats().Writes.Skipped)
+	assert.LessOrEqual(t, client.PTTL(ctx, key).Val(), time.Minute, "an identical write should not extend the TTL")
+}
+
+func TestRedisTKV_WithExpirySemantics(t *testing.T) {
+	ctx := context.Background()
+	client := newGoRedisClient(0)
+	key := func(id string) string { return t.Name() + rtkv.DelimUnit + id }
+	start := time.Now()
+
+	store := newRTKV(t, client).With(
+		rtkv.WithChangeFeed(rtkv.ChangeFeedOptions{}),
+		rtkv.WithIDIndex(),
+	)
+
+	_, err := store.Clear(ctx)
+	require.NoError(t, err)
+
+	tombstones := store.With(rtkv.WithExpirySemantics(rtkv.ExpiryOptions{Mode: rtkv.ExpiryTombstones}))
+	shadows := store.With(rtkv.WithExpirySemantics(rtkv.ExpiryOptions{Mode: rtkv.ExpiryShadows}))
+
+	for _, id := range []string{"a", "b"} {
+		_, err = store.SetWithTTL(ctx, []byte(id), start, 50*time.Millisecond, id)
+		require.NoError(t, err)
+	}
+
+	// Let Redis expire the keys, as miniredis doesn't.
+	time.Sleep(100 * time.Millisecond)
+	require.NoError(t, client.Del(ctx, key("a"), key("b")).Err())
+
+	removed, err := tombstones.RemoveExpired(ctx)
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, removed)
+
+	events, err := store.ReadChanges(ctx, "0", 10)
+	require.NoError(t, err)
+	require.Len(t, events, 4)
+	assert.Equal(t, rtkv.ChangeExpire, events[2].Op)
+	assert.Equal(t, rtkv.ChangeExpire, events[3].Op)
+	assert.WithinDuration(t, start.Add(50*time.Millisecond), events[2].LastModified, 10*time.Millisecond)
+
+	assert.Zero(t, client.ZCard(ctx, key("ids")).Val(), "Expired entities should leave the ID index")
+
+	_, err = shadows.SetWithTTL(ctx, []byte("c"), start, 50*time.Millisecond, "c")
+	require.NoError(t, err)
+
+	time.Sleep(100 * time.Millisecond)
+	require.NoError(t, client.Del(ctx, key("c")).Err())
+
+	_, err = shadows.RemoveExpired(ctx)
+	require.NoError(t, err)
+
+	expired, err := shadows.FetchExpired(ctx, start, 10)
+	require.NoError(t, err)
+	require.Len(t, expired, 1)
+	assert.Equal(t, []string{"c"}, expired[0].ID)
+	assert.True(t, expired[0].Expired.After(start))
+}
+
+func TestRedisTKV_UpdateMany_TTL(t *testing.T) {
+	ctx := context.Background()
+	client := newGoRedisClient(0)
+	store := newRTKV(t, client)
+	key := func(id string) string { return t.Name() + rtkv.DelimUnit + id }
+	exclaim := func(_ []string, old []byte) ([]byte, bool, error) {
+		return append(old, '!'), true, nil
+	}
+
+	_, err := store.SetWithTTL(ctx, []byte("a"), time.Now(), time.Hour, "a")
+	require.NoError(t, err)
+
+	_, err = store.UpdateMany(ctx, [][]string{{"a"}}, exclaim)
+	require.NoError(t, err)
+	assert.Greater(t, client.PTTL(ctx, key("a")).Val(), time.Minute, "Updates should keep the TTL")
+
+	withDefault := store.With(rtkv.WithDefaultTTL(time.Minute))
+
+	_, err = withDefault.Set(ctx, []byte("b"), time.Now(), "b")
+	require.NoError(t, err)
+	require.NoError(t, client.Persist(ctx, key("b")).Err())
+	require.NoError(t, client.ZRem(ctx, t.Name()+rtkv.DelimUnit+"\x00rtkv:expiry", key("b")).Err())
+
+	_, err = withDefault.UpdateMany(ctx, [][]string{{"b"}}, exclaim)
+	require.NoError(t, err)
+	assert.Positive(t, client.PTTL(ctx, key("b")).Val(), "Updates should apply the default TTL")
+	assert.NoError(t, client.ZScore(ctx, t.Name()+rtkv.DelimUnit+"\x00rtkv:expiry", key("b")).Err(),
+		"Updates should record when entities expire")
+}
@@ -0,0 +1,103 @@
+// GNU AFFERO GENERAL PUBLIC LICENSE
+// Version 3, 19 November 2007
+//
+// Copyright (C) 2025 John Kleijn
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// For more details, see the full AGPL-3.0 license at:
+// https://www.gnu.org/licenses/agpl-3.0.html
+
+package rtkv
+
+import (
+	"context"
+	"iter"
+	"time"
+)
+
+// FetchPageTyped fetches a page like RedisTKV.FetchPage, yielding
+// decoded values. Values that fail to decode are yielded as errors,
+// after which iteration continues if the consumer doesn't stop.
+func (s *Store[T]) FetchPageTyped(
+	ctx context.Context,
+	from, to *time.Time, //nolint:varnamelen // from and to are clear
+	offset, limit int,
+) (iter.Seq2[T, error], int64, error) {
+	values, total, err := s.tkv.FetchPage(ctx, from, to, offset, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return s.decodeValues(ctx, values), total, nil
+}
+
+// FetchPageTypedRecords fetches a page like RedisTKV.FetchPageRecords,
+// yielding records with decoded values.
+func (s *Store[T]) FetchPageTypedRecords(
+	ctx context.Context,
+	from, to *time.Time, //nolint:varnamelen // from and to are clear
+	offset, limit int,
+) (iter.Seq2[TypedRecord[T], error], int64, error) {
+	records, total, err := s.tkv.FetchPageRecords(ctx, from, to, offset, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return func(yield func(TypedRecord[T], error) bool) {
+		for record, err := range records {
+			var typed TypedRecord[T]
+
+			if err == nil {
+				typed.LastModified, typed.ID = record.LastModified, record.ID
+				err = s.decodeProbed(ctx, record.ID, record.LastModified, record.Data, &typed.Value)
+			}
+
+			if !yield(typed, err) {
+				return
+			}
+		}
+	}, total, nil
+}
+
+// PaginateTyped iterates over all pages like Paginate over
+// RedisTKV.FetchPage, yielding decoded values.
+func (s *Store[T]) PaginateTyped(
+	ctx context.Context,
+	from, to *time.Time, //nolint:varnamelen // from and to are clear
+	offset, limit int,
+) (iter.Seq2[T, error], error) {
+	values, err := Paginate(ctx, s.tkv.FetchPage, from, to, offset, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.decodeValues(ctx, values), nil
+}
+
+func (s *Store[T]) decodeValues(ctx context.Context, values iter.Seq2[[]byte, error]) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		for data, err := range values {
+			var v T
+
+			if err == nil {
+				err = s.decodeProbed(ctx, nil, time.Time{}, data, &v)
+			}
+
+			if !yield(v, err) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,123 @@
+// GNU AFFERO GENERAL PUBLIC LICENSE
+// Version 3, 19 November 2007
+//
+// Copyright (C) 2025 John Kleijn
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// For more details, see the full AGPL-3.0 license at:
+// https://www.gnu.org/licenses/agpl-3.0.html
+
+package rtkv_test
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/johnknl/rtkv"
+	"github.com/johnknl/rtkv/jsoncodec"
+	"github.com/johnknl/rtkv/rtkvtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type typedOrder struct {
+	N int `json:"n"`
+}
+
+func newTypedStore(t *testing.T) *rtkv.Store[typedOrder] {
+	t.Helper()
+
+	_, client := rtkvtest.NewMiniredis(t)
+	store := rtkv.NewStore[typedOrder](rtkv.NewRedisTKV(rtkv.DelimUnit, t.Name(), client), jsoncodec.Codec{})
+	start := time.Unix(1700000000, 0)
+
+	for i := range 5 {
+		_, err := store.Set(context.Background(), typedOrder{N: i}, start.Add(time.Duration(i)*time.Second), strconv.Itoa(i))
+		require.NoError(t, err)
+	}
+
+	return store
+}
+
+func TestStore_FetchPageTyped(t *testing.T) {
+	ctx := context.Background()
+	store := newTypedStore(t)
+
+	values, total, err := store.FetchPageTyped(ctx, nil, nil, 1, 2)
+	require.NoError(t, err)
+	assert.EqualValues(t, 5, total)
+
+	var got []typedOrder
+
+	for v, err := range values {
+		require.NoError(t, err)
+
+		got = append(got, v)
+	}
+
+	assert.Equal(t, []typedOrder{{N: 1}, {N: 2}}, got)
+}
+
+func TestStore_FetchPageTypedRecords(t *testing.T) {
+	ctx := context.Background()
+	store := newTypedStore(t)
+
+	records, _, err := store.FetchPageTypedRecords(ctx, nil, nil, 4, 10)
+	require.NoError(t, err)
+
+	var got []rtkv.TypedRecord[typedOrder]
+
+	for record, err := range records {
+		require.NoError(t, err)
+
+		got = append(got, record)
+	}
+
+	require.Len(t, got, 1)
+	assert.Equal(t, []string{"4"}, got[0].ID)
+	assert.Equal(t, typedOrder{N: 4}, got[0].Value)
+	assert.Equal(t, time.Unix(1700000004, 0), got[0].LastModified)
+}
+
+func TestStore_PaginateTyped(t *testing.T) {
+	ctx := context.Background()
+	store := newTypedStore(t)
+
+	_, err := store.TKV().Set(ctx, []byte("not json"), time.Unix(1700000010, 0), "bad")
+	require.NoError(t, err)
+
+	values, err := store.PaginateTyped(ctx, nil, nil, 0, 2)
+	require.NoError(t, err)
+
+	var (
+		got  []int
+		errs int
+	)
+
+	for v, err := range values {
+		if err != nil {
+			errs++
+
+			continue
+		}
+
+		got = append(got, v.N)
+	}
+
+	assert.Equal(t, []int{0, 1, 2, 3, 4}, got)
+	assert.Equal(t, 1, errs, "Values that fail to decode should be yielded as errors")
+}
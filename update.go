@@ -0,0 +1,407 @@
+// GNU AFFERO GENERAL PUBLIC LICENSE
+// Version 3, 19 November 2007
+//
+// Copyright (C) 2025 John Kleijn
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// For more details, see the full AGPL-3.0 license at:
+// https://www.gnu.org/licenses/agpl-3.0.html
+
+package rtkv
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	updateManyChunkSize = 100
+
+	defaultUpdateMaxRetries = 5
+	defaultUpdateBackoff    = 5 * time.Millisecond
+	defaultUpdateMaxBackoff = 200 * time.Millisecond
+)
+
+// ErrUpdateConflict is returned when entities kept being modified
+// concurrently while trying to update them.
+var ErrUpdateConflict = errors.New("entities modified concurrently during update")
+
+// UpdateRetryPolicy controls how Update and UpdateMany retry when
+// entities are modified concurrently.
+type UpdateRetryPolicy struct {
+	// MaxRetries is the number of attempts after which
+	// ErrUpdateConflict is returned. Defaults to 5.
+	MaxRetries int
+
+	// Backoff is the wait before the first retry, doubled for every
+	// further retry up to MaxBackoff. Defaults to 5ms and 200ms.
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+}
+
+// WithUpdateRetries sets how Update and UpdateMany retry
+// when entities are modified concurrently.
+func WithUpdateRetries(policy UpdateRetryPolicy) Option {
+	return func(r *RedisTKV) {
+		if policy.MaxRetries <= 0 {
+			policy.MaxRetries = defaultUpdateMaxRetries
+		}
+
+		if policy.Backoff <= 0 {
+			policy.Backoff = defaultUpdateBackoff
+		}
+
+		if policy.MaxBackoff < policy.Backoff {
+			policy.MaxBackoff = max(defaultUpdateMaxBackoff, policy.Backoff)
+		}
+
+		r.updateRetries = policy
+	}
+}
+
+// UpdateFunc transforms the value of an entity. The old value is
+// nil if the entity doesn't exist. Returning nil deletes the entity.
+type UpdateFunc func(old []byte) ([]byte, error)
+
+// Update reads an entity, applies fn and writes the result back,
+// unless the entity was modified in the meantime, in which case fn
+// is applied again to the new value, backing off between attempts
+// as set with WithUpdateRetries. fn must therefore be free of side
+// effects. The entity gets the current time as its lastModified
+// time. Returns the new value.
+func (r *RedisTKV) Update(ctx context.Context, id []string, fn UpdateFunc) ([]byte, error) {
+	defer r.observe(ctx, "update", time.Now())
+
+	var value []byte
+
+	_, err := r.UpdateMany(ctx, [][]string{id}, func(_ []string, old []byte) ([]byte, bool, error) {
+		var err error
+
+		value, err = fn(old)
+
+		return value, value != nil, err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return value, nil
+}
+
+// UpdateManyFunc transforms the value of a single entity. The old
+// value is nil if the entity doesn't exist. Return keep as false to
+// delete the entity. Returning a value identical to the old value
+// leaves the entity untouched.
+type UpdateManyFunc func(id []string, old []byte) (value []byte, keep bool, err error)
+
+// UpdateMany applies fn to the entities with the given IDs and writes
+// the changes back. Entities are processed in chunks; each chunk is
+// read and written atomically, and retried if any of its entities is
+// modified concurrently. Changed entities get the current time as
+// their lastModified time. Returns the number of entities changed.
+//
+// Use WithUpdateQueue to avoid conflicts between concurrent updates
+// made by the same process.
+//
+// If fn returns an error, processing stops. Chunks written before
+// the failing one are not rolled back.
+//
+// With a RefPolicy other than RefPolicyIgnore, entities fn deletes
+// are deleted as with Delete, one at a time after the rest of their
+// chunk is written, stopping at the first error.
+func (r *RedisTKV) UpdateMany(ctx context.Context, ids [][]string, fn UpdateManyFunc) (int, error) {
+	for _, id := range ids {
+		if err := r.checkID(id); err != nil {
+			return 0, err
+		}
+	}
+
+	if err := r.checkWritable(ctx); err != nil {
+		return 0, err
+	}
+
+	if err := r.checkWriteConcern(ctx); err != nil {
+		return 0, err
+	}
+
+	var changed int
+
+	for start := 0; start < len(ids); start += updateManyChunkSize {
+		chunk := ids[start:min(start+updateManyChunkSize, len(ids))]
+
+		n, err := r.updateChunk(ctx, chunk, fn)
+		changed += n
+
+		if err != nil {
+			return changed, err
+		}
+	}
+
+	return changed, nil
+}
+
+func (r *RedisTKV) updateChunk(ctx context.Context, ids [][]string, fn UpdateManyFunc) (int, error) {
+	keys := make([]string, len(ids))
+
+	for i := range ids {
+		keys[i] = r.namespacedKey(ids[i]...)
+	}
+
+	release, err := r.updateQueue.acquire(ctx, keys)
+	if err != nil {
+		return 0, err
+	}
+
+	defer release()
+
+	policy := r.updateRetries
+	backoff := policy.Backoff
+
+	for attempt := range policy.MaxRetries {
+		if attempt > 0 {
+			opResultFrom(ctx).retry()
+
+			select {
+			case <-time.After(backoff):
+			case <-ctx.Done():
+				return 0, fmt.Errorf("update interrupted: %w", context.Cause(ctx))
+			}
+
+			backoff = min(backoff*2, policy.MaxBackoff) //nolint:mnd // exponential backoff
+		}
+
+		var (
+			changes  map[int][]byte
+			deferred [][]string
+			zaddRes  map[int]*redis.IntCmd
+			delRes   []*redis.IntCmd
+		)
+
+		err := r.client.Watch(ctx, func(tx *redis.Tx) error {
+			values, err := tx.MGet(ctx, keys...).Result()
+			if err != nil {
+				return fmt.Errorf("failed to execute mget: %w", err)
+			}
+
+			if err = r.decodeRaw(values); err != nil {
+				return err
+			}
+
+			if changes, err = applyUpdates(ids, values, fn); err != nil {
+				return err
+			}
+
+			for i, value := range changes {
+				if value == nil {
+					continue
+				}
+
+				if err = r.validate(ctx, ids[i], value); err != nil {
+					return err
+				}
+
+				if changes[i], err = r.encodeValue(ids[i], value); err != nil {
+					return err
+				}
+			}
+
+			// Deletes subject to a reference policy are made
+			// after the chunk is written, as with Delete.
+			for i, value := range changes {
+				if value == nil && r.refPolicy != RefPolicyIgnore {
+					deferred = append(deferred, ids[i])
+					delete(changes, i)
+				}
+			}
+
+			if len(changes) == 0 {
+				return nil
+			}
+
+			timestamp := time.Now().UnixNano()
+
+			// Without a default TTL, updates keep the TTL entities had,
+			// along with their entries in the expiry index.
+			ttl := r.ttlFor(0)
+			if ttl <= 0 {
+				ttl = redis.KeepTTL
+			}
+
+			zaddRes, delRes = make(map[int]*redis.IntCmd, len(changes)), nil
+
+			_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+				r.writerAdd(ctx, pipe)
+
+				for i, value := range changes {
+					if value == nil {
+						delRes = append(delRes, pipe.Del(ctx, keys[i]))
+						r.indexRemove(ctx, pipe, keys[i], ids[i])
+
+						continue
+					}
+
+					zaddRes[i] = r.setEntity(ctx, pipe, value, timestamp, ttl, keys[i], ids[i])
+				}
+
+				return nil
+			})
+			if err != nil || !writeConcernFrom(ctx).waits() {
+				return err
+			}
+
+			return waitForReplicas(ctx, tx, writeConcernFrom(ctx))
+		}, keys...)
+
+		if errors.Is(err, redis.TxFailedErr) {
+			r.recordConflicts(ctx, keys...)
+
+			continue
+		}
+
+		if err != nil {
+			return 0, fmt.Errorf("failed to update entities: %w", err)
+		}
+
+		r.recordUpdates(ctx, changes, zaddRes, delRes)
+
+		if err = r.compactedUpdates(ctx, keys, changes); err != nil {
+			return len(changes), err
+		}
+
+		if err = r.verifyUpdates(ctx, keys, changes); err != nil {
+			return len(changes), err
+		}
+
+		changed := len(changes)
+
+		for _, id := range deferred {
+			if err = r.deleteReferenced(ctx, id); err != nil {
+				return changed, err
+			}
+
+			changed++
+		}
+
+		return changed, nil
+	}
+
+	return 0, ErrUpdateConflict
+}
+
+// recordUpdates records the writes and deletes made by
+// an update in the store's stats.
+func (r *RedisTKV) recordUpdates(
+	ctx context.Context,
+	changes map[int][]byte,
+	zaddRes map[int]*redis.IntCmd,
+	delRes []*redis.IntCmd,
+) {
+	for i, res := range zaddRes {
+		r.stats.recordSet(ctx, len(changes[i]), res.Val() == 1)
+	}
+
+	var deleted int
+
+	for _, res := range delRes {
+		deleted += int(res.Val())
+	}
+
+	r.stats.recordDeletes(ctx, deleted)
+}
+
+// compactedUpdates removes the entities written or deleted by an
+// update from their archive shards.
+func (r *RedisTKV) compactedUpdates(ctx context.Context, keys []string, changes map[int][]byte) error {
+	var written, deleted []string
+
+	for i, value := range changes {
+		if value == nil {
+			deleted = append(deleted, keys[i])
+		} else {
+			written = append(written, keys[i])
+		}
+	}
+
+	if err := r.compactedRemove(ctx, false, written...); err != nil {
+		return err
+	}
+
+	return r.compactedRemove(ctx, true, deleted...)
+}
+
+// verifyUpdates verifies the values written by an update, if the
+// write concern of ctx asks for it. Deletes are not verified.
+func (r *RedisTKV) verifyUpdates(ctx context.Context, keys []string, changes map[int][]byte) error {
+	if !writeConcernFrom(ctx).verifies() {
+		return nil
+	}
+
+	var written []string
+
+	var values [][]byte
+
+	for i, value := range changes {
+		if value != nil {
+			written = append(written, keys[i])
+			values = append(values, value)
+		}
+	}
+
+	if len(written) == 0 {
+		return nil
+	}
+
+	return r.verifyWrites(ctx, written, values)
+}
+
+// applyUpdates runs fn over MGET results and returns the changed
+// values by position. A nil value means the entity is deleted.
+func applyUpdates(ids [][]string, values []any, fn UpdateManyFunc) (map[int][]byte, error) {
+	changes := make(map[int][]byte)
+
+	for i, raw := range values {
+		var old []byte
+
+		if s, ok := raw.(string); ok {
+			old = []byte(s)
+		}
+
+		value, keep, err := fn.call(ids[i], old)
+		if err != nil {
+			return nil, fmt.Errorf("update of entity %v failed: %w", ids[i], err)
+		}
+
+		switch {
+		case !keep && old != nil:
+			changes[i] = nil
+		case keep && value != nil && !bytes.Equal(old, value):
+			changes[i] = value
+		}
+	}
+
+	return changes, nil
+}
+
+// call calls fn, converting a panic into an error.
+func (fn UpdateManyFunc) call(id []string, old []byte) (value []byte, keep bool, err error) {
+	defer recoverPanic(&err)
+
+	return fn(id, old)
+}
@@ -0,0 +1,189 @@
+// GNU AFFERO GENERAL PUBLIC LICENSE
+// Version 3, 19 November 2007
+//
+// Copyright (C) 2025 John Kleijn
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// For more details, see the full AGPL-3.0 license at:
+// https://www.gnu.org/licenses/agpl-3.0.html
+
+package rtkv_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/johnknl/rtkv"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedisTKV_UpdateMany(t *testing.T) {
+	ctx := context.Background()
+
+	redisClient := newGoRedisClient(0)
+
+	t.Cleanup(func() {
+		redisClient.FlushDB(ctx).Err()
+	})
+
+	store := newRTKV(t, redisClient)
+	then := time.Now().Add(-time.Hour)
+
+	for _, id := range []string{"a", "b", "c"} {
+		_, err := store.Set(ctx, []byte(id), then, id)
+		require.NoError(t, err)
+	}
+
+	ids := [][]string{{"a"}, {"b"}, {"c"}, {"d"}}
+
+	changed, err := store.UpdateMany(ctx, ids, func(id []string, old []byte) ([]byte, bool, error) {
+		switch id[0] {
+		case "a":
+			return []byte(strings.ToUpper(string(old))), true, nil
+		case "b":
+			return old, true, nil
+		case "c":
+			return nil, false, nil
+		default:
+			assert.Nil(t, old)
+
+			return nil, false, nil
+		}
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, 2, changed)
+
+	data, err := store.Get(ctx, "a")
+
+	require.NoError(t, err)
+	assert.Equal(t, []byte("A"), data)
+
+	exists, err := store.Exists(ctx, "c")
+
+	require.NoError(t, err)
+	assert.False(t, exists)
+
+	now := time.Now().Add(-time.Minute)
+
+	_, total, err := store.FetchPage(ctx, &now, nil, 0, 10)
+
+	require.NoError(t, err)
+	assert.EqualValuesf(t, 1, total, "Only changed entities should be bumped")
+
+	_, total, err = store.FetchPage(ctx, nil, nil, 0, 10)
+
+	require.NoError(t, err)
+	assert.EqualValuesf(t, 2, total, "Deleted entities should leave the index")
+
+	t.Run("Error", func(t *testing.T) {
+		errUpdate := errors.New("mock error")
+
+		changed, err := store.UpdateMany(ctx, ids, func([]string, []byte) ([]byte, bool, error) {
+			return nil, false, errUpdate
+		})
+
+		require.ErrorIs(t, err, errUpdate)
+		assert.Zero(t, changed)
+	})
+}
+
+func TestRedisTKV_UpdateMany_StatsAndRefs(t *testing.T) {
+	ctx := context.Background()
+	store := newRTKV(t, newGoRedisClient(0)).With(rtkv.WithRefPolicy(rtkv.RefPolicyBlock))
+
+	for _, id := range []string{"order", "line", "note"} {
+		_, err := store.Set(ctx, []byte(id), time.Now(), id)
+		require.NoError(t, err)
+	}
+
+	require.NoError(t, store.AddRef(ctx, []string{"order"}, []string{"line"}))
+
+	before := store.Stats().Writes
+
+	changed, err := store.UpdateMany(ctx, [][]string{{"order"}, {"note"}, {"new"}},
+		func(id []string, old []byte) ([]byte, bool, error) {
+			if id[0] == "note" {
+				return nil, false, nil
+			}
+
+			return append([]byte(id[0]), '!'), true, nil
+		})
+	require.NoError(t, err)
+	assert.Equal(t, 3, changed)
+
+	writes := store.Stats().Writes
+
+	assert.EqualValues(t, 2, writes.Sets-before.Sets)
+	assert.EqualValues(t, 1, writes.Creates-before.Creates)
+	assert.EqualValues(t, 1, writes.Deletes-before.Deletes)
+
+	_, err = store.UpdateMany(ctx, [][]string{{"line"}}, func([]string, []byte) ([]byte, bool, error) {
+		return nil, false, nil
+	})
+	require.ErrorIs(t, err, rtkv.ErrReferenced, "Updates should honour the reference policy")
+
+	exists, err := store.Exists(ctx, "line")
+	require.NoError(t, err)
+	assert.True(t, exists)
+}
+
+func TestRedisTKV_Update(t *testing.T) {
+	ctx := context.Background()
+	store := newRTKV(t, newGoRedisClient(0))
+
+	_, err := store.Set(ctx, []byte("a"), time.Now(), "a")
+	require.NoError(t, err)
+
+	calls := 0
+
+	value, err := store.Update(ctx, []string{"a"}, func(old []byte) ([]byte, error) {
+		calls++
+
+		if calls == 1 {
+			_, err := store.Set(ctx, []byte("b"), time.Now(), "a")
+			require.NoError(t, err)
+		}
+
+		return append(old, '!'), nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "b!", string(value), "Update should be retried on the new value")
+	assert.Equal(t, 2, calls)
+
+	impatient := store.With(rtkv.WithUpdateRetries(rtkv.UpdateRetryPolicy{MaxRetries: 2, Backoff: time.Millisecond}))
+
+	_, err = impatient.Update(ctx, []string{"a"}, func(old []byte) ([]byte, error) {
+		_, err := store.Set(ctx, []byte("c"), time.Now(), "a")
+		require.NoError(t, err)
+
+		return append(old, '?'), nil
+	})
+	require.ErrorIs(t, err, rtkv.ErrUpdateConflict)
+
+	value, err = store.Update(ctx, []string{"a"}, func([]byte) ([]byte, error) {
+		return nil, nil
+	})
+	require.NoError(t, err)
+	assert.Nil(t, value)
+
+	exists, err := store.Exists(ctx, "a")
+	require.NoError(t, err)
+	assert.False(t, exists, "Returning nil should delete the entity")
+}
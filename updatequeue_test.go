@@ -0,0 +1,85 @@
+// GNU AFFERO GENERAL PUBLIC LICENSE
+// Version 3, 19 November 2007
+//
+// Copyright (C) 2025 John Kleijn
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// For more details, see the full AGPL-3.0 license at:
+// https://www.gnu.org/licenses/agpl-3.0.html
+
+package rtkv_test
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/johnknl/rtkv"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithUpdateQueue(t *testing.T) {
+	ctx := context.Background()
+	store := newRTKV(t, newGoRedisClient(0)).With(rtkv.WithUpdateQueue(), rtkv.WithConflictTracking())
+
+	increment := func(_ []string, old []byte) ([]byte, bool, error) {
+		n, _ := strconv.Atoi(string(old))
+
+		return []byte(strconv.Itoa(n + 1)), true, nil
+	}
+
+	var wg sync.WaitGroup
+
+	for i := range 50 {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			// Overlapping IDs in varying order must not deadlock.
+			ids := [][]string{{"hot"}, {"other", strconv.Itoa(i % 2)}}
+			if i%3 == 0 {
+				ids[0], ids[1] = ids[1], ids[0]
+			}
+
+			_, err := store.UpdateMany(ctx, ids, increment)
+			assert.NoError(t, err)
+		}()
+	}
+
+	wg.Wait()
+
+	value, err := store.Get(ctx, "hot")
+	require.NoError(t, err)
+	assert.Equal(t, "50", string(value))
+
+	conflicts, err := store.HotConflicts(ctx, 1)
+	require.NoError(t, err)
+	assert.Empty(t, conflicts)
+
+	cancelled, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+
+	_, err = store.UpdateMany(cancelled, [][]string{{"hot"}}, func(id []string, old []byte) ([]byte, bool, error) {
+		_, err := store.UpdateMany(cancelled, [][]string{id}, increment)
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+
+		return old, true, nil
+	})
+	require.NoError(t, err)
+}
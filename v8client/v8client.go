@@ -0,0 +1,133 @@
+// GNU AFFERO GENERAL PUBLIC LICENSE
+// Version 3, 19 November 2007
+//
+// Copyright (C) 2025 John Kleijn
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// For more details, see the full AGPL-3.0 license at:
+// https://www.gnu.org/licenses/agpl-3.0.html
+
+// Package v8client runs rtkv stores on a go-redis v8 client, for
+// applications that haven't moved to go-redis v9 yet.
+//
+// The store still talks to a go-redis v9 client, but one whose
+// connections are served in memory by the v8 client: each of them
+// holds a connection of the v8 client, so transactions and WATCH
+// behave as they would on a connection to Redis. The deadline of the
+// context of a command is passed on to the v8 client, but its other
+// values, such as trace spans, aren't. Every store feature works,
+// with these exceptions:
+//
+//   - Pub/sub, MONITOR and CLIENT TRACKING are rejected.
+//   - Cluster clients and rings aren't supported, as the slots of
+//     arbitrary commands are unknown.
+package v8client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	redisv8 "github.com/go-redis/redis/v8"
+	"github.com/johnknl/rtkv"
+	"github.com/johnknl/rtkv/internal/bridge"
+	"github.com/redis/go-redis/v9"
+)
+
+// NewClient returns a go-redis v9 client whose commands are executed
+// by client. Its pool has the size of the pool of client, as each of
+// its connections holds one of client. Closing it doesn't close client.
+func NewClient(client *redisv8.Client) *redis.Client {
+	return bridge.NewClient(backend{client: client}, bridge.Options{
+		Name:     "v8client",
+		Protocol: 2, //nolint:mnd // RESP2, which go-redis v8 speaks
+		PoolSize: client.Options().PoolSize,
+	})
+}
+
+// NewRedisTKV creates a store on client.
+func NewRedisTKV(idDelimiter, namespace string, client *redisv8.Client, opts ...rtkv.Option) *rtkv.RedisTKV {
+	return rtkv.NewRedisTKV(idDelimiter, namespace, NewClient(client), opts...)
+}
+
+// backend executes the commands of the go-redis v9 client with the
+// v8 client.
+type backend struct {
+	client *redisv8.Client
+}
+
+func (b backend) Conn(ctx context.Context) (bridge.Conn, error) {
+	v8conn := b.client.Conn(ctx)
+	if err := v8conn.Ping(ctx).Err(); err != nil {
+		_ = v8conn.Close()
+
+		return nil, fmt.Errorf("failed to connect: %w", err)
+	}
+
+	return conn{v8conn: v8conn}, nil
+}
+
+// conn executes the commands of a connection of the go-redis v9
+// client with a connection of the v8 client.
+type conn struct {
+	v8conn *redisv8.Conn
+}
+
+// Do forwards commands in a pipeline and writes their replies.
+// Status replies can't be told from bulk strings anymore, so both
+// are written as bulk strings, which go-redis reads alike.
+func (c conn) Do(ctx context.Context, w *bridge.Writer, pending [][]string) error {
+	cmds := make([]*redisv8.Cmd, len(pending))
+
+	_, _ = c.v8conn.Pipelined(ctx, func(pipe redisv8.Pipeliner) error {
+		for i, args := range pending {
+			cmds[i] = pipe.Do(ctx, strings2any(args)...)
+		}
+
+		return nil
+	})
+
+	for _, cmd := range cmds {
+		val, err := cmd.Result()
+
+		var redisErr redisv8.Error
+
+		switch {
+		case errors.Is(err, redisv8.Nil):
+			w.Nil()
+		case errors.As(err, &redisErr):
+			w.Error(redisErr.Error())
+		case err != nil:
+			return err //nolint:wrapcheck // only ends the connection
+		default:
+			w.Value(val)
+		}
+	}
+
+	return nil
+}
+
+func (c conn) Close() {
+	_ = c.v8conn.Close()
+}
+
+func strings2any(args []string) []any {
+	result := make([]any, len(args))
+	for i := range args {
+		result[i] = args[i]
+	}
+
+	return result
+}
@@ -0,0 +1,121 @@
+// GNU AFFERO GENERAL PUBLIC LICENSE
+// Version 3, 19 November 2007
+//
+// Copyright (C) 2025 John Kleijn
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// For more details, see the full AGPL-3.0 license at:
+// https://www.gnu.org/licenses/agpl-3.0.html
+
+package v8client_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	redisv8 "github.com/go-redis/redis/v8"
+	"github.com/johnknl/rtkv"
+	"github.com/johnknl/rtkv/rtkvtest"
+	"github.com/johnknl/rtkv/v8client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newStore(t *testing.T, tkvOpts ...rtkv.Option) *rtkv.RedisTKV {
+	t.Helper()
+
+	server, _ := rtkvtest.NewMiniredis(t)
+
+	client := redisv8.NewClient(&redisv8.Options{Addr: server.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+
+	return v8client.NewRedisTKV(rtkv.DelimUnit, t.Name(), client, tkvOpts...)
+}
+
+func TestNewRedisTKV(t *testing.T) {
+	ctx := context.Background()
+	store := newStore(t, rtkv.WithVersioning(), rtkv.WithNotFoundError())
+	now := time.Now()
+
+	_, err := store.Set(ctx, []byte("a"), now, "a")
+	require.NoError(t, err)
+
+	err = store.BulkSet(ctx, []rtkv.BulkSetRecord{
+		{ID: []string{"b"}, Data: []byte("b"), LastModified: now.Add(time.Second)},
+		{ID: []string{"c"}, Data: []byte("c"), LastModified: now.Add(2 * time.Second), TTL: time.Hour},
+	})
+	require.NoError(t, err)
+
+	value, err := store.Get(ctx, "a")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("a"), value)
+
+	_, err = store.Get(ctx, "missing")
+	require.ErrorIs(t, err, rtkv.ErrNotFound)
+
+	entries, err := store.BulkGet(ctx, [][]string{{"a"}, {"missing"}, {"c"}})
+	require.NoError(t, err)
+	require.Len(t, entries, 3)
+	assert.Equal(t, []byte("a"), entries[0].Data)
+	assert.False(t, entries[1].Exists)
+	assert.Equal(t, []byte("c"), entries[2].Data)
+
+	values, total, err := store.FetchPage(ctx, nil, nil, 0, 10)
+	require.NoError(t, err)
+	assert.EqualValues(t, 3, total)
+
+	var all []string
+
+	for value, err := range values {
+		require.NoError(t, err)
+
+		all = append(all, string(value))
+	}
+
+	assert.Equal(t, []string{"a", "b", "c"}, all)
+
+	changed, err := store.UpdateMany(ctx, [][]string{{"a"}, {"b"}}, func(_ []string, old []byte) ([]byte, bool, error) {
+		return append(old, '!'), true, nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 2, changed)
+
+	version, err := store.Version(ctx, "a")
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, version)
+
+	require.NoError(t, store.Delete(ctx, "b"))
+
+	count, err := store.Count(ctx)
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, count)
+}
+
+func TestNewClient_Context(t *testing.T) {
+	server, _ := rtkvtest.NewMiniredis(t)
+
+	v8 := redisv8.NewClient(&redisv8.Options{Addr: server.Addr(), PoolSize: 1})
+	t.Cleanup(func() { _ = v8.Close() })
+
+	client := v8client.NewClient(v8)
+	t.Cleanup(func() { _ = client.Close() })
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	require.Error(t, client.BLPop(ctx, 0, "list").Err(), "BLPOP should end at the deadline")
+	require.NoError(t, client.RPush(context.Background(), "list", "a").Err(), "The client should still work")
+}
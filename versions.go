@@ -0,0 +1,240 @@
+// GNU AFFERO GENERAL PUBLIC LICENSE
+// Version 3, 19 November 2007
+//
+// Copyright (C) 2025 John Kleijn
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// For more details, see the full AGPL-3.0 license at:
+// https://www.gnu.org/licenses/agpl-3.0.html
+
+package rtkv
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	versionsSuffix = "versions"
+
+	// compareAndSetScript sets an entity if its version is the
+	// expected one. Returns the new version and the number of index
+	// entries added, or -1 and the current version on a conflict.
+	compareAndSetScript = `
+local key = KEYS[1] -- the entity key
+local index = KEYS[2] -- the lastModified index
+local versions = KEYS[3] -- the versions hash
+local expiry = KEYS[4] -- the expiry index
+local data = ARGV[1] -- the new value
+local score = ARGV[2] -- the lastModified score
+local expected = tonumber(ARGV[3]) -- the expected version
+local ttl = tonumber(ARGV[4]) -- the TTL in milliseconds, or 0
+local expireAt = ARGV[5] -- the expiry score
+
+local current = tonumber(redis.call("HGET", versions, key) or "0")
+
+if current ~= expected then
+  return { -1, current }
+end
+
+if ttl > 0 then
+  redis.call("SET", key, data, "PX", ttl)
+  redis.call("ZADD", expiry, expireAt, key)
+else
+  redis.call("SET", key, data)
+end
+
+local added = redis.call("ZADD", index, score, key)
+
+return { redis.call("HINCRBY", versions, key, 1), added }
+`
+)
+
+var (
+	// ErrVersionConflict is returned by CompareAndSet when the
+	// version of the entity isn't the expected one.
+	ErrVersionConflict = errors.New("entity version conflict")
+
+	// ErrVersioningDisabled is returned by CompareAndSet on
+	// stores created without WithVersioning.
+	ErrVersioningDisabled = errors.New("versioning is not enabled")
+)
+
+// WithVersioning keeps a version number per entity that every write
+// increments, for optimistic concurrency with CompareAndSet. Entities
+// that don't exist, or were last written before versioning was
+// enabled, have version 0. Deleting an entity resets its version.
+//
+// Versions are kept in a hash next to the entities. All writers of
+// a namespace must enable versioning for versions to be reliable.
+func WithVersioning() Option {
+	return func(r *RedisTKV) {
+		r.versioned = true
+	}
+}
+
+// Version returns the version of an entity.
+func (r *RedisTKV) Version(ctx context.Context, id ...string) (int64, error) {
+	version, err := r.client.HGet(ctx, r.internalKey(versionsSuffix), r.namespacedKey(id...)).Int64()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return 0, fmt.Errorf("failed to get version: %w", err)
+	}
+
+	return version, nil
+}
+
+// GetVersioned returns the value and the version of an entity, read
+// atomically. The value is nil if the entity doesn't exist. Like Get,
+// it follows aliases to the value of their target, but the version is
+// that of the entity itself, as checked by CompareAndSet.
+func (r *RedisTKV) GetVersioned(ctx context.Context, id ...string) ([]byte, int64, error) {
+	defer r.observe(ctx, "getVersioned", time.Now())
+
+	if err := r.checkID(id); err != nil {
+		return nil, 0, err
+	}
+
+	key := r.namespacedKey(id...)
+
+	var (
+		get     *redis.StringCmd
+		version *redis.StringCmd
+	)
+
+	_, err := r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		get = pipe.Get(ctx, key)
+		version = pipe.HGet(ctx, r.internalKey(versionsSuffix), key)
+
+		return nil
+	})
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return nil, 0, fmt.Errorf("failed to get entity: %w", err)
+	}
+
+	n, _ := version.Int64()
+
+	data, err := get.Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, n, nil
+	}
+
+	data, err = r.readValue(ctx, data)
+
+	return data, n, err
+}
+
+// CompareAndSet sets an entity if its version is expectedVersion and
+// returns the new version. It fails with ErrVersionConflict if the
+// entity was written in the meantime. Pass 0 to only create the
+// entity if it doesn't exist. The entity gets the current time as
+// its lastModified time. Requires WithVersioning.
+func (r *RedisTKV) CompareAndSet(ctx context.Context, data []byte, expectedVersion int64, id ...string) (int64, error) {
+	defer r.observe(ctx, "compareAndSet", time.Now())
+
+	if !r.versioned {
+		return 0, ErrVersioningDisabled
+	}
+
+	if err := r.checkID(id); err != nil {
+		return 0, err
+	}
+
+	if err := r.checkWritable(ctx); err != nil {
+		return 0, err
+	}
+
+	if err := r.validate(ctx, id, data); err != nil {
+		return 0, err
+	}
+
+	if err := r.checkWriteConcern(ctx); err != nil {
+		return 0, err
+	}
+
+	data, err := r.encodeValue(id, data)
+	if err != nil {
+		return 0, err
+	}
+
+	timestamp := time.Now().UnixNano()
+	key := r.namespacedKey(id...)
+	ttl := r.ttlFor(0)
+	keys := []string{
+		key,
+		r.namespacedKey(lastModifiedIdxSuffix),
+		r.internalKey(versionsSuffix),
+		r.internalKey(expirySuffix),
+	}
+
+	result, err := r.writeScript(ctx, compareAndSetScript, keys, data, timestamp, expectedVersion,
+		ttl.Milliseconds(), time.Now().Add(ttl).UnixNano())
+	if err != nil {
+		return 0, fmt.Errorf("failed to set entity: %w", err)
+	}
+
+	values, ok := result.([]any)
+	if !ok || len(values) != 2 { //nolint:mnd // version and added
+		return 0, ErrUnexpectedScriptResult
+	}
+
+	first, _ := values[0].(int64)
+	second, _ := values[1].(int64)
+
+	if first < 0 {
+		r.recordConflicts(ctx, key)
+
+		return second, fmt.Errorf("%w: expected version %d, found %d", ErrVersionConflict, expectedVersion, second)
+	}
+
+	r.stats.recordSet(ctx, len(data), second == 1)
+
+	if err = r.conditionalSetIndexes(ctx, data, timestamp, key); err != nil {
+		return first, err
+	}
+
+	return first, r.verifyWrites(ctx, []string{key}, [][]byte{data})
+}
+
+// versionsArgs returns the key of the versions hash and whether
+// scripts should increment versions.
+func (r *RedisTKV) versionsArgs() (string, int) {
+	if !r.versioned {
+		return r.internalKey(versionsSuffix), 0
+	}
+
+	return r.internalKey(versionsSuffix), 1
+}
+
+// versionsAdd increments the version of a written entity.
+func (r *RedisTKV) versionsAdd(ctx context.Context, pipe redis.Pipeliner, key string) {
+	if !r.versioned {
+		return
+	}
+
+	pipe.HIncrBy(ctx, r.internalKey(versionsSuffix), key, 1)
+}
+
+// versionsRemove resets the version of a deleted entity.
+func (r *RedisTKV) versionsRemove(ctx context.Context, pipe redis.Pipeliner, key string) {
+	if !r.versioned {
+		return
+	}
+
+	pipe.HDel(ctx, r.internalKey(versionsSuffix), key)
+}
@@ -0,0 +1,82 @@
+// GNU AFFERO GENERAL PUBLIC LICENSE
+// Version 3, 19 November 2007
+//
+// Copyright (C) 2025 John Kleijn
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// For more details, see the full AGPL-3.0 license at:
+// https://www.gnu.org/licenses/agpl-3.0.html
+
+package rtkv_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/johnknl/rtkv"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedisTKV_CompareAndSet(t *testing.T) {
+	ctx := context.Background()
+	store := newRTKV(t, newGoRedisClient(0))
+
+	_, err := store.CompareAndSet(ctx, []byte("a"), 0, "a")
+	require.ErrorIs(t, err, rtkv.ErrVersioningDisabled)
+
+	store = store.With(rtkv.WithVersioning())
+
+	version, err := store.CompareAndSet(ctx, []byte("v1"), 0, "a")
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, version)
+
+	_, err = store.CompareAndSet(ctx, []byte("v1 again"), 0, "a")
+	require.ErrorIs(t, err, rtkv.ErrVersionConflict)
+
+	_, err = store.Set(ctx, []byte("v2"), time.Now(), "a")
+	require.NoError(t, err)
+
+	_, err = store.CompareAndSet(ctx, []byte("v2 lost"), 1, "a")
+	require.ErrorIs(t, err, rtkv.ErrVersionConflict, "Other writes should bump the version")
+
+	value, version, err := store.GetVersioned(ctx, "a")
+	require.NoError(t, err)
+	assert.Equal(t, "v2", string(value))
+	assert.EqualValues(t, 2, version)
+
+	version, err = store.CompareAndSet(ctx, []byte("v3"), version, "a")
+	require.NoError(t, err)
+	assert.EqualValues(t, 3, version)
+
+	skipping := store.With(rtkv.WithSkipIdenticalWrites())
+
+	_, err = skipping.Set(ctx, []byte("v3"), time.Now(), "a")
+	require.NoError(t, err)
+	_, err = skipping.Set(ctx, []byte("v4"), time.Now(), "a")
+	require.NoError(t, err)
+
+	version, err = store.Version(ctx, "a")
+	require.NoError(t, err)
+	assert.EqualValues(t, 4, version, "Only writes that change the entity should bump the version")
+
+	require.NoError(t, store.Delete(ctx, "a"))
+
+	value, version, err = store.GetVersioned(ctx, "a")
+	require.NoError(t, err)
+	assert.Nil(t, value)
+	assert.Zero(t, version)
+}
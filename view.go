@@ -0,0 +1,208 @@
+// GNU AFFERO GENERAL PUBLIC LICENSE
+// Version 3, 19 November 2007
+//
+// Copyright (C) 2025 John Kleijn
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// For more details, see the full AGPL-3.0 license at:
+// https://www.gnu.org/licenses/agpl-3.0.html
+
+package rtkv
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"iter"
+	"time"
+)
+
+const (
+	viewSuffix = "view"
+
+	defaultViewTTL = time.Hour
+
+	// snapshotViewScript copies a score range of the lastModified
+	// index into a new sorted set. ZRANGESTORE requires Redis 6.2;
+	// older servers fall back to copying in chunks. The copy runs
+	// atomically either way. Returns the number of entities copied.
+	snapshotViewScript = `
+local index = KEYS[1] -- the lastModified index
+local view = KEYS[2] -- the view to create
+local min = ARGV[1] -- the minimum score
+local max = ARGV[2] -- the maximum score
+local ttl = ARGV[3] -- the TTL of the view in milliseconds
+local chunk = 1000
+
+redis.call("DEL", view)
+
+local ok, copied = pcall(redis.call, "ZRANGESTORE", view, index, min, max, "BYSCORE")
+
+if not ok then
+  copied = 0
+
+  while true do
+    local entries = redis.call("ZRANGE", index, min, max, "BYSCORE", "LIMIT", copied, chunk, "WITHSCORES")
+    if #entries == 0 then
+      break
+    end
+
+    local args = {}
+    for i = 1, #entries, 2 do
+      args[#args + 1] = entries[i + 1]
+      args[#args + 1] = entries[i]
+    end
+
+    redis.call("ZADD", view, unpack(args))
+    copied = copied + #entries / 2
+  end
+end
+
+if copied > 0 then
+  redis.call("PEXPIRE", view, ttl)
+end
+
+return copied
+`
+)
+
+// ErrViewReleased is returned when reading from a released SnapshotView.
+var ErrViewReleased = errors.New("snapshot view released")
+
+// SnapshotViewOptions controls SnapshotView.
+type SnapshotViewOptions struct {
+	// From and To limit the view to a lastModified range. Nil
+	// means unbounded.
+	From, To *time.Time
+
+	// TTL is how long the view is kept if it's never released,
+	// so crashed consumers don't leak it. Defaults to an hour.
+	TTL time.Duration
+}
+
+// SnapshotView is a read-only view of the entities in a store as of
+// the moment it was created. It freezes the lastModified index: pages
+// list the same entities at the same positions until the view is
+// released, no matter what is written in the meantime, which makes
+// it suited for analytics that need several consistent passes over
+// a namespace.
+//
+// Only the index is frozen, not the values. Entities deleted since
+// the view was created are skipped, and entities written since are
+// returned with their current value.
+type SnapshotView struct {
+	store   *RedisTKV
+	key     string
+	created time.Time
+	entries int64
+}
+
+// SnapshotView freezes the lastModified index of the store. Release
+// the view when done with it.
+func (r *RedisTKV) SnapshotView(ctx context.Context, opts SnapshotViewOptions) (*SnapshotView, error) {
+	if opts.TTL <= 0 {
+		opts.TTL = defaultViewTTL
+	}
+
+	random := make([]byte, 8) //nolint:mnd // 64 bits
+	_, _ = rand.Read(random)
+
+	view := &SnapshotView{
+		store:   r,
+		key:     r.internalKey(viewSuffix, hex.EncodeToString(random)),
+		created: time.Now(),
+	}
+
+	rangeMin, rangeMax := scoreRange(opts.From, opts.To)
+	keys := []string{r.namespacedKey(lastModifiedIdxSuffix), view.key}
+
+	result, err := r.evalScript(ctx, snapshotViewScript, keys, rangeMin, rangeMax, opts.TTL.Milliseconds())
+	if err != nil {
+		return nil, fmt.Errorf("failed to create snapshot view: %w", err)
+	}
+
+	entries, ok := result.(int64)
+	if !ok {
+		return nil, ErrUnexpectedScriptResult
+	}
+
+	view.entries = entries
+
+	return view, nil
+}
+
+// Created returns when the view was created.
+func (v *SnapshotView) Created() time.Time {
+	return v.created
+}
+
+// Count returns the number of entities in the view that were
+// last modified in the given range. Nil means unbounded.
+func (v *SnapshotView) Count(ctx context.Context, from, to *time.Time) (int64, error) { //nolint:varnamelen // from and to are clear
+	if v.store == nil {
+		return 0, ErrViewReleased
+	}
+
+	if from == nil && to == nil {
+		return v.entries, nil
+	}
+
+	rangeMin, rangeMax := scoreRange(from, to)
+
+	count, err := v.store.client.ZCount(ctx, v.key, rangeMin, rangeMax).Result()
+	if err != nil {
+		return 0, fmt.Errorf("failed to count: %w", err)
+	}
+
+	return count, nil
+}
+
+// FetchPage works like RedisTKV.FetchPage, reading from the view.
+func (v *SnapshotView) FetchPage(
+	ctx context.Context,
+	from, to *time.Time, //nolint:varnamelen // from and to are clear
+	offset, limit int,
+) (iter.Seq2[[]byte, error], int64, error) {
+	if v.store == nil {
+		return nil, 0, ErrViewReleased
+	}
+
+	defer v.store.observe(ctx, "viewFetchPage", time.Now())
+
+	rangeMin, rangeMax := scoreRange(from, to)
+
+	// Replicas may not have the view yet.
+	ctx = ContextWithConsistency(ctx, ConsistencyStrong)
+
+	return v.store.fetchIndexPage(ctx, v.key, rangeMin, rangeMax, offset, limit)
+}
+
+// Release deletes the view. Reading from a released view
+// returns ErrViewReleased.
+func (v *SnapshotView) Release(ctx context.Context) error {
+	if v.store == nil {
+		return nil
+	}
+
+	if err := v.store.client.Del(ctx, v.key).Err(); err != nil {
+		return fmt.Errorf("failed to release snapshot view: %w", err)
+	}
+
+	v.store = nil
+
+	return nil
+}
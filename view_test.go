@@ -0,0 +1,104 @@
+// GNU AFFERO GENERAL PUBLIC LICENSE
+// Version 3, 19 November 2007
+//
+// Copyright (C) 2025 John Kleijn
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// For more details, see the full AGPL-3.0 license at:
+// https://www.gnu.org/licenses/agpl-3.0.html
+
+package rtkv_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/johnknl/rtkv"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSnapshotView(t *testing.T) {
+	ctx := context.Background()
+	store := goRedisSetup(t, 20)
+
+	view, err := store.SnapshotView(ctx, rtkv.SnapshotViewOptions{})
+	require.NoError(t, err)
+
+	_, err = store.Set(ctx, []byte("new"), time.Now(), "new")
+	require.NoError(t, err)
+
+	count, err := view.Count(ctx, nil, nil)
+	require.NoError(t, err)
+	assert.EqualValues(t, 20, count)
+
+	for range 2 {
+		values, total, err := view.FetchPage(ctx, nil, nil, 0, 100)
+		require.NoError(t, err)
+		assert.EqualValues(t, 20, total)
+
+		var n int
+
+		for value, err := range values {
+			require.NoError(t, err)
+			assert.NotEqual(t, []byte("new"), value)
+
+			n++
+		}
+
+		assert.Equal(t, 20, n)
+	}
+
+	_, total, err := store.FetchPage(ctx, nil, nil, 0, 1)
+	require.NoError(t, err)
+	assert.EqualValues(t, 21, total)
+
+	require.NoError(t, view.Release(ctx))
+
+	_, _, err = view.FetchPage(ctx, nil, nil, 0, 10)
+	require.ErrorIs(t, err, rtkv.ErrViewReleased)
+
+	_, err = view.Count(ctx, nil, nil)
+	require.ErrorIs(t, err, rtkv.ErrViewReleased)
+}
+
+func TestSnapshotView_Range(t *testing.T) {
+	ctx := context.Background()
+	store := newRTKV(t, newGoRedisClient(0))
+	base := time.Unix(0, 0).Add(1000 * time.Hour)
+
+	for i, id := range []string{"a", "b", "c"} {
+		_, err := store.Set(ctx, []byte(id), base.Add(time.Duration(i)*time.Hour), id)
+		require.NoError(t, err)
+	}
+
+	from := base.Add(time.Hour)
+
+	view, err := store.SnapshotView(ctx, rtkv.SnapshotViewOptions{From: &from})
+	require.NoError(t, err)
+
+	t.Cleanup(func() { _ = view.Release(ctx) })
+
+	count, err := view.Count(ctx, nil, nil)
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, count)
+
+	to := base.Add(time.Hour)
+
+	count, err = view.Count(ctx, nil, &to)
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, count)
+}
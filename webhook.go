@@ -0,0 +1,179 @@
+// GNU AFFERO GENERAL PUBLIC LICENSE
+// Version 3, 19 November 2007
+//
+// Copyright (C) 2025 John Kleijn
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// For more details, see the full AGPL-3.0 license at:
+// https://www.gnu.org/licenses/agpl-3.0.html
+
+package rtkv
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	defaultWebhookRetries = 3
+	defaultWebhookBackoff = 500 * time.Millisecond
+
+	// WebhookTimestampHeader carries the Unix time a webhook was sent at.
+	WebhookTimestampHeader = "X-Rtkv-Timestamp"
+
+	// WebhookSignatureHeader carries the hex encoded HMAC-SHA256 of
+	// the timestamp, a dot and the body, keyed with the shared secret.
+	WebhookSignatureHeader = "X-Rtkv-Signature"
+
+	cloudEventsBatchContentType = "application/cloudevents-batch+json"
+)
+
+// WebhookOptions configures a WebhookPublisher.
+type WebhookOptions struct {
+	// URL is the endpoint events are POSTed to.
+	URL string
+
+	// Secret, if set, signs requests. See WebhookSignatureHeader.
+	Secret []byte
+
+	// Source is the CloudEvents source of the events.
+	Source string
+
+	// Retries is the number of times a failed request is retried,
+	// doubling the backoff every time. Defaults to 3.
+	Retries int
+
+	// Backoff is the wait before the first retry.
+	// Defaults to 500 milliseconds.
+	Backoff time.Duration
+
+	// HTTPClient is the client used to send requests.
+	// Defaults to http.DefaultClient.
+	HTTPClient *http.Client
+}
+
+// WebhookPublisher is a ChangePublisher that POSTs batches of change
+// events to an HTTP endpoint as a CloudEvents JSON batch, for systems
+// that can't speak Redis. Use it with a ChangeBridge, whose BatchSize
+// and Interval control how events are batched.
+type WebhookPublisher struct {
+	opts WebhookOptions
+}
+
+// NewWebhookPublisher creates a webhook publisher.
+func NewWebhookPublisher(opts WebhookOptions) *WebhookPublisher {
+	if opts.Retries <= 0 {
+		opts.Retries = defaultWebhookRetries
+	}
+
+	if opts.Backoff <= 0 {
+		opts.Backoff = defaultWebhookBackoff
+	}
+
+	if opts.HTTPClient == nil {
+		opts.HTTPClient = http.DefaultClient
+	}
+
+	return &WebhookPublisher{opts: opts}
+}
+
+// Publish implements ChangePublisher. Requests failing with a network
+// error, 429 or a 5xx status are retried with exponential backoff.
+func (p *WebhookPublisher) Publish(ctx context.Context, events []ChangeEvent) error {
+	batch := make([]CloudEvent, len(events))
+
+	for i, event := range events {
+		batch[i] = event.CloudEvent(p.opts.Source)
+	}
+
+	body, err := json.Marshal(batch)
+	if err != nil {
+		return fmt.Errorf("failed to encode events: %w", err)
+	}
+
+	backoff := p.opts.Backoff
+
+	for attempt := 0; ; attempt++ {
+		retry, err := p.post(ctx, body)
+		if err == nil || !retry || attempt == p.opts.Retries {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("webhook not delivered: %w", context.Cause(ctx))
+		case <-time.After(backoff):
+			backoff *= 2
+		}
+	}
+}
+
+// post sends body and reports whether a failure is worth retrying.
+func (p *WebhookPublisher) post(ctx context.Context, body []byte) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.opts.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+
+	req.Header.Set("Content-Type", cloudEventsBatchContentType)
+	req.Header.Set(WebhookTimestampHeader, timestamp)
+
+	if p.opts.Secret != nil {
+		req.Header.Set(WebhookSignatureHeader, signWebhook(p.opts.Secret, timestamp, body))
+	}
+
+	resp, err := p.opts.HTTPClient.Do(req)
+	if err != nil {
+		return true, fmt.Errorf("webhook request failed: %w", err)
+	}
+
+	defer resp.Body.Close()
+
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode >= http.StatusMultipleChoices {
+		retry := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= http.StatusInternalServerError
+
+		return retry, fmt.Errorf("webhook failed with %s", resp.Status) //nolint:err113 // no sentinel
+	}
+
+	return false, nil
+}
+
+// VerifyWebhookSignature reports whether signature is a valid
+// signature of a webhook request with the given timestamp and body.
+// Receivers should also reject timestamps that are too old.
+func VerifyWebhookSignature(secret []byte, timestamp, signature string, body []byte) bool {
+	return hmac.Equal([]byte(signWebhook(secret, timestamp, body)), []byte(signature))
+}
+
+func signWebhook(secret []byte, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,105 @@
+// GNU AFFERO GENERAL PUBLIC LICENSE
+// Version 3, 19 November 2007
+//
+// Copyright (C) 2025 John Kleijn
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// For more details, see the full AGPL-3.0 license at:
+// https://www.gnu.org/licenses/agpl-3.0.html
+
+package rtkv_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/johnknl/rtkv"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebhookPublisher(t *testing.T) {
+	ctx := context.Background()
+	secret := []byte("secret")
+
+	var (
+		attempts atomic.Int32
+		received []rtkv.CloudEvent
+		verified bool
+	)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if attempts.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+
+			return
+		}
+
+		body, _ := io.ReadAll(r.Body)
+		verified = rtkv.VerifyWebhookSignature(secret,
+			r.Header.Get(rtkv.WebhookTimestampHeader), r.Header.Get(rtkv.WebhookSignatureHeader), body)
+
+		_ = json.Unmarshal(body, &received)
+	}))
+
+	t.Cleanup(server.Close)
+
+	store := newRTKV(t, newGoRedisClient(0)).With(rtkv.WithChangeFeed(rtkv.ChangeFeedOptions{}))
+	publisher := rtkv.NewWebhookPublisher(rtkv.WebhookOptions{
+		URL:     server.URL,
+		Secret:  secret,
+		Source:  "/users",
+		Backoff: time.Millisecond,
+	})
+	bridge := rtkv.NewChangeBridge(store, publisher, rtkv.ChangeBridgeOptions{Group: "webhook"})
+
+	for _, id := range []string{"a", "b"} {
+		_, err := store.Set(ctx, []byte("v"), time.Now(), id)
+		require.NoError(t, err)
+	}
+
+	n, err := bridge.RunOnce(ctx)
+
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+	assert.EqualValues(t, 2, attempts.Load(), "the failed request should be retried")
+	assert.True(t, verified)
+	require.Len(t, received, 2)
+	assert.Equal(t, "b", received[1].Subject)
+}
+
+func TestWebhookPublisher_PermanentFailure(t *testing.T) {
+	var attempts atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		attempts.Add(1)
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+
+	t.Cleanup(server.Close)
+
+	publisher := rtkv.NewWebhookPublisher(rtkv.WebhookOptions{URL: server.URL, Backoff: time.Millisecond})
+
+	err := publisher.Publish(context.Background(), []rtkv.ChangeEvent{{Op: rtkv.ChangeSet, ID: []string{"a"}}})
+
+	require.ErrorContains(t, err, "400")
+	assert.EqualValues(t, 1, attempts.Load())
+}
@@ -0,0 +1,211 @@
+// GNU AFFERO GENERAL PUBLIC LICENSE
+// Version 3, 19 November 2007
+//
+// Copyright (C) 2025 John Kleijn
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// For more details, see the full AGPL-3.0 license at:
+// https://www.gnu.org/licenses/agpl-3.0.html
+
+package rtkv
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const defaultWriteConcernTimeout = time.Second
+
+var (
+	// ErrWriteConcern is returned by writes whose WriteConcern
+	// isn't satisfied. The write itself is not rolled back.
+	ErrWriteConcern = errors.New("write concern not satisfied")
+
+	// ErrWriteConcernUnsupported is returned by writes with a
+	// WriteConcern that requires replicas to acknowledge them, on
+	// stores using a Cluster or Ring client or WithSkipIdenticalWrites,
+	// and by SetFromReader with a WriteConcern that verifies.
+	ErrWriteConcernUnsupported = errors.New("write concern not supported by this store")
+)
+
+// WriteConcern sets what Set, SetWithTTL, BulkSet, SetIfNewer,
+// SetIfAbsent, CompareAndSet, GetSet, Update, UpdateMany and
+// SetFromReader check before reporting success, for writes that
+// must survive a failover. Pass one with ContextWithWriteConcern.
+type WriteConcern struct {
+	// Replicas is the number of replicas that must acknowledge
+	// the write, checked with WAIT on the connection that wrote it.
+	Replicas int
+
+	// Timeout is how long to wait for the replicas.
+	// Defaults to a second.
+	Timeout time.Duration
+
+	// Verify reads the written values back from the primary
+	// and checks they match.
+	Verify bool
+}
+
+type writeConcernCtxKey struct{}
+
+// ContextWithWriteConcern returns a context that makes
+// writes made with it honour wc.
+func ContextWithWriteConcern(ctx context.Context, wc WriteConcern) context.Context {
+	return context.WithValue(ctx, writeConcernCtxKey{}, &wc)
+}
+
+// writeConcernFrom returns the WriteConcern attached to ctx, or nil.
+func writeConcernFrom(ctx context.Context) *WriteConcern {
+	wc, _ := ctx.Value(writeConcernCtxKey{}).(*WriteConcern)
+
+	return wc
+}
+
+func (wc *WriteConcern) waits() bool {
+	return wc != nil && wc.Replicas > 0
+}
+
+func (wc *WriteConcern) verifies() bool {
+	return wc != nil && wc.Verify
+}
+
+// checkWriteConcern returns ErrWriteConcernUnsupported
+// if the store can't honour the write concern of ctx.
+func (r *RedisTKV) checkWriteConcern(ctx context.Context) error {
+	if !writeConcernFrom(ctx).waits() {
+		return nil
+	}
+
+	if _, ok := r.client.(*redis.Client); !ok || r.skipIdentical {
+		return ErrWriteConcernUnsupported
+	}
+
+	return nil
+}
+
+// writeTx runs fn in a transaction, with the write concern of ctx.
+func (r *RedisTKV) writeTx(ctx context.Context, fn func(pipe redis.Pipeliner) error) error {
+	return r.withWriteConcern(ctx, func(c redis.Cmdable) error {
+		_, err := c.TxPipelined(ctx, fn)
+
+		return err //nolint:wrapcheck // callers wrap with context
+	})
+}
+
+// writeScript runs a Lua script like evalScript, with the
+// write concern of ctx.
+func (r *RedisTKV) writeScript(ctx context.Context, src string, keys []string, args ...any) (any, error) {
+	var result any
+
+	err := r.withWriteConcern(ctx, func(c redis.Cmdable) error {
+		var err error
+
+		result, err = r.evalScriptOn(ctx, c, src, keys, args...)
+
+		return err
+	})
+
+	return result, err
+}
+
+// withWriteConcern runs fn with the client. If the write concern of
+// ctx requires replicas, fn runs on a dedicated connection instead,
+// which then waits for the replicas to acknowledge its writes.
+func (r *RedisTKV) withWriteConcern(ctx context.Context, fn func(c redis.Cmdable) error) error {
+	wc := writeConcernFrom(ctx)
+	client, ok := r.client.(*redis.Client)
+
+	if !wc.waits() || !ok {
+		return fn(r.client)
+	}
+
+	conn := client.Conn()
+	defer conn.Close()
+
+	if err := fn(conn); err != nil {
+		return err
+	}
+
+	return waitForReplicas(ctx, conn, wc)
+}
+
+// replicaWaiter is a connection bound client, such as
+// a *redis.Conn or *redis.Tx, that can WAIT for replicas.
+type replicaWaiter interface {
+	Wait(ctx context.Context, numReplicas int, timeout time.Duration) *redis.IntCmd
+}
+
+// waitForReplicas waits for the replicas required by wc to
+// acknowledge the writes made on the connection of c.
+func waitForReplicas(ctx context.Context, c replicaWaiter, wc *WriteConcern) error {
+	timeout := wc.Timeout
+	if timeout <= 0 {
+		timeout = defaultWriteConcernTimeout
+	}
+
+	acked, err := c.Wait(ctx, wc.Replicas, timeout).Result()
+	if err != nil {
+		return fmt.Errorf("failed to wait for replicas: %w", err)
+	}
+
+	if acked < int64(wc.Replicas) {
+		return fmt.Errorf("%w: %d of %d replicas acknowledged", ErrWriteConcern, acked, wc.Replicas)
+	}
+
+	return nil
+}
+
+// verifyWrites reads keys back if the write concern
+// of ctx asks for it, and checks they hold values.
+func (r *RedisTKV) verifyWrites(ctx context.Context, keys []string, values [][]byte) error {
+	if !writeConcernFrom(ctx).verifies() {
+		return nil
+	}
+
+	stored, err := r.client.MGet(ctx, keys...).Result()
+	if err != nil {
+		return fmt.Errorf("failed to read back values: %w", err)
+	}
+
+	for i, value := range stored {
+		s, ok := value.(string)
+		if !ok || !bytes.Equal([]byte(s), values[i]) {
+			return fmt.Errorf("%w: read back a different value of %v", ErrWriteConcern, r.idFromKey(keys[i]))
+		}
+	}
+
+	return nil
+}
+
+// verifyRecords verifies the writes of BulkSet.
+func (r *RedisTKV) verifyRecords(ctx context.Context, records []BulkSetRecord) error {
+	if !writeConcernFrom(ctx).verifies() {
+		return nil
+	}
+
+	keys := make([]string, len(records))
+	values := make([][]byte, len(records))
+
+	for i := range records {
+		keys[i], values[i] = r.namespacedKey(records[i].ID...), records[i].Data
+	}
+
+	return r.verifyWrites(ctx, keys, values)
+}
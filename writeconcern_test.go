@@ -0,0 +1,143 @@
+// GNU AFFERO GENERAL PUBLIC LICENSE
+// Version 3, 19 November 2007
+//
+// Copyright (C) 2025 John Kleijn
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// For more details, see the full AGPL-3.0 license at:
+// https://www.gnu.org/licenses/agpl-3.0.html
+
+package rtkv_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/johnknl/rtkv"
+	"github.com/johnknl/rtkv/rtkvtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestContextWithWriteConcern_Verify(t *testing.T) {
+	store := rtkvtest.NewMiniredisTKV(t, rtkv.WithValueCompression(rtkv.ValueZstd, 1))
+	ctx := rtkv.ContextWithWriteConcern(context.Background(), rtkv.WriteConcern{Verify: true})
+	now := time.Now()
+
+	_, err := store.Set(ctx, []byte("value"), now, "a")
+	require.NoError(t, err)
+
+	err = store.BulkSet(ctx, []rtkv.BulkSetRecord{
+		{ID: []string{"b"}, Data: []byte("b"), LastModified: now},
+		{ID: []string{"c"}, Data: []byte("c"), LastModified: now},
+	})
+	require.NoError(t, err)
+
+	value, err := store.Get(ctx, "a")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("value"), value)
+}
+
+func TestContextWithWriteConcern_Replicas(t *testing.T) {
+	store := rtkvtest.NewMiniredisTKV(t)
+	ctx := rtkv.ContextWithWriteConcern(context.Background(), rtkv.WriteConcern{
+		Replicas: 1,
+		Timeout:  10 * time.Millisecond,
+	})
+
+	_, err := store.Set(ctx, []byte("value"), time.Now(), "a")
+	require.ErrorIs(t, err, rtkv.ErrWriteConcern, "There are no replicas to acknowledge the write")
+
+	value, err := store.Get(context.Background(), "a")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("value"), value, "The write should not be rolled back")
+
+	_, err = store.With(rtkv.WithSkipIdenticalWrites()).Set(ctx, []byte("value"), time.Now(), "a")
+	require.ErrorIs(t, err, rtkv.ErrWriteConcernUnsupported)
+}
+
+func TestContextWithWriteConcern_WritePaths(t *testing.T) {
+	store := rtkvtest.NewMiniredisTKV(t, rtkv.WithVersioning())
+	verify := rtkv.ContextWithWriteConcern(context.Background(), rtkv.WriteConcern{Verify: true})
+	replicas := rtkv.ContextWithWriteConcern(context.Background(), rtkv.WriteConcern{
+		Replicas: 1,
+		Timeout:  10 * time.Millisecond,
+	})
+	now := time.Now()
+
+	writes := map[string]func(ctx context.Context) error{
+		"SetIfNewer": func(ctx context.Context) error {
+			now = now.Add(time.Second)
+			_, err := store.SetIfNewer(ctx, []byte("value"), now, "newer")
+
+			return err
+		},
+		"SetIfAbsent": func(ctx context.Context) error {
+			_, err := store.SetIfAbsent(ctx, []byte("value"), now, "absent", now.String())
+
+			return err
+		},
+		"CompareAndSet": func(ctx context.Context) error {
+			version, err := store.Version(context.Background(), "cas")
+			if err != nil {
+				return err
+			}
+
+			_, err = store.CompareAndSet(ctx, []byte("value"), version, "cas")
+
+			return err
+		},
+		"GetSet": func(ctx context.Context) error {
+			_, err := store.GetSet(ctx, []byte("value"), now, "getset")
+
+			return err
+		},
+		"Update": func(ctx context.Context) error {
+			_, err := store.Update(ctx, []string{"update"}, func(old []byte) ([]byte, error) {
+				return append(old, 'x'), nil
+			})
+
+			return err
+		},
+		"UpdateMany": func(ctx context.Context) error {
+			_, err := store.UpdateMany(ctx, [][]string{{"a"}, {"b"}}, func(_ []string, old []byte) ([]byte, bool, error) {
+				return append(old, 'x'), true, nil
+			})
+
+			return err
+		},
+	}
+
+	for name, write := range writes {
+		t.Run(name, func(t *testing.T) {
+			require.NoError(t, write(verify))
+			require.ErrorIs(t, write(replicas), rtkv.ErrWriteConcern, "There are no replicas to acknowledge the write")
+		})
+	}
+
+	t.Run("SetFromReader", func(t *testing.T) {
+		_, err := store.SetFromReader(verify, strings.NewReader("value"), now, "streamed")
+		require.ErrorIs(t, err, rtkv.ErrWriteConcernUnsupported)
+
+		_, err = store.SetFromReader(replicas, strings.NewReader("value"), now, "streamed")
+		require.ErrorIs(t, err, rtkv.ErrWriteConcern)
+
+		value, err := store.Get(context.Background(), "streamed")
+		require.NoError(t, err)
+		assert.Equal(t, []byte("value"), value, "The write should not be rolled back")
+	})
+}
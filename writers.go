@@ -0,0 +1,140 @@
+// GNU AFFERO GENERAL PUBLIC LICENSE
+// Version 3, 19 November 2007
+//
+// Copyright (C) 2025 John Kleijn
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// For more details, see the full AGPL-3.0 license at:
+// https://www.gnu.org/licenses/agpl-3.0.html
+
+package rtkv
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	writersSuffix = "writers"
+	writersLayout = "20060102"
+	writersDay    = 24 * time.Hour
+)
+
+type writerCtxKey struct{}
+
+// WithWriter returns a context that identifies the service or user
+// writing with it, for WithWriterTracking.
+func WithWriter(ctx context.Context, writer string) context.Context {
+	return context.WithValue(ctx, writerCtxKey{}, writer)
+}
+
+// Writer returns the writer identity of ctx, or an empty string.
+func Writer(ctx context.Context) string {
+	writer, _ := ctx.Value(writerCtxKey{}).(string)
+
+	return writer
+}
+
+// WriterCount is the approximate number of distinct writers on a day.
+type WriterCount struct {
+	Day     time.Time
+	Writers int64
+}
+
+// WithWriterTracking counts the distinct writers that set or delete
+// entities per day (in UTC), using a HyperLogLog per day, so it costs
+// at most 12 KB a day however many writers there are. Writers are
+// identified by WithWriter; writes without an identity are not
+// counted. Counts are kept for retain, or forever if retain is zero.
+func WithWriterTracking(retain time.Duration) Option {
+	return func(r *RedisTKV) {
+		r.writers = &retain
+	}
+}
+
+// DistinctWriters returns the approximate number of distinct writers
+// from the day of from up to and including the day of to. The
+// standard error of the approximation is 0.81%.
+func (r *RedisTKV) DistinctWriters(ctx context.Context, from, to time.Time) (int64, error) { //nolint:varnamelen // from and to are clear
+	keys := r.writerKeys(from, to)
+	if len(keys) == 0 {
+		return 0, nil
+	}
+
+	count, err := r.client.PFCount(ctx, keys...).Result()
+	if err != nil {
+		return 0, fmt.Errorf("failed to count writers: %w", err)
+	}
+
+	return count, nil
+}
+
+// DailyWriters returns the approximate number of distinct writers
+// per day, from the day of from up to and including the day of to.
+func (r *RedisTKV) DailyWriters(ctx context.Context, from, to time.Time) ([]WriterCount, error) { //nolint:varnamelen // from and to are clear
+	keys := r.writerKeys(from, to)
+	counts := make([]*redis.IntCmd, len(keys))
+
+	_, err := r.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for i, key := range keys {
+			counts[i] = pipe.PFCount(ctx, key)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to count writers: %w", err)
+	}
+
+	result := make([]WriterCount, len(keys))
+	start := from.UTC().Truncate(writersDay)
+
+	for i := range counts {
+		result[i] = WriterCount{Day: start.AddDate(0, 0, i), Writers: counts[i].Val()}
+	}
+
+	return result, nil
+}
+
+// writerAdd records the writer of ctx, if any. Call it once per
+// operation, in the pipeline that writes.
+func (r *RedisTKV) writerAdd(ctx context.Context, pipe redis.Pipeliner) {
+	writer := Writer(ctx)
+	if r.writers == nil || writer == "" {
+		return
+	}
+
+	now := time.Now().UTC()
+	key := r.internalKey(writersSuffix, now.Format(writersLayout))
+
+	pipe.PFAdd(ctx, key, writer)
+
+	if retain := *r.writers; retain > 0 {
+		pipe.ExpireAt(ctx, key, now.Truncate(writersDay).Add(writersDay+retain))
+	}
+}
+
+func (r *RedisTKV) writerKeys(from, to time.Time) []string { //nolint:varnamelen // from and to are clear
+	var keys []string
+
+	for d := from.UTC().Truncate(writersDay); !d.After(to.UTC()); d = d.AddDate(0, 0, 1) {
+		keys = append(keys, r.internalKey(writersSuffix, d.Format(writersLayout)))
+	}
+
+	return keys
+}
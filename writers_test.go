@@ -0,0 +1,74 @@
+// GNU AFFERO GENERAL PUBLIC LICENSE
+// Version 3, 19 November 2007
+//
+// Copyright (C) 2025 John Kleijn
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program. If not, see <https://www.gnu.org/licenses/>.
+//
+// For more details, see the full AGPL-3.0 license at:
+// https://www.gnu.org/licenses/agpl-3.0.html
+
+package rtkv_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/johnknl/rtkv"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithWriterTracking(t *testing.T) {
+	ctx := context.Background()
+	store := newRTKV(t, newGoRedisClient(0)).With(rtkv.WithWriterTracking(0))
+
+	for _, writer := range []string{"billing", "billing", "search", ""} {
+		_, err := store.Set(rtkv.WithWriter(ctx, writer), []byte("v"), time.Now(), "a")
+		require.NoError(t, err)
+	}
+
+	err := store.BulkSet(rtkv.WithWriter(ctx, "import"), []rtkv.BulkSetRecord{
+		{LastModified: time.Now(), ID: []string{"b"}, Data: []byte("v")},
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, store.Delete(rtkv.WithWriter(ctx, "cleanup"), "b"))
+
+	now := time.Now()
+
+	count, err := store.DistinctWriters(ctx, now, now)
+	require.NoError(t, err)
+	assert.EqualValues(t, 4, count)
+
+	daily, err := store.DailyWriters(ctx, now.AddDate(0, 0, -1), now)
+	require.NoError(t, err)
+	require.Len(t, daily, 2)
+	assert.Zero(t, daily[0].Writers)
+	assert.EqualValues(t, 4, daily[1].Writers)
+	assert.Equal(t, now.UTC().Truncate(24*time.Hour), daily[1].Day)
+}
+
+func TestWithWriterTracking_SkipIdentical(t *testing.T) {
+	ctx := rtkv.WithWriter(context.Background(), "billing")
+	store := newRTKV(t, newGoRedisClient(0)).With(rtkv.WithWriterTracking(time.Hour), rtkv.WithSkipIdenticalWrites())
+
+	_, err := store.Set(ctx, []byte("v"), time.Now(), "a")
+	require.NoError(t, err)
+
+	count, err := store.DistinctWriters(ctx, time.Now(), time.Now())
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, count)
+}